@@ -176,34 +176,6 @@ func (cm ChatMessage) MarshalJSON() ([]byte, error) {
 	return json.Marshal(aux)
 }
 
-// OpenAIRequest OpenAI API请求
-type OpenAIRequest struct {
-	Model    string        `json:"model"`
-	Messages []ChatMessage `json:"messages"`
-	Tools    []Tool        `json:"tools,omitempty"`
-	Stream   bool          `json:"stream,omitempty"`
-}
-
-// OpenAIResponse OpenAI API响应
-type OpenAIResponse struct {
-	ID      string   `json:"id"`
-	Choices []Choice `json:"choices"`
-	Error   *Error   `json:"error,omitempty"`
-}
-
-// Choice 选择
-type Choice struct {
-	Message      MessageWithTools `json:"message"`
-	FinishReason string           `json:"finish_reason"`
-}
-
-// MessageWithTools 带工具调用的消息
-type MessageWithTools struct {
-	Role      string     `json:"role"`
-	Content   string     `json:"content"`
-	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
-}
-
 // Tool OpenAI工具定义
 type Tool struct {
 	Type     string             `json:"type"`
@@ -215,12 +187,8 @@ type FunctionDefinition struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
 	Parameters  map[string]interface{} `json:"parameters"`
-}
-
-// Error OpenAI错误
-type Error struct {
-	Message string `json:"message"`
-	Type    string `json:"type"`
+	// Strict 严格模式：要求模型输出严格符合 Parameters schema（agent.tool_strict_schema 开启时设置）。
+	Strict bool `json:"strict,omitempty"`
 }
 
 // ToolCall 工具调用
@@ -332,13 +300,16 @@ func (a *Agent) getAvailableTools(roleTools []string) []Tool {
 		// 转换schema中的类型为OpenAI标准类型
 		convertedSchema := a.convertSchemaTypes(mcpTool.InputSchema)
 
+		fn := FunctionDefinition{
+			Name:        mcpTool.Name,
+			Description: description, // 使用简短描述减少token消耗
+			Parameters:  convertedSchema,
+		}
+		a.applyStrictToolSchema(&fn)
+
 		tools = append(tools, Tool{
-			Type: "function",
-			Function: FunctionDefinition{
-				Name:        mcpTool.Name,
-				Description: description, // 使用简短描述减少token消耗
-				Parameters:  convertedSchema,
-			},
+			Type:     "function",
+			Function: fn,
 		})
 	}
 
@@ -413,13 +384,16 @@ func (a *Agent) getAvailableTools(roleTools []string) []Tool {
 				// 保存名称映射关系（OpenAI格式 -> 原始格式）
 				extMap[openAIName] = externalTool.Name
 
+				fn := FunctionDefinition{
+					Name:        openAIName, // 使用符合OpenAI规范的名称
+					Description: description,
+					Parameters:  convertedSchema,
+				}
+				a.applyStrictToolSchema(&fn)
+
 				tools = append(tools, Tool{
-					Type: "function",
-					Function: FunctionDefinition{
-						Name:        openAIName, // 使用符合OpenAI规范的名称
-						Description: description,
-						Parameters:  convertedSchema,
-					},
+					Type:     "function",
+					Function: fn,
 				})
 			}
 		}
@@ -507,11 +481,59 @@ func (a *Agent) convertToOpenAIType(configType string) string {
 	}
 }
 
+// strictToolSchemaEnabled 是否开启工具参数严格模式（agent.tool_strict_schema）。
+func (a *Agent) strictToolSchemaEnabled() bool {
+	return a.agentConfig != nil && a.agentConfig.ToolStrictSchema
+}
+
+// applyStrictToolSchema 严格模式下为工具定义标记 strict:true，并在参数 schema 上追加
+// additionalProperties:false（已显式声明时不覆盖），约束模型不要编造未定义的参数名。
+func (a *Agent) applyStrictToolSchema(fn *FunctionDefinition) {
+	if !a.strictToolSchemaEnabled() {
+		return
+	}
+	fn.Strict = true
+	if fn.Parameters == nil {
+		return
+	}
+	if _, exists := fn.Parameters["additionalProperties"]; !exists {
+		fn.Parameters["additionalProperties"] = false
+	}
+}
+
 // ToolExecutionResult MCP 工具执行结果（供 Eino 桥与监控落库使用）。
 type ToolExecutionResult struct {
 	Result      string
 	ExecutionID string
 	IsError     bool
+	// StructuredResult 工具 parser 从文本输出提取的机器可读结构（可选），
+	// 见 [mcp.ParseStructuredToolResult]；攻击链与漏洞展示可直接消费。
+	StructuredResult map[string]interface{}
+}
+
+// isTransientToolError 判断工具执行失败是否为可重试的瞬时错误（端口临时不通、连接被重置等）。
+// 手动终止与执行超时不可重试；参数/语法类错误不会命中这些网络特征，同样不重试。
+func isTransientToolError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	errStr := strings.ToLower(err.Error())
+	for _, marker := range []string{
+		"connection refused",
+		"connection reset",
+		"broken pipe",
+		"i/o timeout",
+		"unexpected eof",
+		"no route to host",
+		"network is unreachable",
+		"temporarily unavailable",
+		"tls handshake timeout",
+	} {
+		if strings.Contains(errStr, marker) {
+			return true
+		}
+	}
+	return false
 }
 
 func buildToolFailureMessage(toolName, detail string, err error) string {
@@ -530,6 +552,30 @@ func (a *Agent) executeToolViaMCP(ctx context.Context, toolName string, args map
 		zap.Any("args", args),
 	)
 
+	// 严格模式：执行前按工具 schema 校验参数，非法参数直接反馈给模型修正，
+	// 而不是带着编造的参数名或缺失的必填项去执行。须在自动注入 conversation_id
+	// 等系统参数之前校验，避免注入参数被误报；外部 MCP 工具 schema 在远端，交由其自行校验。
+	if a.strictToolSchemaEnabled() {
+		a.mu.RLock()
+		_, mappedExternal := a.toolNameMapping[toolName]
+		a.mu.RUnlock()
+		if !mappedExternal {
+			if def, ok := a.mcpServer.GetTool(toolName); ok {
+				if issues := validateToolArgs(def.InputSchema, args); len(issues) > 0 {
+					a.logger.Warn("工具参数校验未通过",
+						zap.String("tool", toolName),
+						zap.Strings("issues", issues),
+					)
+					detail := "参数校验未通过：\n- " + strings.Join(issues, "\n- ") + "\n请对照工具参数 schema 修正后重新调用。"
+					return &ToolExecutionResult{
+						Result:  buildToolFailureMessage(toolName, detail, nil),
+						IsError: true,
+					}, nil
+				}
+			}
+		}
+	}
+
 	// 如果是record_vulnerability工具，自动添加conversation_id
 	if toolName == builtin.ToolRecordVulnerability {
 		conversationID := agentConversationIDFromContext(ctx)
@@ -566,21 +612,38 @@ func (a *Agent) executeToolViaMCP(ctx context.Context, toolName string, args map
 	originalToolName, isExternalTool := a.toolNameMapping[toolName]
 	a.mu.RUnlock()
 
-	if isExternalTool && a.externalMCPMgr != nil {
-		// 使用原始工具名称调用外部MCP工具
-		a.logger.Debug("调用外部MCP工具",
-			zap.String("openAIName", toolName),
-			zap.String("originalName", originalToolName),
-		)
-		result, executionID, err = a.externalMCPMgr.CallTool(toolCtx, originalToolName, args)
-	} else {
+	callTool := func() (*mcp.ToolResult, string, error) {
+		if isExternalTool && a.externalMCPMgr != nil {
+			// 使用原始工具名称调用外部MCP工具
+			a.logger.Debug("调用外部MCP工具",
+				zap.String("openAIName", toolName),
+				zap.String("originalName", originalToolName),
+			)
+			return a.externalMCPMgr.CallTool(toolCtx, originalToolName, args)
+		}
 		// 调用内部MCP工具
-		result, executionID, err = a.mcpServer.CallTool(toolCtx, toolName, args)
+		return a.mcpServer.CallTool(toolCtx, toolName, args)
+	}
+
+	result, executionID, err = callTool()
+
+	// 瞬时失败（连接重置、端口临时不通等）自动重试一次；手动终止与执行超时不重试
+	retried := false
+	if err != nil && toolCtx.Err() == nil && isTransientToolError(err) {
+		a.logger.Warn("工具执行瞬时失败，自动重试一次",
+			zap.String("tool", toolName),
+			zap.Error(err),
+		)
+		retried = true
+		result, executionID, err = callTool()
 	}
 
 	// 如果调用失败（如工具不存在、超时），返回友好的错误信息而不是抛出异常
 	if err != nil {
 		detail := err.Error()
+		if retried {
+			detail += "（瞬时失败已自动重试 1 次仍失败）"
+		}
 		timeoutMinutes := 10
 		if a.agentConfig != nil && a.agentConfig.ToolTimeoutMinutes > 0 {
 			timeoutMinutes = a.agentConfig.ToolTimeoutMinutes
@@ -607,11 +670,15 @@ func (a *Agent) executeToolViaMCP(ctx context.Context, toolName string, args map
 	}
 
 	resultStr := resultText.String()
+	if retried {
+		resultStr = "（提示：首次调用瞬时失败，已自动重试成功）\n" + resultStr
+	}
 
 	return &ToolExecutionResult{
-		Result:      resultStr,
-		ExecutionID: executionID,
-		IsError:     result != nil && result.IsError,
+		Result:           resultStr,
+		ExecutionID:      executionID,
+		IsError:          result != nil && result.IsError,
+		StructuredResult: mcp.ParseStructuredToolResult(toolName, result),
 	}, nil
 }
 
@@ -650,14 +717,14 @@ func (a *Agent) UpdateToolDescriptionMode(mode string) {
 }
 
 // RepairOrphanToolMessages 清理失去配对的tool消息和未完成的tool_calls，避免OpenAI报错
-// 同时确保历史消息中的tool_calls只作为上下文记忆，不会触发重新执行
+// 同时纠正角色大小写、丢弃非法角色的消息，并确保历史消息中的tool_calls只作为上下文记忆，不会触发重新执行
 // 这是一个公开方法，可以在恢复历史消息时调用
 func (a *Agent) RepairOrphanToolMessages(messages *[]ChatMessage) bool {
 	return a.repairOrphanToolMessages(messages)
 }
 
 // repairOrphanToolMessages 清理失去配对的tool消息和未完成的tool_calls，避免OpenAI报错
-// 同时确保历史消息中的tool_calls只作为上下文记忆，不会触发重新执行
+// 同时纠正角色大小写、丢弃非法角色的消息，并确保历史消息中的tool_calls只作为上下文记忆，不会触发重新执行
 func (a *Agent) repairOrphanToolMessages(messages *[]ChatMessage) bool {
 	if messages == nil {
 		return false
@@ -673,7 +740,13 @@ func (a *Agent) repairOrphanToolMessages(messages *[]ChatMessage) bool {
 	removed := false
 
 	for _, msg := range msgs {
-		switch strings.ToLower(msg.Role) {
+		role := strings.ToLower(strings.TrimSpace(msg.Role))
+		if role != msg.Role {
+			// 纠正大小写/空白差异（上游 API 只接受小写角色）
+			msg.Role = role
+			removed = true
+		}
+		switch role {
 		case "assistant":
 			if len(msg.ToolCalls) > 0 {
 				// 记录所有tool_call IDs
@@ -701,8 +774,13 @@ func (a *Agent) repairOrphanToolMessages(messages *[]ChatMessage) bool {
 				removed = true
 				continue
 			}
-		default:
+		case "user", "system":
 			cleaned = append(cleaned, msg)
+		default:
+			// 非法角色（历史脏数据或拼写错误）直接丢弃，避免发给上游 API 报错
+			a.logger.Warn("丢弃历史中非法角色的消息", zap.String("role", msg.Role))
+			removed = true
+			continue
 		}
 	}
 
@@ -0,0 +1,42 @@
+package agent
+
+import "testing"
+
+func TestRepairOrphanToolMessagesSanitizesRoles(t *testing.T) {
+	agent := setupTestAgent(t)
+	messages := []ChatMessage{
+		{Role: "User", Content: "扫描目标"},
+		{Role: "hacker", Content: "脏数据"},
+		{Role: "assistant", Content: "收到"},
+	}
+
+	fixed := agent.RepairOrphanToolMessages(&messages)
+	if !fixed {
+		t.Fatalf("存在非法角色时应返回 true")
+	}
+	if len(messages) != 2 {
+		t.Fatalf("messages = %d, want 2（非法角色应被丢弃）: %+v", len(messages), messages)
+	}
+	if messages[0].Role != "user" {
+		t.Fatalf("角色大小写应被纠正: %q", messages[0].Role)
+	}
+	if messages[1].Role != "assistant" {
+		t.Fatalf("messages[1].Role = %q", messages[1].Role)
+	}
+}
+
+func TestRepairOrphanToolMessagesKeepsValidRoles(t *testing.T) {
+	agent := setupTestAgent(t)
+	messages := []ChatMessage{
+		{Role: "system", Content: "你是渗透测试助手"},
+		{Role: "user", Content: "开始"},
+		{Role: "assistant", Content: "好的"},
+	}
+
+	if fixed := agent.RepairOrphanToolMessages(&messages); fixed {
+		t.Fatalf("正常历史不应被修改: %+v", messages)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("messages = %d, want 3", len(messages))
+	}
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// validateToolArgs 按工具 InputSchema 校验模型产出的 args（严格模式，见 agent.tool_strict_schema）。
+// 检查三类问题：缺失的必填参数、schema 未定义的参数名、与声明类型不符的参数值。
+// 返回问题列表，空切片表示校验通过；schema 为空或不含 properties 时不做校验。
+func validateToolArgs(schema map[string]interface{}, args map[string]interface{}) []string {
+	if schema == nil {
+		return nil
+	}
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var issues []string
+
+	// 必填项检查
+	for _, name := range schemaRequiredFields(schema) {
+		value, exists := args[name]
+		if !exists || value == nil {
+			issues = append(issues, fmt.Sprintf("缺少必填参数 %q", name))
+		}
+	}
+
+	// 未知参数与类型检查（按参数名排序，保证反馈信息稳定）
+	argNames := make([]string, 0, len(args))
+	for name := range args {
+		argNames = append(argNames, name)
+	}
+	sort.Strings(argNames)
+
+	for _, name := range argNames {
+		propValue, exists := properties[name]
+		if !exists {
+			issues = append(issues, fmt.Sprintf("参数 %q 未在工具 schema 中定义", name))
+			continue
+		}
+		prop, ok := propValue.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		declaredType, _ := prop["type"].(string)
+		value := args[name]
+		if value == nil || declaredType == "" {
+			continue
+		}
+		if !argMatchesSchemaType(value, declaredType) {
+			issues = append(issues, fmt.Sprintf("参数 %q 类型应为 %s，实际为 %T", name, declaredType, value))
+		}
+	}
+
+	return issues
+}
+
+// schemaRequiredFields 读取 schema 中的 required 字段；兼容 []string（Go 字面量注册）
+// 与 []interface{}（JSON 反序列化）两种形式。
+func schemaRequiredFields(schema map[string]interface{}) []string {
+	switch required := schema["required"].(type) {
+	case []string:
+		return required
+	case []interface{}:
+		fields := make([]string, 0, len(required))
+		for _, item := range required {
+			if name, ok := item.(string); ok {
+				fields = append(fields, name)
+			}
+		}
+		return fields
+	default:
+		return nil
+	}
+}
+
+// argMatchesSchemaType 判断参数值是否符合声明类型；类型名兼容工具配置写法
+// （bool/int/float 等，见 convertToOpenAIType）与 JSON Schema 标准写法。
+func argMatchesSchemaType(value interface{}, declaredType string) bool {
+	switch strings.ToLower(strings.TrimSpace(declaredType)) {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "bool", "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "int", "integer", "float", "double", "number":
+		switch value.(type) {
+		case float64, float32, int, int32, int64:
+			return true
+		}
+		return false
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	default:
+		// 未知类型不做校验，避免误伤
+		return true
+	}
+}
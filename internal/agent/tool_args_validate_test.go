@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func testToolSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"target": map[string]interface{}{
+				"type":        "string",
+				"description": "目标地址",
+			},
+			"timeout": map[string]interface{}{
+				"type":        "int",
+				"description": "超时秒数",
+			},
+			"verbose": map[string]interface{}{
+				"type":        "bool",
+				"description": "详细输出",
+			},
+		},
+		"required": []string{"target"},
+	}
+}
+
+func TestValidateToolArgsValid(t *testing.T) {
+	args := map[string]interface{}{
+		"target":  "1.1.1.1",
+		"timeout": float64(30), // JSON 反序列化后数字为 float64
+		"verbose": true,
+	}
+	if issues := validateToolArgs(testToolSchema(), args); len(issues) != 0 {
+		t.Fatalf("expected no issues, got %v", issues)
+	}
+}
+
+func TestValidateToolArgsMissingRequired(t *testing.T) {
+	issues := validateToolArgs(testToolSchema(), map[string]interface{}{"timeout": float64(30)})
+	if len(issues) != 1 || !strings.Contains(issues[0], "target") {
+		t.Fatalf("expected missing required target, got %v", issues)
+	}
+}
+
+func TestValidateToolArgsUnknownAndTypeMismatch(t *testing.T) {
+	args := map[string]interface{}{
+		"target":  "1.1.1.1",
+		"timeout": "fast", // 类型不符
+		"made_up": "x",    // schema 未定义
+		"verbose": false,
+	}
+	issues := validateToolArgs(testToolSchema(), args)
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %v", issues)
+	}
+	joined := strings.Join(issues, "\n")
+	if !strings.Contains(joined, "made_up") || !strings.Contains(joined, "timeout") {
+		t.Fatalf("unexpected issues: %v", issues)
+	}
+}
+
+func TestValidateToolArgsRequiredInterfaceSlice(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string"},
+		},
+		"required": []interface{}{"query"},
+	}
+	issues := validateToolArgs(schema, map[string]interface{}{})
+	if len(issues) != 1 || !strings.Contains(issues[0], "query") {
+		t.Fatalf("expected missing required query, got %v", issues)
+	}
+}
+
+func TestValidateToolArgsNoPropertiesSkipsValidation(t *testing.T) {
+	if issues := validateToolArgs(map[string]interface{}{"type": "object"}, map[string]interface{}{"x": 1}); len(issues) != 0 {
+		t.Fatalf("expected no issues without properties, got %v", issues)
+	}
+}
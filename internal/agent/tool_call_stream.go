@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"cyberstrike-ai/internal/openai"
+)
+
+// 流式 tool_calls 增量合并：OpenAI 流式返回时 function.arguments 是跨多个 chunk
+// 增量传来的字符串片段（任意位置都可能被切开，包括多字节字符与转义序列中间），
+// 同一 index 的片段按到达顺序拼接完整后才是合法 JSON。这里提供按 index 聚合的
+// 累加器，结束后一次性解析为 ToolCall（arguments 解析语义与 FunctionCall.UnmarshalJSON 一致）。
+
+// ToolCallStreamDelta 单个 SSE chunk 中 delta.tool_calls 的一项。
+// 除 Index 外其余字段在多数 chunk 中为空：id/type/name 只在首个片段出现，
+// Arguments 为本次的增量片段。
+type ToolCallStreamDelta struct {
+	Index     int
+	ID        string
+	Type      string
+	Name      string
+	Arguments string
+}
+
+type toolCallStreamAccum struct {
+	id   string
+	typ  string
+	name string
+	args strings.Builder
+}
+
+// ToolCallStreamAccumulator 把跨 chunk 的 tool_calls 增量按 index 聚合。
+type ToolCallStreamAccumulator struct {
+	accums map[int]*toolCallStreamAccum
+}
+
+// NewToolCallStreamAccumulator 创建空累加器。
+func NewToolCallStreamAccumulator() *ToolCallStreamAccumulator {
+	return &ToolCallStreamAccumulator{accums: make(map[int]*toolCallStreamAccum)}
+}
+
+// Add 合并一条增量：id/type/name 取首个非空值，arguments 片段按到达顺序拼接。
+func (a *ToolCallStreamAccumulator) Add(delta ToolCallStreamDelta) {
+	acc, ok := a.accums[delta.Index]
+	if !ok {
+		acc = &toolCallStreamAccum{}
+		a.accums[delta.Index] = acc
+	}
+	if delta.ID != "" && acc.id == "" {
+		acc.id = delta.ID
+	}
+	if delta.Type != "" && acc.typ == "" {
+		acc.typ = delta.Type
+	}
+	if delta.Name != "" && acc.name == "" {
+		acc.name = delta.Name
+	}
+	if delta.Arguments != "" {
+		acc.args.WriteString(delta.Arguments)
+	}
+}
+
+// ToolCalls 按 index 升序输出完整的工具调用；arguments 拼接后解析为 map。
+func (a *ToolCallStreamAccumulator) ToolCalls() []ToolCall {
+	indices := make([]int, 0, len(a.accums))
+	for idx := range a.accums {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	calls := make([]ToolCall, 0, len(indices))
+	for _, idx := range indices {
+		acc := a.accums[idx]
+		tc := ToolCall{
+			ID:   acc.id,
+			Type: acc.typ,
+			Function: FunctionCall{
+				Name:      acc.name,
+				Arguments: parseStreamedToolArguments(acc.args.String()),
+			},
+		}
+		if tc.ID == "" {
+			// 个别网关流式下不回传 id；tool 消息配对需要非空 id
+			tc.ID = fmt.Sprintf("call_stream_%d", idx)
+		}
+		if tc.Type == "" {
+			tc.Type = "function"
+		}
+		calls = append(calls, tc)
+	}
+	return calls
+}
+
+// parseStreamedToolArguments 拼接完成的 arguments 字符串解析为 map；
+// 空串视为无参数，非法 JSON 落入 raw 字段（与 FunctionCall.UnmarshalJSON 的回退一致）。
+func parseStreamedToolArguments(argsStr string) map[string]interface{} {
+	s := strings.TrimSpace(argsStr)
+	if s == "" {
+		return make(map[string]interface{})
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &parsed); err != nil || parsed == nil {
+		return map[string]interface{}{"raw": argsStr}
+	}
+	return parsed
+}
+
+// ToolCallsFromStream 把 openai 层累积好的 StreamToolCall 转成可执行的 ToolCall
+// （arguments 字符串在此处统一解析）。
+func ToolCallsFromStream(streamCalls []openai.StreamToolCall) []ToolCall {
+	acc := NewToolCallStreamAccumulator()
+	for _, sc := range streamCalls {
+		acc.Add(ToolCallStreamDelta{
+			Index:     sc.Index,
+			ID:        sc.ID,
+			Type:      sc.Type,
+			Name:      sc.FunctionName,
+			Arguments: sc.FunctionArgsStr,
+		})
+	}
+	return acc.ToolCalls()
+}
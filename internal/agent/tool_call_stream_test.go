@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"testing"
+
+	"cyberstrike-ai/internal/openai"
+)
+
+func TestToolCallStreamAccumulator_SplitBoundaries(t *testing.T) {
+	tests := []struct {
+		name      string
+		fragments []string
+		wantArgs  map[string]interface{}
+	}{
+		{
+			name:      "按键名中间切分",
+			fragments: []string{`{"com`, `mand":"ls`, ` -la"}`},
+			wantArgs:  map[string]interface{}{"command": "ls -la"},
+		},
+		{
+			name:      "转义序列中间切分",
+			fragments: []string{`{"path":"C:\`, `\tmp"}`},
+			wantArgs:  map[string]interface{}{"path": `C:\tmp`},
+		},
+		{
+			name: "多字节字符中间切分",
+			fragments: []string{
+				`{"query":"`, string([]byte{0xE6, 0xB3}), string([]byte{0xA8}), `入"}`,
+			},
+			wantArgs: map[string]interface{}{"query": "注入"},
+		},
+		{
+			name:      "逐字符切分",
+			fragments: []string{`{`, `"`, `a`, `"`, `:`, `1`, `}`},
+			wantArgs:  map[string]interface{}{"a": float64(1)},
+		},
+		{
+			name:      "单片完整到达",
+			fragments: []string{`{"target":"10.0.0.1"}`},
+			wantArgs:  map[string]interface{}{"target": "10.0.0.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := NewToolCallStreamAccumulator()
+			acc.Add(ToolCallStreamDelta{Index: 0, ID: "call_1", Type: "function", Name: "exec"})
+			for _, frag := range tt.fragments {
+				acc.Add(ToolCallStreamDelta{Index: 0, Arguments: frag})
+			}
+			calls := acc.ToolCalls()
+			if len(calls) != 1 {
+				t.Fatalf("calls = %d, want 1", len(calls))
+			}
+			tc := calls[0]
+			if tc.ID != "call_1" || tc.Type != "function" || tc.Function.Name != "exec" {
+				t.Fatalf("元数据不符: %+v", tc)
+			}
+			if len(tc.Function.Arguments) != len(tt.wantArgs) {
+				t.Fatalf("arguments = %v, want %v", tc.Function.Arguments, tt.wantArgs)
+			}
+			for k, want := range tt.wantArgs {
+				if got := tc.Function.Arguments[k]; got != want {
+					t.Fatalf("arguments[%q] = %v, want %v", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestToolCallStreamAccumulator_InterleavedIndexes(t *testing.T) {
+	// 并行 tool_calls：不同 index 的片段交错到达，各自独立拼接，输出按 index 升序
+	acc := NewToolCallStreamAccumulator()
+	acc.Add(ToolCallStreamDelta{Index: 1, ID: "call_b", Name: "nmap_scan", Arguments: `{"tar`})
+	acc.Add(ToolCallStreamDelta{Index: 0, ID: "call_a", Name: "exec", Arguments: `{"comm`})
+	acc.Add(ToolCallStreamDelta{Index: 1, Arguments: `get":"example.com"}`})
+	acc.Add(ToolCallStreamDelta{Index: 0, Arguments: `and":"id"}`})
+
+	calls := acc.ToolCalls()
+	if len(calls) != 2 {
+		t.Fatalf("calls = %d, want 2", len(calls))
+	}
+	if calls[0].ID != "call_a" || calls[0].Function.Arguments["command"] != "id" {
+		t.Fatalf("index 0 聚合错误: %+v", calls[0])
+	}
+	if calls[1].ID != "call_b" || calls[1].Function.Arguments["target"] != "example.com" {
+		t.Fatalf("index 1 聚合错误: %+v", calls[1])
+	}
+}
+
+func TestToolCallStreamAccumulator_Fallbacks(t *testing.T) {
+	acc := NewToolCallStreamAccumulator()
+	// 无 id/type、空 arguments
+	acc.Add(ToolCallStreamDelta{Index: 0, Name: "list_tools"})
+	// 拼接后仍非法的 JSON
+	acc.Add(ToolCallStreamDelta{Index: 1, ID: "call_x", Name: "exec", Arguments: `{"command":"ls`})
+
+	calls := acc.ToolCalls()
+	if len(calls) != 2 {
+		t.Fatalf("calls = %d, want 2", len(calls))
+	}
+	if calls[0].ID == "" || calls[0].Type != "function" {
+		t.Fatalf("缺省 id/type 未补齐: %+v", calls[0])
+	}
+	if len(calls[0].Function.Arguments) != 0 {
+		t.Fatalf("空 arguments 应为空 map: %v", calls[0].Function.Arguments)
+	}
+	raw, ok := calls[1].Function.Arguments["raw"].(string)
+	if !ok || raw != `{"command":"ls` {
+		t.Fatalf("非法 JSON 应落入 raw: %v", calls[1].Function.Arguments)
+	}
+}
+
+func TestToolCallsFromStream(t *testing.T) {
+	calls := ToolCallsFromStream([]openai.StreamToolCall{
+		{Index: 0, ID: "call_1", Type: "function", FunctionName: "exec", FunctionArgsStr: `{"command":"whoami"}`},
+	})
+	if len(calls) != 1 {
+		t.Fatalf("calls = %d, want 1", len(calls))
+	}
+	if calls[0].Function.Name != "exec" || calls[0].Function.Arguments["command"] != "whoami" {
+		t.Fatalf("转换错误: %+v", calls[0])
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"cyberstrike-ai/internal/mcp/builtin"
 	"cyberstrike-ai/internal/monitor"
 	"cyberstrike-ai/internal/multiagent"
+	"cyberstrike-ai/internal/project"
 	"cyberstrike-ai/internal/robot"
 	"cyberstrike-ai/internal/security"
 	"cyberstrike-ai/internal/skillpackage"
@@ -84,7 +85,9 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 	router := gin.Default()
 
 	// CORS中间件
-	router.Use(corsMiddleware(cfg.Server.CORSAllowedOrigins))
+	router.Use(corsMiddleware(cfg.Server.CORSAllowedOrigins, cfg.Server.CORSAllowedHeaders, cfg.Server.CORSAllowedMethods))
+	// 请求体大小限制（防止超大导入/查询拖垮服务）
+	router.Use(maxRequestBodyMiddleware(cfg.Server.MaxRequestBodyBytesEffective()))
 
 	// 初始化数据库
 	dbPath := cfg.Database.Path
@@ -97,13 +100,21 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 		return nil, fmt.Errorf("创建数据库目录失败: %w", err)
 	}
 
-	db, err := database.NewDB(dbPath, log.Logger)
+	// 数据库后端：默认 sqlite；driver=postgres 时走 PG（当前覆盖会话/消息核心功能）
+	var db *database.DB
+	var err error
+	if strings.EqualFold(strings.TrimSpace(cfg.Database.Driver), database.DriverPostgres) {
+		db, err = database.NewPostgresDB(cfg.Database.DSN, log.Logger)
+	} else {
+		db, err = database.NewDB(dbPath, log.Logger)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("初始化数据库失败: %w", err)
 	}
 
 	// 认证管理器（数据库初始化后挂载 RBAC）
 	authManager := security.NewAuthManager(cfg.Auth.SessionDurationHours)
+	authManager.ConfigureAPITokens(cfg.Auth.APITokens)
 	if generatedPassword, err := authManager.AttachRBACStore(db); err != nil {
 		return nil, fmt.Errorf("初始化RBAC失败: %w", err)
 	} else if generatedPassword != "" {
@@ -133,6 +144,23 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 		}
 	}()
 
+	if cfg.Database.Backup.Enabled {
+		backupDir := cfg.Database.Backup.DirEffective(cfg.Database.Path)
+		backupKeep := cfg.Database.Backup.KeepEffective()
+		backupInterval := time.Duration(cfg.Database.Backup.IntervalHoursEffective()) * time.Hour
+		go func() {
+			ticker := time.NewTicker(backupInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				if file, err := db.BackupTo(backupDir, backupKeep); err != nil {
+					log.Logger.Warn("数据库定期备份失败", zap.Error(err))
+				} else {
+					log.Logger.Info("数据库定期备份完成", zap.String("file", file))
+				}
+			}
+		}()
+	}
+
 	monitorRetention := monitor.NewService(db, cfg, log.Logger)
 	monitorRetention.PurgeExpired()
 	monitor.StartRetentionLoop(monitorRetention, log.Logger)
@@ -151,12 +179,28 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 	mcpServer.ConfigureToolWaitTimeoutSeconds(cfg.Agent.ToolWaitTimeoutSeconds)
 	mcpServer.ConfigureToolResultMaxBytes(cfg.MultiAgent.EinoMiddleware.ReductionMaxLengthForTruncEffective())
 	mcpServer.ConfigureToolResultSpillRoot(cfg.MultiAgent.EinoMiddleware.ReductionRootDir)
+	mcpServer.ConfigureSensitiveRedaction(cfg.Security.SensitiveRedaction.Enabled, cfg.Security.SensitiveRedaction.ExtraPatterns)
 
 	// 创建安全工具执行器
 	executor := security.NewExecutor(&cfg.Security, mcpServer, log.Logger)
 	executor.SetShellNoOutputTimeoutSeconds(cfg.Agent.ShellNoOutputTimeoutSeconds)
 	executor.SetToolOutputMaxBytes(cfg.MultiAgent.EinoMiddleware.ReductionMaxLengthForTruncEffective())
 	executor.SetToolOutputSpillRoot(cfg.MultiAgent.EinoMiddleware.ReductionRootDir)
+	security.SetToolResourceLimits(cfg.Agent.ToolMaxOutputBytes, cfg.Agent.ToolMaxCPUSeconds)
+	// 启动时检查启用工具的命令是否都在 PATH 中，缺失的逐个告警（调用时也会直接返回未安装提示）
+	security.LogMissingTools(cfg.Security.Tools, log.Logger)
+	// http_request 内置工具按当前对话绑定项目的 scope_json 做目标范围校验；未绑定项目时不限制。
+	executor.SetHTTPRequestScopeChecker(func(ctx context.Context, host string) error {
+		projectID := strings.TrimSpace(mcp.MCPProjectIDFromContext(ctx))
+		if projectID == "" {
+			return nil
+		}
+		proj, err := db.GetProject(projectID)
+		if err != nil || proj == nil {
+			return nil
+		}
+		return project.CheckTargetInScope(proj.ScopeJSON, host)
+	})
 
 	// 注册工具
 	executor.RegisterTools(mcpServer)
@@ -169,10 +213,11 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 
 	// 创建外部MCP管理器（使用与内部MCP服务器相同的存储）
 	externalMCPMgr := mcp.NewExternalMCPManagerWithStorage(log.Logger, db)
-	externalMCPMgr.SetToolAuthorizer(externalMCPToolAuthorizer())
+	externalMCPMgr.SetToolAuthorizer(externalMCPToolAuthorizer(db))
 	externalMCPMgr.ConfigureToolWaitTimeoutSeconds(cfg.Agent.ToolWaitTimeoutSeconds)
 	externalMCPMgr.ConfigureToolResultMaxBytes(cfg.MultiAgent.EinoMiddleware.ReductionMaxLengthForTruncEffective())
 	externalMCPMgr.ConfigureToolResultSpillRoot(cfg.MultiAgent.EinoMiddleware.ReductionRootDir)
+	externalMCPMgr.ConfigureSensitiveRedaction(cfg.Security.SensitiveRedaction.Enabled, cfg.Security.SensitiveRedaction.ExtraPatterns)
 	externalMCPMgr.ConfigureResilience(mcp.ExternalMCPResilienceConfig{
 		MaxConcurrentPerServer:  cfg.Agent.ExternalMCPMaxConcurrentPerServer,
 		MaxConcurrentTotal:      cfg.Agent.ExternalMCPMaxConcurrentTotal,
@@ -233,16 +278,11 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 
 		// 创建知识库管理器
 		knowledgeManager = knowledge.NewManager(knowledgeDB, cfg.Knowledge.BasePath, log.Logger)
+		knowledgeManager.SetIndexingLimits(&cfg.Knowledge.Indexing)
 
 		// 创建嵌入器
 		// 使用OpenAI配置的API Key（如果知识库配置中没有指定）
-		if cfg.Knowledge.Embedding.APIKey == "" {
-			cfg.Knowledge.Embedding.APIKey = cfg.OpenAI.APIKey
-		}
-		if cfg.Knowledge.Embedding.BaseURL == "" {
-			cfg.Knowledge.Embedding.BaseURL = cfg.OpenAI.BaseURL
-		}
-
+		// key/base_url 的回退由 NewEmbedder 显式处理（带告警日志），段完全缺失时直接报错
 		embedder, err := knowledge.NewEmbedder(context.Background(), &cfg.Knowledge, &cfg.OpenAI, log.Logger)
 		if err != nil {
 			return nil, fmt.Errorf("初始化知识库嵌入器失败: %w", err)
@@ -360,6 +400,7 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 	reductionRoot := strings.TrimSpace(cfg.MultiAgent.EinoMiddleware.ReductionRootDir)
 	workspaceRoot := strings.TrimSpace(cfg.Agent.WorkspaceRootDir)
 	db.SetEinoConversationDirs(plantaskBase, checkpointBase, reductionRoot, workspaceRoot)
+	db.SetRowSpillMaxBytes(cfg.Database.MaxRowBytes)
 	agent.SetPromptBaseDir(configDir)
 
 	agentsDir := cfg.AgentsDir
@@ -384,6 +425,10 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 	if knowledgeManager != nil {
 		agentHandler.SetKnowledgeManager(knowledgeManager)
 	}
+	// 知识库检索器用于 RAG 自动前置注入（knowledge.retrieval.auto_inject）
+	if knowledgeRetriever != nil {
+		agentHandler.SetKnowledgeRetriever(knowledgeRetriever)
+	}
 	monitorHandler := handler.NewMonitorHandler(mcpServer, executor, db, log.Logger)
 	monitorHandler.SetAudit(auditSvc)
 	monitorHandler.SetMonitorRetention(monitorRetention)
@@ -396,7 +441,9 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 	authHandler.SetAudit(auditSvc)
 	attackChainHandler := handler.NewAttackChainHandler(db, &cfg.OpenAI, log.Logger)
 	vulnerabilityHandler := handler.NewVulnerabilityHandler(db, log.Logger)
+	reportHandler := handler.NewReportHandler(db, log.Logger)
 	assetHandler := handler.NewAssetHandler(db, log.Logger)
+	targetViewHandler := handler.NewTargetViewHandler(db, log.Logger)
 	projectHandler := handler.NewProjectHandler(db, log.Logger)
 	rbacHandler := handler.NewRBACHandler(db, log.Logger)
 	rbacHandler.SetAudit(auditSvc)
@@ -409,6 +456,15 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 	webshellHandler.SetAudit(auditSvc)
 	chatUploadsHandler := handler.NewChatUploadsHandler(log.Logger, db)
 	chatUploadsHandler.SetAudit(auditSvc)
+	chatUploadsHandler.SetUploadPolicy(&cfg.Security)
+	chatUploadsHandler.PurgeExpiredUploads()
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			chatUploadsHandler.PurgeExpiredUploads()
+		}
+	}()
 	registerWebshellTools(mcpServer, db, webshellHandler, log.Logger)
 	registerWebshellManagementTools(mcpServer, db, webshellHandler, log.Logger)
 	configHandler := handler.NewConfigHandler(configPath, cfg, mcpServer, executor, agent, attackChainHandler, externalMCPMgr, log.Logger)
@@ -425,6 +481,11 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 	skillsHandler.SetAudit(auditSvc)
 	fofaHandler := handler.NewFofaHandler(cfg, log.Logger)
 	terminalHandler := handler.NewTerminalHandler(log.Logger)
+	databaseBackupHandler := handler.NewDatabaseBackupHandler(db,
+		cfg.Database.Backup.DirEffective(cfg.Database.Path),
+		cfg.Database.Backup.KeepEffective(),
+		log.Logger)
+	databaseBackupHandler.SetAudit(auditSvc)
 	if db != nil {
 		skillsHandler.SetDB(db) // 设置数据库连接以便获取调用统计
 	}
@@ -572,7 +633,9 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 		attackChainHandler,
 		app, // 传递 App 实例以便动态获取 knowledgeHandler
 		vulnerabilityHandler,
+		reportHandler,
 		assetHandler,
+		targetViewHandler,
 		projectHandler,
 		workflowHandler,
 		webshellHandler,
@@ -582,6 +645,7 @@ func New(cfg *config.Config, log *logger.Logger, configPath string) (*App, error
 		markdownAgentsHandler,
 		fofaHandler,
 		terminalHandler,
+		databaseBackupHandler,
 		app.c2Handler,
 		auditHandler,
 		auditSvc,
@@ -657,6 +721,10 @@ func (a *App) RunWithContext(ctx context.Context) error {
 	}
 
 	srv := &http.Server{Addr: addr, Handler: a.router}
+	if seconds := a.config.Server.ReadTimeoutSecondsEffective(); seconds > 0 {
+		// 只限制读取请求（含 body）；不设置 WriteTimeout，避免切断 SSE 等长响应
+		srv.ReadTimeout = time.Duration(seconds) * time.Second
+	}
 	var mainMux *mainServerMux
 	httpRedirect := config.ServerHTTPRedirectEnabled(&a.config.Server)
 	if tlsMode != mainTLSOff {
@@ -875,7 +943,9 @@ func setupRoutes(
 	attackChainHandler *handler.AttackChainHandler,
 	app *App, // 传递 App 实例以便动态获取 knowledgeHandler
 	vulnerabilityHandler *handler.VulnerabilityHandler,
+	reportHandler *handler.ReportHandler,
 	assetHandler *handler.AssetHandler,
+	targetViewHandler *handler.TargetViewHandler,
 	projectHandler *handler.ProjectHandler,
 	workflowHandler *handler.WorkflowHandler,
 	webshellHandler *handler.WebShellHandler,
@@ -885,6 +955,7 @@ func setupRoutes(
 	markdownAgentsHandler *handler.MarkdownAgentsHandler,
 	fofaHandler *handler.FofaHandler,
 	terminalHandler *handler.TerminalHandler,
+	databaseBackupHandler *handler.DatabaseBackupHandler,
 	c2Handler *handler.C2Handler,
 	auditHandler *handler.AuditHandler,
 	auditSvc *audit.Service,
@@ -977,6 +1048,7 @@ func setupRoutes(
 		protected.PUT("/hitl/audit-strategy", agentHandler.UpdateHITLAuditStrategy)
 		// Agent Loop 取消与任务列表
 		protected.POST("/agent-loop/cancel", agentHandler.CancelAgentLoop)
+		protected.GET("/agent-loop/ws", agentHandler.AgentLoopWS)
 		protected.GET("/agent-loop/tasks", agentHandler.ListAgentTasks)
 		protected.GET("/agent-loop/task-events", agentHandler.SubscribeAgentTaskEvents)
 		protected.GET("/agent-loop/tasks/completed", agentHandler.ListCompletedTasks)
@@ -995,8 +1067,12 @@ func setupRoutes(
 		protected.POST("/fofa/search", fofaHandler.Search)
 		// 信息收集 - 自然语言解析为 FOFA 语法（需人工确认后再查询）
 		protected.POST("/fofa/parse", fofaHandler.ParseNaturalLanguage)
+		// 信息收集 - 统一测绘入口（provider 走 query 参数，请求体与 /fofa/search 一致）
+		protected.POST("/asset-search", fofaHandler.AssetSearch)
 
 		// 资产管理
+		protected.GET("/targets/:host", targetViewHandler.GetTarget)
+		protected.GET("/targets/:host/diff", targetViewHandler.GetTargetDiff)
 		protected.GET("/assets", assetHandler.List)
 		protected.GET("/assets/selection", assetHandler.Selection)
 		protected.GET("/assets/stats", assetHandler.Stats)
@@ -1029,11 +1105,18 @@ func setupRoutes(
 		protected.POST("/conversations", conversationHandler.CreateConversation)
 		protected.GET("/conversations", conversationHandler.ListConversations)
 		protected.GET("/conversations/:id", conversationHandler.GetConversation)
+		protected.GET("/conversations/:id/executions", conversationHandler.GetConversationExecutions)
+		protected.GET("/conversations/:id/timeline", conversationHandler.GetConversationTimeline)
 		protected.GET("/messages/:id/process-details", conversationHandler.GetMessageProcessDetails)
 		protected.GET("/process-details/:id", conversationHandler.GetProcessDetail)
 		protected.PUT("/conversations/:id", conversationHandler.UpdateConversation)
 		protected.PUT("/conversations/:id/project", conversationHandler.SetConversationProject)
+		protected.GET("/conversations/:id/targets", conversationHandler.GetConversationTargets)
+		protected.PUT("/conversations/:id/targets", conversationHandler.SetConversationTargets)
 		protected.DELETE("/conversations/:id", conversationHandler.DeleteConversation)
+		protected.POST("/conversations/batch-delete", conversationHandler.BatchDeleteConversations)
+		protected.POST("/conversations/import", conversationHandler.ImportConversation)
+		protected.POST("/conversations/clear", conversationHandler.ClearConversations)
 		protected.POST("/conversations/:id/delete-turn", conversationHandler.DeleteConversationTurn)
 		protected.PUT("/conversations/:id/pinned", groupHandler.UpdateConversationPinned)
 
@@ -1059,6 +1142,7 @@ func setupRoutes(
 		protected.DELETE("/monitor/executions", monitorHandler.DeleteExecutions)
 		protected.GET("/monitor/stats", monitorHandler.GetStats)
 		protected.GET("/monitor/calls-timeline", monitorHandler.GetCallsTimeline)
+		protected.POST("/monitor/retention/purge", monitorHandler.PurgeRetention)
 		protected.GET("/notifications/summary", notificationHandler.GetSummary)
 		protected.POST("/notifications/read", notificationHandler.MarkRead)
 
@@ -1070,8 +1154,18 @@ func setupRoutes(
 		protected.POST("/config/apply", configHandler.ApplyConfig)
 		protected.POST("/config/test-openai", configHandler.TestOpenAI)
 		protected.POST("/config/test-vision", configHandler.TestVision)
+		protected.POST("/config/test-embedding", configHandler.TestEmbedding)
 		protected.POST("/config/list-models", configHandler.ListModels)
 
+		// 工具列表与运行时开关（列表/参数为 /config/tools 的别名，启停持久化到配置文件）
+		protected.GET("/tools", configHandler.GetTools)
+		protected.GET("/tools/:name/schema", configHandler.GetToolSchema)
+		protected.POST("/tools/:name/enable", configHandler.EnableTool)
+		protected.POST("/tools/:name/disable", configHandler.DisableTool)
+
+		// 数据库备份（定时任务之外的手动触发入口）
+		protected.POST("/database/backup", databaseBackupHandler.TriggerBackup)
+
 		// 系统设置 - 终端（执行命令，提高运维效率）
 		protected.POST("/terminal/run", terminalHandler.RunCommand)
 		protected.POST("/terminal/run/stream", terminalHandler.RunCommandStream)
@@ -1097,6 +1191,11 @@ func setupRoutes(
 		protected.GET("/attack-chain/:conversationId", attackChainHandler.GetAttackChain)
 		protected.POST("/attack-chain/:conversationId/regenerate", attackChainHandler.RegenerateAttackChain)
 
+		// 会话综合报告导出（markdown/html，html 可在浏览器打印为 PDF）
+		protected.GET("/reports/:conversationId", reportHandler.GetConversationReport)
+		// 会话重放脚本导出（按执行顺序输出实际命令行的 bash 脚本）
+		protected.GET("/reports/:conversationId/replay-script", reportHandler.GetConversationReplayScript)
+
 		// 知识库管理（始终注册路由，通过 App 实例动态获取 handler）
 		knowledgeRoutes := protected.Group("/knowledge")
 		{
@@ -1111,6 +1210,36 @@ func setupRoutes(
 				}
 				app.knowledgeHandler.GetCategories(c)
 			})
+			knowledgeRoutes.GET("/categories/stats", func(c *gin.Context) {
+				if app.knowledgeHandler == nil {
+					c.JSON(http.StatusOK, gin.H{
+						"enabled": false,
+						"error":   "知识库功能未启用，请前往系统设置启用知识检索功能",
+					})
+					return
+				}
+				app.knowledgeHandler.GetCategoryStats(c)
+			})
+			knowledgeRoutes.PUT("/categories/rename", func(c *gin.Context) {
+				if app.knowledgeHandler == nil {
+					c.JSON(http.StatusOK, gin.H{
+						"enabled": false,
+						"error":   "知识库功能未启用，请前往系统设置启用知识检索功能",
+					})
+					return
+				}
+				app.knowledgeHandler.RenameCategory(c)
+			})
+			knowledgeRoutes.POST("/categories/merge", func(c *gin.Context) {
+				if app.knowledgeHandler == nil {
+					c.JSON(http.StatusOK, gin.H{
+						"enabled": false,
+						"error":   "知识库功能未启用，请前往系统设置启用知识检索功能",
+					})
+					return
+				}
+				app.knowledgeHandler.MergeCategories(c)
+			})
 			knowledgeRoutes.GET("/items", func(c *gin.Context) {
 				if app.knowledgeHandler == nil {
 					c.JSON(http.StatusOK, gin.H{
@@ -1152,6 +1281,16 @@ func setupRoutes(
 				}
 				app.knowledgeHandler.UpdateItem(c)
 			})
+			knowledgeRoutes.PUT("/items/:id/boost", func(c *gin.Context) {
+				if app.knowledgeHandler == nil {
+					c.JSON(http.StatusOK, gin.H{
+						"enabled": false,
+						"error":   "知识库功能未启用，请前往系统设置启用知识检索功能",
+					})
+					return
+				}
+				app.knowledgeHandler.SetItemBoost(c)
+			})
 			knowledgeRoutes.DELETE("/items/:id", func(c *gin.Context) {
 				if app.knowledgeHandler == nil {
 					c.JSON(http.StatusOK, gin.H{
@@ -1186,6 +1325,28 @@ func setupRoutes(
 				}
 				app.knowledgeHandler.StartIndex(c)
 			})
+			knowledgeRoutes.GET("/failed-chunks", func(c *gin.Context) {
+				if app.knowledgeHandler == nil {
+					c.JSON(http.StatusOK, gin.H{
+						"failed_chunks": []interface{}{},
+						"total":         0,
+						"enabled":       false,
+						"message":       "知识库功能未启用，请前往系统设置启用知识检索功能",
+					})
+					return
+				}
+				app.knowledgeHandler.GetFailedChunks(c)
+			})
+			knowledgeRoutes.POST("/retry-failed", func(c *gin.Context) {
+				if app.knowledgeHandler == nil {
+					c.JSON(http.StatusOK, gin.H{
+						"enabled": false,
+						"error":   "知识库功能未启用，请前往系统设置启用知识检索功能",
+					})
+					return
+				}
+				app.knowledgeHandler.RetryFailedChunks(c)
+			})
 			knowledgeRoutes.POST("/scan", func(c *gin.Context) {
 				if app.knowledgeHandler == nil {
 					c.JSON(http.StatusOK, gin.H{
@@ -2050,16 +2211,10 @@ func initializeKnowledge(
 
 	// 创建知识库管理器
 	knowledgeManager := knowledge.NewManager(knowledgeDB, cfg.Knowledge.BasePath, logger)
+	knowledgeManager.SetIndexingLimits(&cfg.Knowledge.Indexing)
 
 	// 创建嵌入器
-	// 使用OpenAI配置的API Key（如果知识库配置中没有指定）
-	if cfg.Knowledge.Embedding.APIKey == "" {
-		cfg.Knowledge.Embedding.APIKey = cfg.OpenAI.APIKey
-	}
-	if cfg.Knowledge.Embedding.BaseURL == "" {
-		cfg.Knowledge.Embedding.BaseURL = cfg.OpenAI.BaseURL
-	}
-
+	// key/base_url 的回退由 NewEmbedder 显式处理（带告警日志），段完全缺失时直接报错
 	embedder, err := knowledge.NewEmbedder(context.Background(), &cfg.Knowledge, &cfg.OpenAI, logger)
 	if err != nil {
 		return nil, fmt.Errorf("初始化知识库嵌入器失败: %w", err)
@@ -2181,13 +2336,23 @@ func initializeKnowledge(
 // corsMiddleware allows same-origin requests, valid Chromium extension
 // origins, and exact origins explicitly configured by the operator. CORS is
 // not an authentication boundary; API access still requires a valid session.
-func corsMiddleware(configuredOrigins []string) gin.HandlerFunc {
+// extraHeaders / extraMethods extend the built-in preflight allow lists for
+// separately deployed frontends; wildcard origins remain unsupported.
+func corsMiddleware(configuredOrigins, extraHeaders, extraMethods []string) gin.HandlerFunc {
 	allowedOrigins := make(map[string]struct{}, len(configuredOrigins))
 	for _, origin := range configuredOrigins {
 		if normalized, ok := normalizeCORSOrigin(origin); ok {
 			allowedOrigins[normalized] = struct{}{}
 		}
 	}
+	allowHeaders := mergeCORSAllowList(
+		[]string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "accept", "origin", "Cache-Control", "X-Requested-With"},
+		extraHeaders,
+	)
+	allowMethods := mergeCORSAllowList(
+		[]string{"POST", "OPTIONS", "GET", "PUT", "DELETE"},
+		extraMethods,
+	)
 
 	return func(c *gin.Context) {
 		origin := strings.TrimSpace(c.GetHeader("Origin"))
@@ -2205,8 +2370,8 @@ func corsMiddleware(configuredOrigins []string) gin.HandlerFunc {
 			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
 			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		}
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+		c.Writer.Header().Set("Access-Control-Allow-Methods", allowMethods)
 		c.Writer.Header().Set("Access-Control-Max-Age", "600")
 
 		if c.Request.Method == "OPTIONS" {
@@ -2218,6 +2383,43 @@ func corsMiddleware(configuredOrigins []string) gin.HandlerFunc {
 	}
 }
 
+// maxRequestBodyMiddleware 限制单个请求体最大字节数（server.max_request_body_bytes），
+// 超限返回 413；Content-Length 未知（chunked）时由 MaxBytesReader 在读取阶段兜底。
+func maxRequestBodyMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes > 0 {
+			if c.Request.ContentLength > maxBytes {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "请求体过大"})
+				return
+			}
+			if c.Request.Body != nil {
+				c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+			}
+		}
+		c.Next()
+	}
+}
+
+// mergeCORSAllowList appends operator-configured entries to the built-in allow
+// list, skipping blanks, wildcards, and case-insensitive duplicates.
+func mergeCORSAllowList(defaults, extras []string) string {
+	seen := make(map[string]struct{}, len(defaults)+len(extras))
+	merged := make([]string, 0, len(defaults)+len(extras))
+	for _, entry := range append(append([]string{}, defaults...), extras...) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || entry == "*" {
+			continue
+		}
+		key := strings.ToLower(entry)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		merged = append(merged, entry)
+	}
+	return strings.Join(merged, ", ")
+}
+
 // isChromiumExtensionOrigin accepts only Chrome's canonical 32-character
 // extension IDs (letters a-p). It does not allow arbitrary custom schemes or
 // web origins, and the extension must separately obtain host permission.
@@ -11,7 +11,7 @@ import (
 func TestCORSMiddlewareAllowsSameOriginAndRejectsForeignOrigin(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(corsMiddleware(nil))
+	router.Use(corsMiddleware(nil, nil, nil))
 	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusNoContent) })
 
 	same := httptest.NewRequest(http.MethodGet, "http://app.example/test", nil)
@@ -36,7 +36,7 @@ func TestCORSMiddlewareAllowsSameOriginAndRejectsForeignOrigin(t *testing.T) {
 func TestCORSMiddlewareAllowsBrowserExtensionWithoutConfiguration(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(corsMiddleware(nil))
+	router.Use(corsMiddleware(nil, nil, nil))
 	router.POST("/api/auth/login", func(c *gin.Context) { c.Status(http.StatusNoContent) })
 
 	req := httptest.NewRequest(http.MethodOptions, "https://server.example/api/auth/login", nil)
@@ -64,7 +64,7 @@ func TestCORSMiddlewareRejectsInvalidExtensionOrigins(t *testing.T) {
 	} {
 		t.Run(origin, func(t *testing.T) {
 			router := gin.New()
-			router.Use(corsMiddleware(nil))
+			router.Use(corsMiddleware(nil, nil, nil))
 			router.GET("/test", func(c *gin.Context) { c.Status(http.StatusNoContent) })
 
 			req := httptest.NewRequest(http.MethodGet, "https://server.example/test", nil)
@@ -89,7 +89,7 @@ func TestCORSMiddlewareRejectsUnsafeConfiguredEntries(t *testing.T) {
 	} {
 		t.Run(configured, func(t *testing.T) {
 			router := gin.New()
-			router.Use(corsMiddleware([]string{configured}))
+			router.Use(corsMiddleware([]string{configured}, nil, nil))
 			router.GET("/test", func(c *gin.Context) { c.Status(http.StatusNoContent) })
 
 			req := httptest.NewRequest(http.MethodGet, "https://server.example/test", nil)
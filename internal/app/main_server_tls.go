@@ -36,6 +36,11 @@ func prepareMainServerTLS(cfg *config.ServerConfig) (mode mainTLSMode, tlsConf *
 	certFile = strings.TrimSpace(cfg.TLSCertPath)
 	keyFile = strings.TrimSpace(cfg.TLSKeyPath)
 	if certFile != "" && keyFile != "" {
+		// 启动时先校验证书/私钥可加载，路径写错或密钥不匹配时立刻报清晰错误，
+		// 而不是等到 ListenAndServeTLS 时才失败。
+		if _, loadErr := tls.LoadX509KeyPair(certFile, keyFile); loadErr != nil {
+			return mainTLSOff, nil, "", "", fmt.Errorf("server: 加载 TLS 证书失败（检查 tls_cert_path / tls_key_path 是否为匹配的 PEM 文件）: %w", loadErr)
+		}
 		// 证书由 ListenAndServeTLS 从文件加载；此处仅提供最小 TLS 配置供 http2.ConfigureServer 合并 ALPN。
 		return mainTLSFromFiles, &tls.Config{MinVersion: tls.VersionTLS12}, certFile, keyFile, nil
 	}
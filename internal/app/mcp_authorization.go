@@ -18,6 +18,9 @@ func mcpToolAuthorizer(db *database.DB) func(context.Context, string, map[string
 		if !ok {
 			return fmt.Errorf("missing authenticated principal")
 		}
+		if err := authorizeConversationTargetScope(ctx, db, args); err != nil {
+			return err
+		}
 		require := func(permission string) error {
 			if !principal.HasPermission(permission) {
 				return fmt.Errorf("missing permission %s", permission)
@@ -197,8 +200,8 @@ func mcpToolAuthorizer(db *database.DB) func(context.Context, string, map[string
 	}
 }
 
-func externalMCPToolAuthorizer() func(context.Context, string, map[string]interface{}) error {
-	return func(ctx context.Context, toolName string, _ map[string]interface{}) error {
+func externalMCPToolAuthorizer(db *database.DB) func(context.Context, string, map[string]interface{}) error {
+	return func(ctx context.Context, toolName string, args map[string]interface{}) error {
 		principal, ok := authctx.PrincipalFromContext(ctx)
 		if !ok {
 			return fmt.Errorf("missing authenticated principal")
@@ -212,8 +215,28 @@ func externalMCPToolAuthorizer() func(context.Context, string, map[string]interf
 		if strings.TrimSpace(toolName) == "" {
 			return fmt.Errorf("missing external tool name")
 		}
+		return authorizeConversationTargetScope(ctx, db, args)
+	}
+}
+
+// authorizeConversationTargetScope 校验工具参数中的目标主机是否越出会话声明的授权目标范围，
+// 防止多轮对话中 LLM 漂移到未授权目标；会话未声明授权目标时不做限制。
+func authorizeConversationTargetScope(ctx context.Context, db *database.DB, args map[string]interface{}) error {
+	if db == nil || len(args) == 0 {
+		return nil
+	}
+	conversationID := mcpAuthorizationConversationID(ctx)
+	if conversationID == "" {
 		return nil
 	}
+	violations, err := db.ConversationTargetViolations(conversationID, args)
+	if err != nil {
+		return fmt.Errorf("failed to verify conversation target scope: %w", err)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("target %s is outside this conversation's authorized scope; update the authorized targets via PUT /api/conversations/%s/targets to confirm", strings.Join(violations, ", "), conversationID)
+	}
+	return nil
 }
 
 func authorizeC2Action(ctx context.Context, principal authctx.Principal, db *database.DB, args map[string]interface{}, resourceType, argument string) error {
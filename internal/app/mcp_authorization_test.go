@@ -237,7 +237,7 @@ func TestMCPAssetToolAuthorizationUsesAssetPermissionsAndScope(t *testing.T) {
 }
 
 func TestExternalMCPRequiresDedicatedPermission(t *testing.T) {
-	authorize := externalMCPToolAuthorizer()
+	authorize := externalMCPToolAuthorizer(nil)
 	ctx := authctx.WithPrincipal(context.Background(), authctx.NewPrincipal("u1", "user", database.RBACScopeAssigned, map[string]bool{"agent:execute": true}))
 	if err := authorize(ctx, "server::tool", nil); err == nil {
 		t.Fatal("agent:execute alone authorized an external MCP tool")
@@ -259,3 +259,45 @@ func TestConfiguredCommandToolRequiresLocalExecutePermission(t *testing.T) {
 		t.Fatalf("agent:local-execute rejected: %v", err)
 	}
 }
+
+func TestMCPToolAuthorizerEnforcesConversationTargetScope(t *testing.T) {
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "mcp-target-scope.db"), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conv, err := db.CreateConversation("目标范围测试", database.ConversationCreateMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.SetConversationAuthorizedTargets(conv.ID, []string{"example.com", "10.0.0.5"}); err != nil {
+		t.Fatal(err)
+	}
+
+	principal := authctx.NewPrincipal("u1", "user", database.RBACScopeAssigned, map[string]bool{"agent:local-execute": true})
+	ctx := authctx.WithPrincipal(context.Background(), principal)
+	ctx = mcp.WithMCPConversationID(ctx, conv.ID)
+	authorize := mcpToolAuthorizer(db)
+
+	if err := authorize(ctx, "nmap_scan", map[string]interface{}{"target": "api.example.com"}); err != nil {
+		t.Fatalf("授权目标的子域被拒绝: %v", err)
+	}
+	if err := authorize(ctx, "nmap_scan", map[string]interface{}{"target": "http://cdn.evil.com/x"}); err == nil {
+		t.Fatal("越权目标未被拦截")
+	} else if !strings.Contains(err.Error(), "cdn.evil.com") {
+		t.Fatalf("越权错误应包含目标主机: %v", err)
+	}
+	// 无目标参数的工具调用不受影响
+	if err := authorize(ctx, "nmap_scan", map[string]interface{}{"threads": "10"}); err != nil {
+		t.Fatalf("无目标参数的调用被拒绝: %v", err)
+	}
+	// 未声明授权目标的会话不做限制
+	other, err := db.CreateConversation("未声明目标", database.ConversationCreateMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCtx := mcp.WithMCPConversationID(authctx.WithPrincipal(context.Background(), principal), other.ID)
+	if err := authorize(otherCtx, "nmap_scan", map[string]interface{}{"target": "cdn.evil.com"}); err != nil {
+		t.Fatalf("未声明授权目标的会话被限制: %v", err)
+	}
+}
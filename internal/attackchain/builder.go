@@ -244,6 +244,11 @@ func (b *Builder) BuildChainFromConversation(ctx context.Context, conversationID
 		}
 	}
 
+	// 补充运行时记录的调用依赖（parent_execution_id）：连边依据实际触发关系而非纯时间顺序
+	if deps := b.formatExecutionDependencies(conversationID); deps != "" {
+		reactInputFinal = reactInputFinal + "\n\n## 工具调用依赖记录（运行时跟踪）\n\n以下依赖关系由 Agent 运行时跟踪得出（箭头左侧调用基于右侧执行的结果发起），构建边时请优先以此为准，而非单纯按时间先后连线：\n\n" + deps
+	}
+
 	// 3. 按 token 预算压缩输入，再构建 prompt（避免超出模型上下文）
 	reactInputFinal, modelOutput, _ = b.fitAttackChainPayload(reactInputFinal, modelOutput)
 
@@ -299,6 +304,43 @@ func reactInputContainsToolTrace(reactInputJSON string) bool {
 		strings.Contains(s, `"role": "tool"`)
 }
 
+// formatExecutionDependencies 将运行时跟踪的 parent_execution_id 整理为依赖清单。
+// 历史会话或未挂跟踪器的执行没有 parent 记录，此时返回空串、连边仍由模型按轨迹推断。
+func (b *Builder) formatExecutionDependencies(conversationID string) string {
+	executions, _, err := b.db.LoadToolExecutionsByConversation(conversationID, 0, 500)
+	if err != nil {
+		b.logger.Warn("加载执行记录用于依赖连边失败", zap.Error(err))
+		return ""
+	}
+	byID := make(map[string]string, len(executions))
+	for _, exec := range executions {
+		if exec != nil {
+			byID[exec.ID] = exec.ToolName
+		}
+	}
+	var sb strings.Builder
+	for _, exec := range executions {
+		if exec == nil || exec.ParentExecutionID == "" {
+			continue
+		}
+		parentTool, ok := byID[exec.ParentExecutionID]
+		if !ok {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("- %s（execution %s）← 基于 %s（execution %s）的结果发起\n",
+			exec.ToolName, shortExecutionID(exec.ID), parentTool, shortExecutionID(exec.ParentExecutionID)))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// shortExecutionID 取执行 ID 前 8 位用于 prompt 展示（足够区分且省 token）。
+func shortExecutionID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
 // formatProcessDetailsForAttackChain 将最后一轮助手的过程详情格式化为攻击链分析的输入（覆盖多代理下 last_react_input 不完整的情况）。
 func (b *Builder) formatProcessDetailsForAttackChain(details []database.ProcessDetail) string {
 	if len(details) == 0 {
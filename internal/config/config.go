@@ -26,6 +26,7 @@ type Config struct {
 	FOFA        FofaConfig            `yaml:"fofa,omitempty" json:"fofa,omitempty"`
 	ZoomEye     SpaceSearchConfig     `yaml:"zoomeye,omitempty" json:"zoomeye,omitempty"`
 	Quake       SpaceSearchConfig     `yaml:"quake,omitempty" json:"quake,omitempty"`
+	Hunter      SpaceSearchConfig     `yaml:"hunter,omitempty" json:"hunter,omitempty"`
 	Shodan      SpaceSearchConfig     `yaml:"shodan,omitempty" json:"shodan,omitempty"`
 	Agent       AgentConfig           `yaml:"agent"`
 	Hitl        HitlConfig            `yaml:"hitl,omitempty" json:"hitl,omitempty"`
@@ -805,6 +806,11 @@ type ServerConfig struct {
 	// CORSAllowedOrigins contains additional, exact origins that may call the API.
 	// Same-origin browser requests are always allowed. Wildcards are intentionally unsupported.
 	CORSAllowedOrigins []string `yaml:"cors_allowed_origins,omitempty" json:"cors_allowed_origins,omitempty"`
+	// CORSAllowedHeaders / CORSAllowedMethods are merged into the built-in allow
+	// lists for preflight responses (e.g. custom headers sent by a separately
+	// deployed frontend). Leave empty to keep the defaults.
+	CORSAllowedHeaders []string `yaml:"cors_allowed_headers,omitempty" json:"cors_allowed_headers,omitempty"`
+	CORSAllowedMethods []string `yaml:"cors_allowed_methods,omitempty" json:"cors_allowed_methods,omitempty"`
 	// TLSEnabled 为 true 时主 Web UI 使用 HTTPS；现代浏览器在同源下会协商 HTTP/2，缓解 HTTP/1.1 每源并发连接数限制。
 	TLSEnabled bool `yaml:"tls_enabled,omitempty" json:"tls_enabled,omitempty"`
 	// TLSCertPath / TLSKeyPath 非空时从 PEM 文件加载证书（生产环境推荐）。
@@ -814,6 +820,32 @@ type ServerConfig struct {
 	TLSAutoSelfSign bool `yaml:"tls_auto_self_sign,omitempty" json:"tls_auto_self_sign,omitempty"`
 	// TLSHTTPRedirect 为 false 时禁用 HTTP→HTTPS 跳转；省略或为 true 且已启用 HTTPS 时，明文 HTTP 访问将 308 跳转到 HTTPS（同端口嗅探分流）。
 	TLSHTTPRedirect *bool `yaml:"tls_http_redirect,omitempty" json:"tls_http_redirect,omitempty"`
+	// MaxRequestBodyBytes 单个请求体最大字节数（知识导入、大查询等）；超限返回 413。0=默认 10MB；负数=不限制。
+	MaxRequestBodyBytes int64 `yaml:"max_request_body_bytes,omitempty" json:"max_request_body_bytes,omitempty"`
+	// ReadTimeoutSeconds 读取整个请求（含 body）的超时秒数；只限制读请求，不影响 SSE 等长响应。0=默认 300；负数=不限制。
+	ReadTimeoutSeconds int `yaml:"read_timeout_seconds,omitempty" json:"read_timeout_seconds,omitempty"`
+}
+
+// MaxRequestBodyBytesEffective 请求体上限；0 表示默认 10MB，负数表示不限制（返回 0）。
+func (s ServerConfig) MaxRequestBodyBytesEffective() int64 {
+	if s.MaxRequestBodyBytes < 0 {
+		return 0
+	}
+	if s.MaxRequestBodyBytes == 0 {
+		return 10 << 20
+	}
+	return s.MaxRequestBodyBytes
+}
+
+// ReadTimeoutSecondsEffective 请求读取超时秒数；0 表示默认 300，负数表示不限制（返回 0）。
+func (s ServerConfig) ReadTimeoutSecondsEffective() int {
+	if s.ReadTimeoutSeconds < 0 {
+		return 0
+	}
+	if s.ReadTimeoutSeconds == 0 {
+		return 300
+	}
+	return s.ReadTimeoutSeconds
 }
 
 type LogConfig struct {
@@ -831,14 +863,41 @@ type MCPConfig struct {
 }
 
 type OpenAIConfig struct {
-	Provider            string `yaml:"provider,omitempty" json:"provider,omitempty"` // API 提供商: "openai"(默认) 或 "claude"，claude 时自动桥接为 Anthropic Messages API
-	APIKey              string `yaml:"api_key" json:"api_key"`
-	BaseURL             string `yaml:"base_url" json:"base_url"`
-	Model               string `yaml:"model" json:"model"`
-	MaxTotalTokens      int    `yaml:"max_total_tokens,omitempty" json:"max_total_tokens,omitempty"`
-	MaxCompletionTokens int    `yaml:"max_completion_tokens,omitempty" json:"max_completion_tokens,omitempty"`
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"` // API 提供商: "openai"(默认) 或 "claude"，claude 时自动桥接为 Anthropic Messages API
+	APIKey   string `yaml:"api_key" json:"api_key"`
+	// APIKeys 多 Key 轮询池（可选）：非空时每次调用轮询选 key，连续 429/401 的 key
+	// 临时摘除冷却并自动切到下一个；仅 OpenAI 兼容端点生效，Claude 桥接仍用 api_key。
+	APIKeys             []string `yaml:"api_keys,omitempty" json:"api_keys,omitempty"`
+	BaseURL             string   `yaml:"base_url" json:"base_url"`
+	Model               string   `yaml:"model" json:"model"`
+	MaxTotalTokens      int      `yaml:"max_total_tokens,omitempty" json:"max_total_tokens,omitempty"`
+	MaxCompletionTokens int      `yaml:"max_completion_tokens,omitempty" json:"max_completion_tokens,omitempty"`
 	// Reasoning 控制 Eino ChatModel 的 thinking / reasoning_effort / output_config 等（Eino 单/多代理路径生效）。
 	Reasoning OpenAIReasoningConfig `yaml:"reasoning,omitempty" json:"reasoning,omitempty"`
+	// Headers 额外请求头（自建网关的路由头、X-Api-Key 等），构建请求时追加；不覆盖标准头。
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	// AuditDir 非空时启用请求/响应完整审计落盘：每次 chat/completions 的请求体与响应体
+	// 按 conversationId 与调用序号写入该目录，供排查模型行为时复盘。
+	// 只写 body，不写 Authorization 等请求头；默认关闭。
+	AuditDir string `yaml:"audit_dir,omitempty" json:"audit_dir,omitempty"`
+}
+
+// NormalizeOpenAIBaseURL 规范化 OpenAI 兼容 base_url：去掉尾部斜杠；若把完整端点
+// （…/chat/completions、…/embeddings、…/models）误配成 base_url，则剥掉端点路径，
+// 避免拼接出 …/chat/completions/chat/completions 之类的 404 URL。空串返回官方默认地址。
+func NormalizeOpenAIBaseURL(raw string) string {
+	base := strings.TrimSuffix(strings.TrimSpace(raw), "/")
+	for _, endpoint := range []string{"/chat/completions", "/embeddings", "/models"} {
+		if strings.HasSuffix(base, endpoint) {
+			base = strings.TrimSuffix(base, endpoint)
+			base = strings.TrimSuffix(base, "/")
+			break
+		}
+	}
+	if base == "" {
+		return "https://api.openai.com/v1"
+	}
+	return base
 }
 
 // AIConfig stores first-class model channels. Runtime callers resolve a channel
@@ -857,6 +916,7 @@ type AIChannelConfig struct {
 	MaxTotalTokens      int                   `yaml:"max_total_tokens,omitempty" json:"max_total_tokens,omitempty"`
 	MaxCompletionTokens int                   `yaml:"max_completion_tokens,omitempty" json:"max_completion_tokens,omitempty"`
 	Reasoning           OpenAIReasoningConfig `yaml:"reasoning,omitempty" json:"reasoning,omitempty"`
+	Headers             map[string]string     `yaml:"headers,omitempty" json:"headers,omitempty"`
 }
 
 func (c AIChannelConfig) ToOpenAIConfig() OpenAIConfig {
@@ -872,6 +932,7 @@ func (c AIChannelConfig) ToOpenAIConfig() OpenAIConfig {
 		MaxTotalTokens:      c.MaxTotalTokens,
 		MaxCompletionTokens: c.MaxCompletionTokens,
 		Reasoning:           c.Reasoning,
+		Headers:             c.Headers,
 	}
 }
 
@@ -888,6 +949,7 @@ func AIChannelFromOpenAI(id, name string, oa OpenAIConfig) AIChannelConfig {
 		MaxTotalTokens:      oa.MaxTotalTokens,
 		MaxCompletionTokens: oa.MaxCompletionTokens,
 		Reasoning:           oa.Reasoning,
+		Headers:             oa.Headers,
 	}
 }
 
@@ -1018,6 +1080,26 @@ type FofaConfig struct {
 	// APIKey 为 FOFA API Key（建议使用只读权限的 Key）
 	APIKey  string `yaml:"api_key,omitempty" json:"api_key,omitempty"`
 	BaseURL string `yaml:"base_url,omitempty" json:"base_url,omitempty"` // 默认 https://fofa.info/api/v1/search/all
+	// MaxSize 单次查询 size 上限，按账户等级配置（免费账户建议 100）；0 表示默认 10000
+	MaxSize int `yaml:"max_size,omitempty" json:"max_size,omitempty"`
+	// DefaultSize 请求未传 size 时的默认值；0 表示默认 100
+	DefaultSize int `yaml:"default_size,omitempty" json:"default_size,omitempty"`
+}
+
+// MaxSizeEffective 返回生效的单次查询 size 上限（未配置时回退 10000）。
+func (c FofaConfig) MaxSizeEffective() int {
+	if c.MaxSize > 0 {
+		return c.MaxSize
+	}
+	return 10000
+}
+
+// DefaultSizeEffective 返回未传 size 时的默认值（未配置时回退 100）。
+func (c FofaConfig) DefaultSizeEffective() int {
+	if c.DefaultSize > 0 {
+		return c.DefaultSize
+	}
+	return 100
 }
 
 type SpaceSearchConfig struct {
@@ -1029,27 +1111,146 @@ type SecurityConfig struct {
 	Tools               []ToolConfig `yaml:"tools,omitempty"`                 // 向后兼容：支持在主配置文件中定义工具
 	ToolsDir            string       `yaml:"tools_dir,omitempty"`             // 工具配置文件目录（新方式）
 	ToolDescriptionMode string       `yaml:"tool_description_mode,omitempty"` // 工具描述模式: "short" | "full"，默认 short
+	KeepANSIOutput      bool         `yaml:"keep_ansi_output,omitempty"`      // true=保留工具输出中的 ANSI 转义序列（默认 strip，彩色输出进 LLM 浪费 token 且干扰解析）
+	// ChatUploadMaxSizeMB 对话附件单文件大小上限（MB）；0=默认 50；-1=不限制。
+	ChatUploadMaxSizeMB int `yaml:"chat_upload_max_size_mb,omitempty"`
+	// ChatUploadAllowedExts 允许上传的附件扩展名白名单（如 [".txt", ".pcap"]，不区分大小写，可省略点号）；空=不限制类型。
+	ChatUploadAllowedExts []string `yaml:"chat_upload_allowed_exts,omitempty"`
+	// ChatUploadRetentionDays 附件保留天数，超期文件及空目录定时清理；0=永久保留。
+	ChatUploadRetentionDays int `yaml:"chat_upload_retention_days,omitempty"`
+	// ExecCommandTemplates exec 命令模板白名单（如 "whois {domain}"）：非空时 exec 只放行
+	// 匹配模板的命令，{xxx} 占位符匹配一个参数且整条命令禁止 shell 控制字符；空=不启用。
+	ExecCommandTemplates []string `yaml:"exec_command_templates,omitempty"`
+	// SensitiveRedaction 工具执行结果敏感信息脱敏：启用后按内置正则（私钥、AWS key、
+	// password 字段、信用卡号等）打码后再落库/返回前端，extra_patterns 可追加自定义正则。
+	SensitiveRedaction SensitiveRedactionConfig `yaml:"sensitive_redaction,omitempty"`
+}
+
+// SensitiveRedactionConfig 工具结果敏感信息脱敏配置（security.sensitive_redaction）
+type SensitiveRedactionConfig struct {
+	Enabled bool `yaml:"enabled"` // true=启用脱敏；默认关闭
+	// ExtraPatterns 追加的自定义脱敏正则（RE2 语法），与内置模式叠加生效
+	ExtraPatterns []string `yaml:"extra_patterns,omitempty"`
 }
 
 type DatabaseConfig struct {
-	Path            string `yaml:"path"`                        // 会话数据库路径
-	KnowledgeDBPath string `yaml:"knowledge_db_path,omitempty"` // 知识库数据库路径（可选，为空则使用会话数据库）
+	// Driver 数据库后端：sqlite（默认）或 postgres。多人协作/大数据量场景下
+	// SQLite 的写锁会成为瓶颈，postgres 后端当前覆盖 conversations/messages 核心功能。
+	Driver          string               `yaml:"driver,omitempty"`
+	DSN             string               `yaml:"dsn,omitempty"`               // postgres 连接串（driver=postgres 时必填）
+	Path            string               `yaml:"path"`                        // 会话数据库路径（sqlite）
+	KnowledgeDBPath string               `yaml:"knowledge_db_path,omitempty"` // 知识库数据库路径（可选，为空则使用会话数据库）
+	Backup          DatabaseBackupConfig `yaml:"backup,omitempty"`            // 定期自动备份（仅 sqlite）
+	// MaxRowBytes 单行落库内容的字节上限（messages.content 与 process_details 的 message/data）。
+	// 超限时全文写入 reduction 缓存树下的独立文件，库里只存带引用路径的截断版，
+	// 防止巨量工具输出让数据库膨胀；<=0 表示不限制（默认）。
+	MaxRowBytes int `yaml:"max_row_bytes,omitempty" json:"max_row_bytes,omitempty"`
+}
+
+// DatabaseBackupConfig 数据库定期备份配置：SQLite 单文件损坏会丢掉所有历史，
+// 启用后按间隔用 VACUUM INTO 备份到指定目录并保留最近 N 份。
+type DatabaseBackupConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	IntervalHours int    `yaml:"interval_hours,omitempty"` // 备份间隔（小时），默认 24
+	Dir           string `yaml:"dir,omitempty"`            // 备份目录，默认数据库同目录下的 backups/
+	Keep          int    `yaml:"keep,omitempty"`           // 保留最近 N 份，默认 7
+}
+
+// IntervalHoursEffective 生效的备份间隔（小时）
+func (c DatabaseBackupConfig) IntervalHoursEffective() int {
+	if c.IntervalHours > 0 {
+		return c.IntervalHours
+	}
+	return 24
+}
+
+// KeepEffective 生效的保留份数
+func (c DatabaseBackupConfig) KeepEffective() int {
+	if c.Keep > 0 {
+		return c.Keep
+	}
+	return 7
+}
+
+// DirEffective 生效的备份目录；未配置时落在数据库文件同目录下的 backups/
+func (c DatabaseBackupConfig) DirEffective(dbPath string) string {
+	if dir := strings.TrimSpace(c.Dir); dir != "" {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(dbPath), "backups")
 }
 
 type AgentConfig struct {
-	MaxIterations                      int `yaml:"max_iterations" json:"max_iterations"`
-	ToolTimeoutMinutes                 int `yaml:"tool_timeout_minutes" json:"tool_timeout_minutes"`                                     // 单次工具执行最大时长（分钟），超时自动终止，防止长时间挂起；0 表示不限制（不推荐）
-	ToolWaitTimeoutSeconds             int `yaml:"tool_wait_timeout_seconds" json:"tool_wait_timeout_seconds"`                           // 工具本轮等待秒数；到时返回 execution_id，worker 继续后台执行；0 表示等到完成
-	ExternalMCPMaxConcurrentPerServer  int `yaml:"external_mcp_max_concurrent_per_server" json:"external_mcp_max_concurrent_per_server"` // 单个外部 MCP server 同时运行的工具数；0 表示默认 2
-	ExternalMCPMaxConcurrentTotal      int `yaml:"external_mcp_max_concurrent_total" json:"external_mcp_max_concurrent_total"`           // 所有外部 MCP 工具全局并发；0 表示默认 16
-	ExternalMCPCircuitFailureThreshold int `yaml:"external_mcp_circuit_failure_threshold" json:"external_mcp_circuit_failure_threshold"` // 单个 MCP server 连续失败多少次后打开熔断；0 表示默认 3；负数关闭
-	ExternalMCPCircuitCooldownSeconds  int `yaml:"external_mcp_circuit_cooldown_seconds" json:"external_mcp_circuit_cooldown_seconds"`   // 熔断后冷却秒数；0 表示默认 60
+	MaxIterations int `yaml:"max_iterations" json:"max_iterations"`
+	// MaxToolCalls 单次任务工具调用总数上限（主/子代理共享计数，transfer/task 等元工具不计入）；
+	// 超限后不再执行新工具，提示模型基于已有结果直接收尾；0 表示不限制
+	MaxToolCalls int `yaml:"max_tool_calls,omitempty" json:"max_tool_calls,omitempty"`
+	// MaxTaskTokens 单次任务累计 token 预算（同一请求内全部模型调用 usage.total_tokens 之和，
+	// 主/子代理与分段续跑共享计数）；超限中止循环并告知用户已达预算上限；<=0 不限制。可在 ChatRequest.budget 覆盖。
+	MaxTaskTokens int `yaml:"max_task_tokens,omitempty" json:"max_task_tokens,omitempty"`
+	// MaxTaskCost 单次任务金额预算，按 token_price_prompt_per_1m / token_price_completion_per_1m 折算；<=0 不限制。
+	MaxTaskCost float64 `yaml:"max_task_cost,omitempty" json:"max_task_cost,omitempty"`
+	// TokenPricePromptPer1M / TokenPriceCompletionPer1M 金额预算折算单价（每百万 token，币种随计费方自定）。
+	TokenPricePromptPer1M              float64 `yaml:"token_price_prompt_per_1m,omitempty" json:"token_price_prompt_per_1m,omitempty"`
+	TokenPriceCompletionPer1M          float64 `yaml:"token_price_completion_per_1m,omitempty" json:"token_price_completion_per_1m,omitempty"`
+	ToolTimeoutMinutes                 int     `yaml:"tool_timeout_minutes" json:"tool_timeout_minutes"`                                     // 单次工具执行最大时长（分钟），超时自动终止，防止长时间挂起；0 表示不限制（不推荐）
+	ToolWaitTimeoutSeconds             int     `yaml:"tool_wait_timeout_seconds" json:"tool_wait_timeout_seconds"`                           // 工具本轮等待秒数；到时返回 execution_id，worker 继续后台执行；0 表示等到完成
+	ExternalMCPMaxConcurrentPerServer  int     `yaml:"external_mcp_max_concurrent_per_server" json:"external_mcp_max_concurrent_per_server"` // 单个外部 MCP server 同时运行的工具数；0 表示默认 2
+	ExternalMCPMaxConcurrentTotal      int     `yaml:"external_mcp_max_concurrent_total" json:"external_mcp_max_concurrent_total"`           // 所有外部 MCP 工具全局并发；0 表示默认 16
+	ExternalMCPCircuitFailureThreshold int     `yaml:"external_mcp_circuit_failure_threshold" json:"external_mcp_circuit_failure_threshold"` // 单个 MCP server 连续失败多少次后打开熔断；0 表示默认 3；负数关闭
+	ExternalMCPCircuitCooldownSeconds  int     `yaml:"external_mcp_circuit_cooldown_seconds" json:"external_mcp_circuit_cooldown_seconds"`   // 熔断后冷却秒数；0 表示默认 60
 	// ShellNoOutputTimeoutSeconds execute/exec 无任何 stdout/stderr 时的空闲终止秒数（通用防挂死，不维护命令黑名单）；0=默认 300（5 分钟）；-1=关闭。
 	ShellNoOutputTimeoutSeconds int `yaml:"shell_no_output_timeout_seconds" json:"shell_no_output_timeout_seconds"`
+	// ToolMaxOutputBytes 工具进程 stdout/stderr 总产出上限（字节）：超限直接终止进程树并在结果末尾附「工具输出超限已终止」。
+	// 与 tool_output_max_bytes 的截断/落盘独立（那是留多少，这是产出多少就杀）；0 表示不限制。
+	ToolMaxOutputBytes int64 `yaml:"tool_max_output_bytes,omitempty" json:"tool_max_output_bytes,omitempty"`
+	// ToolMaxCPUSeconds 工具进程 CPU 时间上限（秒），Linux 下通过 RLIMIT_CPU 下发给子进程，超限由内核终止；其它平台忽略；0 表示不限制。
+	ToolMaxCPUSeconds int `yaml:"tool_max_cpu_seconds,omitempty" json:"tool_max_cpu_seconds,omitempty"`
 	// WorkspaceRootDir 会话工作目录根路径（curl/wget 下载、read_file/glob/grep 本地分析）；空=tmp/workspace，其下按 projects/{id} 或 conversations/{id} 隔离。
 	WorkspaceRootDir string `yaml:"workspace_root_dir,omitempty" json:"workspace_root_dir,omitempty"`
 	// SystemPromptPath 单代理系统提示 Markdown/文本文件路径（相对 config.yaml 所在目录，或可写绝对路径）。非空且可读时替换内置单代理提示；留空用内置。
 	SystemPromptPath string `yaml:"system_prompt_path,omitempty" json:"system_prompt_path,omitempty"`
+	// ResponseTemplate 最终回答的结构模板（Markdown，如「## 概述 / ## 发现 / ## 风险 / ## 建议」）。
+	// 非空时以 prompt 约束的方式注入给各 Agent 入口，要求模型按模板结构输出最终回答，
+	// 便于前端结构化渲染与报告生成；留空不约束格式。
+	ResponseTemplate string `yaml:"response_template,omitempty" json:"response_template,omitempty"`
+	// AgentMode 单代理循环模式："simple"（默认，普通 tool-call 循环）或 "plan"
+	// （先让 LLM 产出结构化测试计划并存为 process detail，再按计划逐步执行、每步反思）。
+	AgentMode string `yaml:"agent_mode,omitempty" json:"agent_mode,omitempty"`
+	// SSEIdleTimeoutMinutes 流式任务空闲超时（分钟）：超过该时长没有任何 progress 事件
+	// 则主动取消任务并发 error 事件，与 600 分钟总超时独立；0 表示关闭（默认）。
+	SSEIdleTimeoutMinutes int `yaml:"sse_idle_timeout_minutes,omitempty" json:"sse_idle_timeout_minutes,omitempty"`
+	// ToolStrictSchema 工具参数严格模式：为工具 schema 追加 additionalProperties:false、
+	// 在请求中标记 strict:true，并在执行前按 schema 校验参数；非法参数直接反馈给模型修正。
+	ToolStrictSchema bool `yaml:"tool_strict_schema,omitempty" json:"tool_strict_schema,omitempty"`
+	// HistoryArchive 会话历史自动归档：消息数超过阈值时把较早历史用 LLM 总结成
+	// 「历史摘要」system 消息，GetMessages 只返回摘要+最近消息；默认关闭。
+	HistoryArchive HistoryArchiveConfig `yaml:"history_archive,omitempty" json:"history_archive,omitempty"`
+}
+
+// HistoryArchiveConfig 会话历史自动归档选项（见 AgentConfig.HistoryArchive）。
+type HistoryArchiveConfig struct {
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// ThresholdMessages 未归档消息数超过该值时触发归档；0 表示默认 200
+	ThresholdMessages int `yaml:"threshold_messages,omitempty" json:"threshold_messages,omitempty"`
+	// KeepRecentMessages 归档时保留的最近消息条数；0 表示默认 50
+	KeepRecentMessages int `yaml:"keep_recent_messages,omitempty" json:"keep_recent_messages,omitempty"`
+}
+
+// ThresholdMessagesEffective 返回生效的归档触发阈值（未配置时回退 200）。
+func (c HistoryArchiveConfig) ThresholdMessagesEffective() int {
+	if c.ThresholdMessages > 0 {
+		return c.ThresholdMessages
+	}
+	return 200
+}
+
+// KeepRecentMessagesEffective 返回归档时保留的最近消息条数（未配置时回退 50）。
+func (c HistoryArchiveConfig) KeepRecentMessagesEffective() int {
+	if c.KeepRecentMessages > 0 {
+		return c.KeepRecentMessages
+	}
+	return 50
 }
 
 // HitlConfig 人机协同全局选项；与会话侧栏/API 中的白名单合并为并集后参与判定。
@@ -1190,12 +1391,18 @@ func normalizeHitlModeForPrompt(mode string) string {
 
 type AuthConfig struct {
 	SessionDurationHours int `yaml:"session_duration_hours" json:"session_duration_hours"`
+	// APITokens 静态 API Token 列表：请求携带 Authorization: Bearer <token> 即以全权限服务身份放行，
+	// 供脚本/CI 等程序化调用；为空时仅保留登录会话鉴权（向后兼容）。
+	APITokens []string `yaml:"api_tokens,omitempty" json:"api_tokens,omitempty"`
 }
 
 // MonitorConfig MCP 状态监控（tool_executions）保留策略。
 type MonitorConfig struct {
 	// RetentionDays 执行记录保留天数；省略时默认 90；0 表示不自动清理。
 	RetentionDays *int `yaml:"retention_days,omitempty" json:"retention_days,omitempty"`
+	// ProcessDetailRetentionDays 过程详情（process_details）保留天数；长期运行后该表会非常大。
+	// 省略时跟随 retention_days；0 表示不自动清理。关联的会话消息不受影响。
+	ProcessDetailRetentionDays *int `yaml:"process_detail_retention_days,omitempty" json:"process_detail_retention_days,omitempty"`
 }
 
 // RetentionDaysEffective returns retention; 0 means keep forever; omitted defaults to 90.
@@ -1209,6 +1416,17 @@ func (m MonitorConfig) RetentionDaysEffective() int {
 	return *m.RetentionDays
 }
 
+// ProcessDetailRetentionDaysEffective returns process_details retention; omitted follows retention_days.
+func (m MonitorConfig) ProcessDetailRetentionDaysEffective() int {
+	if m.ProcessDetailRetentionDays == nil {
+		return m.RetentionDaysEffective()
+	}
+	if *m.ProcessDetailRetentionDays < 0 {
+		return 0
+	}
+	return *m.ProcessDetailRetentionDays
+}
+
 // AuditConfig platform operation audit log settings (not chat/tool execution bodies).
 type AuditConfig struct {
 	// Enabled nil or true enables persistence; explicit false disables.
@@ -1315,6 +1533,30 @@ type ToolConfig struct {
 	Parameters       []ParameterConfig `yaml:"parameters,omitempty"`         // 参数定义（可选）
 	ArgMapping       string            `yaml:"arg_mapping,omitempty"`        // 参数映射方式: "auto", "manual", "template"（可选）
 	AllowedExitCodes []int             `yaml:"allowed_exit_codes,omitempty"` // 允许的退出码列表（某些工具在成功时也返回非零退出码）
+	// CacheTTLSeconds 相同参数的执行结果缓存秒数；0 表示不缓存（默认）。
+	// 适合 nmap/子域名枚举等幂等且耗时的工具；exec 这类有副作用的工具不要开启。
+	CacheTTLSeconds int `yaml:"cache_ttl_seconds,omitempty"`
+	// RequireApproval 执行前强制人工审批：即使会话未开启人机协同也走 HITL 审批流
+	// （暂停等待批准/拒绝，拒绝结果回填给 LLM），且不受免审批白名单影响。
+	// 适合 exec、sqlmap 写权限等高危工具。
+	RequireApproval bool `yaml:"require_approval,omitempty"`
+	// OutputEncoding 工具输出编码（默认 utf-8）：声明为 gbk/gb2312/gb18030 时，
+	// executor 读取输出后转成 UTF-8 再交给 LLM，无法解码的字节替换为 U+FFFD。
+	// 适合中文 Windows / 特定 locale 下输出 GBK 的工具。
+	OutputEncoding string `yaml:"output_encoding,omitempty"`
+	// VersionArgs 版本探测参数（默认 ["--version"]）：配合 version_profiles 使用，
+	// executor 首次执行前运行 `command <version_args>` 并缓存输出。
+	VersionArgs []string `yaml:"version_args,omitempty"`
+	// VersionProfiles 按版本覆盖参数：dalfox 等工具新旧版本 flag 不同时，
+	// 用版本输出的子串匹配选择对应 profile 覆盖 args/parameters。
+	VersionProfiles []ToolVersionProfile `yaml:"version_profiles,omitempty"`
+}
+
+// ToolVersionProfile 工具版本参数 profile：版本探测输出包含 match 子串时生效。
+type ToolVersionProfile struct {
+	Match      string            `yaml:"match"`                // 版本输出子串，如 "v2."
+	Args       []string          `yaml:"args,omitempty"`       // 覆盖固定参数（非空时整体替换）
+	Parameters []ParameterConfig `yaml:"parameters,omitempty"` // 覆盖参数定义（非空时整体替换）
 }
 
 // ParameterConfig 参数配置
@@ -1330,6 +1572,9 @@ type ParameterConfig struct {
 	Format      string      `yaml:"format,omitempty"`    // 参数格式: "flag", "positional", "combined" (flag=value), "template"
 	Template    string      `yaml:"template,omitempty"`  // 模板字符串，如 "{flag} {value}" 或 "{value}"
 	Options     []string    `yaml:"options,omitempty"`   // 可选值列表（用于枚举）
+	// TargetFormat 目标参数自动规范化: "host"（提取主机，去 scheme/端口/路径，nmap 类）、
+	// "host_port"（保留端口）、"url"（保证完整 URL，sqlmap/nikto 类）；空表示不处理
+	TargetFormat string `yaml:"target_format,omitempty"`
 }
 
 func Load(path string) (*Config, error) {
@@ -1989,22 +2234,45 @@ type IndexingConfig struct {
 	RateLimitDelayMs int `yaml:"rate_limit_delay_ms,omitempty" json:"rate_limit_delay_ms,omitempty"` // 请求间隔时间（毫秒），0 表示不使用固定延迟
 	MaxRPM           int `yaml:"max_rpm,omitempty" json:"max_rpm,omitempty"`                         // 每分钟最大请求数，0 表示不限制
 
+	// 容量限制（防止无限制导入撑爆数据库与索引），0 表示不限制
+	MaxItemContentBytes int `yaml:"max_item_content_bytes,omitempty" json:"max_item_content_bytes,omitempty"` // 单个知识项内容最大字节数
+	MaxTotalItems       int `yaml:"max_total_items,omitempty" json:"max_total_items,omitempty"`               // 知识库总 item 数上限
+	MaxTotalChunks      int `yaml:"max_total_chunks,omitempty" json:"max_total_chunks,omitempty"`             // 知识库总 chunk 数上限
+
 	// 重试配置（用于处理临时错误）
 	MaxRetries   int `yaml:"max_retries,omitempty" json:"max_retries,omitempty"`       // 最大重试次数，默认 3
 	RetryDelayMs int `yaml:"retry_delay_ms,omitempty" json:"retry_delay_ms,omitempty"` // 重试间隔（毫秒），默认 1000
 
+	// 熔断配置：嵌入服务整体不可用时（如 RebuildIndex 期间服务挂掉）避免对每个块都发注定失败的请求。
+	// 连续失败达到阈值后在冷却时间内直接快速失败，冷却后放行一次半开探测。
+	BreakerFailureThreshold int `yaml:"breaker_failure_threshold,omitempty" json:"breaker_failure_threshold,omitempty"` // 连续失败阈值，默认 5；<0 关闭熔断
+	BreakerCooldownSeconds  int `yaml:"breaker_cooldown_seconds,omitempty" json:"breaker_cooldown_seconds,omitempty"`   // 冷却时间（秒），默认 30
+
 	// BatchSize 嵌入批大小（SQLite 索引写入），0 表示默认 64
 	BatchSize int `yaml:"batch_size,omitempty" json:"batch_size,omitempty"`
+	// DedupSimilarityThreshold 入库查重阈值（余弦相似度）：>0 时创建知识项前先与库内已有项比对，
+	// 最高相似度达到该值即视为疑似重复，由用户确认跳过或强制导入；0 表示关闭查重。
+	DedupSimilarityThreshold float64 `yaml:"dedup_similarity_threshold,omitempty" json:"dedup_similarity_threshold,omitempty"`
 	// SubIndexes 传入 Eino indexer.WithSubIndexes（逻辑分区标记，随 Document 元数据传递）
 	SubIndexes []string `yaml:"sub_indexes,omitempty" json:"sub_indexes,omitempty"`
 }
 
 // EmbeddingConfig 嵌入配置
 type EmbeddingConfig struct {
-	Provider string `yaml:"provider" json:"provider"` // 嵌入模型提供商
-	Model    string `yaml:"model" json:"model"`       // 模型名称
-	BaseURL  string `yaml:"base_url" json:"base_url"` // API Base URL
-	APIKey   string `yaml:"api_key" json:"api_key"`   // API Key（从OpenAI配置继承）
+	Provider string            `yaml:"provider" json:"provider"`                   // 嵌入模型提供商：openai（默认）| ollama | tei（本地/离线服务）
+	Model    string            `yaml:"model" json:"model"`                         // 模型名称
+	BaseURL  string            `yaml:"base_url" json:"base_url"`                   // API Base URL
+	APIKey   string            `yaml:"api_key" json:"api_key"`                     // API Key（从OpenAI配置继承）
+	Headers  map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"` // 额外请求头（自建嵌入服务的 X-Api-Key、网关路由头等）
+}
+
+// IsConfigured 嵌入段是否显式配置过（provider/model/base_url/api_key 任一非空）。
+// 嵌入服务与 chat 往往不是同一家，段完全缺失时应明确报错而非静默复用 chat 的配置。
+func (c EmbeddingConfig) IsConfigured() bool {
+	return strings.TrimSpace(c.Provider) != "" ||
+		strings.TrimSpace(c.Model) != "" ||
+		strings.TrimSpace(c.BaseURL) != "" ||
+		strings.TrimSpace(c.APIKey) != ""
 }
 
 // PostRetrieveConfig 检索后处理：固定对正文做规范化去重（最佳实践）、上下文预算截断；PrefetchTopK 用于多取候选再收敛到 top_k。
@@ -2017,6 +2285,70 @@ type PostRetrieveConfig struct {
 	MaxContextTokens int `yaml:"max_context_tokens,omitempty" json:"max_context_tokens,omitempty"`
 }
 
+// NeighborExpansionConfig 命中块上下文扩展（small-to-big 检索）：检索到 chunk_index=k 后，
+// 把同一知识项的 k±window 相邻块一并取回拼接返回，弥补小 chunk 单独看缺乏上下文的问题。
+type NeighborExpansionConfig struct {
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// Window 向前后各扩展的块数；<=0 时取 1，上限 5。
+	Window int `yaml:"window,omitempty" json:"window,omitempty"`
+}
+
+// WindowEffective 返回生效的扩展窗口大小（默认 1，上限 5）。
+func (c NeighborExpansionConfig) WindowEffective() int {
+	if c.Window <= 0 {
+		return 1
+	}
+	if c.Window > 5 {
+		return 5
+	}
+	return c.Window
+}
+
+// TimeDecayConfig 检索分数时间衰减（可选）：渗透知识有时效性，旧 payload 可能已失效，
+// 启用后按知识项 updated_at 距今的时长做指数衰减并乘进最终分数（越新分越高）。默认关闭。
+type TimeDecayConfig struct {
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// HalfLifeDays 半衰期天数：距今每过一个半衰期衰减因子减半；<=0 时取 180。
+	HalfLifeDays float64 `yaml:"half_life_days,omitempty" json:"half_life_days,omitempty"`
+	// MinFactor 衰减因子下限，避免久未更新但仍有效的知识被彻底打压；非 (0,1] 取值时取 0.3。
+	MinFactor float64 `yaml:"min_factor,omitempty" json:"min_factor,omitempty"`
+}
+
+// HalfLifeDaysEffective 返回生效的半衰期天数，未配置时为 180。
+func (c TimeDecayConfig) HalfLifeDaysEffective() float64 {
+	if c.HalfLifeDays <= 0 {
+		return 180
+	}
+	return c.HalfLifeDays
+}
+
+// MinFactorEffective 返回生效的衰减因子下限，未配置或越界时为 0.3。
+func (c TimeDecayConfig) MinFactorEffective() float64 {
+	if c.MinFactor <= 0 || c.MinFactor > 1 {
+		return 0.3
+	}
+	return c.MinFactor
+}
+
+// QueryPreprocessConfig 检索前查询预处理（可选）：去掉中英文停用词、截断过长 query。
+// LLM 传来的整段话直接做嵌入效果差，这里做轻量的确定性清洗；
+// 关键词提取与查询变体扩展由 MultiQuery（LLM 改写）承担。
+type QueryPreprocessConfig struct {
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// MaxQueryRunes 预处理后 query 的最大 Unicode 字符数；<=0 时取 512。
+	MaxQueryRunes int `yaml:"max_query_runes,omitempty" json:"max_query_runes,omitempty"`
+	// ExtraStopwords 在内置中英文停用词之外追加的词（大小写不敏感）。
+	ExtraStopwords []string `yaml:"extra_stopwords,omitempty" json:"extra_stopwords,omitempty"`
+}
+
+// MaxQueryRunesEffective 返回预处理后 query 的最大字符数，未配置时为 512。
+func (c QueryPreprocessConfig) MaxQueryRunesEffective() int {
+	if c.MaxQueryRunes <= 0 {
+		return 512
+	}
+	return c.MaxQueryRunes
+}
+
 // MultiQueryConfig Eino MultiQuery 查询改写（始终启用，无关闭开关）。
 type MultiQueryConfig struct {
 	// MaxQueries LLM 生成的检索变体上限（含原问语义覆盖）；0 表示默认 4。
@@ -2068,12 +2400,60 @@ func (c RerankConfig) ModelEffective(provider string) string {
 type RetrievalConfig struct {
 	TopK                int     `yaml:"top_k" json:"top_k"`                               // 检索Top-K
 	SimilarityThreshold float64 `yaml:"similarity_threshold" json:"similarity_threshold"` // 余弦相似度阈值
+	// ThresholdMode 阈值策略：fixed（默认，按 similarity_threshold 硬过滤）、
+	// adaptive（过滤后为空时自动降到 min_similarity_threshold 重试一次并标注实际阈值）、
+	// topk（忽略阈值，直接返回相似度最高的 TopK）。
+	ThresholdMode string `yaml:"threshold_mode,omitempty" json:"threshold_mode,omitempty"`
+	// MinSimilarityThreshold adaptive 模式降阈值的下限；<=0 时取 0.3。
+	MinSimilarityThreshold float64 `yaml:"min_similarity_threshold,omitempty" json:"min_similarity_threshold,omitempty"`
 	// SubIndexFilter 非空时仅保留 sub_indexes 含该标签（逗号分隔之一）的行；sub_indexes 为空的旧行仍返回。
-	SubIndexFilter string           `yaml:"sub_index_filter,omitempty" json:"sub_index_filter,omitempty"`
-	MultiQuery     MultiQueryConfig `yaml:"multi_query" json:"multi_query"`
-	Rerank         RerankConfig     `yaml:"rerank" json:"rerank"`
+	SubIndexFilter string `yaml:"sub_index_filter,omitempty" json:"sub_index_filter,omitempty"`
+	// AutoInject 为 true 时每条用户消息先自动检索知识库，将 TopK 结果作为额外上下文
+	// 前置注入给 LLM，无需模型主动调用 search_knowledge_base。
+	AutoInject bool `yaml:"auto_inject,omitempty" json:"auto_inject,omitempty"`
+	// AutoInjectTopK 自动注入时的 TopK；<=0 时取 3。
+	AutoInjectTopK int `yaml:"auto_inject_top_k,omitempty" json:"auto_inject_top_k,omitempty"`
+	// CacheTTLSeconds 相同查询条件的检索结果缓存秒数；0 表示不缓存（默认）。
+	// 同一会话里 LLM 常用几乎相同的 query 反复检索，命中缓存可省掉嵌入与全表相似度计算。
+	CacheTTLSeconds int `yaml:"cache_ttl_seconds,omitempty" json:"cache_ttl_seconds,omitempty"`
+	// QueryPreprocess 检索前查询预处理（去停用词、截断）；默认关闭。
+	QueryPreprocess QueryPreprocessConfig `yaml:"query_preprocess,omitempty" json:"query_preprocess,omitempty"`
+	MultiQuery      MultiQueryConfig      `yaml:"multi_query" json:"multi_query"`
+	Rerank          RerankConfig          `yaml:"rerank" json:"rerank"`
 	// PostRetrieve 检索后处理（去重、预算截断）；精排在 MultiQuery 融合后执行。
 	PostRetrieve PostRetrieveConfig `yaml:"post_retrieve,omitempty" json:"post_retrieve,omitempty"`
+	// NeighborExpansion 命中块上下文扩展（small-to-big）；默认关闭。
+	NeighborExpansion NeighborExpansionConfig `yaml:"neighbor_expansion,omitempty" json:"neighbor_expansion,omitempty"`
+	// TimeDecay 按知识项 updated_at 做分数时间衰减；默认关闭。
+	TimeDecay TimeDecayConfig `yaml:"time_decay,omitempty" json:"time_decay,omitempty"`
+}
+
+// ThresholdModeEffective 返回归一化的阈值策略；空或未知取值按 fixed 处理。
+func (r *RetrievalConfig) ThresholdModeEffective() string {
+	switch strings.ToLower(strings.TrimSpace(r.ThresholdMode)) {
+	case "adaptive":
+		return "adaptive"
+	case "topk":
+		return "topk"
+	default:
+		return "fixed"
+	}
+}
+
+// MinSimilarityThresholdEffective 返回 adaptive 模式降阈值的下限，未配置时为 0.3。
+func (r *RetrievalConfig) MinSimilarityThresholdEffective() float64 {
+	if r.MinSimilarityThreshold > 0 {
+		return r.MinSimilarityThreshold
+	}
+	return 0.3
+}
+
+// AutoInjectTopKEffective 返回自动注入检索的 TopK，未配置时为 3。
+func (r *RetrievalConfig) AutoInjectTopKEffective() int {
+	if r.AutoInjectTopK > 0 {
+		return r.AutoInjectTopK
+	}
+	return 3
 }
 
 // RolesConfig 角色配置（已废弃，使用 map[string]RoleConfig 替代）
@@ -212,3 +212,22 @@ func TestLatestUserMessageRunesEffective(t *testing.T) {
 		t.Fatalf("custom latest user tail runes = %d", got)
 	}
 }
+
+func TestNormalizeOpenAIBaseURL(t *testing.T) {
+	cases := map[string]string{
+		"":                                        "https://api.openai.com/v1",
+		"https://x.com/v1":                        "https://x.com/v1",
+		"https://x.com/v1/":                       "https://x.com/v1",
+		"https://x.com/v1/chat/completions":       "https://x.com/v1",
+		"https://x.com/v1/chat/completions/":      "https://x.com/v1",
+		"https://x.com/v1/embeddings":             "https://x.com/v1",
+		"https://x.com/v1/models":                 "https://x.com/v1",
+		" https://x.com/v1 ":                      "https://x.com/v1",
+		"https://gateway.example/openai/proxy/v1": "https://gateway.example/openai/proxy/v1",
+	}
+	for raw, want := range cases {
+		if got := NormalizeOpenAIBaseURL(raw); got != want {
+			t.Fatalf("NormalizeOpenAIBaseURL(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 数据库定期备份：SQLite 单文件损坏会丢掉所有历史。用 VACUUM INTO（SQLite online
+// backup，不长时间阻塞读写且产出无碎片的紧凑副本）按间隔备份到指定目录，保留最近 N 份。
+
+// backupTimestampLayout 备份文件名中的时间戳格式（字典序即时间序，便于按名清理旧份）
+const backupTimestampLayout = "20060102-150405"
+
+// BackupTo 用 VACUUM INTO 将数据库备份到 dir，返回备份文件路径；
+// keep > 0 时备份完成后仅保留最近 keep 份，多余旧份删除。
+func (db *DB) BackupTo(dir string, keep int) (string, error) {
+	if !db.IsSQLite() {
+		return "", fmt.Errorf("数据库备份依赖 VACUUM INTO，仅支持 SQLite 后端")
+	}
+	if strings.TrimSpace(dir) == "" {
+		return "", fmt.Errorf("备份目录不能为空")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建备份目录失败: %w", err)
+	}
+
+	base := strings.TrimSuffix(filepath.Base(db.path), filepath.Ext(db.path))
+	if base == "" || base == "." {
+		base = "database"
+	}
+	target := filepath.Join(dir, fmt.Sprintf("%s-%s.db", base, time.Now().Format(backupTimestampLayout)))
+	if _, err := os.Stat(target); err == nil {
+		// 同一秒内重复触发（如手动接口连点）时目标已存在，直接复用
+		return target, nil
+	}
+
+	if _, err := db.Exec(`VACUUM INTO ?`, target); err != nil {
+		return "", fmt.Errorf("执行 VACUUM INTO 失败: %w", err)
+	}
+	if keep > 0 {
+		db.pruneBackups(dir, base, keep)
+	}
+	return target, nil
+}
+
+// pruneBackups 删除超出保留份数的旧备份；清理失败仅告警，不影响本次备份结果
+func (db *DB) pruneBackups(dir, base string, keep int) {
+	matches, err := filepath.Glob(filepath.Join(dir, base+"-*.db"))
+	if err != nil || len(matches) <= keep {
+		return
+	}
+	// 文件名中的时间戳字典序即时间序，升序排列后删除最旧的
+	sort.Strings(matches)
+	for _, stale := range matches[:len(matches)-keep] {
+		if err := os.Remove(stale); err != nil {
+			db.logger.Warn("清理旧备份失败", zap.String("file", stale), zap.Error(err))
+		}
+	}
+}
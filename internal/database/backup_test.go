@@ -0,0 +1,76 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestBackupToCreatesUsableCopy(t *testing.T) {
+	dir := t.TempDir()
+	db, err := NewDB(filepath.Join(dir, "primary.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	conv, err := db.CreateConversation("备份前的会话", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+
+	backupDir := filepath.Join(dir, "backups")
+	file, err := db.BackupTo(backupDir, 7)
+	if err != nil {
+		t.Fatalf("BackupTo: %v", err)
+	}
+
+	// 备份应是可直接打开的完整数据库
+	restored, err := NewDB(file, zap.NewNop())
+	if err != nil {
+		t.Fatalf("打开备份失败: %v", err)
+	}
+	t.Cleanup(func() { _ = restored.Close() })
+	if _, err := restored.GetConversation(conv.ID); err != nil {
+		t.Fatalf("备份中缺少已有会话: %v", err)
+	}
+}
+
+func TestBackupToPrunesOldBackups(t *testing.T) {
+	dir := t.TempDir()
+	db, err := NewDB(filepath.Join(dir, "primary.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	backupDir := filepath.Join(dir, "backups")
+	if err := os.MkdirAll(backupDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// 预置三份更早的备份（文件名时间戳字典序即时间序）
+	for _, stale := range []string{"primary-20200101-000000.db", "primary-20200102-000000.db", "primary-20200103-000000.db"} {
+		if err := os.WriteFile(filepath.Join(backupDir, stale), []byte("stale"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file, err := db.BackupTo(backupDir, 2)
+	if err != nil {
+		t.Fatalf("BackupTo: %v", err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(backupDir, "primary-*.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("保留份数 = %d, want 2: %v", len(matches), matches)
+	}
+	// 最新一份必须是刚生成的备份
+	if matches[len(matches)-1] != file {
+		t.Fatalf("最新备份 = %s, want %s", matches[len(matches)-1], file)
+	}
+}
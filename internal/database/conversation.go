@@ -38,6 +38,7 @@ type Message struct {
 	ReasoningContent string                   `json:"reasoningContent,omitempty"`
 	MCPExecutionIDs  []string                 `json:"mcpExecutionIds,omitempty"`
 	ProcessDetails   []map[string]interface{} `json:"processDetails,omitempty"`
+	Seq              int64                    `json:"seq,omitempty"` // 会话内单调递增序列号，同一毫秒多条消息时保证严格有序
 	CreatedAt        time.Time                `json:"createdAt"`
 	UpdatedAt        time.Time                `json:"updatedAt"`
 }
@@ -780,6 +781,99 @@ func (db *DB) DeleteConversation(id string) error {
 	return nil
 }
 
+// DeleteConversations 批量删除对话，单条 IN 语句完成删除；级联清理与 [DB.DeleteConversation]
+// 一致（messages、process_details、攻击链等由外键 CASCADE 处理，漏洞记录保留并回填来源标签）。
+// 返回实际删除的对话数。
+func (db *DB) DeleteConversations(ids []string) (int64, error) {
+	clean := make([]string, 0, len(ids))
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		clean = append(clean, id)
+	}
+	if len(clean) == 0 {
+		return 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(clean)), ",")
+	args := make([]interface{}, len(clean))
+	for i, id := range clean {
+		args[i] = id
+	}
+
+	// 删除前补全漏洞来源标签，便于在漏洞库中追溯已删除会话的发现。
+	if _, err := db.Exec(`
+		UPDATE vulnerabilities
+		SET conversation_tag = COALESCE(NULLIF(TRIM(conversation_tag), ''), (SELECT title FROM conversations WHERE id = vulnerabilities.conversation_id))
+		WHERE conversation_id IN (`+placeholders+`)`, args...); err != nil {
+		db.logger.Warn("批量更新漏洞来源标签失败", zap.Error(err))
+	}
+
+	if _, err := db.Exec("DELETE FROM knowledge_retrieval_logs WHERE conversation_id IN ("+placeholders+")", args...); err != nil {
+		db.logger.Warn("批量删除知识检索日志失败", zap.Error(err))
+	}
+
+	// 先记录项目归属，删除后用于清理会话级文件目录
+	projectIDs := make(map[string]string, len(clean))
+	for _, id := range clean {
+		pid, _ := db.GetConversationProjectID(id)
+		projectIDs[id] = pid
+	}
+
+	res, err := db.Exec("DELETE FROM conversations WHERE id IN ("+placeholders+")", args...)
+	if err != nil {
+		return 0, fmt.Errorf("批量删除对话失败: %w", err)
+	}
+	deleted, _ := res.RowsAffected()
+
+	for _, id := range clean {
+		db.removeConversationScopedDirs(id, projectIDs[id])
+	}
+
+	db.logger.Info("对话已批量删除（漏洞记录已保留）",
+		zap.Int("requested", len(clean)),
+		zap.Int64("deleted", deleted),
+	)
+	return deleted, nil
+}
+
+// ListConversationIDsUpdatedBefore 返回 updated_at 早于 before 的对话 ID；before 为零值时返回全部。
+func (db *DB) ListConversationIDsUpdatedBefore(before time.Time) ([]string, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if before.IsZero() {
+		rows, err = db.Query("SELECT id FROM conversations")
+	} else {
+		rows, err = db.Query("SELECT id FROM conversations WHERE updated_at < ?", before)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询对话 ID 失败: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("扫描对话 ID 失败: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历对话 ID 失败: %w", err)
+	}
+	return ids, nil
+}
+
 func sanitizeConversationPathSegment(s string) string {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -926,8 +1020,30 @@ func (db *DB) ConversationHasToolProcessDetails(conversationID string) (bool, er
 	return n > 0, nil
 }
 
+// validMessageRoles 消息允许的角色，与 OpenAI Chat 接口一致；
+// 其他值落库后在回放历史时会被上游 API 拒绝，因此写入前即校验
+var validMessageRoles = map[string]struct{}{
+	"user":      {},
+	"assistant": {},
+	"system":    {},
+	"tool":      {},
+}
+
+// ValidateMessageRole 校验消息角色是否合法（user/assistant/system/tool）
+func ValidateMessageRole(role string) error {
+	if _, ok := validMessageRoles[strings.TrimSpace(role)]; !ok {
+		return fmt.Errorf("非法的消息角色: %q（允许 user/assistant/system/tool）", role)
+	}
+	return nil
+}
+
 // AddMessage 添加消息
 func (db *DB) AddMessage(conversationID, role, content string, mcpExecutionIDs []string) (*Message, error) {
+	role = strings.TrimSpace(role)
+	if err := ValidateMessageRole(role); err != nil {
+		return nil, err
+	}
+
 	id := uuid.New().String()
 	now := time.Now()
 
@@ -941,14 +1057,24 @@ func (db *DB) AddMessage(conversationID, role, content string, mcpExecutionIDs [
 		}
 	}
 
+	// 行级字节上限：超大内容（如粘贴的几 MB 工具输出）spill 到独立文件后存截断版
+	content = db.boundRowText(conversationID, "msg-"+id, content)
+
+	// seq 用子查询在同一条 INSERT 内原子分配（会话内 MAX+1），
+	// 避免同一毫秒并发写入时 created_at 相同导致排序错乱
 	_, err := db.Exec(
-		"INSERT INTO messages (id, conversation_id, role, content, reasoning_content, mcp_execution_ids, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
-		id, conversationID, role, content, "", mcpIDsJSON, now, now,
+		"INSERT INTO messages (id, conversation_id, role, content, reasoning_content, mcp_execution_ids, seq, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(seq), 0) + 1 FROM messages WHERE conversation_id = ?), ?, ?)",
+		id, conversationID, role, content, "", mcpIDsJSON, conversationID, now, now,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("添加消息失败: %w", err)
 	}
 
+	var seq int64
+	if err := db.QueryRow("SELECT seq FROM messages WHERE id = ?", id).Scan(&seq); err != nil {
+		db.logger.Warn("读取消息序列号失败", zap.Error(err))
+	}
+
 	// 更新对话时间
 	if err := db.UpdateConversationTime(conversationID); err != nil {
 		db.logger.Warn("更新对话时间失败", zap.Error(err))
@@ -960,6 +1086,7 @@ func (db *DB) AddMessage(conversationID, role, content string, mcpExecutionIDs [
 		Role:            role,
 		Content:         content,
 		MCPExecutionIDs: mcpExecutionIDs,
+		Seq:             seq,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
@@ -977,6 +1104,11 @@ func (db *DB) UpdateAssistantMessageFinalize(messageID, content string, mcpExecu
 		}
 		mcpIDsJSON = string(jsonData)
 	}
+	if db.rowSpillMaxBytes > 0 && len(content) > db.rowSpillMaxBytes {
+		var convID string
+		_ = db.QueryRow("SELECT conversation_id FROM messages WHERE id = ?", messageID).Scan(&convID)
+		content = db.boundRowText(convID, "msg-"+messageID, content)
+	}
 	_, err := db.Exec(
 		"UPDATE messages SET content = ?, mcp_execution_ids = ?, reasoning_content = ?, updated_at = ? WHERE id = ?",
 		content, mcpIDsJSON, strings.TrimSpace(reasoningContent), time.Now(), messageID,
@@ -987,10 +1119,10 @@ func (db *DB) UpdateAssistantMessageFinalize(messageID, content string, mcpExecu
 	return nil
 }
 
-// GetMessages 获取对话的所有消息
+// GetMessages 获取对话消息；开启自动归档后已归档的较早历史不返回（由「历史摘要」system 消息代表）。
 func (db *DB) GetMessages(conversationID string) ([]Message, error) {
 	rows, err := db.Query(
-		"SELECT id, conversation_id, role, content, reasoning_content, mcp_execution_ids, created_at, updated_at FROM messages WHERE conversation_id = ? ORDER BY created_at ASC, rowid ASC",
+		"SELECT id, conversation_id, role, content, reasoning_content, mcp_execution_ids, seq, created_at, updated_at FROM messages WHERE conversation_id = ? AND COALESCE(archived, 0) = 0 ORDER BY seq ASC, created_at ASC, rowid ASC",
 		conversationID,
 	)
 	if err != nil {
@@ -1006,7 +1138,7 @@ func (db *DB) GetMessages(conversationID string) ([]Message, error) {
 		var createdAt string
 		var updatedAt sql.NullString
 
-		if err := rows.Scan(&msg.ID, &msg.ConversationID, &msg.Role, &msg.Content, &reasoning, &mcpIDsJSON, &createdAt, &updatedAt); err != nil {
+		if err := rows.Scan(&msg.ID, &msg.ConversationID, &msg.Role, &msg.Content, &reasoning, &mcpIDsJSON, &msg.Seq, &createdAt, &updatedAt); err != nil {
 			return nil, fmt.Errorf("扫描消息失败: %w", err)
 		}
 		if reasoning.Valid {
@@ -1053,7 +1185,7 @@ func (db *DB) GetMessages(conversationID string) ([]Message, error) {
 // GetMessagesLite 获取对话消息（不含 reasoning_content），用于历史会话快速切换。
 func (db *DB) GetMessagesLite(conversationID string) ([]Message, error) {
 	rows, err := db.Query(
-		"SELECT id, conversation_id, role, content, mcp_execution_ids, created_at, updated_at FROM messages WHERE conversation_id = ? ORDER BY created_at ASC, rowid ASC",
+		"SELECT id, conversation_id, role, content, mcp_execution_ids, seq, created_at, updated_at FROM messages WHERE conversation_id = ? AND COALESCE(archived, 0) = 0 ORDER BY seq ASC, created_at ASC, rowid ASC",
 		conversationID,
 	)
 	if err != nil {
@@ -1068,7 +1200,7 @@ func (db *DB) GetMessagesLite(conversationID string) ([]Message, error) {
 		var createdAt string
 		var updatedAt sql.NullString
 
-		if err := rows.Scan(&msg.ID, &msg.ConversationID, &msg.Role, &msg.Content, &mcpIDsJSON, &createdAt, &updatedAt); err != nil {
+		if err := rows.Scan(&msg.ID, &msg.ConversationID, &msg.Role, &msg.Content, &mcpIDsJSON, &msg.Seq, &createdAt, &updatedAt); err != nil {
 			return nil, fmt.Errorf("扫描消息失败: %w", err)
 		}
 
@@ -1249,6 +1381,8 @@ func (db *DB) GetAssistantCognitionTexts(assistantMessageID string) (AssistantCo
 	if assistantMessageID == "" {
 		return AssistantCognitionTexts{}, nil
 	}
+	// HITL 可能在事件入队后立即读取，先排空异步写入队列
+	db.FlushProcessDetails()
 	rows, err := db.Query(`
 SELECT event_type, message FROM process_details
 WHERE message_id = ? AND event_type IN ('thinking', 'reasoning_chain', 'planning')
@@ -1304,6 +1438,8 @@ func (db *DB) AddProcessDetailWithID(messageID, conversationID, eventType, messa
 		}
 	}
 
+	message = db.boundRowText(conversationID, "pd-"+id, message)
+	dataJSON = db.boundRowJSON(conversationID, "pd-"+id+"-data", dataJSON)
 	_, err := db.Exec(
 		"INSERT INTO process_details (id, message_id, conversation_id, event_type, message, data, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
 		id, messageID, conversationID, eventType, message, dataJSON, time.Now(),
@@ -1315,12 +1451,21 @@ func (db *DB) AddProcessDetailWithID(messageID, conversationID, eventType, messa
 	return id, nil
 }
 
-// GetProcessDetails 获取消息的过程详情
-func (db *DB) GetProcessDetails(messageID string) ([]ProcessDetail, error) {
-	rows, err := db.Query(
-		"SELECT id, message_id, conversation_id, event_type, message, data, created_at FROM process_details WHERE message_id = ? ORDER BY created_at ASC, rowid ASC",
-		messageID,
-	)
+// GetProcessDetails 获取消息的过程详情；可选按事件类型过滤
+// （如只看 tool_call / error），不传类型时返回全量。
+func (db *DB) GetProcessDetails(messageID string, eventTypes ...string) ([]ProcessDetail, error) {
+	// 先排空异步写入队列，保证刚入队的事件可读
+	db.FlushProcessDetails()
+	query := "SELECT id, message_id, conversation_id, event_type, message, data, created_at FROM process_details WHERE message_id = ?"
+	args := []interface{}{messageID}
+	if len(eventTypes) > 0 {
+		query += " AND event_type IN (?" + strings.Repeat(", ?", len(eventTypes)-1) + ")"
+		for _, et := range eventTypes {
+			args = append(args, et)
+		}
+	}
+	query += " ORDER BY created_at ASC, rowid ASC"
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("查询过程详情失败: %w", err)
 	}
@@ -1353,6 +1498,8 @@ func (db *DB) GetProcessDetails(messageID string) ([]ProcessDetail, error) {
 
 // GetProcessDetailByID 获取单条过程详情。
 func (db *DB) GetProcessDetailByID(id string) (*ProcessDetail, error) {
+	// 前端可能在收到 SSE 事件后立刻按 ID 拉取，先排空异步写入队列
+	db.FlushProcessDetails()
 	var detail ProcessDetail
 	var createdAt string
 	err := db.QueryRow(
@@ -1374,6 +1521,21 @@ func (db *DB) GetProcessDetailByID(id string) (*ProcessDetail, error) {
 	return &detail, nil
 }
 
+// PurgeProcessDetailsBefore 删除早于 cutoff 的过程详情（保留策略；关联的会话消息不受影响）。
+// 返回删除行数。先排空异步写入队列，避免与在途批量写入交错。
+func (db *DB) PurgeProcessDetailsBefore(cutoff time.Time) (int64, error) {
+	db.FlushProcessDetails()
+	res, err := db.Exec(`DELETE FROM process_details WHERE `+sqliteEpochGE("created_at", "<"), formatSQLiteUTC(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("清理过期过程详情失败: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, nil
+	}
+	return n, nil
+}
+
 // ProcessDetailsSummary 过程详情摘要（用于折叠态展示，避免全量加载）。
 type ProcessDetailsSummary struct {
 	Total           int                           `json:"total"`
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HistoryArchiveSummaryPrefix 标识会话的「历史摘要」system 消息；
+// 每个会话至多一条，重复归档时更新该条而不是新增。
+const HistoryArchiveSummaryPrefix = "【历史摘要】"
+
+// IsHistoryArchiveSummaryMessage 判断消息是否为自动归档生成的「历史摘要」system 消息。
+func IsHistoryArchiveSummaryMessage(msg *Message) bool {
+	return msg != nil && msg.Role == "system" && strings.HasPrefix(msg.Content, HistoryArchiveSummaryPrefix)
+}
+
+// ListArchivableMessages 返回可归档的较早消息（未归档消息中除「历史摘要」与最近 keepRecent 条以外的部分，
+// 按 seq 升序），以及已有摘要正文（无摘要时为空串），供 LLM 合并总结。
+func (db *DB) ListArchivableMessages(conversationID string, keepRecent int) ([]Message, string, error) {
+	if keepRecent < 0 {
+		keepRecent = 0
+	}
+	messages, err := db.GetMessages(conversationID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	prevSummary := ""
+	rest := make([]Message, 0, len(messages))
+	for i := range messages {
+		if prevSummary == "" && IsHistoryArchiveSummaryMessage(&messages[i]) {
+			prevSummary = strings.TrimSpace(strings.TrimPrefix(messages[i].Content, HistoryArchiveSummaryPrefix))
+			continue
+		}
+		rest = append(rest, messages[i])
+	}
+	if len(rest) <= keepRecent {
+		return nil, prevSummary, nil
+	}
+	return rest[:len(rest)-keepRecent], prevSummary, nil
+}
+
+// ArchiveMessagesWithSummary 幂等归档：把给定消息标记为已归档，并把摘要写入该会话唯一的
+// 「历史摘要」system 消息（已存在则更新正文，否则插入到当前未归档消息之前）。
+func (db *DB) ArchiveMessagesWithSummary(conversationID string, messageIDs []string, summary string) error {
+	summary = strings.TrimSpace(summary)
+	if len(messageIDs) == 0 || summary == "" {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("开启归档事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	placeholders := make([]string, 0, len(messageIDs))
+	args := make([]interface{}, 0, len(messageIDs)+1)
+	args = append(args, conversationID)
+	for _, id := range messageIDs {
+		placeholders = append(placeholders, "?")
+		args = append(args, id)
+	}
+	if _, err := tx.Exec(
+		"UPDATE messages SET archived = 1 WHERE conversation_id = ? AND id IN ("+strings.Join(placeholders, ",")+")",
+		args...,
+	); err != nil {
+		return fmt.Errorf("标记消息归档失败: %w", err)
+	}
+
+	content := HistoryArchiveSummaryPrefix + summary
+	now := time.Now()
+
+	var summaryID string
+	err = tx.QueryRow(
+		"SELECT id FROM messages WHERE conversation_id = ? AND role = 'system' AND COALESCE(archived, 0) = 0 AND content LIKE ? LIMIT 1",
+		conversationID, HistoryArchiveSummaryPrefix+"%",
+	).Scan(&summaryID)
+	switch {
+	case err == nil:
+		if _, err := tx.Exec(
+			"UPDATE messages SET content = ?, updated_at = ? WHERE id = ?",
+			content, now, summaryID,
+		); err != nil {
+			return fmt.Errorf("更新历史摘要消息失败: %w", err)
+		}
+	case err == sql.ErrNoRows:
+		// 摘要消息排在所有未归档消息之前：取当前最小 seq 再减一
+		var minSeq int64
+		if err := tx.QueryRow(
+			"SELECT COALESCE(MIN(seq), 1) FROM messages WHERE conversation_id = ? AND COALESCE(archived, 0) = 0",
+			conversationID,
+		).Scan(&minSeq); err != nil {
+			return fmt.Errorf("查询消息序列号失败: %w", err)
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO messages (id, conversation_id, role, content, seq, created_at, updated_at) VALUES (?, ?, 'system', ?, ?, ?, ?)",
+			uuid.New().String(), conversationID, content, minSeq-1, now, now,
+		); err != nil {
+			return fmt.Errorf("插入历史摘要消息失败: %w", err)
+		}
+	default:
+		return fmt.Errorf("查询历史摘要消息失败: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交归档事务失败: %w", err)
+	}
+	return nil
+}
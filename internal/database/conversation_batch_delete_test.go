@@ -0,0 +1,98 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestDeleteConversationsRemovesMessages(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "batch-delete.db")
+	db, err := NewDB(dbPath, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	conv1, err := db.CreateConversation("batch one", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	conv2, err := db.CreateConversation("batch two", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	keep, err := db.CreateConversation("keep me", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	if _, err := db.AddMessage(conv1.ID, "user", "hello", nil); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+
+	// 空白与重复 ID 应被忽略，不影响删除计数
+	deleted, err := db.DeleteConversations([]string{conv1.ID, conv2.ID, conv1.ID, " ", ""})
+	if err != nil {
+		t.Fatalf("DeleteConversations: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("deleted = %d, want 2", deleted)
+	}
+
+	if _, err := db.GetConversation(conv1.ID); err == nil {
+		t.Fatal("conv1 should be deleted")
+	}
+	if _, err := db.GetConversation(keep.ID); err != nil {
+		t.Fatalf("keep conversation should survive: %v", err)
+	}
+
+	var msgCount int
+	if err := db.QueryRow("SELECT COUNT(*) FROM messages WHERE conversation_id = ?", conv1.ID).Scan(&msgCount); err != nil {
+		t.Fatalf("count messages: %v", err)
+	}
+	if msgCount != 0 {
+		t.Fatalf("messages of deleted conversation = %d, want 0", msgCount)
+	}
+}
+
+func TestListConversationIDsUpdatedBefore(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "list-before.db")
+	db, err := NewDB(dbPath, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	old, err := db.CreateConversation("old", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	recent, err := db.CreateConversation("recent", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	past := time.Now().Add(-48 * time.Hour)
+	if _, err := db.Exec("UPDATE conversations SET updated_at = ? WHERE id = ?", past, old.ID); err != nil {
+		t.Fatalf("backdate conversation: %v", err)
+	}
+
+	ids, err := db.ListConversationIDsUpdatedBefore(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("ListConversationIDsUpdatedBefore: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != old.ID {
+		t.Fatalf("ids = %v, want only %s", ids, old.ID)
+	}
+
+	all, err := db.ListConversationIDsUpdatedBefore(time.Time{})
+	if err != nil {
+		t.Fatalf("ListConversationIDsUpdatedBefore zero: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("all ids = %v, want both %s and %s", all, old.ID, recent.ID)
+	}
+}
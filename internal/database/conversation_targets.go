@@ -0,0 +1,120 @@
+package database
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// 会话授权目标：多轮对话中 LLM 可能"漂移"到用户未授权的目标（如从 example.com
+// 跳到某个外链域名）。会话可通过接口声明授权目标集合，工具执行前据此校验参数中的
+// 目标主机；未声明授权目标的会话不做限制（保持向后兼容）。
+
+// SetConversationAuthorizedTargets 整体替换会话的授权目标集合；
+// 目标支持 IP、域名或 URL（按 NormalizeTargetHost 归一），空列表表示清除限制。
+// 返回归一去重后的目标集合。
+func (db *DB) SetConversationAuthorizedTargets(conversationID string, rawTargets []string) ([]string, error) {
+	seen := make(map[string]bool)
+	hosts := make([]string, 0, len(rawTargets))
+	for _, raw := range rawTargets {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		host := NormalizeTargetHost(raw)
+		if host == "" {
+			return nil, fmt.Errorf("非法的授权目标: %q（支持 IP、域名或 URL）", raw)
+		}
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM conversation_authorized_targets WHERE conversation_id = ?`, conversationID); err != nil {
+		return nil, fmt.Errorf("清除会话授权目标失败: %w", err)
+	}
+	for _, host := range hosts {
+		if _, err := tx.Exec(
+			`INSERT INTO conversation_authorized_targets (conversation_id, host) VALUES (?, ?)`,
+			conversationID, host,
+		); err != nil {
+			return nil, fmt.Errorf("写入会话授权目标失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+// GetConversationAuthorizedTargets 获取会话声明的授权目标集合；未声明时返回空列表
+func (db *DB) GetConversationAuthorizedTargets(conversationID string) ([]string, error) {
+	rows, err := db.Query(
+		`SELECT host FROM conversation_authorized_targets WHERE conversation_id = ? ORDER BY host`,
+		conversationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询会话授权目标失败: %w", err)
+	}
+	defer rows.Close()
+
+	hosts := make([]string, 0)
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// TargetHostAuthorized 判断目标主机是否在授权集合内：
+// IP 须精确匹配；域名精确匹配或为授权域名的子域（授权 example.com 时允许 api.example.com）
+func TargetHostAuthorized(host string, authorized []string) bool {
+	host = NormalizeTargetHost(host)
+	if host == "" {
+		return false
+	}
+	isIP := net.ParseIP(host) != nil
+	for _, auth := range authorized {
+		if host == auth {
+			return true
+		}
+		if !isIP && strings.HasSuffix(host, "."+auth) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConversationTargetViolations 校验工具参数中的目标主机是否越出会话授权范围，
+// 返回未授权的目标列表。会话未声明授权目标，或参数中提取不到目标时返回空。
+func (db *DB) ConversationTargetViolations(conversationID string, arguments map[string]interface{}) ([]string, error) {
+	hosts := ExtractExecutionTargetHosts(arguments)
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+	authorized, err := db.GetConversationAuthorizedTargets(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(authorized) == 0 {
+		return nil, nil
+	}
+	var violations []string
+	for _, host := range hosts {
+		if !TargetHostAuthorized(host, authorized) {
+			violations = append(violations, host)
+		}
+	}
+	return violations, nil
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestTargetHostAuthorized(t *testing.T) {
+	authorized := []string{"10.0.0.5", "example.com"}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"api.example.com", true},
+		{"10.0.0.5", true},
+		{"https://example.com:8443/admin", true},
+		{"evil.com", false},
+		{"example.com.evil.com", false},
+		{"10.0.0.6", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := TargetHostAuthorized(tt.host, authorized); got != tt.want {
+			t.Errorf("TargetHostAuthorized(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestConversationAuthorizedTargets(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "conv-targets.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	conv, err := db.CreateConversation("授权目标测试", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+
+	// 未声明授权目标：不做限制
+	violations, err := db.ConversationTargetViolations(conv.ID, map[string]interface{}{"target": "evil.com"})
+	if err != nil {
+		t.Fatalf("ConversationTargetViolations: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("未声明授权目标时不应有越权: %v", violations)
+	}
+
+	// 声明授权目标（URL 入参归一、去重）
+	targets, err := db.SetConversationAuthorizedTargets(conv.ID, []string{"https://Example.COM/login", "10.0.0.5", "example.com"})
+	if err != nil {
+		t.Fatalf("SetConversationAuthorizedTargets: %v", err)
+	}
+	if len(targets) != 2 || targets[0] != "10.0.0.5" || targets[1] != "example.com" {
+		t.Fatalf("targets = %v", targets)
+	}
+
+	got, err := db.GetConversationAuthorizedTargets(conv.ID)
+	if err != nil {
+		t.Fatalf("GetConversationAuthorizedTargets: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got = %v", got)
+	}
+
+	// 授权域名的子域允许，外链域名拒绝
+	violations, err = db.ConversationTargetViolations(conv.ID, map[string]interface{}{
+		"urls": []interface{}{"http://api.example.com/x", "http://cdn.evil.com/y"},
+	})
+	if err != nil {
+		t.Fatalf("ConversationTargetViolations: %v", err)
+	}
+	if len(violations) != 1 || violations[0] != "cdn.evil.com" {
+		t.Fatalf("violations = %v", violations)
+	}
+
+	// 非法的授权目标应报错
+	if _, err := db.SetConversationAuthorizedTargets(conv.ID, []string{"not a host"}); err == nil {
+		t.Fatal("非法授权目标未被拒绝")
+	}
+
+	// 空列表清除限制
+	if _, err := db.SetConversationAuthorizedTargets(conv.ID, nil); err != nil {
+		t.Fatalf("清除授权目标失败: %v", err)
+	}
+	violations, err = db.ConversationTargetViolations(conv.ID, map[string]interface{}{"target": "evil.com"})
+	if err != nil {
+		t.Fatalf("ConversationTargetViolations: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("清除授权目标后不应再限制: %v", violations)
+	}
+}
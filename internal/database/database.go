@@ -48,6 +48,8 @@ func configureSQLitePragmas(db *sql.DB) error {
 type DB struct {
 	*sql.DB
 	logger                   *zap.Logger
+	dialect                  *Dialect // 数据库方言（nil 等价于 sqlite，见 dialect.go）
+	path                     string   // 数据库文件路径（用于备份文件命名）
 	conversationArtifactsDir string
 	einoPlantaskBaseDir      string // skills_dir + plantask_rel_dir (per-conversation subdirs)
 	einoCheckpointBaseDir    string // checkpoint_dir root (per-conversation subdirs)
@@ -59,6 +61,8 @@ type DB struct {
 	closeOnce                sync.Once
 	closeErr                 error
 	vulnerabilityCreatedHook func(*Vulnerability)
+	processDetailWriter      *processDetailWriter
+	rowSpillMaxBytes         int // 单行落库内容字节上限（见 row_spill.go；<=0 不限制）
 }
 
 // startPassiveCheckpointLoop 启动后台 PASSIVE checkpoint 循环。
@@ -138,9 +142,12 @@ func NewDB(dbPath string, logger *zap.Logger) (*DB, error) {
 		return nil, fmt.Errorf("配置数据库 PRAGMA 失败: %w", err)
 	}
 
+	sqliteDialect, _ := DialectFor(DriverSQLite)
 	database := &DB{
-		DB:     db,
-		logger: logger,
+		DB:      db,
+		logger:  logger,
+		dialect: sqliteDialect,
+		path:    dbPath,
 	}
 	// Keep conversation-scoped artifacts near database files, so cleanup can follow conversation lifecycle.
 	baseDir := filepath.Join(filepath.Dir(dbPath), "conversation_artifacts")
@@ -156,6 +163,7 @@ func NewDB(dbPath string, logger *zap.Logger) (*DB, error) {
 		return nil, fmt.Errorf("初始化表失败: %w", err)
 	}
 	database.startPassiveCheckpointLoop("conversations")
+	database.processDetailWriter = newProcessDetailWriter(database)
 
 	return database, nil
 }
@@ -196,6 +204,8 @@ func (db *DB) initTables() error {
 		role TEXT NOT NULL,
 		content TEXT NOT NULL,
 		mcp_execution_ids TEXT,
+		seq INTEGER NOT NULL DEFAULT 0,
+		archived INTEGER NOT NULL DEFAULT 0,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL,
 		FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE
@@ -223,6 +233,7 @@ func (db *DB) initTables() error {
 		arguments TEXT NOT NULL,
 		status TEXT NOT NULL,
 		result TEXT,
+		structured_result TEXT,
 		error TEXT,
 		start_time DATETIME NOT NULL,
 		end_time DATETIME,
@@ -231,11 +242,33 @@ func (db *DB) initTables() error {
 		partial_output_bytes INTEGER NOT NULL DEFAULT 0,
 		partial_output_truncated INTEGER NOT NULL DEFAULT 0,
 		partial_output_updated_at DATETIME,
+		command_line TEXT,
+		parent_execution_id TEXT,
 		owner_user_id TEXT,
 		conversation_id TEXT,
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);`
 
+	// 创建工具执行目标索引表：从执行参数中提取统一的目标主机标识，支撑按目标聚合的资产视图
+	createToolExecutionTargetsTable := `
+	CREATE TABLE IF NOT EXISTS tool_execution_targets (
+		execution_id TEXT NOT NULL,
+		host TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (execution_id, host),
+		FOREIGN KEY (execution_id) REFERENCES tool_executions(id) ON DELETE CASCADE
+	);`
+
+	// 创建会话授权目标表：声明本会话允许测试的目标主机，执行工具前据此校验目标是否越权
+	createConversationAuthorizedTargetsTable := `
+	CREATE TABLE IF NOT EXISTS conversation_authorized_targets (
+		conversation_id TEXT NOT NULL,
+		host TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (conversation_id, host),
+		FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE
+	);`
+
 	// 创建工具统计表
 	createToolStatsTable := `
 	CREATE TABLE IF NOT EXISTS tool_stats (
@@ -336,6 +369,15 @@ func (db *DB) initTables() error {
 		FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE
 	);`
 
+	// 创建会话目标档案表（工作记忆：目标主机与已发现端口/服务，跨轮持久注入 system 上下文）
+	createConversationTargetProfilesTable := `
+	CREATE TABLE IF NOT EXISTS conversation_target_profiles (
+		conversation_id TEXT PRIMARY KEY,
+		profile TEXT NOT NULL,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE
+	);`
+
 	// 创建项目表
 	createProjectsTable := `
 	CREATE TABLE IF NOT EXISTS projects (
@@ -720,6 +762,7 @@ func (db *DB) initTables() error {
 	CREATE INDEX IF NOT EXISTS idx_tool_executions_tool_name ON tool_executions(tool_name);
 	CREATE INDEX IF NOT EXISTS idx_tool_executions_start_time ON tool_executions(start_time);
 	CREATE INDEX IF NOT EXISTS idx_tool_executions_status ON tool_executions(status);
+	CREATE INDEX IF NOT EXISTS idx_tool_execution_targets_host ON tool_execution_targets(host);
 	CREATE INDEX IF NOT EXISTS idx_chain_nodes_conversation ON attack_chain_nodes(conversation_id);
 	CREATE INDEX IF NOT EXISTS idx_chain_edges_conversation ON attack_chain_edges(conversation_id);
 	CREATE INDEX IF NOT EXISTS idx_chain_edges_source ON attack_chain_edges(source_node_id);
@@ -805,6 +848,14 @@ func (db *DB) initTables() error {
 		return fmt.Errorf("创建tool_executions表失败: %w", err)
 	}
 
+	if _, err := db.Exec(createToolExecutionTargetsTable); err != nil {
+		return fmt.Errorf("创建tool_execution_targets表失败: %w", err)
+	}
+
+	if _, err := db.Exec(createConversationAuthorizedTargetsTable); err != nil {
+		return fmt.Errorf("创建conversation_authorized_targets表失败: %w", err)
+	}
+
 	if _, err := db.Exec(createToolStatsTable); err != nil {
 		return fmt.Errorf("创建tool_stats表失败: %w", err)
 	}
@@ -838,6 +889,9 @@ func (db *DB) initTables() error {
 	if err := db.migrateRobotUserSessionsTable(); err != nil {
 		return fmt.Errorf("迁移robot_user_sessions表失败: %w", err)
 	}
+	if _, err := db.Exec(createConversationTargetProfilesTable); err != nil {
+		return fmt.Errorf("创建conversation_target_profiles表失败: %w", err)
+	}
 
 	if _, err := db.Exec(createProjectsTable); err != nil {
 		return fmt.Errorf("创建projects表失败: %w", err)
@@ -1002,6 +1056,9 @@ func (db *DB) migrateToolExecutionsPartialOutputColumns() error {
 		{"partial_output_bytes", "ALTER TABLE tool_executions ADD COLUMN partial_output_bytes INTEGER NOT NULL DEFAULT 0"},
 		{"partial_output_truncated", "ALTER TABLE tool_executions ADD COLUMN partial_output_truncated INTEGER NOT NULL DEFAULT 0"},
 		{"partial_output_updated_at", "ALTER TABLE tool_executions ADD COLUMN partial_output_updated_at DATETIME"},
+		{"structured_result", "ALTER TABLE tool_executions ADD COLUMN structured_result TEXT"},
+		{"command_line", "ALTER TABLE tool_executions ADD COLUMN command_line TEXT"},
+		{"parent_execution_id", "ALTER TABLE tool_executions ADD COLUMN parent_execution_id TEXT"},
 	} {
 		if err := db.addColumnIfMissing("tool_executions", col.name, col.stmt); err != nil {
 			return err
@@ -1084,6 +1141,49 @@ func (db *DB) migrateMessagesTable() error {
 			}
 		}
 	}
+
+	// seq：会话内单调递增序列号。created_at 精度不足时同一毫秒多条消息会排序错乱，
+	// GetMessages 改为按 seq 排序保证 tool/assistant 顺序严格有序
+	var seqColCount int
+	errSeq := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('messages') WHERE name='seq'").Scan(&seqColCount)
+	if errSeq != nil {
+		if _, addErr := db.Exec("ALTER TABLE messages ADD COLUMN seq INTEGER NOT NULL DEFAULT 0"); addErr != nil {
+			errMsg := strings.ToLower(addErr.Error())
+			if !strings.Contains(errMsg, "duplicate column") && !strings.Contains(errMsg, "already exists") {
+				return fmt.Errorf("添加 messages.seq 字段失败: %w", addErr)
+			}
+		}
+	} else if seqColCount == 0 {
+		if _, err := db.Exec("ALTER TABLE messages ADD COLUMN seq INTEGER NOT NULL DEFAULT 0"); err != nil {
+			errMsg := strings.ToLower(err.Error())
+			if !strings.Contains(errMsg, "duplicate column") && !strings.Contains(errMsg, "already exists") {
+				return fmt.Errorf("添加 messages.seq 字段失败: %w", err)
+			}
+		}
+	}
+
+	// 回填历史数据：rowid 保持插入顺序，直接作为初始序列号，保证老消息与新消息整体有序
+	_, _ = db.Exec("UPDATE messages SET seq = rowid WHERE seq IS NULL OR seq = 0")
+
+	// archived：会话自动归档标记。较早历史被 LLM 总结进「历史摘要」system 消息后置 1，
+	// GetMessages 只返回未归档消息（摘要+最近若干条）
+	var archColCount int
+	errArch := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('messages') WHERE name='archived'").Scan(&archColCount)
+	if errArch != nil {
+		if _, addErr := db.Exec("ALTER TABLE messages ADD COLUMN archived INTEGER NOT NULL DEFAULT 0"); addErr != nil {
+			errMsg := strings.ToLower(addErr.Error())
+			if !strings.Contains(errMsg, "duplicate column") && !strings.Contains(errMsg, "already exists") {
+				return fmt.Errorf("添加 messages.archived 字段失败: %w", addErr)
+			}
+		}
+	} else if archColCount == 0 {
+		if _, err := db.Exec("ALTER TABLE messages ADD COLUMN archived INTEGER NOT NULL DEFAULT 0"); err != nil {
+			errMsg := strings.ToLower(err.Error())
+			if !strings.Contains(errMsg, "duplicate column") && !strings.Contains(errMsg, "already exists") {
+				return fmt.Errorf("添加 messages.archived 字段失败: %w", err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -1686,10 +1786,12 @@ func (db *DB) initKnowledgeTables() error {
 	createKnowledgeBaseItemsTable := `
 	CREATE TABLE IF NOT EXISTS knowledge_base_items (
 		id TEXT PRIMARY KEY,
+		namespace TEXT NOT NULL DEFAULT '',
 		category TEXT NOT NULL,
 		title TEXT NOT NULL,
 		file_path TEXT NOT NULL,
 		content TEXT,
+		boost REAL NOT NULL DEFAULT 1.0,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL
 	);`
@@ -1702,6 +1804,7 @@ func (db *DB) initKnowledgeTables() error {
 		chunk_index INTEGER NOT NULL,
 		chunk_text TEXT NOT NULL,
 		embedding TEXT NOT NULL,
+		namespace TEXT NOT NULL DEFAULT '',
 		sub_indexes TEXT NOT NULL DEFAULT '',
 		embedding_model TEXT NOT NULL DEFAULT '',
 		embedding_dim INTEGER NOT NULL DEFAULT 0,
@@ -1724,6 +1827,7 @@ func (db *DB) initKnowledgeTables() error {
 	// 创建索引
 	createIndexes := `
 	CREATE INDEX IF NOT EXISTS idx_knowledge_items_category ON knowledge_base_items(category);
+	CREATE INDEX IF NOT EXISTS idx_knowledge_items_namespace ON knowledge_base_items(namespace);
 	CREATE INDEX IF NOT EXISTS idx_knowledge_embeddings_item_id ON knowledge_embeddings(item_id);
 	CREATE INDEX IF NOT EXISTS idx_knowledge_retrieval_logs_conversation ON knowledge_retrieval_logs(conversation_id);
 	CREATE INDEX IF NOT EXISTS idx_knowledge_retrieval_logs_message ON knowledge_retrieval_logs(message_id);
@@ -1742,38 +1846,63 @@ func (db *DB) initKnowledgeTables() error {
 		return fmt.Errorf("创建knowledge_retrieval_logs表失败: %w", err)
 	}
 
-	if _, err := db.Exec(createIndexes); err != nil {
-		return fmt.Errorf("创建索引失败: %w", err)
+	// 先补齐历史库缺失的列，再建索引（idx_knowledge_items_namespace 依赖迁移出的 namespace 列）
+	if err := db.migrateKnowledgeBaseItemsColumns(); err != nil {
+		return fmt.Errorf("迁移 knowledge_base_items 列失败: %w", err)
 	}
 
 	if err := db.migrateKnowledgeEmbeddingsColumns(); err != nil {
 		return fmt.Errorf("迁移 knowledge_embeddings 列失败: %w", err)
 	}
 
+	if _, err := db.Exec(createIndexes); err != nil {
+		return fmt.Errorf("创建索引失败: %w", err)
+	}
+
 	db.logger.Info("知识库数据库表初始化完成")
 	return nil
 }
 
-// migrateKnowledgeEmbeddingsColumns 为已有库补充 sub_indexes、embedding_model、embedding_dim。
-func (db *DB) migrateKnowledgeEmbeddingsColumns() error {
-	var n int
-	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='knowledge_embeddings'`).Scan(&n); err != nil {
-		return err
-	}
-	if n == 0 {
-		return nil
+// migrateKnowledgeBaseItemsColumns 为已有库补充 namespace（多知识库隔离）、boost（检索加权）。
+func (db *DB) migrateKnowledgeBaseItemsColumns() error {
+	migrations := []struct {
+		col  string
+		stmt string
+	}{
+		{"namespace", `ALTER TABLE knowledge_base_items ADD COLUMN namespace TEXT NOT NULL DEFAULT ''`},
+		{"boost", `ALTER TABLE knowledge_base_items ADD COLUMN boost REAL NOT NULL DEFAULT 1.0`},
 	}
+	return db.migrateKnowledgeTableColumns("knowledge_base_items", migrations)
+}
+
+// migrateKnowledgeEmbeddingsColumns 为已有库补充 namespace、sub_indexes、embedding_model、embedding_dim。
+func (db *DB) migrateKnowledgeEmbeddingsColumns() error {
 	migrations := []struct {
 		col  string
 		stmt string
 	}{
+		{"namespace", `ALTER TABLE knowledge_embeddings ADD COLUMN namespace TEXT NOT NULL DEFAULT ''`},
 		{"sub_indexes", `ALTER TABLE knowledge_embeddings ADD COLUMN sub_indexes TEXT NOT NULL DEFAULT ''`},
 		{"embedding_model", `ALTER TABLE knowledge_embeddings ADD COLUMN embedding_model TEXT NOT NULL DEFAULT ''`},
 		{"embedding_dim", `ALTER TABLE knowledge_embeddings ADD COLUMN embedding_dim INTEGER NOT NULL DEFAULT 0`},
 	}
+	return db.migrateKnowledgeTableColumns("knowledge_embeddings", migrations)
+}
+
+func (db *DB) migrateKnowledgeTableColumns(table string, migrations []struct {
+	col  string
+	stmt string
+}) error {
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`, table).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
 	for _, m := range migrations {
 		var colCount int
-		q := `SELECT COUNT(*) FROM pragma_table_info('knowledge_embeddings') WHERE name = ?`
+		q := fmt.Sprintf(`SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name = ?`, table)
 		if err := db.QueryRow(q, m.col).Scan(&colCount); err != nil {
 			return err
 		}
@@ -1793,6 +1922,9 @@ func (db *DB) Close() error {
 		return nil
 	}
 	db.closeOnce.Do(func() {
+		if db.processDetailWriter != nil {
+			db.processDetailWriter.shutdown()
+		}
 		if db.checkpointStop != nil {
 			close(db.checkpointStop)
 			if db.checkpointDone != nil {
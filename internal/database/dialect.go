@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 数据库方言层：仓库内的 SQL 统一用 ? 占位符书写，PostgreSQL 需要 $1、$2…。
+// DB.Query/Exec/QueryRow 与 Begin/BeginTx 返回的 Tx 在下发前统一经 Rebind 转换，
+// sqlite 走零开销直通，便于按 config.database.driver 在 sqlite/postgres 之间切换。
+
+// 支持的数据库驱动名
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Dialect 数据库方言：驱动注册名与占位符转换。
+type Dialect struct {
+	Name       string // sqlite / postgres
+	DriverName string // database/sql 驱动注册名
+}
+
+// DialectFor 按配置里的 driver 名解析方言；空值默认 sqlite。
+func DialectFor(driver string) (*Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(driver)) {
+	case "", DriverSQLite, "sqlite3":
+		return &Dialect{Name: DriverSQLite, DriverName: "sqlite3"}, nil
+	case DriverPostgres, "postgresql", "pg":
+		return &Dialect{Name: DriverPostgres, DriverName: "postgres"}, nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %q（支持 sqlite/postgres）", driver)
+	}
+}
+
+// Registered 判断方言对应的 database/sql 驱动是否已编译进当前二进制。
+func (d *Dialect) Registered() bool {
+	for _, name := range sql.Drivers() {
+		if name == d.DriverName {
+			return true
+		}
+	}
+	return false
+}
+
+// Rebind 把 ? 占位符转换为方言格式；单引号字符串字面量内的 ? 原样保留。
+// sqlite 直通返回。
+func (d *Dialect) Rebind(query string) string {
+	if d == nil || d.Name != DriverPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	inString := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			inString = !inString
+			b.WriteByte(c)
+		case c == '?' && !inString:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// IsSQLite 当前连接是否为 SQLite 后端（VACUUM INTO、PRAGMA 等仅 SQLite 可用）。
+func (db *DB) IsSQLite() bool {
+	return db == nil || db.dialect == nil || db.dialect.Name == DriverSQLite
+}
+
+// rebind 按当前方言转换占位符；sqlite 下零开销直通。
+func (db *DB) rebind(query string) string {
+	if db == nil || db.dialect == nil {
+		return query
+	}
+	return db.dialect.Rebind(query)
+}
+
+// Query / QueryRow / Exec 覆盖内嵌 *sql.DB 的同名方法，统一做占位符转换，
+// 使包内既有的 ? 写法对 postgres 后端透明可用。
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.Query(db.rebind(query), args...)
+}
+
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRow(db.rebind(query), args...)
+}
+
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.Exec(db.rebind(query), args...)
+}
+
+// Tx 包装 *sql.Tx：Exec/Query/QueryRow（含 Context 变体）与 Prepare 同样经 Rebind，
+// 事务内的 ? 写法与非事务路径一致地对 postgres 透明；Commit/Rollback 等由内嵌 *sql.Tx 提供。
+type Tx struct {
+	*sql.Tx
+	dialect *Dialect
+}
+
+// Begin / BeginTx 覆盖内嵌 *sql.DB 的同名方法，返回带占位符转换的 Tx。
+func (db *DB) Begin() (*Tx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, dialect: db.dialect}, nil
+}
+
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, dialect: db.dialect}, nil
+}
+
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.Exec(tx.dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, tx.dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(ctx, tx.dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRow(tx.dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, tx.dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) Prepare(query string) (*sql.Stmt, error) {
+	return tx.Tx.Prepare(tx.dialect.Rebind(query))
+}
+
+func (tx *Tx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return tx.Tx.PrepareContext(ctx, tx.dialect.Rebind(query))
+}
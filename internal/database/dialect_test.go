@@ -0,0 +1,156 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestDialectFor(t *testing.T) {
+	for _, name := range []string{"", "sqlite", "sqlite3", "SQLite"} {
+		d, err := DialectFor(name)
+		if err != nil || d.Name != DriverSQLite {
+			t.Errorf("DialectFor(%q) = %v, %v", name, d, err)
+		}
+	}
+	d, err := DialectFor("postgres")
+	if err != nil || d.Name != DriverPostgres {
+		t.Fatalf("DialectFor(postgres) = %v, %v", d, err)
+	}
+	if _, err := DialectFor("mysql"); err == nil {
+		t.Error("不支持的驱动应报错")
+	}
+}
+
+func TestDialectRebind(t *testing.T) {
+	sqlite, _ := DialectFor(DriverSQLite)
+	pg, _ := DialectFor(DriverPostgres)
+
+	query := "SELECT id FROM messages WHERE conversation_id = ? AND seq > ? ORDER BY seq"
+	if got := sqlite.Rebind(query); got != query {
+		t.Errorf("sqlite 应直通: %q", got)
+	}
+	want := "SELECT id FROM messages WHERE conversation_id = $1 AND seq > $2 ORDER BY seq"
+	if got := pg.Rebind(query); got != want {
+		t.Errorf("pg rebind = %q, want %q", got, want)
+	}
+
+	// 字符串字面量里的 ? 不转换
+	quoted := "SELECT '?' AS mark FROM t WHERE x = ?"
+	if got := pg.Rebind(quoted); got != "SELECT '?' AS mark FROM t WHERE x = $1" {
+		t.Errorf("字面量内 ? 被误转换: %q", got)
+	}
+
+	// 无占位符时原样返回
+	plain := "SELECT COUNT(*) FROM conversations"
+	if got := pg.Rebind(plain); got != plain {
+		t.Errorf("无占位符被改写: %q", got)
+	}
+}
+
+// recordingDriver 记录下发到驱动的 SQL 文本的假驱动，用于验证 Rebind 在 DB 与 Tx
+// 两条路径上都已生效（sqlite 直通无法观察到转换结果）。
+type recordingDriver struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (d *recordingDriver) record(query string) {
+	d.mu.Lock()
+	d.queries = append(d.queries, query)
+	d.mu.Unlock()
+}
+
+func (d *recordingDriver) recorded() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.queries...)
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return &recordingConn{d: d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.record(query)
+	return recordingStmt{}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return recordingTx{}, nil }
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error   { return nil }
+func (recordingTx) Rollback() error { return nil }
+
+type recordingStmt struct{}
+
+func (recordingStmt) Close() error  { return nil }
+func (recordingStmt) NumInput() int { return -1 }
+func (recordingStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+func (recordingStmt) Query([]driver.Value) (driver.Rows, error) { return recordingRows{}, nil }
+
+type recordingRows struct{}
+
+func (recordingRows) Columns() []string         { return nil }
+func (recordingRows) Close() error              { return nil }
+func (recordingRows) Next([]driver.Value) error { return io.EOF }
+
+func TestTxRebindsPlaceholders(t *testing.T) {
+	drv := &recordingDriver{}
+	sql.Register("dialect-tx-recorder", drv)
+	raw, err := sql.Open("dialect-tx-recorder", "")
+	if err != nil {
+		t.Fatalf("open fake driver: %v", err)
+	}
+	t.Cleanup(func() { _ = raw.Close() })
+	pg, _ := DialectFor(DriverPostgres)
+	db := &DB{DB: raw, dialect: pg, logger: zap.NewNop()}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO process_details (id, message_id) VALUES (?, ?)", "pd-1", "msg-1",
+	); err != nil {
+		t.Fatalf("tx.Exec: %v", err)
+	}
+	var n int
+	_ = tx.QueryRow("SELECT COUNT(*) FROM messages WHERE conversation_id = ?", "c1").Scan(&n)
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	got := drv.recorded()
+	want := []string{
+		"INSERT INTO process_details (id, message_id) VALUES ($1, $2)",
+		"SELECT COUNT(*) FROM messages WHERE conversation_id = $1",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("recorded queries = %#v, want %#v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("query[%d] = %q, want %q（事务路径未做占位符转换）", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNewPostgresDBRequiresDriver(t *testing.T) {
+	if _, err := NewPostgresDB("", zap.NewNop()); err == nil || !strings.Contains(err.Error(), "dsn") {
+		t.Errorf("空 DSN 应报错: %v", err)
+	}
+	// 测试二进制未注册 postgres 驱动，应给出明确提示而非 unknown driver
+	_, err := NewPostgresDB("postgres://localhost/test", zap.NewNop())
+	if err == nil || !strings.Contains(err.Error(), "驱动未编译") {
+		t.Errorf("缺少驱动时的错误不明确: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package database
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// 从工具文本输出中统一提取 IOC（IP、域名、端口、URL），供资产视图与攻击链使用：
+// 参数索引只覆盖模型显式传入的目标，nmap/subfinder 等工具在输出里发现的新资产
+// 也应能按目标反查到执行记录，因此执行完成后对输出再做一次提取并写入反向索引
+// （复用 tool_execution_targets 表）。
+
+// Indicators 单段文本中提取到的 IOC 集合（各类均已去重、排序）。
+type Indicators struct {
+	IPs     []string `json:"ips"`
+	Domains []string `json:"domains"`
+	URLs    []string `json:"urls"`
+	Ports   []int    `json:"ports"`
+}
+
+var (
+	indicatorIPv4Re = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	indicatorURLRe  = regexp.MustCompile(`(?i)\bhttps?://[^\s"'<>)\]]+`)
+	// 域名：字母数字标签点分，末级为纯字母"TLD"；误判由前缀字符与文件后缀名单再过滤
+	indicatorDomainRe   = regexp.MustCompile(`(?i)\b(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,24}\b`)
+	indicatorPortRe     = regexp.MustCompile(`\b(\d{1,5})/(?:tcp|udp)\b`)
+	indicatorHostPortRe = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}:(\d{1,5})\b`)
+)
+
+// indicatorFileSuffixes 域名正则会把 "report.txt" 这类文件名误判为域名，按末级后缀过滤。
+// sh/md/io 等既是常见后缀又是真实 TLD 的不在名单内，宁可多留不可错杀。
+var indicatorFileSuffixes = map[string]bool{
+	"txt": true, "html": true, "htm": true, "php": true, "asp": true, "aspx": true,
+	"jsp": true, "js": true, "css": true, "png": true, "jpg": true, "jpeg": true, "gif": true,
+	"svg": true, "ico": true, "json": true, "xml": true, "yaml": true, "yml": true,
+	"log": true, "zip": true, "tar": true, "gz": true, "rar": true, "pdf": true,
+	"exe": true, "dll": true, "conf": true, "ini": true, "bak": true, "sql": true,
+	"tmp": true, "lock": true,
+}
+
+// maxIndicatorScanBytes 单次提取扫描的文本上限；超长输出只取前段，避免正则在超大结果上耗时。
+const maxIndicatorScanBytes = 512 << 10
+
+// maxIndicatorsPerCategory 单类 IOC 上限，防止字典类输出（如目录爆破列表）撑爆内存。
+const maxIndicatorsPerCategory = 256
+
+// ExtractIndicators 从文本中提取 IP、域名、端口、URL；各类去重、过滤明显无效项并排序。
+func ExtractIndicators(text string) *Indicators {
+	out := &Indicators{}
+	if strings.TrimSpace(text) == "" {
+		return out
+	}
+	if len(text) > maxIndicatorScanBytes {
+		text = text[:maxIndicatorScanBytes]
+	}
+
+	seenIP := make(map[string]bool)
+	seenDomain := make(map[string]bool)
+	seenURL := make(map[string]bool)
+	seenPort := make(map[int]bool)
+
+	addPort := func(raw string) {
+		p, err := strconv.Atoi(raw)
+		if err != nil || p < 1 || p > 65535 || seenPort[p] || len(out.Ports) >= maxIndicatorsPerCategory {
+			return
+		}
+		seenPort[p] = true
+		out.Ports = append(out.Ports, p)
+	}
+
+	for _, raw := range indicatorURLRe.FindAllString(text, -1) {
+		u := strings.TrimRight(raw, ".,;:!?'\"")
+		parsed, err := url.Parse(u)
+		if err != nil || parsed.Hostname() == "" {
+			continue
+		}
+		if !seenURL[u] && len(out.URLs) < maxIndicatorsPerCategory {
+			seenURL[u] = true
+			out.URLs = append(out.URLs, u)
+		}
+		if p := parsed.Port(); p != "" {
+			addPort(p)
+		}
+	}
+
+	for _, raw := range indicatorIPv4Re.FindAllString(text, -1) {
+		if seenIP[raw] || len(out.IPs) >= maxIndicatorsPerCategory {
+			continue
+		}
+		if net.ParseIP(raw) == nil || raw == "0.0.0.0" || raw == "255.255.255.255" {
+			continue
+		}
+		seenIP[raw] = true
+		out.IPs = append(out.IPs, raw)
+	}
+
+	for _, m := range indicatorDomainRe.FindAllStringIndex(text, -1) {
+		raw := strings.ToLower(text[m[0]:m[1]])
+		if seenDomain[raw] || len(out.Domains) >= maxIndicatorsPerCategory {
+			continue
+		}
+		// 路径/邮箱/文件名场景：前一个字符是 / . - _ @ 时基本不是独立域名
+		if m[0] > 0 && strings.ContainsRune("/.-_@", rune(text[m[0]-1])) {
+			continue
+		}
+		if indicatorFileSuffixes[raw[strings.LastIndexByte(raw, '.')+1:]] {
+			continue
+		}
+		if net.ParseIP(raw) != nil || !validAssetDomain(raw) {
+			continue
+		}
+		seenDomain[raw] = true
+		out.Domains = append(out.Domains, raw)
+	}
+
+	for _, m := range indicatorPortRe.FindAllStringSubmatch(text, -1) {
+		addPort(m[1])
+	}
+	for _, m := range indicatorHostPortRe.FindAllStringSubmatch(text, -1) {
+		addPort(m[1])
+	}
+
+	sort.Strings(out.IPs)
+	sort.Strings(out.Domains)
+	sort.Strings(out.URLs)
+	sort.Ints(out.Ports)
+	return out
+}
+
+// Hosts 返回可作为目标索引键的主机集合（IP ∪ 域名 ∪ URL 主机，经 NormalizeTargetHost 归一去重）。
+func (ind *Indicators) Hosts() []string {
+	if ind == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var hosts []string
+	add := func(raw string) {
+		if h := NormalizeTargetHost(raw); h != "" && !seen[h] {
+			seen[h] = true
+			hosts = append(hosts, h)
+		}
+	}
+	for _, ip := range ind.IPs {
+		add(ip)
+	}
+	for _, d := range ind.Domains {
+		add(d)
+	}
+	for _, u := range ind.URLs {
+		add(u)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// maxOutputIndexHosts 单次执行从输出建立反向索引的主机上限；输出噪声大，超过即截断。
+const maxOutputIndexHosts = 50
+
+// indexToolExecutionOutputTargets 执行完成后从输出文本提取 IOC，把发现的目标主机写入
+// tool_execution_targets 反向索引（与参数索引同表，INSERT OR IGNORE 幂等）。
+func (db *DB) indexToolExecutionOutputTargets(executionID, outputText string) {
+	hosts := ExtractIndicators(outputText).Hosts()
+	if len(hosts) > maxOutputIndexHosts {
+		hosts = hosts[:maxOutputIndexHosts]
+	}
+	for _, host := range hosts {
+		if _, err := db.Exec(
+			`INSERT OR IGNORE INTO tool_execution_targets (execution_id, host) VALUES (?, ?)`,
+			executionID, host,
+		); err != nil {
+			db.logger.Warn("写入输出目标索引失败",
+				zap.String("executionId", executionID),
+				zap.String("host", host),
+				zap.Error(err),
+			)
+		}
+	}
+}
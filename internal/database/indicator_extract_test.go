@@ -0,0 +1,108 @@
+package database
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"cyberstrike-ai/internal/mcp"
+
+	"go.uber.org/zap"
+)
+
+func TestExtractIndicators(t *testing.T) {
+	text := `Nmap scan report for web01.example.com (10.0.0.5)
+80/tcp   open  http    Apache httpd 2.4.41
+443/tcp  open  https
+Found: https://admin.example.com:8443/login
+redirect -> http://10.0.0.6/setup
+listening on 10.0.0.5:3306
+invalid ip 999.1.2.3 should be dropped
+saved to /tmp/report.txt and scan-output.log
+duplicate 10.0.0.5 again`
+
+	got := ExtractIndicators(text)
+	if want := []string{"10.0.0.5", "10.0.0.6"}; !reflect.DeepEqual(got.IPs, want) {
+		t.Fatalf("IPs = %v, want %v", got.IPs, want)
+	}
+	// admin.example.com 在 URL 内（前缀 / 被路径过滤拦下），经 URLs/Hosts 覆盖
+	if want := []string{"web01.example.com"}; !reflect.DeepEqual(got.Domains, want) {
+		t.Fatalf("Domains = %v, want %v", got.Domains, want)
+	}
+	if want := []string{"http://10.0.0.6/setup", "https://admin.example.com:8443/login"}; !reflect.DeepEqual(got.URLs, want) {
+		t.Fatalf("URLs = %v, want %v", got.URLs, want)
+	}
+	if want := []int{80, 443, 3306, 8443}; !reflect.DeepEqual(got.Ports, want) {
+		t.Fatalf("Ports = %v, want %v", got.Ports, want)
+	}
+}
+
+func TestExtractIndicatorsFiltersNoise(t *testing.T) {
+	got := ExtractIndicators("wrote index.html and app.min.js; broadcast 255.255.255.255; bind 0.0.0.0; see docs/readme.txt")
+	if len(got.IPs) != 0 {
+		t.Fatalf("广播/任意地址应被过滤: %v", got.IPs)
+	}
+	if len(got.Domains) != 0 {
+		t.Fatalf("文件名不应识别为域名: %v", got.Domains)
+	}
+	if empty := ExtractIndicators("   "); len(empty.IPs)+len(empty.Domains)+len(empty.URLs)+len(empty.Ports) != 0 {
+		t.Fatalf("空文本应返回空集合: %+v", empty)
+	}
+}
+
+func TestIndicatorsHosts(t *testing.T) {
+	ind := &Indicators{
+		IPs:     []string{"10.0.0.5"},
+		Domains: []string{"example.com"},
+		URLs:    []string{"https://example.com/login", "http://10.0.0.9:8080"},
+	}
+	want := []string{"10.0.0.5", "10.0.0.9", "example.com"}
+	if got := ind.Hosts(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Hosts = %v, want %v", got, want)
+	}
+}
+
+func TestSaveToolExecutionIndexesOutputTargets(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "indicator.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	now := time.Now()
+	exec := &mcp.ToolExecution{
+		ID:        "exec-ioc",
+		ToolName:  "subfinder",
+		Arguments: map[string]interface{}{"domain": "example.com"},
+		Status:    "completed",
+		StartTime: now,
+		EndTime:   &now,
+		Result: &mcp.ToolResult{Content: []mcp.Content{{
+			Type: "text",
+			Text: "api.example.com\nmail.example.com resolves to 10.0.0.7",
+		}}},
+	}
+	if err := db.SaveToolExecution(exec); err != nil {
+		t.Fatalf("SaveToolExecution: %v", err)
+	}
+
+	rows, err := db.Query(`SELECT host FROM tool_execution_targets WHERE execution_id = ? ORDER BY host`, exec.ID)
+	if err != nil {
+		t.Fatalf("query targets: %v", err)
+	}
+	defer rows.Close()
+	var hosts []string
+	for rows.Next() {
+		var h string
+		if err := rows.Scan(&h); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		hosts = append(hosts, h)
+	}
+	// 参数里的 example.com + 输出里发现的子域与 IP
+	want := []string{"10.0.0.7", "api.example.com", "example.com", "mail.example.com"}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Fatalf("hosts = %v, want %v", hosts, want)
+	}
+}
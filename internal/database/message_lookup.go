@@ -0,0 +1,26 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetMessageByID 按 ID 获取指定会话内的单条消息。
+// 不过滤 archived：消息引用（replyToMessageId）要求被引用内容即使已被
+// 历史归档/裁剪也能取到完整原文。
+func (db *DB) GetMessageByID(conversationID, messageID string) (*Message, error) {
+	row := db.QueryRow(
+		"SELECT id, conversation_id, role, content, created_at FROM messages WHERE id = ? AND conversation_id = ?",
+		messageID, conversationID,
+	)
+	var msg Message
+	var createdAt string
+	if err := row.Scan(&msg.ID, &msg.ConversationID, &msg.Role, &msg.Content, &createdAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("消息不存在: %s", messageID)
+		}
+		return nil, fmt.Errorf("查询消息失败: %w", err)
+	}
+	msg.CreatedAt = parseDBTime(createdAt)
+	return &msg, nil
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGetMessageByID(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	conv, err := db.CreateConversation("引用测试", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	msg, err := db.AddMessage(conv.ID, "assistant", "开放端口：22、80、8080", nil)
+	if err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+
+	got, err := db.GetMessageByID(conv.ID, msg.ID)
+	if err != nil {
+		t.Fatalf("GetMessageByID: %v", err)
+	}
+	if got.Content != "开放端口：22、80、8080" || got.Role != "assistant" {
+		t.Errorf("消息内容不符: %+v", got)
+	}
+
+	// 不能跨会话引用
+	other, err := db.CreateConversation("另一会话", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetMessageByID(other.ID, msg.ID); err == nil {
+		t.Error("跨会话引用应报错")
+	}
+
+	if _, err := db.GetMessageByID(conv.ID, "missing-id"); err == nil {
+		t.Error("不存在的消息应报错")
+	}
+}
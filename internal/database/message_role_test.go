@@ -0,0 +1,55 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestValidateMessageRole(t *testing.T) {
+	for _, role := range []string{"user", "assistant", "system", "tool", " user "} {
+		if err := ValidateMessageRole(role); err != nil {
+			t.Fatalf("ValidateMessageRole(%q) = %v，应接受", role, err)
+		}
+	}
+	for _, role := range []string{"", "User", "asistant", "function", "hacker"} {
+		if err := ValidateMessageRole(role); err == nil {
+			t.Fatalf("ValidateMessageRole(%q) 应拒绝", role)
+		}
+	}
+}
+
+func TestAddMessageRejectsInvalidRole(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "message-role.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	conversation, err := db.CreateConversation("message role", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+
+	if _, err := db.AddMessage(conversation.ID, "asistant", "typo role", nil); err == nil {
+		t.Fatalf("非法角色应返回错误")
+	}
+	messages, err := db.GetMessages(conversation.ID)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("非法角色不应落库: %d", len(messages))
+	}
+
+	if _, err := db.AddMessage(conversation.ID, " user ", "trimmed role", nil); err != nil {
+		t.Fatalf("应容忍角色首尾空白: %v", err)
+	}
+	messages, err = db.GetMessages(conversation.ID)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(messages) != 1 || messages[0].Role != "user" {
+		t.Fatalf("角色应按去空白后的值落库: %+v", messages)
+	}
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func setupMessageSeqTest(t *testing.T) (*DB, string) {
+	t.Helper()
+	db, err := NewDB(filepath.Join(t.TempDir(), "message-seq.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	conversation, err := db.CreateConversation("message seq", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	return db, conversation.ID
+}
+
+func TestAddMessageAssignsMonotonicSeq(t *testing.T) {
+	db, conversationID := setupMessageSeqTest(t)
+	var prev int64
+	for i := 0; i < 5; i++ {
+		msg, err := db.AddMessage(conversationID, "assistant", fmt.Sprintf("msg-%d", i), nil)
+		if err != nil {
+			t.Fatalf("AddMessage: %v", err)
+		}
+		if msg.Seq <= prev {
+			t.Fatalf("seq not monotonic: got %d after %d", msg.Seq, prev)
+		}
+		prev = msg.Seq
+	}
+}
+
+func TestGetMessagesOrdersBySeqWhenCreatedAtEqual(t *testing.T) {
+	db, conversationID := setupMessageSeqTest(t)
+
+	// 模拟高频写入：同一 created_at 下多条消息，仅 seq 能区分先后
+	now := time.Now()
+	for i, content := range []string{"user question", "assistant answer", "tool result"} {
+		if _, err := db.Exec(
+			"INSERT INTO messages (id, conversation_id, role, content, reasoning_content, mcp_execution_ids, seq, created_at, updated_at) VALUES (?, ?, ?, ?, '', '', ?, ?, ?)",
+			fmt.Sprintf("m-%d", i), conversationID, "assistant", content, int64(i+1), now, now,
+		); err != nil {
+			t.Fatalf("insert message: %v", err)
+		}
+	}
+
+	messages, err := db.GetMessages(conversationID)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("messages = %d, want 3", len(messages))
+	}
+	for i, want := range []string{"user question", "assistant answer", "tool result"} {
+		if messages[i].Content != want {
+			t.Fatalf("message %d content = %q, want %q", i, messages[i].Content, want)
+		}
+		if messages[i].Seq != int64(i+1) {
+			t.Fatalf("message %d seq = %d, want %d", i, messages[i].Seq, i+1)
+		}
+	}
+}
@@ -29,6 +29,16 @@ func (db *DB) SaveToolExecution(exec *mcp.ToolExecution) error {
 		}
 	}
 
+	var structuredJSON sql.NullString
+	if len(exec.StructuredResult) > 0 {
+		structuredBytes, err := json.Marshal(exec.StructuredResult)
+		if err != nil {
+			db.logger.Warn("序列化结构化结果失败", zap.Error(err))
+		} else {
+			structuredJSON = sql.NullString{String: string(structuredBytes), Valid: true}
+		}
+	}
+
 	var errorText sql.NullString
 	if exec.Error != "" {
 		errorText = sql.NullString{String: exec.Error, Valid: true}
@@ -54,8 +64,8 @@ func (db *DB) SaveToolExecution(exec *mcp.ToolExecution) error {
 
 	query := `
 		INSERT OR REPLACE INTO tool_executions 
-		(id, tool_name, arguments, status, result, error, start_time, end_time, duration_ms, partial_output, partial_output_bytes, partial_output_truncated, partial_output_updated_at, owner_user_id, conversation_id, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		(id, tool_name, arguments, status, result, structured_result, error, start_time, end_time, duration_ms, partial_output, partial_output_bytes, partial_output_truncated, partial_output_updated_at, command_line, parent_execution_id, owner_user_id, conversation_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err = db.Exec(query,
@@ -64,6 +74,7 @@ func (db *DB) SaveToolExecution(exec *mcp.ToolExecution) error {
 		string(argsJSON),
 		exec.Status,
 		resultJSON,
+		structuredJSON,
 		errorText,
 		exec.StartTime,
 		endTime,
@@ -72,6 +83,8 @@ func (db *DB) SaveToolExecution(exec *mcp.ToolExecution) error {
 		exec.PartialOutputBytes,
 		partialTruncated,
 		partialUpdatedAt,
+		sqlNullString(exec.CommandLine),
+		sqlNullString(exec.ParentExecutionID),
 		strings.TrimSpace(exec.OwnerUserID),
 		strings.TrimSpace(exec.ConversationID),
 		time.Now(),
@@ -82,6 +95,13 @@ func (db *DB) SaveToolExecution(exec *mcp.ToolExecution) error {
 		return err
 	}
 
+	// 维护目标主机索引，供按目标聚合的资产视图（/api/targets/:host）使用
+	db.indexToolExecutionTargets(exec.ID, exec.Arguments)
+	// 执行完成后再从输出文本提取 IOC（IP/域名/URL），把发现的新资产也纳入目标→执行反向索引
+	if exec.Status == "completed" && exec.Result != nil {
+		db.indexToolExecutionOutputTargets(exec.ID, mcp.ToolResultPlainText(exec.Result))
+	}
+
 	return nil
 }
 
@@ -111,10 +131,10 @@ func sqlNullString(s string) sql.NullString {
 
 // CountToolExecutions 统计工具执行记录总数
 func (db *DB) CountToolExecutions(status, toolName string) (int, error) {
-	return db.CountToolExecutionsForAccess(status, toolName, RBACListAccess{Scope: RBACScopeAll})
+	return db.CountToolExecutionsForAccess(status, toolName, "", RBACListAccess{Scope: RBACScopeAll})
 }
 
-func (db *DB) CountToolExecutionsForAccess(status, toolName string, access RBACListAccess) (int, error) {
+func (db *DB) CountToolExecutionsForAccess(status, toolName, conversationID string, access RBACListAccess) (int, error) {
 	query := `SELECT COUNT(*) FROM tool_executions`
 	args := []interface{}{}
 	conditions := []string{}
@@ -127,6 +147,10 @@ func (db *DB) CountToolExecutionsForAccess(status, toolName string, access RBACL
 		conditions = append(conditions, "LOWER(tool_name) LIKE ?")
 		args = append(args, "%"+strings.ToLower(toolName)+"%")
 	}
+	if conversationID != "" {
+		conditions = append(conditions, "conversation_id = ?")
+		args = append(args, conversationID)
+	}
 	if len(conditions) > 0 {
 		query += ` WHERE ` + conditions[0]
 		for i := 1; i < len(conditions); i++ {
@@ -161,7 +185,7 @@ func (db *DB) LoadToolExecutionsWithPagination(offset, limit int, status, toolNa
 	}
 
 	query := `
-		SELECT id, tool_name, arguments, status, result, error, start_time, end_time, duration_ms, COALESCE(owner_user_id, ''), COALESCE(conversation_id, '')
+		SELECT id, tool_name, arguments, status, result, structured_result, error, start_time, end_time, duration_ms, COALESCE(owner_user_id, ''), COALESCE(conversation_id, '')
 		FROM tool_executions
 	`
 	args := []interface{}{}
@@ -195,6 +219,7 @@ func (db *DB) LoadToolExecutionsWithPagination(offset, limit int, status, toolNa
 		var exec mcp.ToolExecution
 		var argsJSON string
 		var resultJSON sql.NullString
+		var structuredJSON sql.NullString
 		var errorText sql.NullString
 		var endTime sql.NullTime
 		var durationMs sql.NullInt64
@@ -205,6 +230,7 @@ func (db *DB) LoadToolExecutionsWithPagination(offset, limit int, status, toolNa
 			&argsJSON,
 			&exec.Status,
 			&resultJSON,
+			&structuredJSON,
 			&errorText,
 			&exec.StartTime,
 			&endTime,
@@ -233,6 +259,13 @@ func (db *DB) LoadToolExecutionsWithPagination(offset, limit int, status, toolNa
 			}
 		}
 
+		// 解析结构化结果
+		if structuredJSON.Valid && structuredJSON.String != "" {
+			if err := json.Unmarshal([]byte(structuredJSON.String), &exec.StructuredResult); err != nil {
+				db.logger.Warn("解析结构化结果失败", zap.Error(err))
+			}
+		}
+
 		// 设置错误
 		if errorText.Valid {
 			exec.Error = errorText.String
@@ -254,7 +287,100 @@ func (db *DB) LoadToolExecutionsWithPagination(offset, limit int, status, toolNa
 	return executions, nil
 }
 
-func toolExecutionsFilterSQL(status, toolName string) (string, []interface{}) {
+// LoadToolExecutionsByConversation 按会话分页加载完整执行记录（含 args/result），
+// 按开始时间升序排列，便于前端按对话时间线展示。返回当前页和总数。
+func (db *DB) LoadToolExecutionsByConversation(conversationID string, offset, limit int) ([]*mcp.ToolExecution, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM tool_executions WHERE conversation_id = ?`, conversationID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, tool_name, arguments, status, result, structured_result, error, start_time, end_time, duration_ms, COALESCE(command_line, ''), COALESCE(parent_execution_id, ''), COALESCE(owner_user_id, ''), COALESCE(conversation_id, '')
+		FROM tool_executions
+		WHERE conversation_id = ?
+		ORDER BY start_time ASC LIMIT ? OFFSET ?
+	`
+	rows, err := db.Query(query, conversationID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	executions := make([]*mcp.ToolExecution, 0, limit)
+	for rows.Next() {
+		var exec mcp.ToolExecution
+		var argsJSON string
+		var resultJSON sql.NullString
+		var structuredJSON sql.NullString
+		var errorText sql.NullString
+		var endTime sql.NullTime
+		var durationMs sql.NullInt64
+
+		if err := rows.Scan(
+			&exec.ID,
+			&exec.ToolName,
+			&argsJSON,
+			&exec.Status,
+			&resultJSON,
+			&structuredJSON,
+			&errorText,
+			&exec.StartTime,
+			&endTime,
+			&durationMs,
+			&exec.CommandLine,
+			&exec.ParentExecutionID,
+			&exec.OwnerUserID,
+			&exec.ConversationID,
+		); err != nil {
+			db.logger.Warn("加载会话执行记录失败", zap.Error(err))
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(argsJSON), &exec.Arguments); err != nil {
+			db.logger.Warn("解析执行参数失败", zap.Error(err))
+			exec.Arguments = make(map[string]interface{})
+		}
+		if resultJSON.Valid && resultJSON.String != "" {
+			var result mcp.ToolResult
+			if err := json.Unmarshal([]byte(resultJSON.String), &result); err != nil {
+				db.logger.Warn("解析执行结果失败", zap.Error(err))
+			} else {
+				exec.Result = &result
+			}
+		}
+		if structuredJSON.Valid && structuredJSON.String != "" {
+			if err := json.Unmarshal([]byte(structuredJSON.String), &exec.StructuredResult); err != nil {
+				db.logger.Warn("解析结构化结果失败", zap.Error(err))
+			}
+		}
+		if errorText.Valid {
+			exec.Error = errorText.String
+		}
+		if endTime.Valid {
+			exec.EndTime = &endTime.Time
+		}
+		if durationMs.Valid {
+			exec.Duration = time.Duration(durationMs.Int64) * time.Millisecond
+		}
+
+		executions = append(executions, &exec)
+	}
+
+	return executions, total, nil
+}
+
+func toolExecutionsFilterSQL(status, toolName, conversationID string) (string, []interface{}) {
 	args := []interface{}{}
 	conditions := []string{}
 	if status != "" {
@@ -265,6 +391,10 @@ func toolExecutionsFilterSQL(status, toolName string) (string, []interface{}) {
 		conditions = append(conditions, "LOWER(tool_name) LIKE ?")
 		args = append(args, "%"+strings.ToLower(toolName)+"%")
 	}
+	if conversationID != "" {
+		conditions = append(conditions, "conversation_id = ?")
+		args = append(args, conversationID)
+	}
 	if len(conditions) == 0 {
 		return "", args
 	}
@@ -421,10 +551,10 @@ func (db *DB) LoadToolStatsSummaryForAccess(topN int, access RBACListAccess) (*T
 
 // LoadToolExecutionListPage 分页加载执行记录列表（不含 arguments/result，供监控列表使用）
 func (db *DB) LoadToolExecutionListPage(offset, limit int, status, toolName string) ([]*mcp.ToolExecution, error) {
-	return db.LoadToolExecutionListPageForAccess(offset, limit, status, toolName, RBACListAccess{Scope: RBACScopeAll})
+	return db.LoadToolExecutionListPageForAccess(offset, limit, status, toolName, "", RBACListAccess{Scope: RBACScopeAll})
 }
 
-func (db *DB) LoadToolExecutionListPageForAccess(offset, limit int, status, toolName string, access RBACListAccess) ([]*mcp.ToolExecution, error) {
+func (db *DB) LoadToolExecutionListPageForAccess(offset, limit int, status, toolName, conversationID string, access RBACListAccess) ([]*mcp.ToolExecution, error) {
 	if limit <= 0 {
 		limit = 20
 	}
@@ -436,7 +566,7 @@ func (db *DB) LoadToolExecutionListPageForAccess(offset, limit int, status, tool
 		SELECT id, tool_name, status, start_time, end_time, duration_ms, COALESCE(owner_user_id, ''), COALESCE(conversation_id, '')
 		FROM tool_executions
 	`
-	whereSQL, args := toolExecutionsFilterSQL(status, toolName)
+	whereSQL, args := toolExecutionsFilterSQL(status, toolName, conversationID)
 	query += whereSQL
 	query, args = appendToolExecutionAccessSQL(query, args, access, whereSQL != "")
 	query += ` ORDER BY start_time DESC LIMIT ? OFFSET ?`
@@ -507,9 +637,9 @@ func appendToolExecutionAccessSQL(query string, args []interface{}, access RBACL
 // GetToolExecution 根据ID获取单条工具执行记录
 func (db *DB) GetToolExecution(id string) (*mcp.ToolExecution, error) {
 	query := `
-		SELECT id, tool_name, arguments, status, result, error, start_time, end_time, duration_ms,
+		SELECT id, tool_name, arguments, status, result, structured_result, error, start_time, end_time, duration_ms,
 		       COALESCE(partial_output, ''), COALESCE(partial_output_bytes, 0), COALESCE(partial_output_truncated, 0), partial_output_updated_at,
-		       COALESCE(owner_user_id, ''), COALESCE(conversation_id, '')
+		       COALESCE(command_line, ''), COALESCE(parent_execution_id, ''), COALESCE(owner_user_id, ''), COALESCE(conversation_id, '')
 		FROM tool_executions
 		WHERE id = ?
 	`
@@ -519,6 +649,7 @@ func (db *DB) GetToolExecution(id string) (*mcp.ToolExecution, error) {
 	var exec mcp.ToolExecution
 	var argsJSON string
 	var resultJSON sql.NullString
+	var structuredJSON sql.NullString
 	var errorText sql.NullString
 	var endTime sql.NullTime
 	var durationMs sql.NullInt64
@@ -531,6 +662,7 @@ func (db *DB) GetToolExecution(id string) (*mcp.ToolExecution, error) {
 		&argsJSON,
 		&exec.Status,
 		&resultJSON,
+		&structuredJSON,
 		&errorText,
 		&exec.StartTime,
 		&endTime,
@@ -539,6 +671,8 @@ func (db *DB) GetToolExecution(id string) (*mcp.ToolExecution, error) {
 		&exec.PartialOutputBytes,
 		&partialTruncated,
 		&partialUpdatedAt,
+		&exec.CommandLine,
+		&exec.ParentExecutionID,
 		&exec.OwnerUserID,
 		&exec.ConversationID,
 	)
@@ -560,6 +694,12 @@ func (db *DB) GetToolExecution(id string) (*mcp.ToolExecution, error) {
 		}
 	}
 
+	if structuredJSON.Valid && structuredJSON.String != "" {
+		if err := json.Unmarshal([]byte(structuredJSON.String), &exec.StructuredResult); err != nil {
+			db.logger.Warn("解析结构化结果失败", zap.Error(err))
+		}
+	}
+
 	if errorText.Valid {
 		exec.Error = errorText.String
 	}
@@ -738,7 +878,7 @@ func (db *DB) GetToolExecutionsByIds(ids []string) ([]*mcp.ToolExecution, error)
 	}
 
 	query := `
-		SELECT id, tool_name, arguments, status, result, error, start_time, end_time, duration_ms, COALESCE(owner_user_id, ''), COALESCE(conversation_id, '')
+		SELECT id, tool_name, arguments, status, result, structured_result, error, start_time, end_time, duration_ms, COALESCE(owner_user_id, ''), COALESCE(conversation_id, '')
 		FROM tool_executions
 		WHERE id IN (` + strings.Join(placeholders, ",") + `)
 	`
@@ -754,6 +894,7 @@ func (db *DB) GetToolExecutionsByIds(ids []string) ([]*mcp.ToolExecution, error)
 		var exec mcp.ToolExecution
 		var argsJSON string
 		var resultJSON sql.NullString
+		var structuredJSON sql.NullString
 		var errorText sql.NullString
 		var endTime sql.NullTime
 		var durationMs sql.NullInt64
@@ -764,6 +905,7 @@ func (db *DB) GetToolExecutionsByIds(ids []string) ([]*mcp.ToolExecution, error)
 			&argsJSON,
 			&exec.Status,
 			&resultJSON,
+			&structuredJSON,
 			&errorText,
 			&exec.StartTime,
 			&endTime,
@@ -792,6 +934,13 @@ func (db *DB) GetToolExecutionsByIds(ids []string) ([]*mcp.ToolExecution, error)
 			}
 		}
 
+		// 解析结构化结果
+		if structuredJSON.Valid && structuredJSON.String != "" {
+			if err := json.Unmarshal([]byte(structuredJSON.String), &exec.StructuredResult); err != nil {
+				db.logger.Warn("解析结构化结果失败", zap.Error(err))
+			}
+		}
+
 		// 设置错误
 		if errorText.Valid {
 			exec.Error = errorText.String
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cyberstrike-ai/internal/mcp"
+
+	"go.uber.org/zap"
+)
+
+func TestLoadToolExecutionsByConversation(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "conv-executions.db")
+	db, err := NewDB(dbPath, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		end := now.Add(time.Duration(i)*time.Minute + 30*time.Second)
+		exec := &mcp.ToolExecution{
+			ID:             fmt.Sprintf("exec-%d", i),
+			ToolName:       "nmap::scan",
+			Arguments:      map[string]interface{}{"target": fmt.Sprintf("192.0.2.%d", i)},
+			Status:         "completed",
+			StartTime:      now.Add(time.Duration(i) * time.Minute),
+			EndTime:        &end,
+			Duration:       30 * time.Second,
+			ConversationID: "conv-a",
+		}
+		if i == 4 {
+			exec.ConversationID = "conv-b"
+		}
+		if err := db.SaveToolExecution(exec); err != nil {
+			t.Fatalf("SaveToolExecution(%d): %v", i, err)
+		}
+	}
+
+	executions, total, err := db.LoadToolExecutionsByConversation("conv-a", 0, 3)
+	if err != nil {
+		t.Fatalf("LoadToolExecutionsByConversation: %v", err)
+	}
+	if total != 4 {
+		t.Fatalf("total = %d, want 4", total)
+	}
+	if len(executions) != 3 {
+		t.Fatalf("len(executions) = %d, want 3", len(executions))
+	}
+	// 按开始时间升序
+	if executions[0].ID != "exec-0" || executions[2].ID != "exec-2" {
+		t.Fatalf("order: got %s..%s, want exec-0..exec-2", executions[0].ID, executions[2].ID)
+	}
+	if executions[0].Arguments["target"] != "192.0.2.0" {
+		t.Fatalf("arguments not round-tripped: %v", executions[0].Arguments)
+	}
+
+	rest, total, err := db.LoadToolExecutionsByConversation("conv-a", 3, 3)
+	if err != nil {
+		t.Fatalf("LoadToolExecutionsByConversation offset: %v", err)
+	}
+	if total != 4 || len(rest) != 1 || rest[0].ID != "exec-3" {
+		t.Fatalf("second page: total=%d len=%d, want 4/1 with exec-3", total, len(rest))
+	}
+}
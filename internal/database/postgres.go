@@ -0,0 +1,99 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// PostgreSQL 后端：多人协作/大数据量场景下 SQLite 的写锁会成为瓶颈。
+// 当前覆盖 conversations/messages/process_details 核心功能；SQLite 专属能力
+// （VACUUM INTO 备份、PASSIVE checkpoint、知识库向量表等）不在此后端提供。
+
+// NewPostgresDB 用 postgres 驱动打开会话数据库并初始化核心表。
+// 驱动需由构建方注册到 database/sql（如 blank import lib/pq 或 pgx 的 stdlib 适配），
+// 未注册时返回明确错误而不是晦涩的 unknown driver。
+func NewPostgresDB(dsn string, logger *zap.Logger) (*DB, error) {
+	if strings.TrimSpace(dsn) == "" {
+		return nil, fmt.Errorf("postgres 后端需要配置 database.dsn")
+	}
+	dialect, err := DialectFor(DriverPostgres)
+	if err != nil {
+		return nil, err
+	}
+	if !dialect.Registered() {
+		return nil, fmt.Errorf("postgres 驱动未编译进当前二进制：请在构建时注册 database/sql 驱动（如 lib/pq）后重试")
+	}
+
+	db, err := sql.Open(dialect.DriverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	configureDBPool(db)
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+
+	database := &DB{
+		DB:      db,
+		logger:  logger,
+		dialect: dialect,
+	}
+	if err := database.initCoreTablesPostgres(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("初始化表失败: %w", err)
+	}
+	database.processDetailWriter = newProcessDetailWriter(database)
+	return database, nil
+}
+
+// initCoreTablesPostgres 创建 conversations/messages/process_details 核心表，
+// 结构与 initTables 中的 SQLite 版本对齐（DATETIME → TIMESTAMPTZ）。
+func (db *DB) initCoreTablesPostgres() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS conversations (
+			id TEXT PRIMARY KEY,
+			title TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL,
+			role_name TEXT NOT NULL DEFAULT '默认',
+			last_react_input TEXT,
+			last_react_output TEXT
+		)`,
+		`CREATE TABLE IF NOT EXISTS messages (
+			id TEXT PRIMARY KEY,
+			conversation_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			content TEXT NOT NULL,
+			reasoning_content TEXT,
+			mcp_execution_ids TEXT,
+			seq BIGINT NOT NULL DEFAULT 0,
+			archived INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL,
+			FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS process_details (
+			id TEXT PRIMARY KEY,
+			message_id TEXT NOT NULL,
+			conversation_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			message TEXT,
+			data TEXT,
+			created_at TIMESTAMPTZ NOT NULL,
+			FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+			FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages(conversation_id, seq)`,
+		`CREATE INDEX IF NOT EXISTS idx_process_details_message ON process_details(message_id)`,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package database
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// processDetailQueueSize 异步队列容量；高频 token/进度事件峰值时的缓冲余量。
+	processDetailQueueSize = 1024
+	// processDetailBatchSize 单个事务最多写入的行数。
+	processDetailBatchSize = 64
+	// processDetailFlushInterval 无新事件时的兜底 flush 周期。
+	processDetailFlushInterval = 200 * time.Millisecond
+)
+
+// processDetailRecord 待批量落库的 process_details 行（data 已在入队时序列化）。
+type processDetailRecord struct {
+	id             string
+	messageID      string
+	conversationID string
+	eventType      string
+	message        string
+	dataJSON       string
+	createdAt      time.Time
+}
+
+// processDetailWriter 后台批量写入 process_details：progressCallback 等高频事件入队即返回，
+// 由单个 goroutine 按批 flush，避免逐条同步写库成为 SSE 推送的瓶颈。写入失败仅记 warn。
+type processDetailWriter struct {
+	db       *DB
+	ch       chan processDetailRecord
+	flushReq chan chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newProcessDetailWriter(db *DB) *processDetailWriter {
+	w := &processDetailWriter{
+		db:       db,
+		ch:       make(chan processDetailRecord, processDetailQueueSize),
+		flushReq: make(chan chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *processDetailWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(processDetailFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]processDetailRecord, 0, processDetailBatchSize)
+	flushBatch := func() {
+		if len(batch) == 0 {
+			return
+		}
+		w.writeBatch(batch)
+		batch = batch[:0]
+	}
+	// drain 清空队列中已有的全部事件并落库（flush 请求与关闭时调用）。
+	drain := func() {
+		for {
+			select {
+			case rec := <-w.ch:
+				batch = append(batch, rec)
+				if len(batch) >= processDetailBatchSize {
+					flushBatch()
+				}
+			default:
+				flushBatch()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case rec := <-w.ch:
+			batch = append(batch, rec)
+			if len(batch) >= processDetailBatchSize {
+				flushBatch()
+			}
+		case <-ticker.C:
+			flushBatch()
+		case ack := <-w.flushReq:
+			drain()
+			close(ack)
+		case <-w.stop:
+			drain()
+			return
+		}
+	}
+}
+
+// writeBatch 单事务批量插入；事务不可用、语句失败或提交失败时退化为逐行写入，失败仅记 warn 不中断。
+func (w *processDetailWriter) writeBatch(batch []processDetailRecord) {
+	tx, err := w.db.Begin()
+	if err != nil {
+		w.db.logger.Warn("开启过程详情批量写入事务失败，退化为逐行写入", zap.Error(err))
+		for _, rec := range batch {
+			w.db.insertProcessDetailRecord(rec)
+		}
+		return
+	}
+	for _, rec := range batch {
+		if _, err := tx.Exec(
+			"INSERT INTO process_details (id, message_id, conversation_id, event_type, message, data, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			rec.id, rec.messageID, rec.conversationID, rec.eventType, rec.message, rec.dataJSON, rec.createdAt,
+		); err != nil {
+			// postgres 下语句失败后整个事务进入 aborted 状态，继续执行与提交都只会再失败；
+			// 回滚后整批退化为逐行写入，避免剩余行被静默丢弃
+			w.db.logger.Warn("批量写入过程详情失败，回滚后退化为逐行写入",
+				zap.String("eventType", rec.eventType),
+				zap.String("messageId", rec.messageID),
+				zap.Error(err),
+			)
+			_ = tx.Rollback()
+			for _, r := range batch {
+				w.db.insertProcessDetailRecord(r)
+			}
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		w.db.logger.Warn("提交过程详情批量写入失败，退化为逐行写入", zap.Int("batch", len(batch)), zap.Error(err))
+		_ = tx.Rollback()
+		for _, rec := range batch {
+			w.db.insertProcessDetailRecord(rec)
+		}
+	}
+}
+
+// shutdown 停止后台 goroutine 并 flush 剩余事件（DB.Close 时调用）。
+func (w *processDetailWriter) shutdown() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+func (db *DB) insertProcessDetailRecord(rec processDetailRecord) {
+	if _, err := db.Exec(
+		"INSERT INTO process_details (id, message_id, conversation_id, event_type, message, data, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		rec.id, rec.messageID, rec.conversationID, rec.eventType, rec.message, rec.dataJSON, rec.createdAt,
+	); err != nil {
+		db.logger.Warn("写入过程详情失败",
+			zap.String("eventType", rec.eventType),
+			zap.String("messageId", rec.messageID),
+			zap.Error(err),
+		)
+	}
+}
+
+// EnqueueProcessDetail 异步写入过程详情并立即返回记录 ID，由后台 goroutine 批量落库。
+// 队列已满时退化为同步写入，保证事件不丢；写入失败仅记 warn，不阻塞事件推送。
+func (db *DB) EnqueueProcessDetail(messageID, conversationID, eventType, message string, data interface{}) string {
+	id := uuid.New().String()
+
+	var dataJSON string
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			db.logger.Warn("序列化过程详情数据失败", zap.Error(err))
+		} else {
+			dataJSON = string(jsonData)
+		}
+	}
+
+	rec := processDetailRecord{
+		id:             id,
+		messageID:      messageID,
+		conversationID: conversationID,
+		eventType:      eventType,
+		message:        db.boundRowText(conversationID, "pd-"+id, message),
+		dataJSON:       db.boundRowJSON(conversationID, "pd-"+id+"-data", dataJSON),
+		createdAt:      time.Now(),
+	}
+
+	w := db.processDetailWriter
+	if w == nil {
+		// 未启用异步写入器的连接（如知识库）退化为同步写
+		db.insertProcessDetailRecord(rec)
+		return id
+	}
+	select {
+	case w.ch <- rec:
+	default:
+		db.logger.Warn("过程详情异步队列已满，本条退化为同步写入", zap.String("eventType", eventType))
+		db.insertProcessDetailRecord(rec)
+	}
+	return id
+}
+
+// FlushProcessDetails 等待异步队列中的过程详情全部落库；任务结束与按 ID 读取前调用，
+// 保证读到刚入队的记录。
+func (db *DB) FlushProcessDetails() {
+	if db == nil {
+		return
+	}
+	w := db.processDetailWriter
+	if w == nil {
+		return
+	}
+	ack := make(chan struct{})
+	select {
+	case w.flushReq <- ack:
+		<-ack
+	case <-w.done:
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestEnqueueProcessDetailFlushesToDatabase(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "pd-writer.db")
+	db, err := NewDB(dbPath, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	conv, err := db.CreateConversation("async process details", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	msg, err := db.AddMessage(conv.ID, "assistant", "working", nil)
+	if err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+
+	var ids []string
+	for i := 0; i < 100; i++ {
+		id := db.EnqueueProcessDetail(msg.ID, conv.ID, "iteration", "step", map[string]interface{}{
+			"index": i,
+		})
+		if id == "" {
+			t.Fatal("EnqueueProcessDetail returned empty id")
+		}
+		ids = append(ids, id)
+	}
+
+	db.FlushProcessDetails()
+
+	details, err := db.GetProcessDetails(msg.ID)
+	if err != nil {
+		t.Fatalf("GetProcessDetails: %v", err)
+	}
+	if len(details) != len(ids) {
+		t.Fatalf("details = %d, want %d", len(details), len(ids))
+	}
+
+	// 按 ID 读取会先 flush 队列，入队后应立即可读
+	lastID := db.EnqueueProcessDetail(msg.ID, conv.ID, "progress", "almost done", nil)
+	got, err := db.GetProcessDetailByID(lastID)
+	if err != nil {
+		t.Fatalf("GetProcessDetailByID: %v", err)
+	}
+	if got.EventType != "progress" {
+		t.Fatalf("eventType = %q, want progress", got.EventType)
+	}
+}
+
+func TestCloseFlushesPendingProcessDetails(t *testing.T) {
+	tmp := t.TempDir()
+	dbPath := filepath.Join(tmp, "pd-close.db")
+	db, err := NewDB(dbPath, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+
+	conv, err := db.CreateConversation("close flush", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	msg, err := db.AddMessage(conv.ID, "assistant", "working", nil)
+	if err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+
+	db.EnqueueProcessDetail(msg.ID, conv.ID, "iteration", "pending", nil)
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := NewDB(dbPath, zap.NewNop())
+	if err != nil {
+		t.Fatalf("reopen NewDB: %v", err)
+	}
+	defer reopened.Close()
+
+	details, err := reopened.GetProcessDetails(msg.ID)
+	if err != nil {
+		t.Fatalf("GetProcessDetails: %v", err)
+	}
+	if len(details) != 1 {
+		t.Fatalf("details after close = %d, want 1", len(details))
+	}
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGetProcessDetailsFilterByEventType(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	conv, err := db.CreateConversation("过滤测试", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := db.AddMessage(conv.ID, "assistant", "处理中", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []struct{ eventType, message string }{
+		{"tool_call", "调用 nmap_scan"},
+		{"tool_result", "扫描完成"},
+		{"error", "连接超时"},
+		{"tool_call", "调用 httpx_probe"},
+	} {
+		if _, err := db.AddProcessDetailWithID(msg.ID, conv.ID, d.eventType, d.message, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	calls, err := db.GetProcessDetails(msg.ID, "tool_call")
+	if err != nil {
+		t.Fatalf("GetProcessDetails(tool_call): %v", err)
+	}
+	if len(calls) != 2 {
+		t.Errorf("tool_call 条数 = %d, want 2", len(calls))
+	}
+	for _, d := range calls {
+		if d.EventType != "tool_call" {
+			t.Errorf("混入了其他类型: %s", d.EventType)
+		}
+	}
+
+	mixed, err := db.GetProcessDetails(msg.ID, "tool_call", "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mixed) != 3 {
+		t.Errorf("tool_call+error 条数 = %d, want 3", len(mixed))
+	}
+
+	all, err := db.GetProcessDetails(msg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 {
+		t.Errorf("全量条数 = %d, want 4", len(all))
+	}
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// ListProcessDetailsByConversation 按时间顺序返回对话的全部过程详情（扁平列表，
+// 跨消息保持全局顺序，供时间线等需要整段执行过程的场景使用）。
+func (db *DB) ListProcessDetailsByConversation(conversationID string) ([]ProcessDetail, error) {
+	// 先排空异步写入队列，保证刚入队的事件可读
+	db.FlushProcessDetails()
+	rows, err := db.Query(
+		"SELECT id, message_id, conversation_id, event_type, message, data, created_at FROM process_details WHERE conversation_id = ? ORDER BY created_at ASC, rowid ASC",
+		conversationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询过程详情失败: %w", err)
+	}
+	defer rows.Close()
+
+	var details []ProcessDetail
+	for rows.Next() {
+		var detail ProcessDetail
+		var createdAt string
+
+		if err := rows.Scan(&detail.ID, &detail.MessageID, &detail.ConversationID, &detail.EventType, &detail.Message, &detail.Data, &createdAt); err != nil {
+			return nil, fmt.Errorf("扫描过程详情失败: %w", err)
+		}
+
+		// 尝试多种时间格式解析
+		var err error
+		detail.CreatedAt, err = time.Parse("2006-01-02 15:04:05.999999999-07:00", createdAt)
+		if err != nil {
+			detail.CreatedAt, err = time.Parse("2006-01-02 15:04:05", createdAt)
+		}
+		if err != nil {
+			detail.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		}
+
+		details = append(details, detail)
+	}
+
+	return details, nil
+}
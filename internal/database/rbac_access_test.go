@@ -33,7 +33,7 @@ func TestRBACToolExecutionOwnershipAccess(t *testing.T) {
 		}
 	}
 	access := RBACListAccess{UserID: "u1", Scope: RBACScopeAssigned}
-	rows, err := db.LoadToolExecutionListPageForAccess(0, 20, "", "", access)
+	rows, err := db.LoadToolExecutionListPageForAccess(0, 20, "", "", "", access)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"encoding/json"
+
+	"cyberstrike-ai/internal/tooloutput"
+
+	"go.uber.org/zap"
+)
+
+// SetRowSpillMaxBytes 配置单行落库内容的字节上限（messages.content 与 process_details
+// 的 message/data）。某些工具输出巨大（几 MB），全量存进库会让数据库持续膨胀；
+// 启用后超限内容全文写入 reduction 缓存树下的独立文件，库里只存带引用路径的截断版
+// （与工具结果 reduction 的 <persisted-output> 通知同格式），需要时按路径加载全文。
+// maxBytes<=0 表示不限制（默认）。
+func (db *DB) SetRowSpillMaxBytes(maxBytes int) {
+	if db == nil {
+		return
+	}
+	db.rowSpillMaxBytes = maxBytes
+}
+
+// boundRowText 对落库文本应用行级字节上限：超限时全文 spill 到独立文件，
+// 返回带引用路径的截断版；未启用或未超限时原样返回。refID 用作 spill 文件名。
+func (db *DB) boundRowText(conversationID, refID, text string) string {
+	if db == nil || db.rowSpillMaxBytes <= 0 || len(text) <= db.rowSpillMaxBytes {
+		return text
+	}
+	return tooloutput.BoundWithSpill(text, db.rowSpillMaxBytes, tooloutput.SpillOpts{
+		RootDir:        db.einoReductionRootDir,
+		ConversationID: conversationID,
+		ExecutionID:    refID,
+	})
+}
+
+// boundRowJSON 对 data 列的 JSON 应用行级字节上限：直接截断会破坏 JSON 结构，
+// 超限时整体 spill，库里存指向全文文件的小 JSON（persisted_output / original_bytes）。
+// spill 失败时保留原文——宁可库膨胀也不丢数据。
+func (db *DB) boundRowJSON(conversationID, refID, dataJSON string) string {
+	if db == nil || db.rowSpillMaxBytes <= 0 || len(dataJSON) <= db.rowSpillMaxBytes {
+		return dataJSON
+	}
+	path, err := tooloutput.WriteTruncFile(tooloutput.SpillOpts{
+		RootDir:        db.einoReductionRootDir,
+		ConversationID: conversationID,
+		ExecutionID:    refID,
+	}, dataJSON)
+	if err != nil {
+		db.logger.Warn("过程详情数据 spill 落盘失败，保留原文入库", zap.Error(err), zap.String("refId", refID))
+		return dataJSON
+	}
+	ref, err := json.Marshal(map[string]interface{}{
+		"persisted_output": path,
+		"original_bytes":   len(dataJSON),
+	})
+	if err != nil {
+		return dataJSON
+	}
+	return string(ref)
+}
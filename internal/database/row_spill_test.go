@@ -0,0 +1,134 @@
+package database
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func newRowSpillTestDB(t *testing.T, maxBytes int) *DB {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := NewDB(filepath.Join(dir, "test.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetEinoConversationDirs("", "", filepath.Join(dir, "reduction"), "")
+	db.SetRowSpillMaxBytes(maxBytes)
+	return db
+}
+
+func TestRowSpillBoundsOversizedMessageContent(t *testing.T) {
+	db := newRowSpillTestDB(t, 512)
+	conv, err := db.CreateConversation("行级上限测试", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+
+	big := strings.Repeat("端口 8080 开放，HTTP 服务 Apache。", 200)
+	msg, err := db.AddMessage(conv.ID, "assistant", big, nil)
+	if err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+	if len(msg.Content) > 512 {
+		t.Fatalf("stored content = %d bytes, want <= 512", len(msg.Content))
+	}
+	if !strings.Contains(msg.Content, "persisted-output") {
+		t.Fatalf("stored content should reference spill file, got %q", msg.Content)
+	}
+
+	// 全文应已落盘，且可按通知中的路径读回
+	path := extractPersistedPath(t, msg.Content)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read spill file: %v", err)
+	}
+	if string(data) != big {
+		t.Fatal("spill file should contain the full original content")
+	}
+}
+
+func TestRowSpillDisabledKeepsFullContent(t *testing.T) {
+	db := newRowSpillTestDB(t, 0)
+	conv, err := db.CreateConversation("默认不限制", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	big := strings.Repeat("x", 100000)
+	msg, err := db.AddMessage(conv.ID, "assistant", big, nil)
+	if err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+	if msg.Content != big {
+		t.Fatal("with max_row_bytes disabled content must be stored verbatim")
+	}
+}
+
+func TestRowSpillBoundsProcessDetailData(t *testing.T) {
+	db := newRowSpillTestDB(t, 512)
+	conv, err := db.CreateConversation("过程详情上限", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	msg, err := db.AddMessage(conv.ID, "assistant", "占位", nil)
+	if err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+
+	bigData := map[string]interface{}{"output": strings.Repeat("A", 10000)}
+	if _, err := db.AddProcessDetailWithID(msg.ID, conv.ID, "tool_result", "工具结果", bigData); err != nil {
+		t.Fatalf("AddProcessDetailWithID: %v", err)
+	}
+	details, err := db.GetProcessDetails(msg.ID)
+	if err != nil {
+		t.Fatalf("GetProcessDetails: %v", err)
+	}
+	if len(details) != 1 {
+		t.Fatalf("details = %d, want 1", len(details))
+	}
+	if len(details[0].Data) > 512 {
+		t.Fatalf("stored data = %d bytes, want <= 512", len(details[0].Data))
+	}
+	// data 列保持合法 JSON，并指向全文文件
+	var ref struct {
+		PersistedOutput string `json:"persisted_output"`
+		OriginalBytes   int    `json:"original_bytes"`
+	}
+	if err := json.Unmarshal([]byte(details[0].Data), &ref); err != nil {
+		t.Fatalf("stored data is not valid JSON: %v", err)
+	}
+	if ref.PersistedOutput == "" || ref.OriginalBytes <= 512 {
+		t.Fatalf("unexpected spill reference: %+v", ref)
+	}
+	raw, err := os.ReadFile(ref.PersistedOutput)
+	if err != nil {
+		t.Fatalf("read spill file: %v", err)
+	}
+	var full map[string]interface{}
+	if err := json.Unmarshal(raw, &full); err != nil {
+		t.Fatalf("spill file should hold the original JSON: %v", err)
+	}
+	if s, _ := full["output"].(string); len(s) != 10000 {
+		t.Fatalf("spilled output length = %d, want 10000", len(s))
+	}
+}
+
+// extractPersistedPath 从 <persisted-output> 通知中取出落盘路径。
+func extractPersistedPath(t *testing.T, notice string) string {
+	t.Helper()
+	const marker = "Full output saved to: "
+	idx := strings.Index(notice, marker)
+	if idx < 0 {
+		t.Fatalf("notice missing spill path: %q", notice)
+	}
+	rest := notice[idx+len(marker):]
+	if nl := strings.IndexByte(rest, '\n'); nl >= 0 {
+		rest = rest[:nl]
+	}
+	return strings.TrimSpace(rest)
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"cyberstrike-ai/internal/mcp"
+
+	"go.uber.org/zap"
+)
+
+// 目标执行结果 diff：周期性复扫同一目标时，对比同一 (tool+target) 最近两次
+// 已完成执行的结构化结果，给出"这次比上次多了/少了什么"的变更摘要。
+
+// ErrInsufficientExecutionsForDiff 该目标下此工具可对比的已完成执行不足两次
+var ErrInsufficientExecutionsForDiff = errors.New("该目标下此工具带结构化结果的已完成执行不足两次，无法对比")
+
+// TargetExecutionRef diff 中的执行引用
+type TargetExecutionRef struct {
+	ID        string    `json:"id"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// TargetPortChange 同一端口/协议在两次执行间的服务/状态变化
+type TargetPortChange struct {
+	Port     int               `json:"port"`
+	Protocol string            `json:"protocol"`
+	Before   TargetPortService `json:"before"`
+	After    TargetPortService `json:"after"`
+}
+
+// TargetExecutionDiff 同一 (tool+target) 最近两次执行结果的对比
+type TargetExecutionDiff struct {
+	Host               string              `json:"host"`
+	ToolName           string              `json:"tool_name"`
+	Baseline           TargetExecutionRef  `json:"baseline"`
+	Current            TargetExecutionRef  `json:"current"`
+	AddedPorts         []TargetPortService `json:"added_ports"`
+	RemovedPorts       []TargetPortService `json:"removed_ports"`
+	ChangedPorts       []TargetPortChange  `json:"changed_ports"`
+	NewVulnerabilities []*Vulnerability    `json:"new_vulnerabilities"`
+	Summary            string              `json:"summary"`
+}
+
+// GetTargetExecutionDiff 对比指定目标上某工具最近两次带结构化结果的已完成执行
+func (db *DB) GetTargetExecutionDiff(host, toolName string, access RBACListAccess) (*TargetExecutionDiff, error) {
+	host = NormalizeTargetHost(host)
+	if host == "" {
+		return nil, fmt.Errorf("非法的目标主机")
+	}
+
+	executions, err := db.loadTargetToolExecutions(host, toolName, access)
+	if err != nil {
+		return nil, err
+	}
+	if len(executions) < 2 {
+		return nil, ErrInsufficientExecutionsForDiff
+	}
+	current, baseline := executions[0], executions[1]
+
+	diff := &TargetExecutionDiff{
+		Host:     host,
+		ToolName: toolName,
+		Baseline: TargetExecutionRef{ID: baseline.ID, StartTime: baseline.StartTime},
+		Current:  TargetExecutionRef{ID: current.ID, StartTime: current.StartTime},
+	}
+	diffPortServices(diff, executionPortServices(baseline), executionPortServices(current))
+
+	// 新漏洞：基线执行之后在该目标上记录的漏洞
+	vulnerabilities, err := db.loadTargetVulnerabilities(host, access)
+	if err != nil {
+		return nil, err
+	}
+	diff.NewVulnerabilities = make([]*Vulnerability, 0)
+	for _, vuln := range vulnerabilities {
+		if vuln.CreatedAt.After(baseline.StartTime) {
+			diff.NewVulnerabilities = append(diff.NewVulnerabilities, vuln)
+		}
+	}
+
+	diff.Summary = fmt.Sprintf("新增端口 %d 个，消失端口 %d 个，服务/状态变化 %d 处，新增漏洞 %d 个",
+		len(diff.AddedPorts), len(diff.RemovedPorts), len(diff.ChangedPorts), len(diff.NewVulnerabilities))
+	return diff, nil
+}
+
+// loadTargetToolExecutions 取目标上某工具最近两次带结构化结果的已完成执行（按开始时间倒序）
+func (db *DB) loadTargetToolExecutions(host, toolName string, access RBACListAccess) ([]*mcp.ToolExecution, error) {
+	query := `
+		SELECT id, structured_result, start_time
+		FROM tool_executions
+		WHERE id IN (SELECT execution_id FROM tool_execution_targets WHERE host = ?)
+		  AND tool_name = ? AND status = 'completed' AND structured_result IS NOT NULL
+	`
+	args := []interface{}{host, toolName}
+	query, args = appendToolExecutionAccessSQL(query, args, access, true)
+	query += ` ORDER BY start_time DESC LIMIT 2`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询目标执行记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	executions := make([]*mcp.ToolExecution, 0, 2)
+	for rows.Next() {
+		var exec mcp.ToolExecution
+		var structuredJSON string
+		if err := rows.Scan(&exec.ID, &structuredJSON, &exec.StartTime); err != nil {
+			db.logger.Warn("扫描目标执行记录失败", zap.Error(err))
+			continue
+		}
+		if err := json.Unmarshal([]byte(structuredJSON), &exec.StructuredResult); err != nil {
+			db.logger.Warn("解析结构化结果失败", zap.Error(err), zap.String("executionId", exec.ID))
+			continue
+		}
+		executions = append(executions, &exec)
+	}
+	return executions, nil
+}
+
+// diffPortServices 对比两次执行的端口/服务列表，填充新增、消失与变化项
+func diffPortServices(diff *TargetExecutionDiff, before, after []TargetPortService) {
+	key := func(p TargetPortService) string { return fmt.Sprintf("%d/%s", p.Port, p.Protocol) }
+	beforeMap := make(map[string]TargetPortService, len(before))
+	for _, p := range before {
+		beforeMap[key(p)] = p
+	}
+
+	diff.AddedPorts = make([]TargetPortService, 0)
+	diff.RemovedPorts = make([]TargetPortService, 0)
+	diff.ChangedPorts = make([]TargetPortChange, 0)
+	seenAfter := make(map[string]bool, len(after))
+	for _, p := range after {
+		k := key(p)
+		seenAfter[k] = true
+		prev, existed := beforeMap[k]
+		if !existed {
+			diff.AddedPorts = append(diff.AddedPorts, p)
+			continue
+		}
+		if prev.State != p.State || prev.Service != p.Service || prev.Version != p.Version {
+			diff.ChangedPorts = append(diff.ChangedPorts, TargetPortChange{
+				Port: p.Port, Protocol: p.Protocol, Before: prev, After: p,
+			})
+		}
+	}
+	for _, p := range before {
+		if !seenAfter[key(p)] {
+			diff.RemovedPorts = append(diff.RemovedPorts, p)
+		}
+	}
+}
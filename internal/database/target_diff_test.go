@@ -0,0 +1,106 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cyberstrike-ai/internal/mcp"
+
+	"go.uber.org/zap"
+)
+
+func TestGetTargetExecutionDiff(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "target-diff.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	now := time.Now()
+	portEntry := func(port int, service, version string) map[string]interface{} {
+		return map[string]interface{}{
+			"port": float64(port), "protocol": "tcp", "state": "open",
+			"service": service, "version": version,
+		}
+	}
+	execs := []*mcp.ToolExecution{
+		{
+			ID:        "exec-base",
+			ToolName:  "nmap",
+			Arguments: map[string]interface{}{"target": "10.0.0.9"},
+			Status:    "completed",
+			StructuredResult: map[string]interface{}{
+				"ports": []interface{}{
+					portEntry(22, "ssh", "OpenSSH 8.9"),
+					portEntry(80, "http", "nginx 1.18.0"),
+				},
+			},
+			StartTime: now.Add(-time.Hour),
+		},
+		{
+			ID:        "exec-current",
+			ToolName:  "nmap",
+			Arguments: map[string]interface{}{"target": "10.0.0.9"},
+			Status:    "completed",
+			StructuredResult: map[string]interface{}{
+				"ports": []interface{}{
+					portEntry(22, "ssh", "OpenSSH 9.6"),
+					portEntry(443, "https", "nginx 1.24.0"),
+				},
+			},
+			StartTime: now.Add(-time.Minute),
+		},
+		{
+			// 其他工具的执行不参与同工具 diff
+			ID:               "exec-other-tool",
+			ToolName:         "httpx",
+			Arguments:        map[string]interface{}{"target": "10.0.0.9"},
+			Status:           "completed",
+			StructuredResult: map[string]interface{}{"ports": []interface{}{portEntry(8080, "http", "")}},
+			StartTime:        now,
+		},
+	}
+	for _, exec := range execs {
+		if err := db.SaveToolExecution(exec); err != nil {
+			t.Fatalf("SaveToolExecution(%s): %v", exec.ID, err)
+		}
+	}
+	if _, err := db.CreateVulnerability(&Vulnerability{
+		Title:    "弱口令",
+		Severity: "medium",
+		Target:   "10.0.0.9:22",
+	}); err != nil {
+		t.Fatalf("CreateVulnerability: %v", err)
+	}
+
+	diff, err := db.GetTargetExecutionDiff("10.0.0.9", "nmap", RBACListAccess{Scope: RBACScopeAll})
+	if err != nil {
+		t.Fatalf("GetTargetExecutionDiff: %v", err)
+	}
+	if diff.Baseline.ID != "exec-base" || diff.Current.ID != "exec-current" {
+		t.Fatalf("对比方向错误: baseline=%s current=%s", diff.Baseline.ID, diff.Current.ID)
+	}
+	if len(diff.AddedPorts) != 1 || diff.AddedPorts[0].Port != 443 {
+		t.Fatalf("added = %+v", diff.AddedPorts)
+	}
+	if len(diff.RemovedPorts) != 1 || diff.RemovedPorts[0].Port != 80 {
+		t.Fatalf("removed = %+v", diff.RemovedPorts)
+	}
+	if len(diff.ChangedPorts) != 1 || diff.ChangedPorts[0].Port != 22 ||
+		diff.ChangedPorts[0].After.Version != "OpenSSH 9.6" {
+		t.Fatalf("changed = %+v", diff.ChangedPorts)
+	}
+	if len(diff.NewVulnerabilities) != 1 || diff.NewVulnerabilities[0].Title != "弱口令" {
+		t.Fatalf("new vulnerabilities = %+v", diff.NewVulnerabilities)
+	}
+	if diff.Summary == "" {
+		t.Fatal("summary 不应为空")
+	}
+
+	// 可对比执行不足两次
+	if _, err := db.GetTargetExecutionDiff("10.0.0.9", "httpx", RBACListAccess{Scope: RBACScopeAll}); !errors.Is(err, ErrInsufficientExecutionsForDiff) {
+		t.Fatalf("期望 ErrInsufficientExecutionsForDiff，得到: %v", err)
+	}
+}
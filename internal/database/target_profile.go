@@ -0,0 +1,39 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// GetConversationTargetProfileJSON 返回会话目标档案 JSON；未记录时返回空串。
+func (db *DB) GetConversationTargetProfileJSON(conversationID string) (string, error) {
+	conversationID = strings.TrimSpace(conversationID)
+	if conversationID == "" {
+		return "", nil
+	}
+	var profile string
+	err := db.QueryRow("SELECT profile FROM conversation_target_profiles WHERE conversation_id = ?", conversationID).Scan(&profile)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return profile, nil
+}
+
+// SaveConversationTargetProfileJSON 写入（upsert）会话目标档案 JSON。
+func (db *DB) SaveConversationTargetProfileJSON(conversationID, profileJSON string) error {
+	conversationID = strings.TrimSpace(conversationID)
+	if conversationID == "" {
+		return errors.New("conversation_id 不能为空")
+	}
+	_, err := db.Exec(`
+		INSERT INTO conversation_target_profiles (conversation_id, profile, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(conversation_id) DO UPDATE SET profile=excluded.profile, updated_at=excluded.updated_at`,
+		conversationID, profileJSON, time.Now())
+	return err
+}
@@ -0,0 +1,331 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"cyberstrike-ai/internal/mcp"
+
+	"go.uber.org/zap"
+)
+
+// 按目标聚合的资产视图：一次渗透往往对同一目标跑多个工具，信息散在各条执行记录里。
+// 保存执行时从参数中提取统一的目标主机标识写入 tool_execution_targets 索引，
+// GET /api/targets/:host 据此聚合该目标的执行记录、端口/服务、漏洞与所属会话。
+
+// targetArgumentKeys 工具参数中常见的目标键名（不区分大小写）
+var targetArgumentKeys = map[string]bool{
+	"target": true, "targets": true,
+	"host": true, "hosts": true,
+	"url": true, "urls": true,
+	"domain": true, "ip": true, "address": true,
+	"rhost": true, "rhosts": true,
+}
+
+// NormalizeTargetHost 将原始目标值（URL / host:port / IP / 域名）归一为小写主机标识；
+// 无法识别为合法 IP 或域名时返回空串
+func NormalizeTargetHost(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if strings.Contains(raw, "://") {
+		if u, err := url.Parse(raw); err == nil && u.Hostname() != "" {
+			raw = u.Hostname()
+		}
+	}
+	if h, _, err := net.SplitHostPort(raw); err == nil && h != "" {
+		raw = h
+	}
+	raw = strings.Trim(strings.ToLower(raw), "[]")
+	if net.ParseIP(raw) != nil {
+		return raw
+	}
+	if validAssetDomain(raw) {
+		return raw
+	}
+	return ""
+}
+
+// ExtractExecutionTargetHosts 从执行参数中提取目标主机集合（归一、去重、排序稳定）
+func ExtractExecutionTargetHosts(arguments map[string]interface{}) []string {
+	if len(arguments) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var hosts []string
+	add := func(raw string) {
+		// 同一参数可能以逗号/空白分隔多个目标
+		for _, part := range strings.FieldsFunc(raw, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\n'
+		}) {
+			if h := NormalizeTargetHost(part); h != "" && !seen[h] {
+				seen[h] = true
+				hosts = append(hosts, h)
+			}
+		}
+	}
+	for key, value := range arguments {
+		if !targetArgumentKeys[strings.ToLower(strings.TrimSpace(key))] {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			add(v)
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					add(s)
+				}
+			}
+		case []string:
+			for _, s := range v {
+				add(s)
+			}
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// indexToolExecutionTargets 维护执行→目标主机索引；执行记录会随状态变化多次保存，
+// 用 INSERT OR IGNORE 保证幂等。索引失败不影响执行记录本身，仅告警。
+func (db *DB) indexToolExecutionTargets(executionID string, arguments map[string]interface{}) {
+	for _, host := range ExtractExecutionTargetHosts(arguments) {
+		if _, err := db.Exec(
+			`INSERT OR IGNORE INTO tool_execution_targets (execution_id, host) VALUES (?, ?)`,
+			executionID, host,
+		); err != nil {
+			db.logger.Warn("写入执行目标索引失败",
+				zap.String("executionId", executionID),
+				zap.String("host", host),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// TargetPortService 目标上发现的端口/服务（来自 nmap 等工具的结构化结果）
+type TargetPortService struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	State    string `json:"state"`
+	Service  string `json:"service,omitempty"`
+	Version  string `json:"version,omitempty"`
+}
+
+// TargetConversationRef 目标相关的会话引用
+type TargetConversationRef struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// TargetView 按目标聚合的资产视图
+type TargetView struct {
+	Host            string                  `json:"host"`
+	Executions      []*mcp.ToolExecution    `json:"executions"`
+	Ports           []TargetPortService     `json:"ports"`
+	Vulnerabilities []*Vulnerability        `json:"vulnerabilities"`
+	Conversations   []TargetConversationRef `json:"conversations"`
+}
+
+// targetViewExecutionLimit 单目标视图最多返回的执行记录数
+const targetViewExecutionLimit = 200
+
+// GetTargetView 聚合指定目标主机的执行记录、端口/服务、漏洞与会话
+func (db *DB) GetTargetView(host string, access RBACListAccess) (*TargetView, error) {
+	host = NormalizeTargetHost(host)
+	if host == "" {
+		return nil, fmt.Errorf("非法的目标主机")
+	}
+
+	executions, err := db.loadTargetExecutions(host, access)
+	if err != nil {
+		return nil, err
+	}
+
+	vulnerabilities, err := db.loadTargetVulnerabilities(host, access)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &TargetView{
+		Host:            host,
+		Executions:      executions,
+		Ports:           collectTargetPorts(executions),
+		Vulnerabilities: vulnerabilities,
+		Conversations:   db.loadTargetConversations(executions, vulnerabilities),
+	}
+	return view, nil
+}
+
+func (db *DB) loadTargetExecutions(host string, access RBACListAccess) ([]*mcp.ToolExecution, error) {
+	query := `
+		SELECT id, tool_name, status, structured_result, COALESCE(error, ''), start_time, end_time, duration_ms,
+		       COALESCE(owner_user_id, ''), COALESCE(conversation_id, '')
+		FROM tool_executions
+		WHERE id IN (SELECT execution_id FROM tool_execution_targets WHERE host = ?)
+	`
+	args := []interface{}{host}
+	query, args = appendToolExecutionAccessSQL(query, args, access, true)
+	query += ` ORDER BY start_time DESC LIMIT ?`
+	args = append(args, targetViewExecutionLimit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询目标执行记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	executions := make([]*mcp.ToolExecution, 0)
+	for rows.Next() {
+		var exec mcp.ToolExecution
+		var structuredJSON sql.NullString
+		var endTime sql.NullTime
+		var durationMs sql.NullInt64
+		if err := rows.Scan(
+			&exec.ID,
+			&exec.ToolName,
+			&exec.Status,
+			&structuredJSON,
+			&exec.Error,
+			&exec.StartTime,
+			&endTime,
+			&durationMs,
+			&exec.OwnerUserID,
+			&exec.ConversationID,
+		); err != nil {
+			db.logger.Warn("扫描目标执行记录失败", zap.Error(err))
+			continue
+		}
+		if structuredJSON.Valid && structuredJSON.String != "" {
+			if err := json.Unmarshal([]byte(structuredJSON.String), &exec.StructuredResult); err != nil {
+				db.logger.Warn("解析结构化结果失败", zap.Error(err), zap.String("executionId", exec.ID))
+			}
+		}
+		if endTime.Valid {
+			exec.EndTime = &endTime.Time
+		}
+		if durationMs.Valid {
+			exec.Duration = time.Duration(durationMs.Int64) * time.Millisecond
+		}
+		executions = append(executions, &exec)
+	}
+	return executions, nil
+}
+
+// collectTargetPorts 汇总各执行结构化结果中的端口/服务；执行按时间倒序，
+// 同一端口/协议以最新一次结果为准
+func collectTargetPorts(executions []*mcp.ToolExecution) []TargetPortService {
+	seen := make(map[string]bool)
+	ports := make([]TargetPortService, 0)
+	for _, exec := range executions {
+		for _, p := range executionPortServices(exec) {
+			key := fmt.Sprintf("%d/%s", p.Port, p.Protocol)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			ports = append(ports, p)
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Port < ports[j].Port })
+	return ports
+}
+
+// executionPortServices 解析单次执行结构化结果中的端口/服务列表（按端口号排序）
+func executionPortServices(exec *mcp.ToolExecution) []TargetPortService {
+	if exec == nil || exec.StructuredResult == nil {
+		return nil
+	}
+	rawPorts, ok := exec.StructuredResult["ports"].([]interface{})
+	if !ok {
+		return nil
+	}
+	ports := make([]TargetPortService, 0, len(rawPorts))
+	for _, raw := range rawPorts {
+		entry, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		port, ok := entry["port"].(float64)
+		if !ok {
+			continue
+		}
+		protocol, _ := entry["protocol"].(string)
+		p := TargetPortService{Port: int(port), Protocol: protocol}
+		p.State, _ = entry["state"].(string)
+		p.Service, _ = entry["service"].(string)
+		p.Version, _ = entry["version"].(string)
+		ports = append(ports, p)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Port < ports[j].Port })
+	return ports
+}
+
+func (db *DB) loadTargetVulnerabilities(host string, access RBACListAccess) ([]*Vulnerability, error) {
+	query := `
+		SELECT id, COALESCE(conversation_id,''), COALESCE(project_id,''), title, description, severity, status,
+		       conversation_tag, task_tag, vulnerability_type, target,
+		       created_at, updated_at
+		FROM vulnerabilities
+		WHERE 1=1 AND LOWER(COALESCE(target, '')) LIKE LOWER(?) ESCAPE '\'
+	`
+	args := []interface{}{"%" + escapeVulnerabilityLikePattern(host) + "%"}
+	query, args = appendVulnerabilityAccessFilter(query, args, access)
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询目标漏洞失败: %w", err)
+	}
+	defer rows.Close()
+
+	vulnerabilities := make([]*Vulnerability, 0)
+	for rows.Next() {
+		var vuln Vulnerability
+		if err := rows.Scan(
+			&vuln.ID, &vuln.ConversationID, &vuln.ProjectID, &vuln.Title, &vuln.Description,
+			&vuln.Severity, &vuln.Status, &vuln.ConversationTag, &vuln.TaskTag, &vuln.Type, &vuln.Target,
+			&vuln.CreatedAt, &vuln.UpdatedAt,
+		); err != nil {
+			db.logger.Warn("扫描目标漏洞失败", zap.Error(err))
+			continue
+		}
+		vulnerabilities = append(vulnerabilities, &vuln)
+	}
+	return vulnerabilities, nil
+}
+
+// loadTargetConversations 汇总执行与漏洞涉及的会话（去重、补标题；已删除的会话跳过）
+func (db *DB) loadTargetConversations(executions []*mcp.ToolExecution, vulnerabilities []*Vulnerability) []TargetConversationRef {
+	seen := make(map[string]bool)
+	ids := make([]string, 0)
+	collect := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, exec := range executions {
+		collect(exec.ConversationID)
+	}
+	for _, vuln := range vulnerabilities {
+		collect(vuln.ConversationID)
+	}
+	refs := make([]TargetConversationRef, 0, len(ids))
+	for _, id := range ids {
+		var title string
+		if err := db.QueryRow(`SELECT title FROM conversations WHERE id = ?`, id).Scan(&title); err != nil {
+			continue
+		}
+		refs = append(refs, TargetConversationRef{ID: id, Title: title})
+	}
+	return refs
+}
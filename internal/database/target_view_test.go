@@ -0,0 +1,144 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cyberstrike-ai/internal/mcp"
+
+	"go.uber.org/zap"
+)
+
+func TestNormalizeTargetHost(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"10.0.0.1", "10.0.0.1"},
+		{"10.0.0.1:8080", "10.0.0.1"},
+		{"Example.COM", "example.com"},
+		{"https://example.com:8443/admin?x=1", "example.com"},
+		{"http://10.0.0.1/login.php", "10.0.0.1"},
+		{" scanme.nmap.org ", "scanme.nmap.org"},
+		{"not a host", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTargetHost(tt.input); got != tt.want {
+			t.Errorf("NormalizeTargetHost(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestExtractExecutionTargetHosts(t *testing.T) {
+	hosts := ExtractExecutionTargetHosts(map[string]interface{}{
+		"target":  "https://example.com/login",
+		"Hosts":   "10.0.0.1, 10.0.0.2",
+		"urls":    []interface{}{"http://10.0.0.1:8080", "ftp://files.example.com"},
+		"threads": "10",
+		"query":   "site:example.com",
+	})
+	want := []string{"10.0.0.1", "10.0.0.2", "example.com", "files.example.com"}
+	if len(hosts) != len(want) {
+		t.Fatalf("hosts = %v, want %v", hosts, want)
+	}
+	for i := range want {
+		if hosts[i] != want[i] {
+			t.Fatalf("hosts = %v, want %v", hosts, want)
+		}
+	}
+}
+
+func TestGetTargetViewAggregates(t *testing.T) {
+	db, err := NewDB(filepath.Join(t.TempDir(), "target-view.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	conv, err := db.CreateConversation("扫描 10.0.0.5", ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+
+	now := time.Now()
+	execs := []*mcp.ToolExecution{
+		{
+			ID:        "exec-nmap",
+			ToolName:  "nmap",
+			Arguments: map[string]interface{}{"target": "10.0.0.5"},
+			Status:    "completed",
+			StructuredResult: map[string]interface{}{
+				"tool": "nmap",
+				"ports": []interface{}{
+					map[string]interface{}{"port": float64(22), "protocol": "tcp", "state": "open", "service": "ssh"},
+					map[string]interface{}{"port": float64(80), "protocol": "tcp", "state": "open", "service": "http", "version": "nginx 1.18.0"},
+				},
+			},
+			StartTime:      now.Add(-2 * time.Minute),
+			ConversationID: conv.ID,
+		},
+		{
+			ID:             "exec-sqlmap",
+			ToolName:       "sqlmap",
+			Arguments:      map[string]interface{}{"url": "http://10.0.0.5:8080/item.php?id=1"},
+			Status:         "completed",
+			StartTime:      now.Add(-1 * time.Minute),
+			ConversationID: conv.ID,
+		},
+		{
+			ID:        "exec-other",
+			ToolName:  "nmap",
+			Arguments: map[string]interface{}{"target": "192.168.1.1"},
+			Status:    "completed",
+			StartTime: now,
+		},
+	}
+	for _, exec := range execs {
+		if err := db.SaveToolExecution(exec); err != nil {
+			t.Fatalf("SaveToolExecution(%s): %v", exec.ID, err)
+		}
+	}
+
+	if _, err := db.CreateVulnerability(&Vulnerability{
+		Title:          "SQL 注入",
+		Severity:       "high",
+		Target:         "http://10.0.0.5:8080/item.php",
+		ConversationID: conv.ID,
+	}); err != nil {
+		t.Fatalf("CreateVulnerability: %v", err)
+	}
+
+	view, err := db.GetTargetView("10.0.0.5", RBACListAccess{Scope: RBACScopeAll})
+	if err != nil {
+		t.Fatalf("GetTargetView: %v", err)
+	}
+	if len(view.Executions) != 2 {
+		t.Fatalf("executions = %d, want 2: %+v", len(view.Executions), view.Executions)
+	}
+	if view.Executions[0].ID != "exec-sqlmap" || view.Executions[1].ID != "exec-nmap" {
+		t.Fatalf("执行记录应按开始时间倒序: %s, %s", view.Executions[0].ID, view.Executions[1].ID)
+	}
+	if len(view.Ports) != 2 || view.Ports[0].Port != 22 || view.Ports[1].Port != 80 {
+		t.Fatalf("ports = %+v", view.Ports)
+	}
+	if view.Ports[1].Version != "nginx 1.18.0" {
+		t.Fatalf("端口版本信息丢失: %+v", view.Ports[1])
+	}
+	if len(view.Vulnerabilities) != 1 || view.Vulnerabilities[0].Title != "SQL 注入" {
+		t.Fatalf("vulnerabilities = %+v", view.Vulnerabilities)
+	}
+	if len(view.Conversations) != 1 || view.Conversations[0].ID != conv.ID {
+		t.Fatalf("conversations = %+v", view.Conversations)
+	}
+
+	// URL 形式的入参同样可查（按归一后的主机匹配）
+	view, err = db.GetTargetView("https://10.0.0.5/login", RBACListAccess{Scope: RBACScopeAll})
+	if err != nil {
+		t.Fatalf("GetTargetView(url): %v", err)
+	}
+	if len(view.Executions) != 2 {
+		t.Fatalf("URL 入参应归一到同一目标: %d", len(view.Executions))
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"cyberstrike-ai/internal/authctx"
 	"cyberstrike-ai/internal/config"
 	"cyberstrike-ai/internal/database"
+	"cyberstrike-ai/internal/knowledge"
 	"cyberstrike-ai/internal/mcp/builtin"
 	"cyberstrike-ai/internal/multiagent"
 	"cyberstrike-ai/internal/openai"
@@ -184,8 +185,9 @@ type AgentHandler struct {
 	knowledgeManager interface {    // 知识库管理器接口
 		LogRetrieval(conversationID, messageID, query, riskType string, retrievedItems []string) error
 	}
-	agentsMarkdownDir string // 多代理：Markdown 子 Agent 目录（绝对路径，空则不从磁盘合并）
-	batchCronParser   cron.Parser
+	knowledgeRetriever *knowledge.Retriever // 知识库检索器（RAG 自动前置注入，可为 nil）
+	agentsMarkdownDir  string               // 多代理：Markdown 子 Agent 目录（绝对路径，空则不从磁盘合并）
+	batchCronParser    cron.Parser
 	// hitlWhitelistSaver 侧栏「应用」HITL 时将会话增量白名单合并写入 config.yaml（可选）
 	hitlWhitelistSaver       HitlToolWhitelistSaver
 	hitlStrategySaver        HitlAuditStrategySaver
@@ -268,6 +270,7 @@ func NewAgentHandler(agent *agent.Agent, db *database.DB, cfg *config.Config, lo
 		auditLLM:         openai.NewClient(llmCfg, llmHTTP, logger),
 	}
 	tm.SetToolCanceler(handler.cancelRunningMCPToolsForConversation)
+	handler.hitlManager.SetRequireApprovalLookup(handler.toolRequiresApproval)
 	if err := handler.hitlManager.EnsureSchema(); err != nil {
 		logger.Warn("初始化 HITL 表失败", zap.Error(err))
 	}
@@ -309,6 +312,24 @@ func (h *AgentHandler) hitlEffectiveDefaultReviewer() string {
 	return "human"
 }
 
+// toolRequiresApproval 查询当前工具配置的 require_approval 标记；
+// 读取 h.config（tools 目录热更新后即时生效），命中时强制走 HITL 审批。
+func (h *AgentHandler) toolRequiresApproval(toolName string) bool {
+	if h == nil || h.config == nil {
+		return false
+	}
+	name := strings.ToLower(strings.TrimSpace(toolName))
+	if name == "" {
+		return false
+	}
+	for _, t := range h.config.Security.Tools {
+		if t.RequireApproval && strings.ToLower(strings.TrimSpace(t.Name)) == name {
+			return true
+		}
+	}
+	return false
+}
+
 // HITLNeedsToolApproval 供 C2 危险任务门控：与会话侧人机协同及免审批白名单判定一致。
 func (h *AgentHandler) HITLNeedsToolApproval(conversationID, toolName string) bool {
 	if h == nil || h.hitlManager == nil {
@@ -333,6 +354,12 @@ type ChatReasoningRequest struct {
 	Effort string `json:"effort,omitempty"`
 }
 
+// ChatBudgetRequest 本次任务的成本预算覆盖：>0 的字段覆盖 agent 配置对应上限。
+type ChatBudgetRequest struct {
+	MaxTokens int     `json:"maxTokens,omitempty"` // 累计 token 上限，覆盖 agent.max_task_tokens
+	MaxCost   float64 `json:"maxCost,omitempty"`   // 金额上限，覆盖 agent.max_task_cost
+}
+
 // ChatRequest 聊天请求
 type ChatRequest struct {
 	Message              string                `json:"message" binding:"required"`
@@ -342,8 +369,11 @@ type ChatRequest struct {
 	Attachments          []ChatAttachment      `json:"attachments,omitempty"`
 	WebShellConnectionID string                `json:"webshellConnectionId,omitempty"` // WebShell 管理 - AI 助手：当前选中的连接 ID，仅使用 webshell_* 工具
 	AIChannelID          string                `json:"aiChannelId,omitempty"`          // 会话级 AI 通道；空则使用 ai.default_channel
+	ReplyToMessageID     string                `json:"replyToMessageId,omitempty"`     // 引用的历史消息 ID：其完整内容会注入当前轮，即使已被历史裁剪
 	Hitl                 *HITLRequest          `json:"hitl,omitempty"`
 	Reasoning            *ChatReasoningRequest `json:"reasoning,omitempty"`
+	Budget               *ChatBudgetRequest    `json:"budget,omitempty"`   // 本次任务成本预算覆盖；空则用 agent.max_task_tokens / max_task_cost
+	SafeMode             bool                  `json:"safeMode,omitempty"` // 仅信息收集安全模式：只暴露非侵入性工具并在提示词中强调边界（见 safe_mode.go）
 	// Orchestration 仅对 /api/multi-agent、/api/multi-agent/stream：deep | plan_execute | supervisor；空则等同 deep。机器人/批量等无请求体时由服务端默认 deep。/api/eino-agent* 不使用此字段。
 	Orchestration string `json:"orchestration,omitempty"`
 }
@@ -640,6 +670,20 @@ func (h *AgentHandler) appendAssistantMessageNotice(messageID, notice string) er
 	return err
 }
 
+// assistantPartialPersistInterval 流式 token 阶段把已生成的 partial content 刷进
+// messages 表的最小间隔；服务崩溃后至少保留已生成的部分内容，同时避免每个 delta 都写库。
+const assistantPartialPersistInterval = 2 * time.Second
+
+// persistAssistantPartialContent 用流式累计内容覆盖「处理中...」占位或上一次 partial；
+// 最终内容仍由 UpdateAssistantMessageFinalize 以完整 Response 收口。
+func (h *AgentHandler) persistAssistantPartialContent(messageID, partial string) error {
+	if strings.TrimSpace(messageID) == "" || strings.TrimSpace(partial) == "" {
+		return nil
+	}
+	_, err := h.db.Exec("UPDATE messages SET content = ?, updated_at = ? WHERE id = ?", partial, time.Now(), messageID)
+	return err
+}
+
 // mergeAssistantMessagePartialOnCancel 将取消前已生成的部分回复尽量合并进消息：
 // - content 为空或仅占位（处理中...）时，直接替换为 partial；
 // - 已有正文时，仅在尚未包含 partial 时追加，避免丢失与重复。
@@ -786,6 +830,7 @@ func (h *AgentHandler) ProcessMessageForRobot(ctx context.Context, platform stri
 			for _, msg := range historyMessages {
 				agentHistoryMessages = append(agentHistoryMessages, agent.ChatMessage{Role: msg.Role, Content: msg.Content})
 			}
+			h.maybeArchiveConversationHistory(conversationID, len(historyMessages))
 		}
 	}
 
@@ -951,6 +996,8 @@ func (h *AgentHandler) createProgressCallback(runCtx context.Context, cancelRun
 	// response_start + response_delta：前端时间线显示为「📝 规划中」（monitor.js），不落逐条 delta；
 	// 聚合为一条 planning 写入 process_details，刷新后与线上一致。
 	var respPlan responsePlanAgg
+	// 流式 partial content 节流落库的时间基准（见 assistantPartialPersistInterval）。
+	lastPartialPersistAt := time.Now()
 	if assistantMessageID != "" {
 		h.tasks.SetHitlAssistantMessageID(conversationID, assistantMessageID)
 	}
@@ -973,9 +1020,7 @@ func (h *AgentHandler) createProgressCallback(runCtx context.Context, cancelRun
 		for k, v := range respPlan.meta {
 			data[k] = v
 		}
-		if err := h.db.AddProcessDetail(assistantMessageID, conversationID, "planning", content, data); err != nil {
-			h.logger.Warn("保存过程详情失败", zap.Error(err), zap.String("eventType", "planning"))
-		}
+		h.db.EnqueueProcessDetail(assistantMessageID, conversationID, "planning", content, data)
 		syncHitlCognition()
 		respPlan.meta = nil
 		respPlan.b.Reset()
@@ -1008,9 +1053,7 @@ func (h *AgentHandler) createProgressCallback(runCtx context.Context, cancelRun
 			if persist != "reasoning_chain" {
 				persist = "thinking"
 			}
-			if err := h.db.AddProcessDetail(assistantMessageID, conversationID, persist, content, data); err != nil {
-				h.logger.Warn("保存过程详情失败", zap.Error(err), zap.String("eventType", persist))
-			}
+			h.db.EnqueueProcessDetail(assistantMessageID, conversationID, persist, content, data)
 			flushedThinking[sid] = true
 		}
 		syncHitlCognition()
@@ -1020,6 +1063,12 @@ func (h *AgentHandler) createProgressCallback(runCtx context.Context, cancelRun
 		progressMu.Lock()
 		defer progressMu.Unlock()
 
+		// 任务收尾事件（done/error/cancelled/timeout）时排空异步写入队列，
+		// 确保本次任务的全部过程详情已落库（defer 在本事件入队之后执行）。
+		if eventType == "done" || eventType == "error" || eventType == "cancelled" || eventType == "timeout" {
+			defer h.db.FlushProcessDetails()
+		}
+
 		// 上游在重试/补偿时可能重复回调相同 tool_call/tool_result。
 		// 这里做幂等过滤，保证前端展示和 process_details 都以唯一事件为准。
 		if (eventType == "tool_call" || eventType == "tool_result") && data != nil {
@@ -1103,6 +1152,13 @@ func (h *AgentHandler) createProgressCallback(runCtx context.Context, cancelRun
 			}
 		}
 
+		// 会话目标档案（工作记忆）：从工具结果中提取开放端口等目标信息并持久化
+		if eventType == "tool_result" {
+			if dataMap, ok := data.(map[string]interface{}); ok {
+				h.updateTargetProfileFromToolResult(conversationID, dataMap)
+			}
+		}
+
 		// 处理知识检索日志记录
 		if eventType == "tool_result" && h.knowledgeManager != nil {
 			if dataMap, ok := data.(map[string]interface{}); ok {
@@ -1205,9 +1261,7 @@ func (h *AgentHandler) createProgressCallback(runCtx context.Context, cancelRun
 							"riskType": riskType,
 							"toolName": toolName,
 						}
-						if err := h.db.AddProcessDetail(assistantMessageID, conversationID, "knowledge_retrieval", fmt.Sprintf("检索知识: %s", query), retrievalData); err != nil {
-							h.logger.Warn("保存知识检索详情失败", zap.Error(err))
-						}
+						h.db.EnqueueProcessDetail(assistantMessageID, conversationID, "knowledge_retrieval", fmt.Sprintf("检索知识: %s", query), retrievalData)
 					}
 				}
 			}
@@ -1257,9 +1311,7 @@ func (h *AgentHandler) createProgressCallback(runCtx context.Context, cancelRun
 			flushResponsePlan()
 			// 确保思考流在子代理回复前能持久化（刷新后可读）
 			flushThinkingStreams()
-			if err := h.db.AddProcessDetail(assistantMessageID, conversationID, "eino_agent_reply", message, data); err != nil {
-				h.logger.Warn("保存过程详情失败", zap.Error(err), zap.String("eventType", eventType))
-			}
+			h.db.EnqueueProcessDetail(assistantMessageID, conversationID, "eino_agent_reply", message, data)
 			return
 		}
 
@@ -1310,6 +1362,15 @@ func (h *AgentHandler) createProgressCallback(runCtx context.Context, cancelRun
 					respPlan.meta[k] = v
 				}
 			}
+			// 定期把已生成的部分内容刷进 messages 表，崩溃后不至于永远停在「处理中...」
+			if assistantMessageID != "" && time.Since(lastPartialPersistAt) >= assistantPartialPersistInterval {
+				if partial := strings.TrimSpace(respPlan.b.String()); partial != "" {
+					if err := h.persistAssistantPartialContent(assistantMessageID, partial); err != nil {
+						h.logger.Debug("流式部分内容落库失败", zap.Error(err))
+					}
+					lastPartialPersistAt = time.Now()
+				}
+			}
 			syncHitlCognition()
 			return
 		}
@@ -1412,6 +1473,8 @@ func (h *AgentHandler) createProgressCallback(runCtx context.Context, cancelRun
 			eventType != "response_start" &&
 			eventType != "response_delta" &&
 			eventType != "tool_result_delta" &&
+			// tool_output_chunk 仅实时推送（带 toolCallId 供前端按 id 归并），不逐条落库；完整输出以 tool_result 为准。
+			eventType != "tool_output_chunk" &&
 			eventType != "eino_trace_run" &&
 			eventType != "eino_trace_start" &&
 			eventType != "eino_trace_end" &&
@@ -1425,10 +1488,7 @@ func (h *AgentHandler) createProgressCallback(runCtx context.Context, cancelRun
 			// 在关键过程事件落库前，先把「规划中」与聚合中的 thinking / reasoning_chain 流落库
 			flushResponsePlan()
 			flushThinkingStreams()
-			processDetailID, err := h.db.AddProcessDetailWithID(assistantMessageID, conversationID, eventType, message, data)
-			if err != nil {
-				h.logger.Warn("保存过程详情失败", zap.Error(err), zap.String("eventType", eventType))
-			}
+			processDetailID := h.db.EnqueueProcessDetail(assistantMessageID, conversationID, eventType, message, data)
 			if deferToolProgressSend {
 				clientData := enrichProgressEventData(summarizeProcessDetailData(eventType, data), conversationID, assistantMessageID)
 				if m, ok := clientData.(map[string]interface{}); ok {
@@ -1591,7 +1651,8 @@ func (h *AgentHandler) CancelAgentLoop(c *gin.Context) {
 	})
 }
 
-// SubscribeAgentTaskEvents GET SSE：订阅指定会话当前运行中任务的事件镜像（帧格式与 POST .../stream 一致），用于刷新页面或断线后接续 UI。
+// SubscribeAgentTaskEvents GET SSE：订阅指定会话当前运行中任务的事件镜像（帧格式与 POST .../stream 一致），
+// 用于刷新页面或断线后接续 UI；带 Last-Event-ID（或 lastEventId 查询参数）时从该序号之后回放缺失事件。
 func (h *AgentHandler) SubscribeAgentTaskEvents(c *gin.Context) {
 	conversationID := strings.TrimSpace(c.Query("conversationId"))
 	if conversationID == "" {
@@ -1616,7 +1677,19 @@ func (h *AgentHandler) SubscribeAgentTaskEvents(c *gin.Context) {
 	c.Header("Connection", "keep-alive")
 	c.Header("X-Accel-Buffering", "no")
 
-	sub, ch := h.taskEventBus.Subscribe(conversationID)
+	// EventSource 重连会自动带 Last-Event-ID；手动重订阅可用 lastEventId 查询参数。
+	// 带序号订阅时先原子回放缓冲里漏掉的帧，再接实时事件。
+	lastEventID := strings.TrimSpace(c.GetHeader("Last-Event-ID"))
+	if lastEventID == "" {
+		lastEventID = strings.TrimSpace(c.Query("lastEventId"))
+	}
+	var sub *taskEventSub
+	var ch <-chan []byte
+	if afterSeq, perr := strconv.ParseUint(lastEventID, 10, 64); perr == nil && lastEventID != "" {
+		sub, ch = h.taskEventBus.SubscribeSince(conversationID, afterSeq)
+	} else {
+		sub, ch = h.taskEventBus.Subscribe(conversationID)
+	}
 	defer h.taskEventBus.Unsubscribe(conversationID, sub)
 
 	flusher, _ := c.Writer.(http.Flusher)
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"cyberstrike-ai/internal/multiagent"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// Agent 任务 WebSocket 双向通道：同一连接里下行推送任务事件（镜像自 taskEventBus，
+// 帧内容与 SSE 的 data 负载一致），上行接收 cancel / decision 等控制指令，
+// 前端无需为取消/审批另起 HTTP 请求。任务仍由 POST .../stream 或机器人等入口发起，
+// 连接不要求当前有运行中任务，可跨任务保持（刷新页面后直接重连接续 UI）。
+
+const agentLoopWSPingInterval = 25 * time.Second
+
+// agentLoopWSUpgrader 与终端 WS 一样依赖上层路由组的登录保护，放宽 Origin。
+var agentLoopWSUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// agentLoopWSCommand 客户端上行控制指令。
+type agentLoopWSCommand struct {
+	Type string `json:"type"` // cancel | decision | ping
+	// cancel：与 POST /agent-loop/cancel 语义一致
+	ExecutionID   string `json:"executionId,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	ContinueAfter bool   `json:"continueAfter,omitempty"`
+	// decision：与 POST /hitl/decision 语义一致
+	InterruptID     string                 `json:"interruptId,omitempty"`
+	Decision        string                 `json:"decision,omitempty"`
+	Comment         string                 `json:"comment,omitempty"`
+	EditedArguments map[string]interface{} `json:"editedArguments,omitempty"`
+}
+
+// AgentLoopWS GET /api/agent-loop/ws?conversationId=...
+func (h *AgentHandler) AgentLoopWS(c *gin.Context) {
+	conversationID := strings.TrimSpace(c.Query("conversationId"))
+	if conversationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "conversationId is required"})
+		return
+	}
+	if !h.agentConversationAllowed(c, conversationID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权访问该资源"})
+		return
+	}
+	if h.taskEventBus == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "task event bus unavailable"})
+		return
+	}
+
+	conn, err := agentLoopWSUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	writeJSON := func(v interface{}) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.WriteJSON(v)
+	}
+
+	sub, ch := h.taskEventBus.Subscribe(conversationID)
+	defer h.taskEventBus.Unsubscribe(conversationID, sub)
+
+	// 下行：转发任务事件（去掉 SSE 帧前缀，只发 JSON 负载）+ 周期 ping 保活
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(agentLoopWSPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case chunk, ok := <-ch:
+				if !ok {
+					return
+				}
+				payload := taskEventPayload(chunk)
+				if len(payload) == 0 {
+					continue
+				}
+				writeMu.Lock()
+				err := conn.WriteMessage(websocket.TextMessage, payload)
+				writeMu.Unlock()
+				if err != nil {
+					return
+				}
+			case <-ticker.C:
+				writeMu.Lock()
+				err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second))
+				writeMu.Unlock()
+				if err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// 上行：读取控制指令，复用 cancel / HITL decision 的现有逻辑
+	conn.SetReadLimit(256 * 1024)
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		var cmd agentLoopWSCommand
+		if err := conn.ReadJSON(&cmd); err != nil {
+			return
+		}
+		switch strings.ToLower(strings.TrimSpace(cmd.Type)) {
+		case "ping":
+			_ = writeJSON(gin.H{"type": "pong"})
+		case "cancel":
+			_ = writeJSON(h.handleAgentLoopWSCancel(conversationID, cmd))
+		case "decision":
+			_ = writeJSON(h.handleAgentLoopWSDecision(c, cmd))
+		default:
+			_ = writeJSON(gin.H{"type": "command_error", "error": "未知指令类型，可选：cancel、decision、ping"})
+		}
+	}
+}
+
+// handleAgentLoopWSCancel 与 CancelAgentLoop 保持同一套任务管理逻辑。
+func (h *AgentHandler) handleAgentLoopWSCancel(conversationID string, cmd agentLoopWSCommand) gin.H {
+	if cmd.ContinueAfter {
+		if h.tasks.GetTask(conversationID) == nil {
+			return gin.H{"type": "command_error", "error": "未找到正在执行的任务"}
+		}
+		note := strings.TrimSpace(cmd.Reason)
+		activeExec := strings.TrimSpace(h.tasks.ActiveMCPExecutionID(conversationID))
+		if ok, payload := h.cancelToolContinueAfter(conversationID, strings.TrimSpace(cmd.ExecutionID), note); ok {
+			result := gin.H{"type": "command_result", "command": "cancel"}
+			for k, v := range payload {
+				result[k] = v
+			}
+			return result
+		}
+		if activeExec != "" {
+			return gin.H{"type": "command_error", "error": "未找到进行中的工具执行或该调用已结束"}
+		}
+		h.tasks.SetInterruptContinueNote(conversationID, note)
+		ok, err := h.tasks.CancelTask(conversationID, multiagent.ErrInterruptContinue)
+		if err != nil {
+			return gin.H{"type": "command_error", "error": err.Error()}
+		}
+		if !ok {
+			return gin.H{"type": "command_error", "error": "未找到正在执行的任务"}
+		}
+		return gin.H{
+			"type":           "command_result",
+			"command":        "cancel",
+			"status":         "interrupt_continue_scheduled",
+			"conversationId": conversationID,
+		}
+	}
+
+	h.cancelRunningMCPToolsForConversation(conversationID)
+	h.tasks.AbortActiveEinoExecute(conversationID, "")
+	ok, err := h.tasks.CancelTask(conversationID, ErrTaskCancelled)
+	if err != nil {
+		return gin.H{"type": "command_error", "error": err.Error()}
+	}
+	if !ok {
+		return gin.H{"type": "command_error", "error": "未找到正在执行的任务"}
+	}
+	return gin.H{
+		"type":           "command_result",
+		"command":        "cancel",
+		"status":         "cancelling",
+		"conversationId": conversationID,
+	}
+}
+
+// handleAgentLoopWSDecision 与 DecideHITLInterrupt 保持同一套审批逻辑（含权限校验与审计）。
+func (h *AgentHandler) handleAgentLoopWSDecision(c *gin.Context, cmd agentLoopWSCommand) gin.H {
+	if h.hitlManager == nil {
+		return gin.H{"type": "command_error", "error": "hitl manager unavailable"}
+	}
+	interruptID := strings.TrimSpace(cmd.InterruptID)
+	if interruptID == "" {
+		return gin.H{"type": "command_error", "error": "interruptId is required"}
+	}
+	if !h.hitlInterruptAllowed(c, interruptID) {
+		return gin.H{"type": "command_error", "error": "无权访问该资源"}
+	}
+	if err := h.hitlManager.ResolveInterrupt(interruptID, cmd.Decision, cmd.Comment, cmd.EditedArguments); err != nil {
+		return gin.H{"type": "command_error", "error": err.Error()}
+	}
+	if h.audit != nil {
+		h.audit.RecordOK(c, "hitl", "decision", "HITL 审批决策", "hitl_interrupt", interruptID, map[string]interface{}{
+			"decision": cmd.Decision,
+			"via":      "websocket",
+		})
+	}
+	h.logger.Info("WebSocket HITL 审批决策",
+		zap.String("interruptId", interruptID),
+		zap.String("decision", cmd.Decision),
+	)
+	return gin.H{"type": "command_result", "command": "decision", "ok": true, "interruptId": interruptID}
+}
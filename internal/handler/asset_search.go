@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// AssetSearchOptions 各测绘平台通用的查询选项；分页与字段裁剪的语义由各实现
+// 自行翻译成自家 API 的参数。
+type AssetSearchOptions struct {
+	Size   int
+	Page   int
+	Fields string
+	Full   bool
+}
+
+// AssetSearchProvider 统一的测绘平台抽象：每个平台只负责把自家 API 的请求与
+// 响应翻译成归一化的结果结构（与 FOFA 一致的字段），参数校验、凭证缺失提示
+// 和错误响应由统一入口 serveAssetSearch 完成。新增平台时实现该接口并在
+// Provider 中注册即可。
+type AssetSearchProvider interface {
+	Name() string
+	Search(ctx context.Context, query string, opts AssetSearchOptions) (*fofaSearchResponse, error)
+}
+
+type assetSearchFunc func(ctx context.Context, query string, opts AssetSearchOptions) (*fofaSearchResponse, error)
+
+type assetSearchProviderFunc struct {
+	name string
+	fn   assetSearchFunc
+}
+
+func (p assetSearchProviderFunc) Name() string { return p.name }
+
+func (p assetSearchProviderFunc) Search(ctx context.Context, query string, opts AssetSearchOptions) (*fofaSearchResponse, error) {
+	return p.fn(ctx, query, opts)
+}
+
+// Provider 按名称返回对应平台的 AssetSearchProvider；名称不合法时返回 nil。
+func (h *FofaHandler) Provider(name string) AssetSearchProvider {
+	switch normalizeSpaceSearchProvider(name) {
+	case "fofa":
+		return assetSearchProviderFunc{name: "fofa", fn: h.searchFofa}
+	case "zoomeye":
+		return assetSearchProviderFunc{name: "zoomeye", fn: h.searchZoomEye}
+	case "quake":
+		return assetSearchProviderFunc{name: "quake", fn: h.searchQuake}
+	case "hunter":
+		return assetSearchProviderFunc{name: "hunter", fn: h.searchHunter}
+	case "shodan":
+		return assetSearchProviderFunc{name: "shodan", fn: h.searchShodan}
+	default:
+		return nil
+	}
+}
+
+// providerHTTPError 携带建议返回给前端的 HTTP 状态码，便于统一入口把
+// 配置类错误（500）与上游错误（502/504）区分开。
+type providerHTTPError struct {
+	status  int
+	message string
+}
+
+func (e *providerHTTPError) Error() string { return e.message }
+
+func providerErrorStatus(err error) int {
+	var pe *providerHTTPError
+	if errors.As(err, &pe) && pe.status > 0 {
+		return pe.status
+	}
+	return http.StatusBadGateway
+}
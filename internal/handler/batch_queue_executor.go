@@ -224,6 +224,7 @@ func (h *AgentHandler) executeOneBatchSubTask(queueID string, queue *BatchTaskQu
 	taskCtx = mcp.WithMCPConversationID(taskCtx, conversationID)
 	taskCtx = mcp.WithToolRunRegistry(taskCtx, h.tasks)
 	taskCtx = mcp.WithEinoExecuteRunRegistry(taskCtx, h.tasks)
+	taskCtx = mcp.WithParentExecutionTracker(taskCtx, &mcp.ParentExecutionTracker{})
 
 	useBatchMulti := false
 	batchOrch := "deep"
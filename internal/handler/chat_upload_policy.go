@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"cyberstrike-ai/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// chatUploadDefaultMaxSizeMB 未配置 security.chat_upload_max_size_mb 时的附件大小上限（MB）。
+const chatUploadDefaultMaxSizeMB = 50
+
+// SetUploadPolicy 应用附件上传策略（security.chat_upload_*：大小/类型限制与保留天数）。
+func (h *ChatUploadsHandler) SetUploadPolicy(sec *config.SecurityConfig) {
+	if sec == nil {
+		return
+	}
+	h.uploadMaxSizeMB = sec.ChatUploadMaxSizeMB
+	exts := make(map[string]struct{}, len(sec.ChatUploadAllowedExts))
+	for _, e := range sec.ChatUploadAllowedExts {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" {
+			continue
+		}
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		exts[e] = struct{}{}
+	}
+	h.uploadAllowedExts = exts
+	h.uploadRetentionDays = sec.ChatUploadRetentionDays
+}
+
+func (h *ChatUploadsHandler) uploadMaxSizeBytes() int64 {
+	mb := h.uploadMaxSizeMB
+	if mb < 0 {
+		return 0 // -1=不限制
+	}
+	if mb == 0 {
+		mb = chatUploadDefaultMaxSizeMB
+	}
+	return int64(mb) * 1024 * 1024
+}
+
+// checkUploadPolicy 校验待上传附件是否符合大小/类型限制；不符合时返回可直接回给前端的中文错误。
+func (h *ChatUploadsHandler) checkUploadPolicy(fileName string, size int64) error {
+	if max := h.uploadMaxSizeBytes(); max > 0 && size > max {
+		return fmt.Errorf("附件过大：%d 字节，超过 %d MB 上限（security.chat_upload_max_size_mb）", size, max/(1024*1024))
+	}
+	if len(h.uploadAllowedExts) > 0 {
+		ext := strings.ToLower(filepath.Ext(fileName))
+		if _, ok := h.uploadAllowedExts[ext]; !ok {
+			return fmt.Errorf("附件类型 %q 不在允许列表内（security.chat_upload_allowed_exts）", ext)
+		}
+	}
+	return nil
+}
+
+// PurgeExpiredUploads 附件生命周期管理：删除 chat_uploads 下超过保留天数的文件，
+// 并清理随之变空的日期/会话目录；retention<=0 表示不清理。由 app 启动的定时器周期调用。
+func (h *ChatUploadsHandler) PurgeExpiredUploads() {
+	if h.uploadRetentionDays <= 0 {
+		return
+	}
+	root, err := h.absRoot()
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -h.uploadRetentionDays)
+	removed := 0
+	var dirs []string
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info == nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != root {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return nil
+		}
+		removed++
+		if h.db != nil {
+			if rel, rerr := filepath.Rel(root, path); rerr == nil {
+				_ = h.db.DeleteChatUploadArtifactPath(filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	// 先删深层目录，空目录随文件清理一并移除
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err == nil && len(entries) == 0 {
+			_ = os.Remove(dir)
+		}
+	}
+	if removed > 0 && h.logger != nil {
+		h.logger.Info("已清理过期对话附件",
+			zap.Int("removed", removed),
+			zap.Int("retentionDays", h.uploadRetentionDays),
+		)
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"cyberstrike-ai/internal/config"
+
+	"go.uber.org/zap"
+)
+
+func TestCheckUploadPolicy(t *testing.T) {
+	h := NewChatUploadsHandler(zap.NewNop())
+	h.SetUploadPolicy(&config.SecurityConfig{
+		ChatUploadMaxSizeMB:   1,
+		ChatUploadAllowedExts: []string{"txt", ".PCAP"}, // 省略点号、大小写混用均应归一化
+	})
+
+	if err := h.checkUploadPolicy("targets.txt", 1024); err != nil {
+		t.Fatalf("合法附件不应被拒绝: %v", err)
+	}
+	if err := h.checkUploadPolicy("capture.pcap", 1024); err != nil {
+		t.Fatalf("扩展名大小写不应影响判定: %v", err)
+	}
+	if err := h.checkUploadPolicy("targets.txt", 2*1024*1024); err == nil {
+		t.Fatal("超过大小上限应被拒绝")
+	} else if !strings.Contains(err.Error(), "附件过大") {
+		t.Fatalf("大小超限错误信息不符: %v", err)
+	}
+	if err := h.checkUploadPolicy("shell.exe", 1024); err == nil {
+		t.Fatal("白名单外的扩展名应被拒绝")
+	} else if !strings.Contains(err.Error(), "不在允许列表内") {
+		t.Fatalf("类型限制错误信息不符: %v", err)
+	}
+}
+
+func TestCheckUploadPolicyDefaultsAndUnlimited(t *testing.T) {
+	h := NewChatUploadsHandler(zap.NewNop())
+	// 未配置：默认 50MB、不限类型
+	h.SetUploadPolicy(&config.SecurityConfig{})
+	if err := h.checkUploadPolicy("anything.bin", 10*1024*1024); err != nil {
+		t.Fatalf("默认策略下 10MB 任意类型应放行: %v", err)
+	}
+	if err := h.checkUploadPolicy("huge.bin", 51*1024*1024); err == nil {
+		t.Fatal("默认 50MB 上限应生效")
+	}
+	// -1 不限制大小
+	h.SetUploadPolicy(&config.SecurityConfig{ChatUploadMaxSizeMB: -1})
+	if err := h.checkUploadPolicy("huge.bin", 200*1024*1024); err != nil {
+		t.Fatalf("-1 应表示不限制大小: %v", err)
+	}
+}
@@ -44,6 +44,10 @@ type ChatUploadsHandler struct {
 	logger *zap.Logger
 	audit  *audit.Service
 	db     *database.DB
+	// 上传策略（security.chat_upload_*，见 SetUploadPolicy）
+	uploadMaxSizeMB     int                 // 0=默认 50；-1=不限制
+	uploadAllowedExts   map[string]struct{} // 空=不限制类型
+	uploadRetentionDays int                 // <=0=不清理
 }
 
 // SetAudit wires platform audit logging.
@@ -1405,6 +1409,10 @@ func (h *ChatUploadsHandler) Upload(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
 		return
 	}
+	if err := h.checkUploadPolicy(fh.Filename, fh.Size); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	root, err := h.absRoot()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
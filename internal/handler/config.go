@@ -284,6 +284,7 @@ type ToolConfigInfo struct {
 	ExternalMCP string                 `json:"external_mcp,omitempty"` // 外部MCP名称（如果是外部工具）
 	RoleEnabled *bool                  `json:"role_enabled,omitempty"` // 该工具在当前角色中是否启用（nil表示未指定角色或使用所有工具）
 	InputSchema map[string]interface{} `json:"input_schema,omitempty"` // 工具参数 JSON Schema（用于前端展示详情）
+	Installed   *bool                  `json:"installed,omitempty"`    // 工具命令是否在 PATH 中（仅配置文件工具；nil 表示不适用）
 }
 
 // GetConfig 获取当前配置
@@ -492,6 +493,8 @@ func (h *ConfigHandler) GetTools(c *gin.Context) {
 			Enabled:     tool.Enabled,
 			IsExternal:  false,
 		}
+		installed := security.ToolCommandInstalled(tool.Command)
+		toolInfo.Installed = &installed
 
 		// 根据角色配置标注工具状态
 		if roleName != "" {
@@ -736,6 +739,7 @@ type AgentConfigUpdate struct {
 	ExternalMCPCircuitFailureThreshold *int    `json:"external_mcp_circuit_failure_threshold,omitempty"`
 	ExternalMCPCircuitCooldownSeconds  *int    `json:"external_mcp_circuit_cooldown_seconds,omitempty"`
 	SystemPromptPath                   *string `json:"system_prompt_path,omitempty"`
+	ResponseTemplate                   *string `json:"response_template,omitempty"`
 }
 
 func applyAgentConfigUpdate(dst *config.AgentConfig, src *AgentConfigUpdate) {
@@ -766,6 +770,9 @@ func applyAgentConfigUpdate(dst *config.AgentConfig, src *AgentConfigUpdate) {
 	if src.SystemPromptPath != nil {
 		dst.SystemPromptPath = *src.SystemPromptPath
 	}
+	if src.ResponseTemplate != nil {
+		dst.ResponseTemplate = *src.ResponseTemplate
+	}
 }
 
 // ToolEnableStatus 工具启用状态
@@ -863,6 +870,7 @@ func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
 			h.executor.SetToolOutputMaxBytes(h.config.MultiAgent.EinoMiddleware.ReductionMaxLengthForTruncEffective())
 			h.executor.SetToolOutputSpillRoot(h.config.MultiAgent.EinoMiddleware.ReductionRootDir)
 		}
+		security.SetToolResourceLimits(h.config.Agent.ToolMaxOutputBytes, h.config.Agent.ToolMaxCPUSeconds)
 		if h.mcpServer != nil {
 			h.mcpServer.ConfigureHTTPToolCallTimeoutFromAgentMinutes(h.config.Agent.ToolTimeoutMinutes)
 			h.mcpServer.ConfigureToolWaitTimeoutSeconds(h.config.Agent.ToolWaitTimeoutSeconds)
@@ -1157,7 +1165,8 @@ func (h *ConfigHandler) TestOpenAI(c *gin.Context) {
 		return
 	}
 
-	if strings.TrimSpace(req.APIKey) == "" {
+	// Ollama 等本地 OpenAI 兼容端点无需 API Key，允许留空测试；Claude 仍必填
+	if strings.TrimSpace(req.APIKey) == "" && strings.EqualFold(strings.TrimSpace(req.Provider), "claude") {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "API Key 不能为空"})
 		return
 	}
@@ -1531,6 +1540,7 @@ func (h *ConfigHandler) ApplyConfig(c *gin.Context) {
 	// 重新注册安全工具
 	h.executor.SetToolOutputMaxBytes(h.config.MultiAgent.EinoMiddleware.ReductionMaxLengthForTruncEffective())
 	h.executor.SetToolOutputSpillRoot(h.config.MultiAgent.EinoMiddleware.ReductionRootDir)
+	security.SetToolResourceLimits(h.config.Agent.ToolMaxOutputBytes, h.config.Agent.ToolMaxCPUSeconds)
 	h.executor.RegisterTools(h.mcpServer)
 	mcp.RegisterExecutionControlTools(h.mcpServer, h.externalMCPMgr)
 
@@ -1611,6 +1621,7 @@ func (h *ConfigHandler) ApplyConfig(c *gin.Context) {
 		h.executor.SetToolOutputMaxBytes(h.config.MultiAgent.EinoMiddleware.ReductionMaxLengthForTruncEffective())
 		h.executor.SetToolOutputSpillRoot(h.config.MultiAgent.EinoMiddleware.ReductionRootDir)
 	}
+	security.SetToolResourceLimits(h.config.Agent.ToolMaxOutputBytes, h.config.Agent.ToolMaxCPUSeconds)
 	if h.externalMCPMgr != nil {
 		h.externalMCPMgr.ConfigureToolWaitTimeoutSeconds(h.config.Agent.ToolWaitTimeoutSeconds)
 		h.externalMCPMgr.ConfigureToolResultMaxBytes(h.config.MultiAgent.EinoMiddleware.ReductionMaxLengthForTruncEffective())
@@ -1817,6 +1828,7 @@ func updateAgentConfig(doc *yaml.Node, agent config.AgentConfig) {
 	setIntInMap(agentNode, "external_mcp_circuit_failure_threshold", agent.ExternalMCPCircuitFailureThreshold)
 	setIntInMap(agentNode, "external_mcp_circuit_cooldown_seconds", agent.ExternalMCPCircuitCooldownSeconds)
 	setStringInMap(agentNode, "system_prompt_path", agent.SystemPromptPath)
+	setStringInMap(agentNode, "response_template", agent.ResponseTemplate)
 }
 
 func updateMCPConfig(doc *yaml.Node, cfg config.MCPConfig) {
@@ -1992,7 +2004,17 @@ func updateKnowledgeConfig(doc *yaml.Node, cfg config.KnowledgeConfig) {
 	retrievalNode := ensureMap(knowledgeNode, "retrieval")
 	setIntInMap(retrievalNode, "top_k", cfg.Retrieval.TopK)
 	setFloatInMap(retrievalNode, "similarity_threshold", cfg.Retrieval.SimilarityThreshold)
+	if cfg.Retrieval.ThresholdMode != "" {
+		setStringInMap(retrievalNode, "threshold_mode", cfg.Retrieval.ThresholdMode)
+	}
+	if cfg.Retrieval.MinSimilarityThreshold > 0 {
+		setFloatInMap(retrievalNode, "min_similarity_threshold", cfg.Retrieval.MinSimilarityThreshold)
+	}
 	setStringInMap(retrievalNode, "sub_index_filter", cfg.Retrieval.SubIndexFilter)
+	setBoolInMap(retrievalNode, "auto_inject", cfg.Retrieval.AutoInject)
+	if cfg.Retrieval.AutoInjectTopK > 0 {
+		setIntInMap(retrievalNode, "auto_inject_top_k", cfg.Retrieval.AutoInjectTopK)
+	}
 	mqNode := ensureMap(retrievalNode, "multi_query")
 	setIntInMap(mqNode, "max_queries", cfg.Retrieval.MultiQuery.MaxQueries)
 	rerankNode := ensureMap(retrievalNode, "rerank")
@@ -2500,8 +2522,8 @@ func pickToolDescriptionWithMode(mode, shortDesc, fullDesc string) string {
 	} else if description == "" {
 		description = fullDesc
 	}
-	if len(description) > 10000 {
-		description = description[:10000] + "..."
+	if d := safeTruncateString(description, 10000); d != description {
+		description = d + "..."
 	}
 	return description
 }
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cyberstrike-ai/internal/knowledge"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestEmbeddingRequest 嵌入服务连通性自检请求；字段留空时回退当前配置里的对应值。
+type TestEmbeddingRequest struct {
+	Provider string `json:"provider"`
+	BaseURL  string `json:"base_url"`
+	APIKey   string `json:"api_key"`
+	Model    string `json:"model"`
+}
+
+// TestEmbedding 发送一个最小的嵌入请求验证 knowledge.embedding 配置是否可用，
+// 返回耗时、模型与向量维度；配置页"测试连接"按钮用。与 TestOpenAI 一致，
+// 探测失败也返回 200 + success:false，便于前端展示错误详情。
+func (h *ConfigHandler) TestEmbedding(c *gin.Context) {
+	var req TestEmbeddingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数: " + err.Error()})
+		return
+	}
+
+	h.mu.RLock()
+	knowledgeCfg := h.config.Knowledge
+	openAICfg := h.config.OpenAI
+	h.mu.RUnlock()
+
+	// 请求里给了哪项就覆盖哪项，便于保存前试探新配置
+	if req.Provider != "" {
+		knowledgeCfg.Embedding.Provider = req.Provider
+	}
+	if req.BaseURL != "" {
+		knowledgeCfg.Embedding.BaseURL = req.BaseURL
+	}
+	if req.APIKey != "" {
+		knowledgeCfg.Embedding.APIKey = req.APIKey
+	}
+	if req.Model != "" {
+		knowledgeCfg.Embedding.Model = req.Model
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	embedder, err := knowledge.NewEmbedder(ctx, &knowledgeCfg, &openAICfg, h.logger)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"error":   "嵌入服务初始化失败: " + err.Error(),
+		})
+		return
+	}
+
+	start := time.Now()
+	vec, err := embedder.EmbedText(ctx, "connectivity check")
+	latency := time.Since(start)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success":    false,
+			"error":      "嵌入请求失败: " + err.Error(),
+			"latency_ms": latency.Milliseconds(),
+		})
+		return
+	}
+	if len(vec) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"success":    false,
+			"error":      "嵌入服务返回了空向量",
+			"latency_ms": latency.Milliseconds(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"model":      embedder.EmbeddingModelName(),
+		"dimensions": len(vec),
+		"latency_ms": latency.Milliseconds(),
+		"message":    fmt.Sprintf("嵌入服务连接成功（%d 维，耗时 %dms）", len(vec), latency.Milliseconds()),
+	})
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 工具运行时开关：前端在工具列表页（GET /api/tools，复用 GetTools）单个启停工具，
+// 无需走整份配置的 PUT /api/config。变更会同步写回配置文件，重启后仍生效。
+
+// EnableTool 启用指定工具并持久化到配置文件
+// POST /api/tools/:name/enable（外部 MCP 工具用 "MCP名::工具名" 形式）
+func (h *ConfigHandler) EnableTool(c *gin.Context) {
+	h.setToolEnabled(c, true)
+}
+
+// DisableTool 禁用指定工具并持久化到配置文件
+// POST /api/tools/:name/disable
+func (h *ConfigHandler) DisableTool(c *gin.Context) {
+	h.setToolEnabled(c, false)
+}
+
+func (h *ConfigHandler) setToolEnabled(c *gin.Context, enabled bool) {
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, "工具名称不能为空")
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if mcpName, toolName, isExternal := strings.Cut(name, "::"); isExternal {
+		h.setExternalToolEnabledLocked(c, mcpName, toolName, enabled)
+		return
+	}
+
+	found := false
+	for i := range h.config.Security.Tools {
+		if h.config.Security.Tools[i].Name == name {
+			h.config.Security.Tools[i].Enabled = enabled
+			found = true
+			break
+		}
+	}
+	if !found {
+		RespondError(c, http.StatusNotFound, ErrCodeNotFound, "工具未找到: "+name)
+		return
+	}
+	h.finishToolToggleLocked(c, name, enabled)
+}
+
+// setExternalToolEnabledLocked 切换外部 MCP 工具启用状态；与 UpdateConfig 的批量逻辑一致：
+// 启用工具时自动启用所属 MCP 并异步拉起客户端，禁用单个工具不影响 MCP 本身。
+func (h *ConfigHandler) setExternalToolEnabledLocked(c *gin.Context, mcpName, toolName string, enabled bool) {
+	cfg, exists := h.config.ExternalMCP.Servers[mcpName]
+	if !exists {
+		RespondError(c, http.StatusNotFound, ErrCodeNotFound, "外部MCP配置不存在: "+mcpName)
+		return
+	}
+
+	if cfg.ToolEnabled == nil {
+		cfg.ToolEnabled = make(map[string]bool)
+	}
+	cfg.ToolEnabled[toolName] = enabled
+	if enabled && !cfg.ExternalMCPEnable {
+		cfg.ExternalMCPEnable = true
+		h.logger.Info("自动启用外部MCP（因为有工具启用）", zap.String("mcp", mcpName))
+	}
+	h.config.ExternalMCP.Servers[mcpName] = cfg
+
+	if h.externalMCPMgr != nil {
+		// 同步更新 externalMCPMgr 中的配置，确保 GetConfigs() 返回最新配置
+		h.externalMCPMgr.LoadConfigs(&h.config.ExternalMCP)
+		if enabled {
+			if client, ok := h.externalMCPMgr.GetClient(mcpName); !ok || !client.IsConnected() {
+				go func() {
+					if err := h.externalMCPMgr.StartClient(mcpName); err != nil {
+						h.logger.Warn("启动外部MCP失败", zap.String("mcp", mcpName), zap.Error(err))
+					}
+				}()
+			}
+		}
+	}
+	h.finishToolToggleLocked(c, mcpName+"::"+toolName, enabled)
+}
+
+// finishToolToggleLocked 持久化配置、记录审计并返回最终状态；调用方需持有 h.mu 写锁
+func (h *ConfigHandler) finishToolToggleLocked(c *gin.Context, name string, enabled bool) {
+	if err := h.saveConfig(); err != nil {
+		h.logger.Error("保存配置失败", zap.Error(err))
+		RespondError(c, http.StatusInternalServerError, ErrCodeInternal, "保存配置失败: "+err.Error())
+		return
+	}
+	h.logger.Info("切换工具启用状态", zap.String("tool", name), zap.Bool("enabled", enabled))
+	if h.audit != nil {
+		action := "tool_disable"
+		if enabled {
+			action = "tool_enable"
+		}
+		h.audit.RecordOK(c, "config", action, "切换工具启用状态", "tool", name, map[string]interface{}{
+			"enabled": enabled,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"name": name, "enabled": enabled})
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"cyberstrike-ai/internal/audit"
 	"cyberstrike-ai/internal/database"
@@ -236,6 +237,31 @@ func (h *ConversationHandler) GetMessageProcessDetails(c *gin.Context) {
 		return
 	}
 
+	// 按事件类型过滤（event_type=tool_call 或 event_type=tool_call,tool_result），返回匹配的全量详情
+	if eventTypeStr := strings.TrimSpace(c.Query("event_type")); eventTypeStr != "" {
+		var eventTypes []string
+		for _, et := range strings.Split(eventTypeStr, ",") {
+			if et = strings.TrimSpace(et); et != "" {
+				eventTypes = append(eventTypes, et)
+			}
+		}
+		details, err := h.db.GetProcessDetails(messageID, eventTypes...)
+		if err != nil {
+			h.logger.Error("获取过程详情失败", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		out := processDetailsToJSON(h.logger, details, true)
+		c.JSON(http.StatusOK, gin.H{
+			"processDetails": out,
+			"total":          len(out),
+			"offset":         0,
+			"limit":          len(out),
+			"hasMore":        false,
+		})
+		return
+	}
+
 	fullStr := strings.TrimSpace(c.Query("full"))
 	if fullStr == "1" || strings.EqualFold(fullStr, "true") || strings.EqualFold(fullStr, "yes") {
 		details, err := h.db.GetProcessDetails(messageID)
@@ -317,6 +343,48 @@ func (h *ConversationHandler) GetMessageProcessDetails(c *gin.Context) {
 	})
 }
 
+// GetConversationExecutions 按会话分页返回 MCP 执行记录（含 args/result/status/耗时），
+// 避免前端拿着 MCPExecutionIDs 一条条调 GetExecution。
+func (h *ConversationHandler) GetConversationExecutions(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "conversation id required"})
+		return
+	}
+	if _, err := h.db.GetConversationLite(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "对话不存在"})
+		return
+	}
+
+	limit := 50
+	if limitStr := strings.TrimSpace(c.Query("limit")); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+	offset, _ := strconv.Atoi(strings.TrimSpace(c.Query("offset")))
+	if offset < 0 {
+		offset = 0
+	}
+
+	executions, total, err := h.db.LoadToolExecutionsByConversation(id, offset, limit)
+	if err != nil {
+		h.logger.Error("获取会话执行记录失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"executions": executions,
+		"total":      total,
+		"offset":     offset,
+		"limit":      limit,
+		"hasMore":    offset+len(executions) < total,
+	})
+}
+
 // GetProcessDetail 获取单条完整过程详情。列表接口默认不给工具 payload，用户点开单条工具时再拉这里。
 func (h *ConversationHandler) GetProcessDetail(c *gin.Context) {
 	id := strings.TrimSpace(c.Param("id"))
@@ -450,6 +518,99 @@ func (h *ConversationHandler) DeleteConversation(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
 }
 
+// BatchDeleteConversationsRequest 批量删除对话请求
+type BatchDeleteConversationsRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// BatchDeleteConversations 批量删除对话（POST /api/conversations/batch-delete）
+func (h *ConversationHandler) BatchDeleteConversations(c *gin.Context) {
+	var req BatchDeleteConversationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.IDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids 不能为空"})
+		return
+	}
+
+	if h.taskStopper != nil {
+		for _, id := range req.IDs {
+			h.taskStopper.CancelRunningTaskForConversation(id)
+		}
+	}
+
+	deleted, err := h.db.DeleteConversations(req.IDs)
+	if err != nil {
+		h.logger.Error("批量删除对话失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.audit != nil {
+		h.audit.RecordOK(c, "conversation", "batch_delete", "批量删除对话", "conversation", "", map[string]interface{}{
+			"requested": len(req.IDs),
+			"deleted":   deleted,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": deleted,
+		"message": "删除成功",
+	})
+}
+
+// ClearConversationsRequest 清空对话请求；before 为 RFC3339 时间，省略时清空全部对话。
+type ClearConversationsRequest struct {
+	Before string `json:"before"`
+}
+
+// ClearConversations 清空对话（POST /api/conversations/clear），仅删除 updated_at 早于 before 的会话。
+func (h *ConversationHandler) ClearConversations(c *gin.Context) {
+	var req ClearConversationsRequest
+	// 允许空请求体（清空全部）
+	_ = c.ShouldBindJSON(&req)
+
+	var before time.Time
+	if s := strings.TrimSpace(req.Before); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "before 需为 RFC3339 时间，如 2025-01-01T00:00:00Z"})
+			return
+		}
+		before = t
+	}
+
+	ids, err := h.db.ListConversationIDsUpdatedBefore(before)
+	if err != nil {
+		h.logger.Error("查询待清空对话失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.taskStopper != nil {
+		for _, id := range ids {
+			h.taskStopper.CancelRunningTaskForConversation(id)
+		}
+	}
+
+	deleted, err := h.db.DeleteConversations(ids)
+	if err != nil {
+		h.logger.Error("清空对话失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.audit != nil {
+		h.audit.RecordOK(c, "conversation", "clear", "清空对话", "conversation", "", map[string]interface{}{
+			"before":  req.Before,
+			"deleted": deleted,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": deleted,
+		"message": "清空成功",
+	})
+}
+
 // DeleteTurnRequest 删除一轮对话（POST /api/conversations/:id/delete-turn）
 type DeleteTurnRequest struct {
 	MessageID string `json:"messageId"`
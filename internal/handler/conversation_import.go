@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cyberstrike-ai/internal/audit"
+	"cyberstrike-ai/internal/database"
+	"cyberstrike-ai/internal/security"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 会话导入：接收导出的会话 JSON（GET /api/conversations/:id 的结构），重建为一个
+// 新会话（新 ID），保留消息顺序、角色与 MCP 执行 ID 关联。用于实例间迁移历史或
+// 从备份恢复误删的会话。过程详情/执行记录不随 JSON 迁移，仅保留 ID 引用。
+
+// ImportConversationRequest 会话导入请求，兼容导出 JSON 的顶层结构
+type ImportConversationRequest struct {
+	Title     string                      `json:"title"`
+	ProjectID string                      `json:"projectId,omitempty"`
+	Messages  []ImportConversationMessage `json:"messages"`
+}
+
+// ImportConversationMessage 导入的单条消息（时间戳与 seq 由导入端按顺序重新分配）
+type ImportConversationMessage struct {
+	Role             string   `json:"role"`
+	Content          string   `json:"content"`
+	ReasoningContent string   `json:"reasoningContent,omitempty"`
+	MCPExecutionIDs  []string `json:"mcpExecutionIds,omitempty"`
+}
+
+// ImportConversation 从导出的 JSON 重建会话
+// POST /api/conversations/import
+func (h *ConversationHandler) ImportConversation(c *gin.Context) {
+	var req ImportConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, "导入数据不是合法的会话 JSON: "+err.Error())
+		return
+	}
+
+	if len(req.Messages) == 0 {
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, "导入数据缺少 messages，无法重建会话")
+		return
+	}
+	for i, msg := range req.Messages {
+		if err := database.ValidateMessageRole(msg.Role); err != nil {
+			RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, fmt.Sprintf("第 %d 条消息角色非法: %v", i+1, err))
+			return
+		}
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = "导入的对话"
+	}
+
+	meta := audit.ConversationCreateMetaFromGin(c, "import")
+	meta.ProjectID = strings.TrimSpace(req.ProjectID)
+	if !h.conversationProjectAllowed(c, meta.ProjectID) {
+		RespondError(c, http.StatusForbidden, ErrCodeForbidden, "无权访问目标项目")
+		return
+	}
+
+	conv, err := h.db.CreateConversation(title, meta)
+	if err != nil {
+		h.logger.Error("导入时创建对话失败", zap.Error(err))
+		RespondError(c, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	if session, ok := security.CurrentSession(c); ok {
+		_ = h.db.SetResourceOwner("conversation", conv.ID, session.UserID)
+		_ = h.db.AssignResourceToUser(session.UserID, "conversation", conv.ID)
+		if conv.ProjectID != "" {
+			_ = h.db.AssignResourceToUser(session.UserID, "project", conv.ProjectID)
+		}
+	}
+
+	// AddMessage 的 seq 为会话内 MAX+1，按导出顺序逐条写入即保证消息顺序不变
+	for i, msg := range req.Messages {
+		saved, err := h.db.AddMessage(conv.ID, strings.TrimSpace(msg.Role), msg.Content, msg.MCPExecutionIDs)
+		if err != nil {
+			h.logger.Error("导入消息失败，回滚已创建的会话",
+				zap.String("conversationId", conv.ID),
+				zap.Int("index", i),
+				zap.Error(err),
+			)
+			_ = h.db.DeleteConversation(conv.ID)
+			RespondError(c, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("导入第 %d 条消息失败: %v", i+1, err))
+			return
+		}
+		if rc := strings.TrimSpace(msg.ReasoningContent); rc != "" {
+			if err := h.db.UpdateAssistantMessageFinalize(saved.ID, msg.Content, msg.MCPExecutionIDs, rc); err != nil {
+				h.logger.Warn("导入消息思考链失败", zap.String("messageId", saved.ID), zap.Error(err))
+			}
+		}
+	}
+
+	if h.audit != nil {
+		h.audit.RecordOK(c, "conversation", "import", "导入对话", "conversation", conv.ID, map[string]interface{}{
+			"messages": len(req.Messages),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":       conv.ID,
+		"title":    title,
+		"imported": len(req.Messages),
+	})
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cyberstrike-ai/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestImportConversationRebuildsMessagesInOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "import.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	h := NewConversationHandler(db, zap.NewNop())
+	body := `{
+		"title": "迁移的会话",
+		"messages": [
+			{"role": "user", "content": "扫描 example.com"},
+			{"role": "assistant", "content": "已完成扫描", "mcpExecutionIds": ["exec-1", "exec-2"]},
+			{"role": "user", "content": "输出报告"}
+		]
+	}`
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/api/conversations/import", strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	h.ImportConversation(ctx)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("ImportConversation() status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+
+	convs, err := db.ListConversations(10, 0, "", "", "")
+	if err != nil || len(convs) != 1 {
+		t.Fatalf("ListConversations: %v, len = %d", err, len(convs))
+	}
+	if convs[0].Title != "迁移的会话" {
+		t.Fatalf("Title = %q", convs[0].Title)
+	}
+
+	messages, err := db.GetMessages(convs[0].ID)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("messages = %d, want 3", len(messages))
+	}
+	if messages[0].Role != "user" || messages[1].Role != "assistant" || messages[2].Role != "user" {
+		t.Fatalf("消息顺序/角色不符: %+v", messages)
+	}
+	if len(messages[1].MCPExecutionIDs) != 2 || messages[1].MCPExecutionIDs[0] != "exec-1" {
+		t.Fatalf("MCP 执行 ID 未保留: %+v", messages[1].MCPExecutionIDs)
+	}
+}
+
+func TestImportConversationRejectsInvalidStructure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "import-bad.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	h := NewConversationHandler(db, zap.NewNop())
+	for _, body := range []string{
+		`{"title": "空消息"}`,
+		`{"messages": [{"role": "hacker", "content": "x"}]}`,
+		`not json`,
+	} {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/api/conversations/import", strings.NewReader(body))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		h.ImportConversation(ctx)
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("body %q status = %d, want 400", body, recorder.Code)
+		}
+	}
+}
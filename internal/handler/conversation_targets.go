@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 会话授权目标：声明本会话允许测试的目标主机（IP、域名或 URL），
+// 工具执行前会校验参数中的目标是否越权，防止多轮对话中 LLM 漂移到未授权目标。
+
+// SetConversationTargetsRequest 设置会话授权目标请求
+type SetConversationTargetsRequest struct {
+	Targets []string `json:"targets"`
+}
+
+// GetConversationTargets 获取会话声明的授权目标
+// GET /api/conversations/:id/targets
+func (h *ConversationHandler) GetConversationTargets(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.db.GetConversation(id); err != nil {
+		RespondError(c, http.StatusNotFound, ErrCodeNotFound, "对话不存在")
+		return
+	}
+	targets, err := h.db.GetConversationAuthorizedTargets(id)
+	if err != nil {
+		h.logger.Error("查询会话授权目标失败", zap.String("conversationId", id), zap.Error(err))
+		RespondError(c, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"targets": targets})
+}
+
+// SetConversationTargets 整体替换会话的授权目标集合；空列表表示清除限制
+// PUT /api/conversations/:id/targets
+func (h *ConversationHandler) SetConversationTargets(c *gin.Context) {
+	id := c.Param("id")
+	var req SetConversationTargetsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+	if _, err := h.db.GetConversation(id); err != nil {
+		RespondError(c, http.StatusNotFound, ErrCodeNotFound, "对话不存在")
+		return
+	}
+	targets, err := h.db.SetConversationAuthorizedTargets(id, req.Targets)
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+	if h.audit != nil {
+		h.audit.RecordOK(c, "conversation", "set_targets", "设置会话授权目标", "conversation", id, map[string]interface{}{
+			"targets": targets,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"targets": targets})
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"cyberstrike-ai/internal/audit"
+	"cyberstrike-ai/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DatabaseBackupHandler 数据库备份：后台定时任务之外提供手动触发入口
+type DatabaseBackupHandler struct {
+	db     *database.DB
+	dir    string
+	keep   int
+	logger *zap.Logger
+	audit  *audit.Service
+}
+
+// NewDatabaseBackupHandler 创建数据库备份处理器
+func NewDatabaseBackupHandler(db *database.DB, dir string, keep int, logger *zap.Logger) *DatabaseBackupHandler {
+	return &DatabaseBackupHandler{db: db, dir: dir, keep: keep, logger: logger}
+}
+
+// SetAudit wires platform audit logging.
+func (h *DatabaseBackupHandler) SetAudit(s *audit.Service) {
+	h.audit = s
+}
+
+// TriggerBackup 手动触发一次数据库备份
+// POST /api/database/backup
+func (h *DatabaseBackupHandler) TriggerBackup(c *gin.Context) {
+	file, err := h.db.BackupTo(h.dir, h.keep)
+	if err != nil {
+		h.logger.Error("手动数据库备份失败", zap.Error(err))
+		RespondError(c, http.StatusInternalServerError, ErrCodeInternal, "数据库备份失败: "+err.Error())
+		return
+	}
+	h.logger.Info("手动数据库备份完成", zap.String("file", file))
+	if h.audit != nil {
+		h.audit.RecordOK(c, "database", "backup", "手动触发数据库备份", "database", "", map[string]interface{}{
+			"file": file,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"file": file})
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cyberstrike-ai/internal/config"
+	openaiClient "cyberstrike-ai/internal/openai"
+
+	"go.uber.org/zap"
+)
+
+// 计划-执行-反思模式（agent.agent_mode: plan）：
+// 在进入 Eino 单代理循环前先让 LLM 产出结构化测试计划，计划存为 process detail
+// 供前端展示，并注入到任务消息里要求代理逐步执行、每步后反思是否需要调整计划。
+// 计划生成失败时静默回退 simple 模式，不阻塞任务。
+
+type agentPlanStep struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+type agentPlan struct {
+	Goal  string          `json:"goal,omitempty"`
+	Steps []agentPlanStep `json:"steps"`
+}
+
+func agentPlanModeEnabled(cfg *config.Config) bool {
+	if cfg == nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(cfg.Agent.AgentMode), "plan")
+}
+
+// applyAgentPlanMode 生成测试计划、落库并广播给前端；返回应追加到任务消息
+// 末尾的计划文本块，失败时返回空串（回退 simple 行为）。
+func (h *AgentHandler) applyAgentPlanMode(ctx context.Context, runCfg *config.Config, conversationID, assistantMessageID, message string, sendEvent func(eventType, message string, data interface{})) string {
+	plan, err := h.generateAgentPlan(ctx, runCfg, message)
+	if err != nil {
+		h.logger.Warn("计划模式：生成测试计划失败，回退 simple 模式",
+			zap.String("conversationId", conversationID),
+			zap.Error(err),
+		)
+		sendEvent("progress", "测试计划生成失败，按普通模式继续执行", map[string]interface{}{
+			"conversationId": conversationID,
+		})
+		return ""
+	}
+
+	if assistantMessageID != "" {
+		_ = h.db.AddProcessDetail(assistantMessageID, conversationID, "plan", "测试计划已生成", plan)
+	}
+	sendEvent("plan", "测试计划已生成", map[string]interface{}{
+		"conversationId": conversationID,
+		"plan":           plan,
+	})
+	return formatAgentPlanBlock(plan)
+}
+
+func (h *AgentHandler) generateAgentPlan(ctx context.Context, runCfg *config.Config, message string) (*agentPlan, error) {
+	if runCfg == nil || strings.TrimSpace(runCfg.OpenAI.APIKey) == "" || strings.TrimSpace(runCfg.OpenAI.Model) == "" {
+		return nil, fmt.Errorf("openai 配置不完整，无法生成计划")
+	}
+	client := openaiClient.NewClient(&runCfg.OpenAI, &http.Client{Timeout: 2 * time.Minute}, h.logger)
+
+	systemPrompt := strings.TrimSpace(`
+你是渗透测试任务规划器。把用户的测试需求拆解为一个有序的步骤计划。
+
+输出要求：
+1) 只输出 JSON（不要 markdown、不要代码块、不要额外解释文本）
+2) JSON 结构必须是：
+{
+  "goal": "string，一句话概括测试目标",
+  "steps": [{"title": "string，步骤短标题", "description": "string，该步骤做什么、预期产出"}...]
+}
+3) 步骤控制在 3-8 步，从信息收集到验证汇报循序渐进；只规划授权测试范围内的动作。
+`)
+
+	requestBody := map[string]interface{}{
+		"model": runCfg.OpenAI.Model,
+		"messages": []map[string]interface{}{
+			{"role": "system", "content": systemPrompt},
+			{"role": "user", "content": "测试需求：" + message},
+		},
+		"temperature":           0.1,
+		"max_completion_tokens": 4000,
+	}
+
+	var apiResponse struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+
+	planCtx, cancel := context.WithTimeout(ctx, 90*time.Second)
+	defer cancel()
+	if err := client.ChatCompletion(planCtx, requestBody, &apiResponse); err != nil {
+		return nil, err
+	}
+	if len(apiResponse.Choices) == 0 {
+		return nil, fmt.Errorf("计划生成未返回内容")
+	}
+
+	jsonContent, err := extractInfoCollectJSONObject(apiResponse.Choices[0].Message.Content)
+	if err != nil {
+		return nil, fmt.Errorf("计划内容无法解析为 JSON: %w", err)
+	}
+	var plan agentPlan
+	if err := json.Unmarshal([]byte(jsonContent), &plan); err != nil {
+		return nil, fmt.Errorf("计划内容解析失败: %w", err)
+	}
+	if len(plan.Steps) == 0 {
+		return nil, fmt.Errorf("计划步骤为空")
+	}
+	return &plan, nil
+}
+
+// formatAgentPlanBlock 把计划渲染成注入任务消息的文本块，附带逐步执行与反思的指令。
+func formatAgentPlanBlock(plan *agentPlan) string {
+	var b strings.Builder
+	b.WriteString("【测试计划】请严格按以下计划逐步执行：\n")
+	if goal := strings.TrimSpace(plan.Goal); goal != "" {
+		b.WriteString("目标：" + goal + "\n")
+	}
+	for i, step := range plan.Steps {
+		b.WriteString(fmt.Sprintf("%d. %s", i+1, strings.TrimSpace(step.Title)))
+		if desc := strings.TrimSpace(step.Description); desc != "" {
+			b.WriteString("：" + desc)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n执行要求：每完成一步，先输出一段以「【反思】」开头的简短自查（该步结果是否达到预期、后续计划是否需要调整），再进入下一步；如需调整计划，明确说明调整内容后按新计划继续。最终汇总全部步骤的结论。")
+	return b.String()
+}
@@ -60,7 +60,8 @@ func (h *AgentHandler) EinoSingleAgentLoopStream(c *gin.Context) {
 		sseLine = append(sseLine, b...)
 		sseLine = append(sseLine, '\n', '\n')
 		if ssePublishConversationID != "" && h.taskEventBus != nil {
-			h.taskEventBus.Publish(ssePublishConversationID, sseLine)
+			// Publish 返回带 id: 序号的完整帧，主流直接写它，与镜像流序号对齐（断线可按 Last-Event-ID 续传）
+			sseLine = h.taskEventBus.Publish(ssePublishConversationID, sseLine)
 		}
 		if clientDisconnected {
 			return
@@ -185,15 +186,30 @@ func (h *AgentHandler) EinoSingleAgentLoopStream(c *gin.Context) {
 	}
 	taskOwned = true
 
+	if agentPlanModeEnabled(h.config) {
+		if planBlock := h.applyAgentPlanMode(taskCtx, runCfg, conversationID, assistantMessageID, curFinalMessage, sendEvent); planBlock != "" {
+			curFinalMessage = curFinalMessage + "\n\n" + planBlock
+		}
+	}
+
+	// RAG 自动前置注入：每条用户消息检索一次，分段续跑复用同一结果。
+	knowledgeBlock := h.knowledgeAutoInjectBlock(taskCtx, req.Message)
+
 	var cumulativeMCPExecutionIDs []string
 	// 同一请求内分段续跑时，主代理 iteration 事件按偏移累计，避免 UI 出现「第3轮 → 第1轮」回跳。
 	var mainIterationOffset int
 	var emptyResponseContinueAttempt int
+	// 工具调用预算贯穿同一请求内全部分段续跑（中断继续/空响应续跑共享计数）。
+	toolBudget := h.newEinoToolCallBudget(runCfg, conversationID, sendEvent)
+	// 成本预算同样贯穿分段续跑；超限由预算中间件中止循环。
+	tokenBudget := h.newEinoTokenBudget(runCfg, req.Budget, conversationID, sendEvent)
 
 	for {
 		segmentMainIterationMax := 0
 		rawProgressCallback := h.createProgressCallback(taskCtx, cancelWithCause, conversationID, assistantMessageID, sendEvent)
+		idleTouch, idleStop := startSSEIdleWatchdog(taskCtx, h.sseIdleTimeout(), cancelWithCause)
 		progressCallback := func(eventType, message string, data interface{}) {
+			idleTouch()
 			if eventType == "iteration" {
 				if m, ok := data.(map[string]interface{}); ok {
 					if scope, _ := m["einoScope"].(string); scope == "main" {
@@ -224,9 +240,12 @@ func (h *AgentHandler) EinoSingleAgentLoopStream(c *gin.Context) {
 		taskCtxLoop := mcp.WithMCPConversationID(taskCtx, conversationID)
 		taskCtxLoop = mcp.WithToolRunRegistry(taskCtxLoop, h.tasks)
 		taskCtxLoop = mcp.WithEinoExecuteRunRegistry(taskCtxLoop, h.tasks)
+		taskCtxLoop = mcp.WithParentExecutionTracker(taskCtxLoop, &mcp.ParentExecutionTracker{})
 		taskCtxLoop = multiagent.WithHITLToolInterceptor(taskCtxLoop, func(ctx context.Context, toolName, arguments string) (string, error) {
 			return h.interceptHITLForEinoTool(ctx, cancelWithCause, conversationID, assistantMessageID, sendEvent, toolName, arguments)
 		})
+		taskCtxLoop = multiagent.WithToolCallBudget(taskCtxLoop, toolBudget)
+		taskCtxLoop = multiagent.WithTokenBudget(taskCtxLoop, tokenBudget)
 
 		result, runErr = multiagent.RunEinoSingleChatModelAgent(
 			taskCtxLoop,
@@ -242,8 +261,9 @@ func (h *AgentHandler) EinoSingleAgentLoopStream(c *gin.Context) {
 			roleTools,
 			progressCallback,
 			chatReasoningToClientIntent(req.Reasoning),
-			h.agentSessionContextBlock(conversationID),
+			joinContextBlocks(h.agentSessionContextBlock(conversationID), knowledgeBlock),
 		)
+		idleStop()
 		_ = resolvedAIChannelID
 
 		if result != nil && len(result.MCPExecutionIDs) > 0 {
@@ -321,6 +341,56 @@ func (h *AgentHandler) EinoSingleAgentLoopStream(c *gin.Context) {
 			return
 		}
 
+		if errors.Is(cause, ErrTaskResourceLimit) {
+			taskStatus = "cancelled"
+			h.tasks.UpdateTaskStatus(conversationID, taskStatus)
+			limitMsg := "任务因系统资源限流被终止，请稍后重试或减少并发任务数。"
+			if assistantMessageID != "" {
+				if result != nil {
+					if err := h.mergeAssistantMessagePartialOnCancel(assistantMessageID, result.Response); err != nil {
+						h.logger.Warn("合并限流取消前的部分回复失败", zap.Error(err))
+					}
+				}
+				if err := h.appendAssistantMessageNotice(assistantMessageID, limitMsg); err != nil {
+					h.logger.Warn("更新限流取消后的助手消息失败", zap.Error(err))
+				}
+				_ = h.db.AddProcessDetail(assistantMessageID, conversationID, "cancelled", limitMsg, nil)
+			}
+			sendEvent("error", limitMsg, map[string]interface{}{
+				"conversationId": conversationID,
+				"messageId":      assistantMessageID,
+				"errorType":      "resource_limit",
+			})
+			sendEvent("done", "", map[string]interface{}{"conversationId": conversationID})
+			timeoutCancel()
+			return
+		}
+
+		if errors.Is(cause, ErrTaskIdleTimeout) {
+			taskStatus = "timeout"
+			h.tasks.UpdateTaskStatus(conversationID, taskStatus)
+			idleMsg := fmt.Sprintf("任务已连续 %d 分钟没有任何进度事件，已自动终止（agent.sse_idle_timeout_minutes）。", h.config.Agent.SSEIdleTimeoutMinutes)
+			if assistantMessageID != "" {
+				if result != nil {
+					if err := h.mergeAssistantMessagePartialOnCancel(assistantMessageID, result.Response); err != nil {
+						h.logger.Warn("合并空闲超时前的部分回复失败", zap.Error(err))
+					}
+				}
+				if err := h.appendAssistantMessageNotice(assistantMessageID, idleMsg); err != nil {
+					h.logger.Warn("更新空闲超时后的助手消息失败", zap.Error(err))
+				}
+				_ = h.db.AddProcessDetail(assistantMessageID, conversationID, "timeout", idleMsg, nil)
+			}
+			sendEvent("error", idleMsg, map[string]interface{}{
+				"conversationId": conversationID,
+				"messageId":      assistantMessageID,
+				"errorType":      "idle_timeout",
+			})
+			sendEvent("done", "", map[string]interface{}{"conversationId": conversationID})
+			timeoutCancel()
+			return
+		}
+
 		if errors.Is(runErr, context.DeadlineExceeded) || errors.Is(context.Cause(taskCtx), context.DeadlineExceeded) {
 			taskStatus = "timeout"
 			h.tasks.UpdateTaskStatus(conversationID, taskStatus)
@@ -424,9 +494,12 @@ func (h *AgentHandler) EinoSingleAgentLoop(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	taskCtx = multiagent.WithToolCallBudget(taskCtx, h.newEinoToolCallBudget(runCfg, prep.ConversationID, progressCallback))
+	taskCtx = multiagent.WithTokenBudget(taskCtx, h.newEinoTokenBudget(runCfg, req.Budget, prep.ConversationID, progressCallback))
 
 	curHist := prep.History
 	curMsg := prep.FinalMessage
+	knowledgeBlock := h.knowledgeAutoInjectBlock(taskCtx, req.Message)
 	var result *multiagent.RunResult
 	var runErr error
 	for {
@@ -444,7 +517,7 @@ func (h *AgentHandler) EinoSingleAgentLoop(c *gin.Context) {
 			prep.RoleTools,
 			progressCallback,
 			chatReasoningToClientIntent(req.Reasoning),
-			h.agentSessionContextBlock(prep.ConversationID),
+			joinContextBlocks(h.agentSessionContextBlock(prep.ConversationID), knowledgeBlock),
 		)
 		if runErr == nil {
 			break
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+
+	"cyberstrike-ai/internal/config"
+	"cyberstrike-ai/internal/multiagent"
+
+	"go.uber.org/zap"
+)
+
+// newEinoToolCallBudget 按 agent.max_tool_calls 构建本次任务的工具调用预算（0 表示只计数不限制）。
+// 首次超限时通过 emit 发一条说明事件（SSE 路径传 sendEvent，非流式路径传 progressCallback），
+// 之后的工具调用由预算中间件软拒，模型据此收尾。
+func (h *AgentHandler) newEinoToolCallBudget(cfg *config.Config, conversationID string, emit func(eventType, message string, data interface{})) *multiagent.ToolCallBudget {
+	limit := 0
+	if cfg != nil {
+		limit = cfg.Agent.MaxToolCalls
+	}
+	return multiagent.NewToolCallBudget(limit, func(used, limit int) {
+		if h.logger != nil {
+			h.logger.Warn("工具调用总数已达上限，停止执行新工具",
+				zap.String("conversationId", conversationID),
+				zap.Int("toolCallLimit", limit))
+		}
+		if emit == nil {
+			return
+		}
+		emit("progress", fmt.Sprintf("⚠️ 工具调用总数已达上限（%d 次），不再执行新工具，将基于已有结果给出结论。", limit), map[string]interface{}{
+			"conversationId": conversationID,
+			"errorType":      "tool_call_limit",
+			"toolCallLimit":  limit,
+		})
+	})
+}
+
+// newEinoTokenBudget 按 agent.max_task_tokens / max_task_cost 构建本次任务的成本预算，
+// ChatRequest.budget 中 >0 的字段覆盖配置；首次超限时通过 emit 发说明事件，循环由预算中间件中止。
+func (h *AgentHandler) newEinoTokenBudget(cfg *config.Config, override *ChatBudgetRequest, conversationID string, emit func(eventType, message string, data interface{})) *multiagent.TokenBudget {
+	limits := multiagent.TokenBudgetLimits{}
+	if cfg != nil {
+		limits.MaxTokens = cfg.Agent.MaxTaskTokens
+		limits.MaxCost = cfg.Agent.MaxTaskCost
+		limits.PromptPricePer1M = cfg.Agent.TokenPricePromptPer1M
+		limits.CompletionPricePer1M = cfg.Agent.TokenPriceCompletionPer1M
+	}
+	if override != nil {
+		if override.MaxTokens > 0 {
+			limits.MaxTokens = override.MaxTokens
+		}
+		if override.MaxCost > 0 {
+			limits.MaxCost = override.MaxCost
+		}
+	}
+	return multiagent.NewTokenBudget(limits, func(usage multiagent.TokenBudgetUsage) {
+		if h.logger != nil {
+			h.logger.Warn("任务累计消耗已达预算上限，中止执行",
+				zap.String("conversationId", conversationID),
+				zap.Int("totalTokens", usage.TotalTokens),
+				zap.Float64("cost", usage.Cost))
+		}
+		if emit == nil {
+			return
+		}
+		emit("progress", fmt.Sprintf("⚠️ 已达本次预算上限（累计 %d tokens，折算金额 %.4f），任务中止。", usage.TotalTokens, usage.Cost), map[string]interface{}{
+			"conversationId": conversationID,
+			"errorType":      "token_budget_exceeded",
+			"tokenUsage":     usage,
+		})
+	})
+}
@@ -95,6 +95,8 @@ func normalizeSpaceSearchProvider(provider string) string {
 		return "zoomeye"
 	case "quake":
 		return "quake"
+	case "hunter":
+		return "hunter"
 	case "shodan":
 		return "shodan"
 	default:
@@ -108,6 +110,8 @@ func providerDisplayName(provider string) string {
 		return "ZoomEye"
 	case "quake":
 		return "Quake"
+	case "hunter":
+		return "Hunter"
 	case "shodan":
 		return "Shodan"
 	default:
@@ -121,6 +125,8 @@ func defaultFieldsForProvider(provider string) string {
 		return "ip,port,domain,hostname,title,service,app,country,city"
 	case "quake":
 		return "ip,port,domain,service.name,service.http.title,location.country_cn,location.province_cn,location.city_cn"
+	case "hunter":
+		return "ip,port,domain,web_title,protocol,country,province,city,company"
 	case "shodan":
 		return "ip_str,port,hostnames,domains,org,isp,location.country_name,location.city,product,transport"
 	default:
@@ -135,6 +141,7 @@ func (h *FofaHandler) resolveAPIKey(provider string) string {
 		"fofa":    "FOFA_API_KEY",
 		"zoomeye": "ZOOMEYE_API_KEY",
 		"quake":   "QUAKE_API_KEY",
+		"hunter":  "HUNTER_API_KEY",
 		"shodan":  "SHODAN_API_KEY",
 	}[provider]
 	if apiKey := strings.TrimSpace(os.Getenv(envKey)); apiKey != "" {
@@ -146,6 +153,8 @@ func (h *FofaHandler) resolveAPIKey(provider string) string {
 			return strings.TrimSpace(h.cfg.ZoomEye.APIKey)
 		case "quake":
 			return strings.TrimSpace(h.cfg.Quake.APIKey)
+		case "hunter":
+			return strings.TrimSpace(h.cfg.Hunter.APIKey)
 		case "shodan":
 			return strings.TrimSpace(h.cfg.Shodan.APIKey)
 		default:
@@ -166,6 +175,10 @@ func (h *FofaHandler) resolveBaseURL(provider string) string {
 			if v := strings.TrimSpace(h.cfg.Quake.BaseURL); v != "" {
 				return v
 			}
+		case "hunter":
+			if v := strings.TrimSpace(h.cfg.Hunter.BaseURL); v != "" {
+				return v
+			}
 		case "shodan":
 			if v := strings.TrimSpace(h.cfg.Shodan.BaseURL); v != "" {
 				return v
@@ -181,6 +194,8 @@ func (h *FofaHandler) resolveBaseURL(provider string) string {
 		return "https://api.zoomeye.org/v2/search"
 	case "quake":
 		return "https://quake.360.cn/api/v3/search/quake_service"
+	case "hunter":
+		return "https://hunter.qianxin.com/openApi/search"
 	case "shodan":
 		return "https://api.shodan.io"
 	default:
@@ -198,7 +213,7 @@ func (h *FofaHandler) ParseNaturalLanguage(c *gin.Context) {
 	req.Text = strings.TrimSpace(req.Text)
 	provider := normalizeSpaceSearchProvider(req.Provider)
 	if provider == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "provider 不支持，可选：fofa、zoomeye、quake、shodan"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider 不支持，可选：fofa、zoomeye、quake、hunter、shodan"})
 		return
 	}
 	if req.Text == "" {
@@ -313,6 +328,36 @@ Quake 查询语法参考：
   - 用户说“中国/浙江/杭州”等中文地理位置时，Quake 优先使用 country_cn/province_cn/city_cn 并保留中文值。
   - 用户说“标题”映射为 service.http.title；说“Server/服务端软件”映射为 service.http.server；说“favicon/hash/icon”映射为 service.http.favicon.hash。
   - Quake 不使用 && / || 作为首选输出；优先输出 AND / OR / NOT。
+`,
+		"hunter": `
+鹰图 Hunter 查询语法参考：
+- 基本格式：field="value"，字符串值使用英文双引号；多个条件用 &&（与）、||（或）连接，排除使用 field!="value"。
+- 组合优先级：复杂表达式使用 () 明确优先级，例如：(web.title="登录" || web.title="login") && ip.country="中国"。
+- 常用字段：ip、ip.port、ip.country、ip.province、ip.city、domain、domain.suffix、web.title、web.body、header、header.server、protocol、icp.name、icp.number、cert、icon_hash、app。
+- 字段示例：
+  - ip="1.1.1.1"
+  - ip.port="443"
+  - ip.country="中国"
+  - ip.province="浙江"
+  - domain="example.com"
+  - domain.suffix="example.com"
+  - web.title="后台管理"
+  - web.body="Powered by"
+  - header.server="nginx"
+  - protocol="http"
+  - icp.name="某某公司"
+  - cert="example.com"
+  - icon_hash="-247388890"
+  - app="Apache"
+- 组合示例：
+  - web.title="登录" && ip.country="中国"
+  - domain.suffix="example.com" && protocol="http"
+  - (app="Apache" || app="nginx") && ip.port="443"
+  - icp.name="某某公司" && web.title!="404"
+- 生成注意：
+  - Hunter 地理位置使用中文值，例如 ip.country="中国"，不要翻译成国家代码。
+  - 用户说“标题”映射为 web.title；说“正文/页面包含”映射为 web.body；说“备案/ICP”映射为 icp.name 或 icp.number。
+  - 排除条件使用 field!="value"，不要使用 ! 前缀。
 `,
 		"shodan": `
 Shodan 官方查询语法参考：
@@ -546,9 +591,27 @@ func (h *FofaHandler) Search(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数: " + err.Error()})
 		return
 	}
+	h.serveAssetSearch(c, req)
+}
+
+// AssetSearch 统一的测绘查询入口：provider 从 query 参数指定（如 /api/asset-search?provider=quake），
+// 请求体与 /api/fofa/search 保持一致，便于前端用同一套 UI 对接多平台。
+func (h *FofaHandler) AssetSearch(c *gin.Context) {
+	var req fofaSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数: " + err.Error()})
+		return
+	}
+	if p := strings.TrimSpace(c.Query("provider")); p != "" {
+		req.Provider = p
+	}
+	h.serveAssetSearch(c, req)
+}
+
+func (h *FofaHandler) serveAssetSearch(c *gin.Context, req fofaSearchRequest) {
 	provider := normalizeSpaceSearchProvider(req.Provider)
 	if provider == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "provider 不支持，可选：fofa、zoomeye、quake、shodan"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider 不支持，可选：fofa、zoomeye、quake、hunter、shodan"})
 		return
 	}
 
@@ -557,51 +620,86 @@ func (h *FofaHandler) Search(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "query 不能为空"})
 		return
 	}
+	// 只有 FOFA 语法做本地预检；其他平台语法各异，直接透传
+	if provider == "fofa" {
+		if err := validateFofaQuery(req.Query); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	fofaCfg := config.FofaConfig{}
+	if h.cfg != nil {
+		fofaCfg = h.cfg.FOFA
+	}
 	if req.Size <= 0 {
-		req.Size = 100
+		req.Size = fofaCfg.DefaultSizeEffective()
 	}
 	if req.Page <= 0 {
 		req.Page = 1
 	}
-	// FOFA 接口 size 上限和账户权限相关，这里只做一个合理的保护
-	if req.Size > 10000 {
-		req.Size = 10000
+	// size 上限与账户等级相关：超过配置上限时明确提示，而不是静默截断后返回少于预期的结果
+	if maxSize := fofaCfg.MaxSizeEffective(); req.Size > maxSize {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("size 超过当前配置上限 %d（fofa.max_size，可按账户等级调整），请减小 size 或分页查询", maxSize),
+		})
+		return
 	}
 	if req.Fields == "" {
 		req.Fields = defaultFieldsForProvider(provider)
 	}
 
-	if provider != "fofa" {
-		h.searchExternalProvider(c, provider, req)
+	if h.resolveAPIKey(provider) == "" {
+		if provider == "fofa" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "FOFA 未配置：请在系统设置的资产管理中填写 FOFA API Key，或设置环境变量 FOFA_API_KEY",
+				"need":    []string{"fofa.api_key"},
+				"env_key": []string{"FOFA_API_KEY"},
+			})
+			return
+		}
+		envKey := map[string]string{
+			"zoomeye": "ZOOMEYE_API_KEY",
+			"quake":   "QUAKE_API_KEY",
+			"hunter":  "HUNTER_API_KEY",
+			"shodan":  "SHODAN_API_KEY",
+		}[provider]
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   providerDisplayName(provider) + " 未配置：请在 config.yaml 中填写 api_key，或设置环境变量 " + envKey,
+			"need":    []string{provider + ".api_key"},
+			"env_key": []string{envKey},
+		})
 		return
 	}
 
-	apiKey := h.resolveAPIKey(provider)
-	if apiKey == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "FOFA 未配置：请在系统设置的资产管理中填写 FOFA API Key，或设置环境变量 FOFA_API_KEY",
-			"need":    []string{"fofa.api_key"},
-			"env_key": []string{"FOFA_API_KEY"},
-		})
+	result, err := h.Provider(provider).Search(c.Request.Context(), req.Query, AssetSearchOptions{
+		Size:   req.Size,
+		Page:   req.Page,
+		Fields: req.Fields,
+		Full:   req.Full,
+	})
+	if err != nil {
+		c.JSON(providerErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, result)
+}
 
-	baseURL := h.resolveBaseURL(provider)
-	qb64 := base64.StdEncoding.EncodeToString([]byte(req.Query))
+func (h *FofaHandler) searchFofa(ctx context.Context, query string, opts AssetSearchOptions) (*fofaSearchResponse, error) {
+	baseURL := h.resolveBaseURL("fofa")
+	qb64 := base64.StdEncoding.EncodeToString([]byte(query))
 
 	u, err := url.Parse(baseURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "FOFA base_url 无效: " + err.Error()})
-		return
+		return nil, &providerHTTPError{status: http.StatusInternalServerError, message: "FOFA base_url 无效: " + err.Error()}
 	}
 
 	params := u.Query()
-	params.Set("key", apiKey)
+	params.Set("key", h.resolveAPIKey("fofa"))
 	params.Set("qbase64", qb64)
-	params.Set("size", fmt.Sprintf("%d", req.Size))
-	params.Set("page", fmt.Sprintf("%d", req.Page))
-	params.Set("fields", strings.TrimSpace(req.Fields))
-	if req.Full {
+	params.Set("size", fmt.Sprintf("%d", opts.Size))
+	params.Set("page", fmt.Sprintf("%d", opts.Page))
+	params.Set("fields", strings.TrimSpace(opts.Fields))
+	if opts.Full {
 		params.Set("full", "true")
 	} else {
 		// 明确传 false，便于排查
@@ -609,47 +707,19 @@ func (h *FofaHandler) Search(c *gin.Context) {
 	}
 	u.RawQuery = params.Encode()
 
-	httpReq, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, u.String(), nil)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建请求失败: " + err.Error()})
-		return
-	}
-	httpReq.Header.Set("User-Agent", "CyberStrikeAI/1.7.4")
-	httpReq.Header.Set("Accept", "application/json")
-
-	resp, err := h.client.Do(httpReq)
-	if err != nil {
-		status, message, timeout := safeFofaRequestError(err)
-		h.logger.Warn("请求 FOFA 失败",
-			zap.String("host", u.Host),
-			zap.Bool("timeout", timeout),
-			zap.String("error_type", fmt.Sprintf("%T", err)),
-		)
-		c.JSON(status, gin.H{"error": message})
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("FOFA 返回非 2xx: %d", resp.StatusCode)})
-		return
-	}
-
 	var apiResp fofaAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "解析 FOFA 响应失败: " + err.Error()})
-		return
+	if err := h.doProviderRequest(ctx, http.MethodGet, u.String(), "", "", nil, &apiResp, "FOFA"); err != nil {
+		return nil, err
 	}
 	if apiResp.Error {
 		msg := strings.TrimSpace(apiResp.ErrMsg)
 		if msg == "" {
 			msg = "FOFA 返回错误"
 		}
-		c.JSON(http.StatusBadGateway, gin.H{"error": msg})
-		return
+		return nil, &providerHTTPError{status: http.StatusBadGateway, message: msg}
 	}
 
-	fields := splitAndCleanCSV(req.Fields)
+	fields := splitAndCleanCSV(opts.Fields)
 	results := make([]map[string]interface{}, 0, len(apiResp.Results))
 	for _, row := range apiResp.Results {
 		item := make(map[string]interface{}, len(fields))
@@ -663,57 +733,30 @@ func (h *FofaHandler) Search(c *gin.Context) {
 		results = append(results, item)
 	}
 
-	c.JSON(http.StatusOK, fofaSearchResponse{
-		Provider:     provider,
-		Query:        req.Query,
+	return &fofaSearchResponse{
+		Provider:     "fofa",
+		Query:        query,
 		Size:         apiResp.Size,
 		Page:         apiResp.Page,
 		Total:        apiResp.Total,
 		Fields:       fields,
 		ResultsCount: len(results),
 		Results:      results,
-	})
+	}, nil
 }
 
-func (h *FofaHandler) searchExternalProvider(c *gin.Context, provider string, req fofaSearchRequest) {
-	apiKey := h.resolveAPIKey(provider)
-	if apiKey == "" {
-		envKey := map[string]string{
-			"zoomeye": "ZOOMEYE_API_KEY",
-			"quake":   "QUAKE_API_KEY",
-			"shodan":  "SHODAN_API_KEY",
-		}[provider]
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   providerDisplayName(provider) + " 未配置：请在 config.yaml 中填写 api_key，或设置环境变量 " + envKey,
-			"need":    []string{provider + ".api_key"},
-			"env_key": []string{envKey},
-		})
-		return
-	}
-
-	switch provider {
-	case "zoomeye":
-		h.searchZoomEye(c, req, apiKey)
-	case "quake":
-		h.searchQuake(c, req, apiKey)
-	case "shodan":
-		h.searchShodan(c, req, apiKey)
-	}
-}
-
-func (h *FofaHandler) searchZoomEye(c *gin.Context, req fofaSearchRequest, apiKey string) {
+func (h *FofaHandler) searchZoomEye(ctx context.Context, query string, opts AssetSearchOptions) (*fofaSearchResponse, error) {
 	baseURL := h.resolveBaseURL("zoomeye")
 	u, err := url.Parse(baseURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ZoomEye base_url 无效: " + err.Error()})
-		return
+		return nil, &providerHTTPError{status: http.StatusInternalServerError, message: "ZoomEye base_url 无效: " + err.Error()}
 	}
 	body := map[string]interface{}{
-		"qbase64":  base64.StdEncoding.EncodeToString([]byte(req.Query)),
-		"page":     req.Page,
-		"pagesize": req.Size,
+		"qbase64":  base64.StdEncoding.EncodeToString([]byte(query)),
+		"page":     opts.Page,
+		"pagesize": opts.Size,
 	}
-	if fields := strings.TrimSpace(req.Fields); fields != "" {
+	if fields := strings.TrimSpace(opts.Fields); fields != "" {
 		body["fields"] = fields
 	}
 	var apiResp struct {
@@ -725,43 +768,41 @@ func (h *FofaHandler) searchZoomEye(c *gin.Context, req fofaSearchRequest, apiKe
 		PageSize int                      `json:"pagesize"`
 		Data     []map[string]interface{} `json:"data"`
 	}
-	if !h.doJSONRequest(c, http.MethodPost, u.String(), apiKey, "API-KEY", body, &apiResp, "ZoomEye") {
-		return
+	if err := h.doProviderRequest(ctx, http.MethodPost, u.String(), h.resolveAPIKey("zoomeye"), "API-KEY", body, &apiResp, "ZoomEye"); err != nil {
+		return nil, err
 	}
 	if apiResp.Code != 60000 {
 		msg := strings.TrimSpace(apiResp.Message)
 		if msg == "" {
 			msg = "ZoomEye 返回错误"
 		}
-		c.JSON(http.StatusBadGateway, gin.H{"error": msg})
-		return
+		return nil, &providerHTTPError{status: http.StatusBadGateway, message: msg}
 	}
-	fields := splitAndCleanCSV(req.Fields)
-	c.JSON(http.StatusOK, fofaSearchResponse{
+	fields := splitAndCleanCSV(opts.Fields)
+	return &fofaSearchResponse{
 		Provider:     "zoomeye",
-		Query:        firstNonEmptySpaceSearchValue(apiResp.Query, req.Query),
-		Size:         firstPositive(apiResp.PageSize, req.Size),
-		Page:         firstPositive(apiResp.Page, req.Page),
+		Query:        firstNonEmptySpaceSearchValue(apiResp.Query, query),
+		Size:         firstPositive(apiResp.PageSize, opts.Size),
+		Page:         firstPositive(apiResp.Page, opts.Page),
 		Total:        apiResp.Total,
 		Fields:       fields,
 		ResultsCount: len(apiResp.Data),
 		Results:      projectRows(apiResp.Data, fields),
-	})
+	}, nil
 }
 
-func (h *FofaHandler) searchQuake(c *gin.Context, req fofaSearchRequest, apiKey string) {
+func (h *FofaHandler) searchQuake(ctx context.Context, query string, opts AssetSearchOptions) (*fofaSearchResponse, error) {
 	baseURL := h.resolveBaseURL("quake")
 	u, err := url.Parse(baseURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Quake base_url 无效: " + err.Error()})
-		return
+		return nil, &providerHTTPError{status: http.StatusInternalServerError, message: "Quake base_url 无效: " + err.Error()}
 	}
-	fields := splitAndCleanCSV(req.Fields)
+	fields := splitAndCleanCSV(opts.Fields)
 	body := map[string]interface{}{
-		"query":  req.Query,
-		"size":   req.Size,
-		"start":  (req.Page - 1) * req.Size,
-		"latest": req.Full,
+		"query":  query,
+		"size":   opts.Size,
+		"start":  (opts.Page - 1) * opts.Size,
+		"latest": opts.Full,
 	}
 	if len(fields) > 0 {
 		body["include"] = fields
@@ -777,36 +818,101 @@ func (h *FofaHandler) searchQuake(c *gin.Context, req fofaSearchRequest, apiKey
 			} `json:"pagination"`
 		} `json:"meta"`
 	}
-	if !h.doJSONRequest(c, http.MethodPost, u.String(), apiKey, "X-QuakeToken", body, &apiResp, "Quake") {
-		return
+	if err := h.doProviderRequest(ctx, http.MethodPost, u.String(), h.resolveAPIKey("quake"), "X-QuakeToken", body, &apiResp, "Quake"); err != nil {
+		return nil, err
 	}
 	if apiResp.Code != 0 {
 		msg := strings.TrimSpace(apiResp.Message)
 		if msg == "" {
 			msg = "Quake 返回错误"
 		}
-		c.JSON(http.StatusBadGateway, gin.H{"error": msg})
-		return
+		return nil, &providerHTTPError{status: http.StatusBadGateway, message: msg}
 	}
 	total := firstPositive(apiResp.TotalCount, apiResp.Meta.Pagination.Total)
-	c.JSON(http.StatusOK, fofaSearchResponse{
+	return &fofaSearchResponse{
 		Provider:     "quake",
-		Query:        req.Query,
-		Size:         req.Size,
-		Page:         req.Page,
+		Query:        query,
+		Size:         opts.Size,
+		Page:         opts.Page,
 		Total:        total,
 		Fields:       fields,
 		ResultsCount: len(apiResp.Data),
 		Results:      projectRows(apiResp.Data, fields),
-	})
+	}, nil
+}
+
+func (h *FofaHandler) searchHunter(ctx context.Context, query string, opts AssetSearchOptions) (*fofaSearchResponse, error) {
+	baseURL := h.resolveBaseURL("hunter")
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, &providerHTTPError{status: http.StatusInternalServerError, message: "Hunter base_url 无效: " + err.Error()}
+	}
+	// Hunter 单页最多 100 条，按需拉多页后在本地拼接
+	targetSize := opts.Size
+	if targetSize > 1000 {
+		targetSize = 1000
+	}
+	var apiResp struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
+			Total int                      `json:"total"`
+			Arr   []map[string]interface{} `json:"arr"`
+		} `json:"data"`
+	}
+	rows := make([]map[string]interface{}, 0, targetSize)
+	pageSize := targetSize
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	pagesNeeded := (targetSize + pageSize - 1) / pageSize
+	for i := 0; i < pagesNeeded; i++ {
+		pageURL := *u
+		params := pageURL.Query()
+		params.Set("api-key", h.resolveAPIKey("hunter"))
+		// Hunter 要求 search 参数使用 base64url 编码
+		params.Set("search", base64.URLEncoding.EncodeToString([]byte(query)))
+		params.Set("page", fmt.Sprintf("%d", opts.Page+i))
+		params.Set("page_size", fmt.Sprintf("%d", pageSize))
+		pageURL.RawQuery = params.Encode()
+		apiResp.Data.Arr = nil
+		if err := h.doProviderRequest(ctx, http.MethodGet, pageURL.String(), "", "", nil, &apiResp, "Hunter"); err != nil {
+			return nil, err
+		}
+		if apiResp.Code != 200 {
+			msg := strings.TrimSpace(apiResp.Message)
+			if msg == "" {
+				msg = "Hunter 返回错误"
+			}
+			return nil, &providerHTTPError{status: http.StatusBadGateway, message: msg}
+		}
+		if len(apiResp.Data.Arr) == 0 {
+			break
+		}
+		rows = append(rows, apiResp.Data.Arr...)
+		if len(rows) >= targetSize {
+			rows = rows[:targetSize]
+			break
+		}
+	}
+	fields := splitAndCleanCSV(opts.Fields)
+	return &fofaSearchResponse{
+		Provider:     "hunter",
+		Query:        query,
+		Size:         targetSize,
+		Page:         opts.Page,
+		Total:        apiResp.Data.Total,
+		Fields:       fields,
+		ResultsCount: len(rows),
+		Results:      projectRows(rows, fields),
+	}, nil
 }
 
-func (h *FofaHandler) searchShodan(c *gin.Context, req fofaSearchRequest, apiKey string) {
+func (h *FofaHandler) searchShodan(ctx context.Context, query string, opts AssetSearchOptions) (*fofaSearchResponse, error) {
 	baseURL := strings.TrimRight(h.resolveBaseURL("shodan"), "/") + "/shodan/host/search"
 	u, err := url.Parse(baseURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Shodan base_url 无效: " + err.Error()})
-		return
+		return nil, &providerHTTPError{status: http.StatusInternalServerError, message: "Shodan base_url 无效: " + err.Error()}
 	}
 
 	var apiResp struct {
@@ -814,35 +920,34 @@ func (h *FofaHandler) searchShodan(c *gin.Context, req fofaSearchRequest, apiKey
 		Matches []map[string]interface{} `json:"matches"`
 		Error   string                   `json:"error"`
 	}
-	targetSize := req.Size
+	targetSize := opts.Size
 	if targetSize <= 0 {
 		targetSize = 100
 	}
 	if targetSize > 1000 {
 		targetSize = 1000
 	}
-	page := req.Page
+	page := opts.Page
 	matches := make([]map[string]interface{}, 0, targetSize)
 	pagesNeeded := (targetSize + 99) / 100
 	for i := 0; i < pagesNeeded; i++ {
 		pageURL := *u
 		params := pageURL.Query()
-		params.Set("key", apiKey)
-		params.Set("query", req.Query)
+		params.Set("key", h.resolveAPIKey("shodan"))
+		params.Set("query", query)
 		params.Set("page", fmt.Sprintf("%d", page+i))
 		params.Set("minify", "false")
-		if fields := strings.TrimSpace(req.Fields); fields != "" {
+		if fields := strings.TrimSpace(opts.Fields); fields != "" {
 			params.Set("fields", fields)
 		}
 		pageURL.RawQuery = params.Encode()
 		apiResp.Matches = nil
 		apiResp.Error = ""
-		if !h.doJSONRequest(c, http.MethodGet, pageURL.String(), "", "", nil, &apiResp, "Shodan") {
-			return
+		if err := h.doProviderRequest(ctx, http.MethodGet, pageURL.String(), "", "", nil, &apiResp, "Shodan"); err != nil {
+			return nil, err
 		}
 		if strings.TrimSpace(apiResp.Error) != "" {
-			c.JSON(http.StatusBadGateway, gin.H{"error": apiResp.Error})
-			return
+			return nil, &providerHTTPError{status: http.StatusBadGateway, message: apiResp.Error}
 		}
 		if len(apiResp.Matches) == 0 {
 			break
@@ -853,16 +958,16 @@ func (h *FofaHandler) searchShodan(c *gin.Context, req fofaSearchRequest, apiKey
 			break
 		}
 	}
-	fields := splitAndCleanCSV(req.Fields)
+	fields := splitAndCleanCSV(opts.Fields)
 	expectedCount := shodanExpectedResultCount(apiResp.Total, page, targetSize)
 	shortfall := expectedCount - len(matches)
 	warning := ""
 	if shortfall > 0 {
 		warning = fmt.Sprintf("Shodan 统计总数为 %d，但本次分页实际只返回 %d/%d 条明细", apiResp.Total, len(matches), expectedCount)
 	}
-	c.JSON(http.StatusOK, fofaSearchResponse{
+	return &fofaSearchResponse{
 		Provider:      "shodan",
-		Query:         req.Query,
+		Query:         query,
 		Size:          targetSize,
 		Page:          page,
 		Total:         apiResp.Total,
@@ -872,7 +977,7 @@ func (h *FofaHandler) searchShodan(c *gin.Context, req fofaSearchRequest, apiKey
 		Shortfall:     max(0, shortfall),
 		Warning:       warning,
 		Results:       projectRows(matches, fields),
-	})
+	}, nil
 }
 
 func shodanExpectedResultCount(total, page, size int) int {
@@ -893,22 +998,20 @@ func shodanExpectedResultCount(total, page, size int) int {
 	return size
 }
 
-func (h *FofaHandler) doJSONRequest(c *gin.Context, method, endpoint, apiKey, headerName string, body interface{}, out interface{}, label string) bool {
+func (h *FofaHandler) doProviderRequest(ctx context.Context, method, endpoint, apiKey, headerName string, body interface{}, out interface{}, label string) error {
 	var reqBody *strings.Reader
 	if body != nil {
 		b, err := json.Marshal(body)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "创建请求失败: " + err.Error()})
-			return false
+			return &providerHTTPError{status: http.StatusInternalServerError, message: "创建请求失败: " + err.Error()}
 		}
 		reqBody = strings.NewReader(string(b))
 	} else {
 		reqBody = strings.NewReader("")
 	}
-	httpReq, err := http.NewRequestWithContext(c.Request.Context(), method, endpoint, reqBody)
+	httpReq, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建请求失败: " + err.Error()})
-		return false
+		return &providerHTTPError{status: http.StatusInternalServerError, message: "创建请求失败: " + err.Error()}
 	}
 	httpReq.Header.Set("User-Agent", "CyberStrikeAI/1.7.4")
 	httpReq.Header.Set("Accept", "application/json")
@@ -926,19 +1029,16 @@ func (h *FofaHandler) doJSONRequest(c *gin.Context, method, endpoint, apiKey, he
 			zap.Bool("timeout", timeout),
 			zap.String("error_type", fmt.Sprintf("%T", err)),
 		)
-		c.JSON(status, gin.H{"error": strings.Replace(message, "FOFA", label, 1)})
-		return false
+		return &providerHTTPError{status: status, message: strings.Replace(message, "FOFA", label, 1)}
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("%s 返回非 2xx: %d", label, resp.StatusCode)})
-		return false
+		return &providerHTTPError{status: http.StatusBadGateway, message: fmt.Sprintf("%s 返回非 2xx: %d", label, resp.StatusCode)}
 	}
 	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "解析 " + label + " 响应失败: " + err.Error()})
-		return false
+		return &providerHTTPError{status: http.StatusBadGateway, message: "解析 " + label + " 响应失败: " + err.Error()}
 	}
-	return true
+	return nil
 }
 
 func safeFofaRequestError(err error) (status int, message string, timeout bool) {
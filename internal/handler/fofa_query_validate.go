@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FOFA 查询语法本地预检：括号/引号配对、字段名白名单。
+// 明显写错的查询直接在本地拦截并给出具体位置，避免透传给 FOFA 只换来一句笼统的 errmsg。
+// 只做基础校验，不实现完整语法解析；拿不准的查询一律放行，由 FOFA 侧裁决。
+
+// fofaKnownFields FOFA 官方查询字段白名单（含常用 cert./tls. 子字段）。
+var fofaKnownFields = map[string]bool{
+	"ip": true, "port": true, "domain": true, "host": true, "title": true,
+	"header": true, "body": true, "server": true, "protocol": true, "banner": true,
+	"country": true, "region": true, "city": true, "org": true, "asn": true,
+	"app": true, "product": true, "category": true, "type": true, "os": true,
+	"icp": true, "icon_hash": true, "fid": true, "sdk_hash": true, "js_name": true,
+	"js_md5": true, "cname": true, "cname_domain": true, "status_code": true,
+	"base_protocol": true, "cloud_name": true, "after": true, "before": true,
+	"is_domain": true, "is_ipv6": true, "is_fraud": true, "is_honeypot": true,
+	"is_cloud": true, "port_size": true, "port_size_gt": true, "port_size_lt": true,
+	"cert": true, "cert.subject": true, "cert.issuer": true, "cert.is_valid": true,
+	"cert.is_match": true, "cert.is_expired": true, "cert.subject.org": true,
+	"cert.subject.cn": true, "cert.issuer.org": true, "cert.issuer.cn": true,
+	"cert.sn": true, "cert.domain": true, "tls.version": true, "tls.ja3s": true,
+	"body_hash": true, "header_hash": true,
+}
+
+// validateFofaQuery 对 FOFA 查询做基础语法校验；发现明显错误时返回带位置（第 N 个字符，
+// 按 rune 计）的错误，查询看起来合法或无法判断时返回 nil。
+func validateFofaQuery(query string) error {
+	runes := []rune(query)
+
+	inQuote := false
+	quoteStart := 0
+	var parenStack []int
+	fieldStart := -1 // 当前正在累积的字段名起始位置；-1 表示不在字段名中
+
+	checkField := func(end int) error {
+		if fieldStart < 0 {
+			return nil
+		}
+		start := fieldStart
+		fieldStart = -1
+		field := strings.ToLower(string(runes[start:end]))
+		// 运算符 != / *= 的前缀符号不属于字段名
+		field = strings.TrimSuffix(strings.TrimSuffix(field, "!"), "*")
+		if field == "" || fofaKnownFields[field] {
+			return nil
+		}
+		return fmt.Errorf("FOFA 语法错误：第 %d 个字符处的字段名 %q 不存在，常用字段如 ip、port、domain、title、body、app", start+1, field)
+	}
+
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		if inQuote {
+			switch ch {
+			case '\\':
+				i++ // 跳过转义字符
+			case '"':
+				inQuote = false
+			}
+			continue
+		}
+		switch {
+		case ch == '"':
+			inQuote = true
+			quoteStart = i
+			fieldStart = -1
+		case ch == '(':
+			parenStack = append(parenStack, i)
+			fieldStart = -1
+		case ch == ')':
+			if len(parenStack) == 0 {
+				return fmt.Errorf("FOFA 语法错误：第 %d 个字符处的右括号没有匹配的左括号", i+1)
+			}
+			parenStack = parenStack[:len(parenStack)-1]
+			fieldStart = -1
+		case ch == '=':
+			// 字段名在 = 前结束（= / != / *= 均会走到这里）
+			if err := checkField(i); err != nil {
+				return err
+			}
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9', ch == '_', ch == '.':
+			if fieldStart < 0 {
+				fieldStart = i
+			}
+		case ch == '!', ch == '*':
+			// != / *= 的前半个符号，字段名继续保持到 = 为止
+		default:
+			fieldStart = -1
+		}
+	}
+
+	if inQuote {
+		return fmt.Errorf("FOFA 语法错误：第 %d 个字符处的引号没有闭合", quoteStart+1)
+	}
+	if len(parenStack) > 0 {
+		return fmt.Errorf("FOFA 语法错误：第 %d 个字符处的左括号没有闭合", parenStack[0]+1)
+	}
+	return nil
+}
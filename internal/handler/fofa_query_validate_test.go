@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cyberstrike-ai/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestValidateFofaQuery(t *testing.T) {
+	valid := []string{
+		`domain="example.com"`,
+		`title="admin" && port=8080`,
+		`(body="login" || title="登录") && country="CN"`,
+		`app="nginx" && status_code!="404"`,
+		`cert.subject="Example Inc" && tls.version="TLSv1.3"`,
+		`host*=".gov.cn"`,
+		`body="a \" quoted value"`,
+		`icon_hash=-1588080585`,
+		// 纯关键字查询（无字段）不做字段校验
+		`"Apache Tomcat"`,
+	}
+	for _, q := range valid {
+		if err := validateFofaQuery(q); err != nil {
+			t.Errorf("validateFofaQuery(%q) = %v, want nil", q, err)
+		}
+	}
+
+	invalid := []struct {
+		query    string
+		wantHint string
+	}{
+		{`title="admin`, "引号没有闭合"},
+		{`(title="a" && port=80`, "左括号没有闭合"},
+		{`title="a") && port=80`, "右括号没有匹配"},
+		{`titel="admin"`, `"titel"`},
+		{`domian="example.com" && port=80`, `"domian"`},
+	}
+	for _, tc := range invalid {
+		err := validateFofaQuery(tc.query)
+		if err == nil {
+			t.Errorf("validateFofaQuery(%q) = nil, want error containing %q", tc.query, tc.wantHint)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.wantHint) {
+			t.Errorf("validateFofaQuery(%q) = %v, want error containing %q", tc.query, err, tc.wantHint)
+		}
+	}
+}
+
+func TestFofaSearchRejectsInvalidQuerySyntax(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewFofaHandler(&config.Config{
+		FOFA: config.FofaConfig{APIKey: "test-api-key"},
+	}, zap.NewNop())
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	body := `{"query":"titel=\"admin\""}`
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/api/fofa/search", strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	h.Search(ctx)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("Search() status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+	if !strings.Contains(recorder.Body.String(), "titel") {
+		t.Fatalf("error should point at the bad field, body = %s", recorder.Body.String())
+	}
+}
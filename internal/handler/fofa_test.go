@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -65,6 +66,33 @@ func TestFofaSearchUsesAPIKeyWithoutEmail(t *testing.T) {
 	}
 }
 
+func TestFofaSearchRejectsSizeOverConfiguredMax(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("FOFA_API_KEY", "")
+
+	h := NewFofaHandler(&config.Config{
+		FOFA: config.FofaConfig{
+			APIKey:  "test-api-key",
+			MaxSize: 100,
+		},
+	}, zap.NewNop())
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	body := `{"query":"domain=\"example.com\"","size":500}`
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/api/fofa/search", strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	h.Search(ctx)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("Search() status = %d, want %d, body = %s", recorder.Code, http.StatusBadRequest, recorder.Body.String())
+	}
+	if !strings.Contains(recorder.Body.String(), "100") {
+		t.Fatalf("error should mention configured max size, body = %s", recorder.Body.String())
+	}
+}
+
 func TestSafeFofaRequestErrorDoesNotExposeURLOrAPIKey(t *testing.T) {
 	const secretURL = "https://fofa.info/api/v1/search/all?key=secret-api-key"
 	err := &url.Error{
@@ -155,6 +183,104 @@ func TestShodanSearchReportsShortfallWhenTotalExceedsMatches(t *testing.T) {
 	}
 }
 
+func TestAssetSearchDispatchesByProviderQueryParam(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("FOFA_API_KEY", "")
+
+	fofaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"error":false,"size":1,"page":1,"total":1,"results":[["https://example.com"]]}`))
+	}))
+	defer fofaServer.Close()
+
+	h := NewFofaHandler(&config.Config{
+		FOFA: config.FofaConfig{
+			BaseURL: fofaServer.URL,
+			APIKey:  "test-api-key",
+		},
+	}, zap.NewNop())
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	body := `{"query":"domain=\"example.com\"","fields":"host"}`
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/api/asset-search?provider=fofa", strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	h.AssetSearch(ctx)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("AssetSearch() status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+	var response fofaSearchResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Provider != "fofa" || response.ResultsCount != 1 {
+		t.Fatalf("provider=%s results_count=%d, want fofa/1", response.Provider, response.ResultsCount)
+	}
+}
+
+func TestHunterSearchNormalizesResults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("HUNTER_API_KEY", "")
+
+	hunterServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("api-key"); got != "test-hunter-key" {
+			t.Fatalf("Hunter api-key = %q, want test-hunter-key", got)
+		}
+		search := r.URL.Query().Get("search")
+		decoded, err := base64.URLEncoding.DecodeString(search)
+		if err != nil {
+			t.Fatalf("search param is not base64url: %v", err)
+		}
+		if string(decoded) != `web.title="登录"` {
+			t.Fatalf("decoded search = %q", decoded)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"code":    200,
+			"message": "success",
+			"data": map[string]interface{}{
+				"total": 2,
+				"arr": []map[string]interface{}{
+					{"ip": "192.0.2.1", "port": 443, "web_title": "登录"},
+					{"ip": "192.0.2.2", "port": 80, "web_title": "登录"},
+				},
+			},
+		})
+	}))
+	defer hunterServer.Close()
+
+	h := NewFofaHandler(&config.Config{
+		Hunter: config.SpaceSearchConfig{
+			BaseURL: hunterServer.URL,
+			APIKey:  "test-hunter-key",
+		},
+	}, zap.NewNop())
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	body := `{"provider":"hunter","query":"web.title=\"登录\"","fields":"ip,port,web_title","size":10}`
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/api/fofa/search", strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	h.Search(ctx)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Search() status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+	var response fofaSearchResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Provider != "hunter" || response.Total != 2 || response.ResultsCount != 2 {
+		t.Fatalf("provider=%s total=%d results_count=%d, want hunter/2/2", response.Provider, response.Total, response.ResultsCount)
+	}
+	if response.Results[0]["ip"] != "192.0.2.1" {
+		t.Fatalf("results[0].ip = %v", response.Results[0]["ip"])
+	}
+}
+
 func TestExtractInfoCollectJSONObject(t *testing.T) {
 	t.Parallel()
 	cases := []struct {
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"cyberstrike-ai/internal/database"
+	openaiClient "cyberstrike-ai/internal/openai"
+
+	"go.uber.org/zap"
+)
+
+// 会话历史自动归档（agent.history_archive，默认关闭）：
+// 未归档消息数超过阈值时，在后台把较早的历史用 LLM 总结成一条「历史摘要」system 消息
+// 并标记原消息为已归档，GetMessages 之后只返回摘要+最近消息，控制长会话构建开销。
+// 归档幂等：重复触发时把新内容并入已有摘要，每个会话始终只有一条摘要消息；
+// 总结失败只告警，下次超阈值时重试，不影响当前任务。
+
+const (
+	// historyArchiveTranscriptMsgMaxRunes 单条消息进入总结转写的最大长度
+	historyArchiveTranscriptMsgMaxRunes = 500
+	// historyArchiveTranscriptMaxRunes 总结转写的总长度上限，超出部分丢弃更早的消息
+	historyArchiveTranscriptMaxRunes = 16000
+)
+
+// historyArchiveInFlight 防止同一会话并发归档（key 为 conversationID）。
+var historyArchiveInFlight sync.Map
+
+// maybeArchiveConversationHistory 在构建历史后检查是否需要归档；满足条件时起后台协程执行，
+// 不阻塞当前请求。activeMessages 为本次 GetMessages 返回的未归档消息数。
+func (h *AgentHandler) maybeArchiveConversationHistory(conversationID string, activeMessages int) {
+	if h.config == nil || !h.config.Agent.HistoryArchive.Enabled {
+		return
+	}
+	cfg := h.config.Agent.HistoryArchive
+	if activeMessages <= cfg.ThresholdMessagesEffective() {
+		return
+	}
+	if _, loaded := historyArchiveInFlight.LoadOrStore(conversationID, struct{}{}); loaded {
+		return
+	}
+	go func() {
+		defer historyArchiveInFlight.Delete(conversationID)
+		if err := h.archiveConversationHistory(conversationID, cfg.KeepRecentMessagesEffective()); err != nil {
+			h.logger.Warn("会话历史归档失败，等待下次触发重试",
+				zap.String("conversationId", conversationID),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// archiveConversationHistory 总结并归档较早的历史消息。
+func (h *AgentHandler) archiveConversationHistory(conversationID string, keepRecent int) error {
+	archivable, prevSummary, err := h.db.ListArchivableMessages(conversationID, keepRecent)
+	if err != nil {
+		return err
+	}
+	if len(archivable) == 0 {
+		return nil
+	}
+
+	summary, err := h.summarizeArchivedHistory(prevSummary, archivable)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(archivable))
+	for i := range archivable {
+		ids = append(ids, archivable[i].ID)
+	}
+	if err := h.db.ArchiveMessagesWithSummary(conversationID, ids, summary); err != nil {
+		return err
+	}
+	h.logger.Info("会话历史已归档",
+		zap.String("conversationId", conversationID),
+		zap.Int("archivedMessages", len(ids)),
+	)
+	return nil
+}
+
+// summarizeArchivedHistory 调用 LLM 把待归档消息（连同已有摘要）合并总结成新的摘要正文。
+func (h *AgentHandler) summarizeArchivedHistory(prevSummary string, messages []database.Message) (string, error) {
+	if h.config == nil || strings.TrimSpace(h.config.OpenAI.APIKey) == "" || strings.TrimSpace(h.config.OpenAI.Model) == "" {
+		return "", fmt.Errorf("openai 配置不完整，无法总结历史")
+	}
+	client := openaiClient.NewClient(&h.config.OpenAI, &http.Client{Timeout: 2 * time.Minute}, h.logger)
+
+	systemPrompt := strings.TrimSpace(`
+你是渗透测试会话的历史归档器。把给出的较早对话历史压缩成一份摘要，供后续对话作为上下文使用。
+
+输出要求：
+1) 只输出摘要正文，不要任何前言、标题或解释
+2) 重点保留：测试目标与范围、已确认的发现（漏洞、凭据、资产信息）、已执行的关键操作及其结论、未完成的事项
+3) 丢弃寒暄、重复内容和无结论的中间过程
+4) 如提供了「已有摘要」，把新内容并入其中输出一份完整摘要，不要丢失已有信息
+5) 控制在 800 字以内
+`)
+
+	var b strings.Builder
+	if prev := strings.TrimSpace(prevSummary); prev != "" {
+		b.WriteString("已有摘要：\n" + prev + "\n\n")
+	}
+	b.WriteString("待归档的对话历史：\n")
+	b.WriteString(buildHistoryArchiveTranscript(messages))
+
+	requestBody := map[string]interface{}{
+		"model": h.config.OpenAI.Model,
+		"messages": []map[string]interface{}{
+			{"role": "system", "content": systemPrompt},
+			{"role": "user", "content": b.String()},
+		},
+		"temperature":           0.1,
+		"max_completion_tokens": 2000,
+	}
+
+	var apiResponse struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+	defer cancel()
+	if err := client.ChatCompletion(ctx, requestBody, &apiResponse); err != nil {
+		return "", err
+	}
+	if len(apiResponse.Choices) == 0 {
+		return "", fmt.Errorf("历史总结未返回内容")
+	}
+	summary := strings.TrimSpace(apiResponse.Choices[0].Message.Content)
+	if summary == "" {
+		return "", fmt.Errorf("历史总结内容为空")
+	}
+	return summary, nil
+}
+
+// buildHistoryArchiveTranscript 把待归档消息拼成有长度上限的转写文本；
+// 超出总上限时优先丢弃更早的消息（保留靠后的部分）。
+func buildHistoryArchiveTranscript(messages []database.Message) string {
+	lines := make([]string, 0, len(messages))
+	total := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		content := strings.TrimSpace(messages[i].Content)
+		if content == "" {
+			continue
+		}
+		if runes := []rune(content); len(runes) > historyArchiveTranscriptMsgMaxRunes {
+			content = string(runes[:historyArchiveTranscriptMsgMaxRunes]) + "…（截断）"
+		}
+		line := fmt.Sprintf("[%s] %s", messages[i].Role, content)
+		total += len([]rune(line))
+		if total > historyArchiveTranscriptMaxRunes {
+			lines = append(lines, "（更早的历史已省略）")
+			break
+		}
+		lines = append(lines, line)
+	}
+	// 上面按从新到旧收集，这里翻转回时间顺序
+	for l, r := 0, len(lines)-1; l < r; l, r = l+1, r-1 {
+		lines[l], lines[r] = lines[r], lines[l]
+	}
+	return strings.Join(lines, "\n")
+}
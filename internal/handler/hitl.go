@@ -52,6 +52,9 @@ type HITLManager struct {
 	pending map[string]*pendingInterrupt
 	// approvedExec 审批通过、待回写 tool_result 的队列（按会话 FIFO）
 	approvedExec map[string][]hitlApprovedExecTrack
+	// requireApprovalFn 查询工具配置的 require_approval 标记（ToolConfig），
+	// 命中时即使会话未开启人机协同也强制审批。
+	requireApprovalFn func(toolName string) bool
 }
 
 func NewHITLManager(db *database.DB, logger *zap.Logger) *HITLManager {
@@ -209,10 +212,27 @@ func (h *AgentHandler) hitlRequestWithMergedConfigWhitelist(req *HITLRequest) *H
 	return &out
 }
 
+// SetRequireApprovalLookup 注入 ToolConfig require_approval 查询（读取当前配置，tools 目录热更新后即时生效）。
+func (m *HITLManager) SetRequireApprovalLookup(fn func(toolName string) bool) {
+	m.mu.Lock()
+	m.requireApprovalFn = fn
+	m.mu.Unlock()
+}
+
 func (m *HITLManager) shouldInterrupt(conversationID, toolName string) (hitlRuntimeConfig, bool) {
 	m.mu.RLock()
 	cfg, ok := m.runtime[conversationID]
+	requireFn := m.requireApprovalFn
 	m.mu.RUnlock()
+	// ToolConfig require_approval：配置层把该工具标记为高危，即使会话未开启人机协同
+	// 也强制审批，且不受免审批白名单影响。
+	if requireFn != nil && requireFn(toolName) {
+		if !ok || !cfg.Enabled {
+			// 无会话级配置时按默认审批模式走人工审批，不设超时（等待裁决）
+			return hitlRuntimeConfig{Enabled: true, Mode: "approval", Reviewer: "human"}, true
+		}
+		return cfg, true
+	}
 	if !ok || !cfg.Enabled {
 		return hitlRuntimeConfig{}, false
 	}
@@ -77,8 +77,8 @@ func (h *AgentHandler) auditAgentReview(ctx context.Context, hitlMode, toolName
 	dec, err := parseAuditAgentLLMContent(raw)
 	if err != nil {
 		snippet := raw
-		if len(snippet) > 240 {
-			snippet = snippet[:240] + "..."
+		if s := safeTruncateString(snippet, 240); s != snippet {
+			snippet = s + "..."
 		}
 		h.logger.Warn("审计 Agent 响应解析失败",
 			zap.Error(err),
@@ -243,6 +243,8 @@ func (h *KnowledgeHandler) CreateItem(c *gin.Context) {
 		Category string `json:"category" binding:"required"`
 		Title    string `json:"title" binding:"required"`
 		Content  string `json:"content" binding:"required"`
+		// Force 为 true 时跳过入库查重仍然导入（用户确认疑似重复后重试）
+		Force bool `json:"force"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -250,6 +252,28 @@ func (h *KnowledgeHandler) CreateItem(c *gin.Context) {
 		return
 	}
 
+	// 入库前近似查重（阈值 indexing.dedup_similarity_threshold，0 关闭）：
+	// 疑似重复返回 409 与命中来源，由用户选择跳过或带 force=true 强制导入。查重失败不阻断导入。
+	if !req.Force && h.indexer != nil {
+		if threshold := h.indexer.DedupThreshold(); threshold > 0 {
+			dedupCtx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+			candidates, dedupErr := h.indexer.FindSimilarItems(dedupCtx, req.Category, req.Title, req.Content, threshold, 3)
+			cancel()
+			if dedupErr != nil {
+				h.logger.Warn("知识项入库查重失败，继续导入", zap.Error(dedupErr))
+			} else if len(candidates) > 0 {
+				top := candidates[0]
+				c.JSON(http.StatusConflict, gin.H{
+					"duplicate":  true,
+					"message":    fmt.Sprintf("疑似重复，来源 %s（相似度 %.2f）。确认仍要导入请带 force=true 重试。", top.Title, top.Similarity),
+					"candidates": candidates,
+					"threshold":  threshold,
+				})
+				return
+			}
+		}
+	}
+
 	item, err := h.manager.CreateItem(req.Category, req.Title, req.Content)
 	if err != nil {
 		h.logger.Error("创建知识项失败", zap.Error(err))
@@ -301,6 +325,27 @@ func (h *KnowledgeHandler) UpdateItem(c *gin.Context) {
 	c.JSON(http.StatusOK, item)
 }
 
+// SetItemBoost 设置知识项检索加权因子（只改元数据，不触发重新索引）
+func (h *KnowledgeHandler) SetItemBoost(c *gin.Context) {
+	id := c.Param("id")
+
+	var req struct {
+		Boost float64 `json:"boost" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.manager.SetItemBoost(id, req.Boost); err != nil {
+		h.logger.Error("更新知识项 boost 失败", zap.String("itemId", id), zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "boost 已更新", "id": id, "boost": req.Boost})
+}
+
 // DeleteItem 删除知识项
 func (h *KnowledgeHandler) DeleteItem(c *gin.Context) {
 	id := c.Param("id")
@@ -334,6 +379,9 @@ func (h *KnowledgeHandler) StartIndex(c *gin.Context) {
 		return
 	}
 
+	// namespace 仅对 mode=full 生效：只重建该命名空间的知识项索引
+	namespace := strings.TrimSpace(c.Query("namespace"))
+
 	fullRebuild := mode == "full"
 	message := "索引构建已开始，将在后台进行"
 	auditAction := "index_build"
@@ -342,6 +390,10 @@ func (h *KnowledgeHandler) StartIndex(c *gin.Context) {
 		message = "全量索引重建已开始，将在后台进行"
 		auditAction = "index_rebuild_full"
 		auditDetail = "全量重建知识库索引"
+		if namespace != "" {
+			message = fmt.Sprintf("命名空间 %s 的索引重建已开始，将在后台进行", namespace)
+			auditDetail = "重建命名空间知识库索引：" + namespace
+		}
 	}
 
 	go func() {
@@ -349,7 +401,7 @@ func (h *KnowledgeHandler) StartIndex(c *gin.Context) {
 		ctx := context.Background()
 		var err error
 		if fullRebuild {
-			err = h.indexer.RunRebuildIndex(ctx)
+			err = h.indexer.RunRebuildIndexNamespace(ctx, namespace)
 		} else {
 			err = h.indexer.RunIndexMissing(ctx)
 		}
@@ -368,6 +420,63 @@ func (h *KnowledgeHandler) StartIndex(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": message, "mode": mode})
 }
 
+// GetFailedChunks 查询嵌入失败的知识块（knowledge_failed_chunks 登记表）
+func (h *KnowledgeHandler) GetFailedChunks(c *gin.Context) {
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := parseInt(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	chunks, err := h.indexer.ListFailedChunks(limit)
+	if err != nil {
+		h.logger.Error("查询失败块失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	total, err := h.indexer.CountFailedChunks()
+	if err != nil {
+		total = len(chunks)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"failed_chunks": chunks, "total": total})
+}
+
+// RetryFailedChunks 只重试嵌入失败的知识块（占用索引任务槽位，后台执行）
+func (h *KnowledgeHandler) RetryFailedChunks(c *gin.Context) {
+	total, err := h.indexer.CountFailedChunks()
+	if err != nil {
+		h.logger.Error("统计失败块失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if total == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "没有待重试的失败块", "failed_chunks": 0})
+		return
+	}
+
+	if err := h.indexer.TryBeginIndexRun(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "已有索引任务正在进行，请等待完成"})
+		return
+	}
+
+	go func() {
+		defer h.indexer.FinishIndexRun()
+		if _, _, err := h.indexer.RetryFailedChunks(context.Background()); err != nil {
+			h.logger.Error("重试失败块失败", zap.Error(err))
+		}
+	}()
+
+	if h.audit != nil {
+		h.audit.RecordOK(c, "knowledge", "retry_failed_chunks", "重试嵌入失败的知识块", "knowledge", "", nil)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":       fmt.Sprintf("开始重试 %d 个失败块，将在后台进行", total),
+		"failed_chunks": total,
+	})
+}
+
 // ScanKnowledgeBase 扫描知识库
 func (h *KnowledgeHandler) ScanKnowledgeBase(c *gin.Context) {
 	itemsToIndex, err := h.manager.ScanKnowledgeBase()
@@ -497,6 +606,11 @@ func (h *KnowledgeHandler) GetIndexStatus(c *gin.Context) {
 			}
 		}
 
+		// 有待重试的失败块时提示索引不完整
+		if failedChunks, err := h.indexer.CountFailedChunks(); err == nil && failedChunks > 0 {
+			status["failed_chunks"] = failedChunks
+		}
+
 		// 获取重建索引状态
 		isRebuilding, totalItems, current, failed, lastItemID, lastChunks, startTime := h.indexer.GetRebuildStatus()
 		if isRebuilding {
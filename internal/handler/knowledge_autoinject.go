@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cyberstrike-ai/internal/knowledge"
+
+	"go.uber.org/zap"
+)
+
+// knowledgeAutoInjectTimeout 自动注入检索的耗时上限，避免拖慢首 token。
+const knowledgeAutoInjectTimeout = 30 * time.Second
+
+// SetKnowledgeRetriever 设置知识库检索器（用于 RAG 自动前置注入）。
+func (h *AgentHandler) SetKnowledgeRetriever(r *knowledge.Retriever) {
+	h.knowledgeRetriever = r
+}
+
+// joinContextBlocks 拼接 system prompt 追加块，跳过空块。
+func joinContextBlocks(blocks ...string) string {
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		if b = strings.TrimSpace(b); b != "" {
+			parts = append(parts, b)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// knowledgeAutoInjectBlock 在 knowledge.retrieval.auto_inject 开启时，用用户消息对知识库
+// 做一次自动检索，将 TopK 结果格式化为 system prompt 追加块（无需模型主动调用
+// search_knowledge_base）。未开启、检索失败或无命中时返回空串。
+func (h *AgentHandler) knowledgeAutoInjectBlock(ctx context.Context, userMessage string) string {
+	if h == nil || h.config == nil || h.knowledgeRetriever == nil {
+		return ""
+	}
+	if !h.config.Knowledge.Enabled || !h.config.Knowledge.Retrieval.AutoInject {
+		return ""
+	}
+	query := strings.TrimSpace(userMessage)
+	if query == "" {
+		return ""
+	}
+
+	searchCtx, cancel := context.WithTimeout(ctx, knowledgeAutoInjectTimeout)
+	defer cancel()
+
+	results, err := h.knowledgeRetriever.Search(searchCtx, &knowledge.SearchRequest{
+		Query: query,
+		TopK:  h.config.Knowledge.Retrieval.AutoInjectTopKEffective(),
+	})
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Warn("知识库自动注入检索失败", zap.Error(err))
+		}
+		return ""
+	}
+	if len(results) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("## 知识库参考（自动检索）\n\n")
+	b.WriteString("以下片段由系统根据用户消息自动从知识库检索，供参考；与任务无关时可忽略，无需再调用 search_knowledge_base 重复检索相同内容：\n")
+	for i, res := range results {
+		if res == nil || res.Chunk == nil || res.Item == nil {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\n### 参考 %d：[%s] %s（相似度 %.0f%%）\n%s\n",
+			i+1, res.Item.Category, res.Item.Title, res.Similarity*100, strings.TrimSpace(res.Chunk.ChunkText)))
+	}
+	return strings.TrimSpace(b.String())
+}
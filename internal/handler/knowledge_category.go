@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetCategoryStats 获取所有分类及条目数（不含条目明细）
+func (h *KnowledgeHandler) GetCategoryStats(c *gin.Context) {
+	stats, err := h.manager.GetCategoryStats()
+	if err != nil {
+		h.logger.Error("获取分类统计失败", zap.Error(err))
+		RespondError(c, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"categories": stats,
+		"total":      len(stats),
+	})
+}
+
+// RenameCategory 重命名分类（批量更新该分类下的知识项并移动磁盘文件）
+func (h *KnowledgeHandler) RenameCategory(c *gin.Context) {
+	var req struct {
+		OldName string `json:"oldName" binding:"required"`
+		NewName string `json:"newName" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	updated, err := h.manager.RenameCategory(req.OldName, req.NewName)
+	if err != nil {
+		h.logger.Warn("重命名分类失败", zap.String("oldName", req.OldName), zap.String("newName", req.NewName), zap.Error(err))
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	if h.audit != nil {
+		h.audit.RecordOK(c, "knowledge", "category_rename", "重命名知识库分类", "knowledge_category", req.OldName, map[string]interface{}{
+			"newName": req.NewName,
+			"updated": updated,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "重命名成功",
+		"updated": updated,
+	})
+}
+
+// MergeCategories 合并分类：把 source 分类的全部知识项并入 target
+func (h *KnowledgeHandler) MergeCategories(c *gin.Context) {
+	var req struct {
+		Source string `json:"source" binding:"required"`
+		Target string `json:"target" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	moved, err := h.manager.MergeCategories(req.Source, req.Target)
+	if err != nil {
+		h.logger.Warn("合并分类失败", zap.String("source", req.Source), zap.String("target", req.Target), zap.Error(err))
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	if h.audit != nil {
+		h.audit.RecordOK(c, "knowledge", "category_merge", "合并知识库分类", "knowledge_category", req.Source, map[string]interface{}{
+			"target": req.Target,
+			"moved":  moved,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "合并成功",
+		"moved":   moved,
+	})
+}
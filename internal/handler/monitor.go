@@ -119,13 +119,21 @@ func (h *MonitorHandler) Monitor(c *gin.Context) {
 	status := c.Query("status")
 	// 解析工具筛选参数（兼容 mcp__tool 与内部 mcp::tool）
 	toolName := normalizeToolNameFilter(c.Query("tool"))
+	// 解析会话筛选参数（单会话/单项目视图，兼容两种命名）
+	conversationID := strings.TrimSpace(c.Query("conversationId"))
+	if conversationID == "" {
+		conversationID = strings.TrimSpace(c.Query("conversation_id"))
+	}
 
 	access := notificationAccessFromContext(c)
-	executions, total := h.loadExecutionListWithPagination(page, pageSize, status, toolName, access)
+	executions, total := h.loadExecutionListWithPagination(page, pageSize, status, toolName, conversationID, access)
 	h.enrichExecutionsConversationID(executions)
 	var summary *MonitorStatsSummary
 	var topTools []*mcp.ToolStats
-	if access.Scope == database.RBACScopeAll {
+	if conversationID != "" {
+		// 会话筛选时汇总只统计该会话的记录（当前页），不混入全局统计
+		summary, topTools = summarizeAccessibleExecutionPage(executions, monitorPageTopTools)
+	} else if access.Scope == database.RBACScopeAll {
 		summary, topTools = h.loadStatsSummary(monitorPageTopTools)
 	} else if h.db != nil {
 		if scoped, err := h.db.LoadToolStatsSummaryForAccess(monitorPageTopTools, access); err == nil {
@@ -196,20 +204,43 @@ func (h *MonitorHandler) monitorRetentionDays() int {
 	return config.MonitorConfig{}.RetentionDaysEffective()
 }
 
+// PurgeRetention 手动触发保留策略清理（执行记录 + 过程详情），返回清理行数。
+// 与后台定时清理走同一套逻辑；保留天数配置为 0 的表不会被清理。
+func (h *MonitorHandler) PurgeRetention(c *gin.Context) {
+	if h.monitorRetention == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "保留策略服务未初始化"})
+		return
+	}
+	result, err := h.monitorRetention.PurgeExpiredNow()
+	if err != nil {
+		h.logger.Error("手动触发保留策略清理失败", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "清理失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":                    "清理完成",
+		"deletedToolExecutions":      result.ToolExecutions,
+		"deletedProcessDetails":      result.ProcessDetails,
+		"retentionDays":              h.monitorRetentionDays(),
+		"processDetailRetentionDays": h.monitorRetention.ProcessDetailRetentionDays(),
+	})
+}
+
 func (h *MonitorHandler) loadExecutions() []*mcp.ToolExecution {
 	executions, _ := h.loadExecutionsWithPagination(1, 1000, "", "")
 	return executions
 }
 
-func (h *MonitorHandler) loadExecutionListWithPagination(page, pageSize int, status, toolName string, access database.RBACListAccess) ([]*mcp.ToolExecution, int) {
+func (h *MonitorHandler) loadExecutionListWithPagination(page, pageSize int, status, toolName, conversationID string, access database.RBACListAccess) ([]*mcp.ToolExecution, int) {
 	if h.db == nil {
 		allExecutions := filterToolExecutionsForAccess(h.mcpServer.GetAllExecutions(), access, h.db)
-		if status != "" || toolName != "" {
+		if status != "" || toolName != "" || conversationID != "" {
 			filtered := make([]*mcp.ToolExecution, 0)
 			for _, exec := range allExecutions {
 				matchStatus := status == "" || exec.Status == status
 				matchTool := toolNameFilterMatches(exec.ToolName, toolName)
-				if matchStatus && matchTool {
+				matchConversation := conversationID == "" || exec.ConversationID == conversationID
+				if matchStatus && matchTool && matchConversation {
 					filtered = append(filtered, exec)
 				}
 			}
@@ -236,13 +267,13 @@ func (h *MonitorHandler) loadExecutionListWithPagination(page, pageSize int, sta
 	}
 
 	offset := (page - 1) * pageSize
-	executions, err := h.db.LoadToolExecutionListPageForAccess(offset, pageSize, status, toolName, access)
+	executions, err := h.db.LoadToolExecutionListPageForAccess(offset, pageSize, status, toolName, conversationID, access)
 	if err != nil {
 		h.logger.Warn("从数据库加载执行记录列表失败，回退到内存数据", zap.Error(err))
-		return h.loadExecutionListWithPaginationFromMemory(page, pageSize, status, toolName, access)
+		return h.loadExecutionListWithPaginationFromMemory(page, pageSize, status, toolName, conversationID, access)
 	}
 
-	total, err := h.db.CountToolExecutionsForAccess(status, toolName, access)
+	total, err := h.db.CountToolExecutionsForAccess(status, toolName, conversationID, access)
 	if err != nil {
 		h.logger.Warn("获取执行记录总数失败", zap.Error(err))
 		total = offset + len(executions)
@@ -254,14 +285,15 @@ func (h *MonitorHandler) loadExecutionListWithPagination(page, pageSize int, sta
 	return executions, total
 }
 
-func (h *MonitorHandler) loadExecutionListWithPaginationFromMemory(page, pageSize int, status, toolName string, access database.RBACListAccess) ([]*mcp.ToolExecution, int) {
+func (h *MonitorHandler) loadExecutionListWithPaginationFromMemory(page, pageSize int, status, toolName, conversationID string, access database.RBACListAccess) ([]*mcp.ToolExecution, int) {
 	allExecutions := filterToolExecutionsForAccess(h.mcpServer.GetAllExecutions(), access, h.db)
-	if status != "" || toolName != "" {
+	if status != "" || toolName != "" || conversationID != "" {
 		filtered := make([]*mcp.ToolExecution, 0)
 		for _, exec := range allExecutions {
 			matchStatus := status == "" || exec.Status == status
 			matchTool := toolNameFilterMatches(exec.ToolName, toolName)
-			if matchStatus && matchTool {
+			matchConversation := conversationID == "" || exec.ConversationID == conversationID
+			if matchStatus && matchTool && matchConversation {
 				filtered = append(filtered, exec)
 			}
 		}
@@ -77,7 +77,8 @@ func (h *AgentHandler) MultiAgentLoopStream(c *gin.Context) {
 		sseLine = append(sseLine, b...)
 		sseLine = append(sseLine, '\n', '\n')
 		if ssePublishConversationID != "" && h.taskEventBus != nil {
-			h.taskEventBus.Publish(ssePublishConversationID, sseLine)
+			// Publish 返回带 id: 序号的完整帧，主流直接写它，与镜像流序号对齐（断线可按 Last-Event-ID 续传）
+			sseLine = h.taskEventBus.Publish(ssePublishConversationID, sseLine)
 		}
 		if clientDisconnected {
 			return
@@ -192,11 +193,18 @@ func (h *AgentHandler) MultiAgentLoopStream(c *gin.Context) {
 	}
 	taskOwned = true
 
+	// RAG 自动前置注入：每条用户消息检索一次，分段续跑复用同一结果。
+	knowledgeBlock := h.knowledgeAutoInjectBlock(taskCtx, req.Message)
+
 	// 同一 HTTP 流内多段 Run（如中断并继续）合并 MCP execution id，供最终 response / 库表与工具芯片展示完整列表
 	var cumulativeMCPExecutionIDs []string
 	// 同一请求内分段续跑时，主代理 iteration 事件按偏移累计，避免 UI 出现「第3轮 → 第1轮」回跳。
 	var mainIterationOffset int
 	var emptyResponseContinueAttempt int
+	// 工具调用预算贯穿同一请求内全部分段续跑（中断继续/空响应续跑共享计数）。
+	toolBudget := h.newEinoToolCallBudget(runCfg, conversationID, sendEvent)
+	// 成本预算同样贯穿分段续跑；超限由预算中间件中止循环。
+	tokenBudget := h.newEinoTokenBudget(runCfg, req.Budget, conversationID, sendEvent)
 
 	for {
 		segmentMainIterationMax := 0
@@ -232,9 +240,12 @@ func (h *AgentHandler) MultiAgentLoopStream(c *gin.Context) {
 		taskCtxLoop := mcp.WithMCPConversationID(taskCtx, conversationID)
 		taskCtxLoop = mcp.WithToolRunRegistry(taskCtxLoop, h.tasks)
 		taskCtxLoop = mcp.WithEinoExecuteRunRegistry(taskCtxLoop, h.tasks)
+		taskCtxLoop = mcp.WithParentExecutionTracker(taskCtxLoop, &mcp.ParentExecutionTracker{})
 		taskCtxLoop = multiagent.WithHITLToolInterceptor(taskCtxLoop, func(ctx context.Context, toolName, arguments string) (string, error) {
 			return h.interceptHITLForEinoTool(ctx, cancelWithCause, conversationID, assistantMessageID, sendEvent, toolName, arguments)
 		})
+		taskCtxLoop = multiagent.WithToolCallBudget(taskCtxLoop, toolBudget)
+		taskCtxLoop = multiagent.WithTokenBudget(taskCtxLoop, tokenBudget)
 
 		result, runErr = multiagent.RunDeepAgent(
 			taskCtxLoop,
@@ -252,7 +263,7 @@ func (h *AgentHandler) MultiAgentLoopStream(c *gin.Context) {
 			h.agentsMarkdownDir,
 			orch,
 			chatReasoningToClientIntent(req.Reasoning),
-			h.agentSessionContextBlock(conversationID),
+			joinContextBlocks(h.agentSessionContextBlock(conversationID), knowledgeBlock),
 		)
 
 		if result != nil && len(result.MCPExecutionIDs) > 0 {
@@ -330,6 +341,31 @@ func (h *AgentHandler) MultiAgentLoopStream(c *gin.Context) {
 			return
 		}
 
+		if errors.Is(cause, ErrTaskResourceLimit) {
+			taskStatus = "cancelled"
+			h.tasks.UpdateTaskStatus(conversationID, taskStatus)
+			limitMsg := "任务因系统资源限流被终止，请稍后重试或减少并发任务数。"
+			if assistantMessageID != "" {
+				if result != nil {
+					if err := h.mergeAssistantMessagePartialOnCancel(assistantMessageID, result.Response); err != nil {
+						h.logger.Warn("合并限流取消前的部分回复失败", zap.Error(err))
+					}
+				}
+				if err := h.appendAssistantMessageNotice(assistantMessageID, limitMsg); err != nil {
+					h.logger.Warn("更新限流取消后的助手消息失败", zap.Error(err))
+				}
+				_ = h.db.AddProcessDetail(assistantMessageID, conversationID, "cancelled", limitMsg, nil)
+			}
+			sendEvent("error", limitMsg, map[string]interface{}{
+				"conversationId": conversationID,
+				"messageId":      assistantMessageID,
+				"errorType":      "resource_limit",
+			})
+			sendEvent("done", "", map[string]interface{}{"conversationId": conversationID})
+			timeoutCancel()
+			return
+		}
+
 		if errors.Is(runErr, context.DeadlineExceeded) || errors.Is(context.Cause(taskCtx), context.DeadlineExceeded) {
 			taskStatus = "timeout"
 			h.tasks.UpdateTaskStatus(conversationID, taskStatus)
@@ -432,9 +468,12 @@ func (h *AgentHandler) MultiAgentLoop(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	taskCtx = multiagent.WithToolCallBudget(taskCtx, h.newEinoToolCallBudget(runCfg, prep.ConversationID, progressCallback))
+	taskCtx = multiagent.WithTokenBudget(taskCtx, h.newEinoTokenBudget(runCfg, req.Budget, prep.ConversationID, progressCallback))
 
 	curHist := prep.History
 	curMsg := prep.FinalMessage
+	knowledgeBlock := h.knowledgeAutoInjectBlock(taskCtx, req.Message)
 	var result *multiagent.RunResult
 	var runErr error
 	for {
@@ -454,7 +493,7 @@ func (h *AgentHandler) MultiAgentLoop(c *gin.Context) {
 			h.agentsMarkdownDir,
 			strings.TrimSpace(req.Orchestration),
 			chatReasoningToClientIntent(req.Reasoning),
-			h.agentSessionContextBlock(prep.ConversationID),
+			joinContextBlocks(h.agentSessionContextBlock(prep.ConversationID), knowledgeBlock),
 		)
 		if runErr == nil {
 			break
@@ -25,6 +25,20 @@ type multiAgentPrepared struct {
 	UserMessageID      string
 }
 
+// replyQuoteMaxRunes 被引用消息注入时的长度上限，避免引用超长工具输出撑爆上下文。
+const replyQuoteMaxRunes = 4000
+
+// buildReplyQuoteBlock 把被引用的历史消息包装成显式上下文块，置于本轮用户消息之前。
+func buildReplyQuoteBlock(quoted *database.Message) string {
+	roleLabel := "用户"
+	if quoted.Role == "assistant" {
+		roleLabel = "助手"
+	}
+	content := safeTruncateString(quoted.Content, replyQuoteMaxRunes)
+	return fmt.Sprintf("[引用消息] 用户本轮消息引用了会话中这条%s消息（%s）：\n<<<\n%s\n>>>\n后续“按上面”“刚才那个”等指代请以该消息内容为准。",
+		roleLabel, quoted.CreatedAt.Format("2006-01-02 15:04:05"), content)
+}
+
 func (h *AgentHandler) prepareMultiAgentSession(req *ChatRequest, c *gin.Context, source string) (*multiAgentPrepared, error) {
 	if len(req.Attachments) > maxAttachments {
 		return nil, fmt.Errorf("附件最多 %d 个", maxAttachments)
@@ -92,6 +106,7 @@ func (h *AgentHandler) prepareMultiAgentSession(req *ChatRequest, c *gin.Context
 			agentHistoryMessages = []agent.ChatMessage{}
 		} else {
 			agentHistoryMessages = dbMessagesToAgentChatMessages(historyMessages)
+			h.maybeArchiveConversationHistory(conversationID, len(historyMessages))
 		}
 	}
 
@@ -141,6 +156,26 @@ func (h *AgentHandler) prepareMultiAgentSession(req *ChatRequest, c *gin.Context
 		}
 	}
 
+	// 消息引用：把被引用消息的完整内容显式注入本轮，保证"按上面第 2 个端口继续测"
+	// 这类指代即使在历史被裁剪后也有明确所指。仅允许引用当前会话内的消息。
+	if replyTo := strings.TrimSpace(req.ReplyToMessageID); replyTo != "" {
+		quoted, qerr := h.db.GetMessageByID(conversationID, replyTo)
+		if qerr != nil {
+			return nil, fmt.Errorf("引用的消息不存在或不属于当前会话: %w", qerr)
+		}
+		finalMessage = buildReplyQuoteBlock(quoted) + "\n\n" + finalMessage
+	}
+
+	// 仅信息收集安全模式：工具集收敛到非侵入性白名单（角色/WebShell 工具集取交集），
+	// 边界声明置于本轮消息最前
+	if req.SafeMode {
+		roleTools = applySafeModeTools(roleTools)
+		finalMessage = safeModeBoundaryPrompt + "\n\n" + finalMessage
+		h.logger.Info("会话启用仅信息收集安全模式",
+			zap.String("conversationId", conversationID),
+			zap.Int("toolCount", len(roleTools)))
+	}
+
 	var savedPaths []string
 	if len(req.Attachments) > 0 {
 		var aerr error
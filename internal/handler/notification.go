@@ -349,8 +349,8 @@ func (h *NotificationHandler) loadC2SessionOnlineEvents(sinceMs int64, limit int
 			continue
 		}
 		desc := strings.TrimSpace(e.Message)
-		if len(desc) > 220 {
-			desc = desc[:200] + "…"
+		if d := safeTruncateString(desc, 200); d != desc {
+			desc = d + "…"
 		}
 		if desc == "" {
 			desc = i18nText(english, "新会话已建立", "A new session was created")
@@ -7,7 +7,7 @@ import (
 	"go.uber.org/zap"
 )
 
-// agentSessionContextBlock 注入会话工作目录与项目黑板（用于 system prompt 追加块）。
+// agentSessionContextBlock 注入会话工作目录、项目黑板与会话目标档案（用于 system prompt 追加块）。
 // 用户输入由 message history 承载；压缩后由 summarization 摘要指令保留关键约束。
 func (h *AgentHandler) agentSessionContextBlock(conversationID string) string {
 	var parts []string
@@ -17,6 +17,14 @@ func (h *AgentHandler) agentSessionContextBlock(conversationID string) string {
 	if bb := h.projectBlackboardBlock(conversationID); bb != "" {
 		parts = append(parts, bb)
 	}
+	if tp := h.targetProfileBlock(conversationID); tp != "" {
+		parts = append(parts, tp)
+	}
+	if h.config != nil {
+		if rt := project.BuildResponseTemplateBlock(h.config.Agent.ResponseTemplate); rt != "" {
+			parts = append(parts, rt)
+		}
+	}
 	return strings.Join(parts, "\n\n")
 }
 
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cyberstrike-ai/internal/database"
+	"cyberstrike-ai/internal/mcp"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 会话重放脚本导出：培训/复现场景把一次成功的渗透会话变成可直接跑的 bash 脚本。
+// 按执行顺序输出每次工具调用实际拼接出的完整命令行（执行器落库的 command_line），
+// 带工具名/状态/耗时注释；没有命令行的调用（内部工具、MCP 远端工具）以注释形式保留占位。
+
+// replayScriptMaxExecutions 重放脚本内工具执行记录的条数上限
+const replayScriptMaxExecutions = 500
+
+// GetConversationReplayScript 导出会话的可重放 bash 脚本
+// GET /api/reports/:conversationId/replay-script
+func (h *ReportHandler) GetConversationReplayScript(c *gin.Context) {
+	conversationID := strings.TrimSpace(c.Param("conversationId"))
+	if conversationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "conversationId is required"})
+		return
+	}
+	conv, err := h.db.GetConversationLite(conversationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "对话不存在"})
+		return
+	}
+	executions, total, err := h.db.LoadToolExecutionsByConversation(conversationID, 0, replayScriptMaxExecutions)
+	if err != nil {
+		h.logger.Warn("重放脚本：读取工具执行记录失败", zap.String("conversationId", conversationID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取工具执行记录失败"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="replay-%s.sh"`, conversationID))
+	c.Data(http.StatusOK, "text/x-shellscript; charset=utf-8", []byte(renderReplayScript(conv, executions, total)))
+}
+
+// renderReplayScript 生成带注释的 bash 重放脚本。失败/取消的调用命令行也原样输出，
+// 但默认注释掉并标注原执行状态，由使用者自行决定是否放开。
+func renderReplayScript(conv *database.Conversation, executions []*mcp.ToolExecution, total int) string {
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	fmt.Fprintf(&b, "# 会话重放脚本：%s\n", strings.TrimSpace(conv.Title))
+	fmt.Fprintf(&b, "# 会话 ID：%s\n", conv.ID)
+	fmt.Fprintf(&b, "# 导出时间：%s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "# 工具执行：%d 次", total)
+	if total > len(executions) {
+		fmt.Fprintf(&b, "（以下为前 %d 次）", len(executions))
+	}
+	b.WriteString("\n#\n")
+	b.WriteString("# ⚠️ 仅限在获得授权的环境中执行。脚本按原会话顺序逐条重放命令，\n")
+	b.WriteString("# 不做目标校验与速率限制，运行前请逐条确认命令与目标。\n")
+	b.WriteString("set -u\n")
+
+	skipped := 0
+	for i, exec := range executions {
+		cmd := strings.TrimSpace(exec.CommandLine)
+		if cmd == "" {
+			skipped++
+			fmt.Fprintf(&b, "\n# [%d] %s（%s）：无命令行记录（内部工具或远端 MCP 工具），跳过\n", i+1, exec.ToolName, exec.Status)
+			continue
+		}
+		fmt.Fprintf(&b, "\n# [%d] %s（%s", i+1, exec.ToolName, exec.Status)
+		if exec.Duration > 0 {
+			fmt.Fprintf(&b, "，耗时 %s", exec.Duration.Round(time.Millisecond))
+		}
+		b.WriteString("）\n")
+		fmt.Fprintf(&b, "echo '==> [%d/%d] %s'\n", i+1, len(executions), exec.ToolName)
+		if exec.Status == mcp.ToolExecutionStatusCompleted {
+			b.WriteString(cmd + "\n")
+		} else {
+			// 原会话里未成功结束的调用默认不执行，保留命令供人工判断
+			fmt.Fprintf(&b, "# 原执行状态为 %s，默认跳过；如需重放请去掉下行注释\n", exec.Status)
+			b.WriteString("# " + strings.ReplaceAll(cmd, "\n", "\n# ") + "\n")
+		}
+	}
+	if len(executions) == 0 {
+		b.WriteString("\n# 本会话没有工具执行记录，无可重放命令。\n")
+	} else if skipped > 0 {
+		fmt.Fprintf(&b, "\n# 共 %d 条调用无命令行记录，已以注释形式保留位置。\n", skipped)
+	}
+	return b.String()
+}
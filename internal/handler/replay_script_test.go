@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"cyberstrike-ai/internal/database"
+	"cyberstrike-ai/internal/mcp"
+)
+
+func TestRenderReplayScript(t *testing.T) {
+	conv := &database.Conversation{ID: "conv-1", Title: "内网横向测试"}
+	executions := []*mcp.ToolExecution{
+		{ToolName: "nmap", Status: mcp.ToolExecutionStatusCompleted, CommandLine: "nmap -sV -p- 10.0.0.5"},
+		{ToolName: "search_knowledge_base", Status: mcp.ToolExecutionStatusCompleted},
+		{ToolName: "sqlmap", Status: mcp.ToolExecutionStatusFailed, CommandLine: "sqlmap -u 'http://10.0.0.5/login?id=1' --batch"},
+	}
+
+	script := renderReplayScript(conv, executions, len(executions))
+
+	if !strings.HasPrefix(script, "#!/usr/bin/env bash\n") {
+		t.Fatalf("脚本应以 shebang 开头: %q", script[:40])
+	}
+	if !strings.Contains(script, "\nnmap -sV -p- 10.0.0.5\n") {
+		t.Fatalf("成功执行的命令应原样输出:\n%s", script)
+	}
+	if !strings.Contains(script, "无命令行记录") {
+		t.Fatalf("无命令行的调用应以注释占位:\n%s", script)
+	}
+	if !strings.Contains(script, "# sqlmap -u 'http://10.0.0.5/login?id=1' --batch") {
+		t.Fatalf("失败执行的命令应注释保留:\n%s", script)
+	}
+	if strings.Contains(script, "\nsqlmap -u") {
+		t.Fatalf("失败执行的命令不应处于可执行状态:\n%s", script)
+	}
+	if !strings.Contains(script, "授权") {
+		t.Fatalf("脚本头应包含授权提醒:\n%s", script)
+	}
+}
+
+func TestRenderReplayScriptEmpty(t *testing.T) {
+	conv := &database.Conversation{ID: "conv-2", Title: "空会话"}
+	script := renderReplayScript(conv, nil, 0)
+	if !strings.Contains(script, "无可重放命令") {
+		t.Fatalf("空会话应给出说明:\n%s", script)
+	}
+}
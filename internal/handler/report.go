@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"cyberstrike-ai/internal/database"
+	"cyberstrike-ai/internal/mcp"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ReportHandler 按会话导出综合交付报告：对话概览、漏洞（按严重度）、攻击链、工具执行过程。
+// 当前支持 markdown / html（html 自带打印样式，浏览器「打印-另存为 PDF」即可出 PDF）；
+// 服务端直出 PDF 留到后续阶段。报告只汇总已落库的数据，不触发任何 LLM 生成。
+type ReportHandler struct {
+	db     *database.DB
+	logger *zap.Logger
+}
+
+// NewReportHandler 创建报告处理器
+func NewReportHandler(db *database.DB, logger *zap.Logger) *ReportHandler {
+	return &ReportHandler{db: db, logger: logger}
+}
+
+const (
+	// reportMaxExecutions 报告内工具执行记录的条数上限
+	reportMaxExecutions = 200
+	// reportResultSnippetRunes 单条工具结果在报告里的最大长度
+	reportResultSnippetRunes = 400
+)
+
+// conversationReportData 报告各 section 的数据汇总。
+type conversationReportData struct {
+	Conversation    *database.Conversation
+	GeneratedAt     time.Time
+	MessageCount    int
+	TaskDescription string // 首条 user 消息（任务描述）
+	HistorySummary  string // 自动归档生成的「历史摘要」正文（如有）
+	Vulnerabilities []*database.Vulnerability
+	SeverityCounts  map[string]int
+	ChainNodes      []database.AttackChainNode
+	ChainEdges      []database.AttackChainEdge
+	Executions      []*mcp.ToolExecution
+	ExecutionsTotal int
+}
+
+var reportSeverityOrder = []string{"critical", "high", "medium", "low", "info"}
+
+func reportSeverityRank(severity string) int {
+	for i, s := range reportSeverityOrder {
+		if strings.EqualFold(strings.TrimSpace(severity), s) {
+			return i
+		}
+	}
+	return len(reportSeverityOrder)
+}
+
+// GetConversationReport 导出会话综合报告
+// GET /api/reports/:conversationId?format=markdown|html|pdf
+func (h *ReportHandler) GetConversationReport(c *gin.Context) {
+	conversationID := strings.TrimSpace(c.Param("conversationId"))
+	if conversationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "conversationId is required"})
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "markdown")))
+	switch format {
+	case "markdown", "md", "html":
+	case "pdf":
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"error": "服务端直出 PDF 暂未支持：请用 format=html 获取带打印样式的报告，在浏览器中「打印-另存为 PDF」",
+		})
+		return
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format 不支持，可选：markdown、html、pdf"})
+		return
+	}
+
+	data, err := h.collectReportData(conversationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "对话不存在"})
+		return
+	}
+
+	if format == "html" {
+		// inline 渲染，便于直接在浏览器里打印成 PDF
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(renderReportHTML(data)))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="report-%s.md"`, conversationID))
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(renderReportMarkdown(data)))
+}
+
+// collectReportData 汇总报告所需的全部落库数据；对话不存在时返回错误，其余 section 缺数据不报错。
+func (h *ReportHandler) collectReportData(conversationID string) (*conversationReportData, error) {
+	conv, err := h.db.GetConversationLite(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	data := &conversationReportData{
+		Conversation:   conv,
+		GeneratedAt:    time.Now(),
+		SeverityCounts: map[string]int{},
+	}
+
+	if messages, err := h.db.GetMessagesLite(conversationID); err == nil {
+		data.MessageCount = len(messages)
+		for i := range messages {
+			if data.TaskDescription == "" && messages[i].Role == "user" {
+				data.TaskDescription = strings.TrimSpace(messages[i].Content)
+			}
+			if data.HistorySummary == "" && database.IsHistoryArchiveSummaryMessage(&messages[i]) {
+				data.HistorySummary = strings.TrimSpace(strings.TrimPrefix(messages[i].Content, database.HistoryArchiveSummaryPrefix))
+			}
+		}
+	} else {
+		h.logger.Warn("报告：读取会话消息失败", zap.String("conversationId", conversationID), zap.Error(err))
+	}
+
+	if vulns, err := h.db.ListVulnerabilities(500, 0, database.VulnerabilityListFilter{ConversationID: conversationID}); err == nil {
+		sort.SliceStable(vulns, func(i, j int) bool {
+			return reportSeverityRank(vulns[i].Severity) < reportSeverityRank(vulns[j].Severity)
+		})
+		data.Vulnerabilities = vulns
+		for _, v := range vulns {
+			data.SeverityCounts[strings.ToLower(strings.TrimSpace(v.Severity))]++
+		}
+	} else {
+		h.logger.Warn("报告：读取漏洞列表失败", zap.String("conversationId", conversationID), zap.Error(err))
+	}
+
+	if nodes, err := h.db.LoadAttackChainNodes(conversationID); err == nil {
+		data.ChainNodes = nodes
+	}
+	if edges, err := h.db.LoadAttackChainEdges(conversationID); err == nil {
+		data.ChainEdges = edges
+	}
+
+	if executions, total, err := h.db.LoadToolExecutionsByConversation(conversationID, 0, reportMaxExecutions); err == nil {
+		data.Executions = executions
+		data.ExecutionsTotal = total
+	} else {
+		h.logger.Warn("报告：读取工具执行记录失败", zap.String("conversationId", conversationID), zap.Error(err))
+	}
+	return data, nil
+}
+
+// reportExecutionSnippet 取执行结果的文本摘要（优先最终结果，其次错误信息）。
+func reportExecutionSnippet(exec *mcp.ToolExecution) string {
+	if exec.Result != nil {
+		var parts []string
+		for _, content := range exec.Result.Content {
+			if text := strings.TrimSpace(content.Text); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		if joined := strings.Join(parts, "\n"); joined != "" {
+			return trimSnippet(joined, reportResultSnippetRunes)
+		}
+	}
+	return trimSnippet(exec.Error, reportResultSnippetRunes)
+}
+
+func reportChainNodeLabel(nodes []database.AttackChainNode, id string) string {
+	for i := range nodes {
+		if nodes[i].ID == id {
+			return nodes[i].Label
+		}
+	}
+	return id
+}
+
+func renderReportMarkdown(data *conversationReportData) string {
+	var b strings.Builder
+	conv := data.Conversation
+	fmt.Fprintf(&b, "# 渗透测试报告：%s\n\n", strings.TrimSpace(conv.Title))
+	fmt.Fprintf(&b, "- 会话 ID：%s\n", conv.ID)
+	fmt.Fprintf(&b, "- 测试时间：%s ~ %s\n", conv.CreatedAt.Format("2006-01-02 15:04"), conv.UpdatedAt.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "- 报告生成时间：%s\n", data.GeneratedAt.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "- 消息数：%d，工具执行：%d 次\n\n", data.MessageCount, data.ExecutionsTotal)
+
+	b.WriteString("## 概览\n\n")
+	if data.TaskDescription != "" {
+		fmt.Fprintf(&b, "**任务描述**：%s\n\n", trimSnippet(data.TaskDescription, 1000))
+	}
+	if data.HistorySummary != "" {
+		fmt.Fprintf(&b, "**过程摘要**：%s\n\n", data.HistorySummary)
+	}
+	if len(data.Vulnerabilities) > 0 {
+		counts := make([]string, 0, len(reportSeverityOrder))
+		for _, s := range reportSeverityOrder {
+			if n := data.SeverityCounts[s]; n > 0 {
+				counts = append(counts, fmt.Sprintf("%s %d", s, n))
+			}
+		}
+		fmt.Fprintf(&b, "共发现漏洞 %d 个（%s）。\n\n", len(data.Vulnerabilities), strings.Join(counts, "、"))
+	} else {
+		b.WriteString("本次会话未记录漏洞。\n\n")
+	}
+
+	if len(data.Vulnerabilities) > 0 {
+		b.WriteString("## 漏洞详情（按严重度）\n\n")
+		for i, v := range data.Vulnerabilities {
+			fmt.Fprintf(&b, "### %d. [%s] %s\n\n", i+1, strings.ToUpper(v.Severity), strings.TrimSpace(v.Title))
+			fmt.Fprintf(&b, "- 目标：%s\n- 类型：%s\n- 状态：%s\n\n", v.Target, v.Type, v.Status)
+			if desc := strings.TrimSpace(v.Description); desc != "" {
+				fmt.Fprintf(&b, "%s\n\n", desc)
+			}
+			if steps := strings.TrimSpace(v.ReproSteps); steps != "" {
+				fmt.Fprintf(&b, "**复现步骤**：\n\n%s\n\n", steps)
+			}
+			if evidence := strings.TrimSpace(v.Evidence); evidence != "" {
+				fmt.Fprintf(&b, "**证据**：\n\n```\n%s\n```\n\n", trimSnippet(evidence, 2000))
+			}
+			if impact := strings.TrimSpace(v.Impact); impact != "" {
+				fmt.Fprintf(&b, "**影响**：%s\n\n", impact)
+			}
+			if rec := strings.TrimSpace(v.Recommendation); rec != "" {
+				fmt.Fprintf(&b, "**修复建议**：%s\n\n", rec)
+			}
+		}
+	}
+
+	if len(data.ChainNodes) > 0 {
+		b.WriteString("## 攻击链\n\n")
+		for _, node := range data.ChainNodes {
+			fmt.Fprintf(&b, "- [%s] %s（风险分 %d）\n", node.Type, node.Label, node.RiskScore)
+		}
+		if len(data.ChainEdges) > 0 {
+			b.WriteString("\n路径：\n\n")
+			for _, edge := range data.ChainEdges {
+				fmt.Fprintf(&b, "- %s —%s→ %s\n",
+					reportChainNodeLabel(data.ChainNodes, edge.Source), edge.Type, reportChainNodeLabel(data.ChainNodes, edge.Target))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(data.Executions) > 0 {
+		b.WriteString("## 工具执行过程\n\n")
+		if data.ExecutionsTotal > len(data.Executions) {
+			fmt.Fprintf(&b, "（共 %d 次，以下为前 %d 次）\n\n", data.ExecutionsTotal, len(data.Executions))
+		}
+		for i, exec := range data.Executions {
+			fmt.Fprintf(&b, "### %d. %s（%s）\n\n", i+1, exec.ToolName, exec.Status)
+			if cmd := strings.TrimSpace(exec.CommandLine); cmd != "" {
+				fmt.Fprintf(&b, "```\n%s\n```\n\n", trimSnippet(cmd, 500))
+			}
+			if snippet := reportExecutionSnippet(exec); snippet != "" {
+				fmt.Fprintf(&b, "结果摘要：\n\n```\n%s\n```\n\n", snippet)
+			}
+		}
+	}
+	return b.String()
+}
+
+// renderReportHTML 输出自包含的 HTML 报告（内联打印样式，浏览器打印即得 PDF）。
+func renderReportHTML(data *conversationReportData) string {
+	esc := html.EscapeString
+	conv := data.Conversation
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"zh-CN\">\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>渗透测试报告：%s</title>\n", esc(conv.Title))
+	b.WriteString(`<style>
+body { font-family: "PingFang SC", "Microsoft YaHei", sans-serif; max-width: 900px; margin: 0 auto; padding: 24px; color: #1f2430; }
+h1 { border-bottom: 2px solid #1f2430; padding-bottom: 8px; }
+h2 { border-bottom: 1px solid #d0d4dc; padding-bottom: 4px; margin-top: 32px; }
+table { border-collapse: collapse; width: 100%; margin: 12px 0; }
+th, td { border: 1px solid #d0d4dc; padding: 6px 10px; text-align: left; font-size: 14px; vertical-align: top; }
+pre { background: #f5f6f8; padding: 10px; overflow-x: auto; white-space: pre-wrap; word-break: break-all; font-size: 13px; }
+.sev-critical { color: #c0392b; font-weight: bold; }
+.sev-high { color: #e67e22; font-weight: bold; }
+.sev-medium { color: #b8860b; }
+.sev-low { color: #2980b9; }
+.sev-info { color: #7f8c8d; }
+@media print { body { padding: 0; } h2 { page-break-after: avoid; } pre, tr { page-break-inside: avoid; } }
+</style>
+</head>
+<body>
+`)
+	fmt.Fprintf(&b, "<h1>渗透测试报告：%s</h1>\n", esc(conv.Title))
+	fmt.Fprintf(&b, "<p>会话 ID：%s<br>测试时间：%s ~ %s<br>报告生成时间：%s<br>消息数：%d，工具执行：%d 次</p>\n",
+		esc(conv.ID),
+		conv.CreatedAt.Format("2006-01-02 15:04"), conv.UpdatedAt.Format("2006-01-02 15:04"),
+		data.GeneratedAt.Format("2006-01-02 15:04"), data.MessageCount, data.ExecutionsTotal)
+
+	b.WriteString("<h2>概览</h2>\n")
+	if data.TaskDescription != "" {
+		fmt.Fprintf(&b, "<p><strong>任务描述</strong>：%s</p>\n", esc(trimSnippet(data.TaskDescription, 1000)))
+	}
+	if data.HistorySummary != "" {
+		fmt.Fprintf(&b, "<p><strong>过程摘要</strong>：%s</p>\n", esc(data.HistorySummary))
+	}
+	if len(data.Vulnerabilities) > 0 {
+		counts := make([]string, 0, len(reportSeverityOrder))
+		for _, s := range reportSeverityOrder {
+			if n := data.SeverityCounts[s]; n > 0 {
+				counts = append(counts, fmt.Sprintf("%s %d", s, n))
+			}
+		}
+		fmt.Fprintf(&b, "<p>共发现漏洞 %d 个（%s）。</p>\n", len(data.Vulnerabilities), esc(strings.Join(counts, "、")))
+	} else {
+		b.WriteString("<p>本次会话未记录漏洞。</p>\n")
+	}
+
+	if len(data.Vulnerabilities) > 0 {
+		b.WriteString("<h2>漏洞详情（按严重度）</h2>\n")
+		for i, v := range data.Vulnerabilities {
+			sevClass := "sev-" + strings.ToLower(strings.TrimSpace(v.Severity))
+			fmt.Fprintf(&b, "<h3>%d. <span class=\"%s\">[%s]</span> %s</h3>\n", i+1, esc(sevClass), esc(strings.ToUpper(v.Severity)), esc(v.Title))
+			fmt.Fprintf(&b, "<p>目标：%s ｜ 类型：%s ｜ 状态：%s</p>\n", esc(v.Target), esc(v.Type), esc(v.Status))
+			if desc := strings.TrimSpace(v.Description); desc != "" {
+				fmt.Fprintf(&b, "<p>%s</p>\n", esc(desc))
+			}
+			if steps := strings.TrimSpace(v.ReproSteps); steps != "" {
+				fmt.Fprintf(&b, "<p><strong>复现步骤</strong>：</p><pre>%s</pre>\n", esc(steps))
+			}
+			if evidence := strings.TrimSpace(v.Evidence); evidence != "" {
+				fmt.Fprintf(&b, "<p><strong>证据</strong>：</p><pre>%s</pre>\n", esc(trimSnippet(evidence, 2000)))
+			}
+			if impact := strings.TrimSpace(v.Impact); impact != "" {
+				fmt.Fprintf(&b, "<p><strong>影响</strong>：%s</p>\n", esc(impact))
+			}
+			if rec := strings.TrimSpace(v.Recommendation); rec != "" {
+				fmt.Fprintf(&b, "<p><strong>修复建议</strong>：%s</p>\n", esc(rec))
+			}
+		}
+	}
+
+	if len(data.ChainNodes) > 0 {
+		b.WriteString("<h2>攻击链</h2>\n<ul>\n")
+		for _, node := range data.ChainNodes {
+			fmt.Fprintf(&b, "<li>[%s] %s（风险分 %d）</li>\n", esc(node.Type), esc(node.Label), node.RiskScore)
+		}
+		b.WriteString("</ul>\n")
+		if len(data.ChainEdges) > 0 {
+			b.WriteString("<p>路径：</p>\n<ul>\n")
+			for _, edge := range data.ChainEdges {
+				fmt.Fprintf(&b, "<li>%s —%s→ %s</li>\n",
+					esc(reportChainNodeLabel(data.ChainNodes, edge.Source)), esc(edge.Type), esc(reportChainNodeLabel(data.ChainNodes, edge.Target)))
+			}
+			b.WriteString("</ul>\n")
+		}
+	}
+
+	if len(data.Executions) > 0 {
+		b.WriteString("<h2>工具执行过程</h2>\n")
+		if data.ExecutionsTotal > len(data.Executions) {
+			fmt.Fprintf(&b, "<p>（共 %d 次，以下为前 %d 次）</p>\n", data.ExecutionsTotal, len(data.Executions))
+		}
+		b.WriteString("<table>\n<tr><th>#</th><th>工具</th><th>状态</th><th>命令 / 结果摘要</th></tr>\n")
+		for i, exec := range data.Executions {
+			detail := strings.TrimSpace(exec.CommandLine)
+			if snippet := reportExecutionSnippet(exec); snippet != "" {
+				if detail != "" {
+					detail += "\n"
+				}
+				detail += snippet
+			}
+			fmt.Fprintf(&b, "<tr><td>%d</td><td>%s</td><td>%s</td><td><pre>%s</pre></td></tr>\n",
+				i+1, esc(exec.ToolName), esc(exec.Status), esc(trimSnippet(detail, 600)))
+		}
+		b.WriteString("</table>\n")
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cyberstrike-ai/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestGetConversationReportMarkdownOrdersVulnerabilitiesBySeverity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "report.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	conv, err := db.CreateConversation("目标站点渗透测试", database.ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	if _, err := db.CreateVulnerability(&database.Vulnerability{
+		ConversationID: conv.ID, Title: "信息泄露", Severity: "low", Target: "https://example.com",
+	}); err != nil {
+		t.Fatalf("CreateVulnerability: %v", err)
+	}
+	if _, err := db.CreateVulnerability(&database.Vulnerability{
+		ConversationID: conv.ID, Title: "SQL 注入", Severity: "critical", Target: "https://example.com/login",
+	}); err != nil {
+		t.Fatalf("CreateVulnerability: %v", err)
+	}
+
+	h := NewReportHandler(db, zap.NewNop())
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Params = gin.Params{{Key: "conversationId", Value: conv.ID}}
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/api/reports/"+conv.ID, nil)
+
+	h.GetConversationReport(ctx)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GetConversationReport() status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+	body := recorder.Body.String()
+	critical := strings.Index(body, "SQL 注入")
+	low := strings.Index(body, "信息泄露")
+	if critical < 0 || low < 0 {
+		t.Fatalf("report should contain both vulnerabilities, body = %s", body)
+	}
+	if critical > low {
+		t.Fatalf("critical vulnerability should come before low severity, body = %s", body)
+	}
+}
+
+func TestGetConversationReportPdfNotImplemented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "report-pdf.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	conv, err := db.CreateConversation("pdf 导出", database.ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+
+	h := NewReportHandler(db, zap.NewNop())
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Params = gin.Params{{Key: "conversationId", Value: conv.ID}}
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/api/reports/"+conv.ID+"?format=pdf", nil)
+
+	h.GetConversationReport(ctx)
+
+	if recorder.Code != http.StatusNotImplemented {
+		t.Fatalf("format=pdf status = %d, want 501", recorder.Code)
+	}
+	if !strings.Contains(recorder.Body.String(), "format=html") {
+		t.Fatalf("pdf error should point at format=html, body = %s", recorder.Body.String())
+	}
+}
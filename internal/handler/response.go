@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// 统一错误响应结构。历史接口直接返回 {"error": "..."}，前端已有大量依赖，
+// 因此 error 字段保留原语义，在此基础上补充机器可读的 code 与可选 details，
+// 前端可按 code 做分支而不必解析中文文案。新 handler 一律使用 RespondError，
+// 存量 handler 随改动逐步迁移。
+
+// APIError 标准错误响应
+type APIError struct {
+	Error   string      `json:"error"`             // 面向用户的错误描述（兼容旧结构）
+	Code    string      `json:"code"`              // 机器可读错误码，见 ErrCode* 常量
+	Details interface{} `json:"details,omitempty"` // 可选的结构化补充信息
+}
+
+// 通用错误码；业务特有场景可在各 handler 内自定义更细的码
+const (
+	ErrCodeBadRequest   = "bad_request"    // 请求参数缺失或不合法
+	ErrCodeUnauthorized = "unauthorized"   // 未登录或凭证无效
+	ErrCodeForbidden    = "forbidden"      // 已登录但无权访问目标资源
+	ErrCodeNotFound     = "not_found"      // 目标资源不存在
+	ErrCodeConflict     = "conflict"       // 与现有状态冲突（如重名）
+	ErrCodeInternal     = "internal_error" // 服务端内部错误
+)
+
+// RespondError 以标准结构返回错误响应
+func RespondError(c *gin.Context, status int, code, message string) {
+	c.JSON(status, APIError{Error: message, Code: code})
+}
+
+// RespondErrorDetails 同 RespondError，附带结构化补充信息
+func RespondErrorDetails(c *gin.Context, status int, code, message string, details interface{}) {
+	c.JSON(status, APIError{Error: message, Code: code, Details: details})
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRespondErrorKeepsLegacyErrorField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+
+	RespondError(ctx, http.StatusNotFound, ErrCodeNotFound, "对话不存在")
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", recorder.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if body["error"] != "对话不存在" {
+		t.Fatalf("error = %v，旧结构的 error 字段必须保留", body["error"])
+	}
+	if body["code"] != ErrCodeNotFound {
+		t.Fatalf("code = %v", body["code"])
+	}
+	if _, exists := body["details"]; exists {
+		t.Fatalf("未提供 details 时不应输出该字段: %v", body)
+	}
+}
+
+func TestRespondErrorDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+
+	RespondErrorDetails(ctx, http.StatusBadRequest, ErrCodeBadRequest, "参数不合法", map[string]interface{}{"field": "title"})
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	details, ok := body["details"].(map[string]interface{})
+	if !ok || details["field"] != "title" {
+		t.Fatalf("details = %v", body["details"])
+	}
+}
@@ -230,6 +230,17 @@ func (h *RobotHandler) deleteSessionBinding(sk string) {
 	}
 }
 
+// conversationExists 校验绑定的对话在库中仍存在。网页端删除对话后，内存缓存与
+// robot_user_sessions 里的绑定会变成悬挂引用（管理员 scope=all 时权限检查不查库），
+// 此时应重建新对话而不是带着失效 ID 去执行。
+func (h *RobotHandler) conversationExists(convID string) bool {
+	if h.db == nil || strings.TrimSpace(convID) == "" {
+		return false
+	}
+	_, err := h.db.GetConversation(convID)
+	return err == nil
+}
+
 // getOrCreateConversation 获取或创建当前会话，title 用于新对话的标题（取用户首条消息前50字）
 func (h *RobotHandler) getOrCreateConversation(platform, userID, title string, access *database.RBACAccess) (convID string, isNew bool) {
 	sk := h.sessionKey(platform, userID)
@@ -238,11 +249,11 @@ func (h *RobotHandler) getOrCreateConversation(platform, userID, title string, a
 	h.mu.RUnlock()
 	ownerID := access.User.ID
 	readScope := robotPrincipal(access).ScopeFor("chat:read")
-	if convID != "" && access.Permissions["chat:read"] && h.db.UserCanAccessResource(ownerID, readScope, "conversation", convID) {
+	if convID != "" && access.Permissions["chat:read"] && h.db.UserCanAccessResource(ownerID, readScope, "conversation", convID) && h.conversationExists(convID) {
 		return convID, false
 	}
 	if persistedConvID, persistedRole, persistedMode := h.loadSessionBinding(sk); strings.TrimSpace(persistedConvID) != "" {
-		if !access.Permissions["chat:read"] || !h.db.UserCanAccessResource(ownerID, readScope, "conversation", persistedConvID) {
+		if !access.Permissions["chat:read"] || !h.db.UserCanAccessResource(ownerID, readScope, "conversation", persistedConvID) || !h.conversationExists(persistedConvID) {
 			h.deleteSessionBinding(sk)
 		} else {
 			// 会话绑定持久化：服务重启后也可恢复当前对话和角色。
@@ -1722,7 +1733,7 @@ func (h *RobotHandler) sendWecomReply(c *gin.Context, toUser, fromUser, content,
 		msgSignature := h.signWecomRequest(h.config.Robots.Wecom.Token, timestamp, nonce, encrypted)
 
 		h.logger.Debug("企业微信发送加密回复",
-			zap.String("Encrypt", encrypted[:50]+"..."),
+			zap.String("Encrypt", safeTruncateString(encrypted, 50)+"..."),
 			zap.String("MsgSignature", msgSignature),
 			zap.String("TimeStamp", timestamp),
 			zap.String("Nonce", nonce))
@@ -1749,7 +1760,7 @@ func (h *RobotHandler) sendWecomReply(c *gin.Context, toUser, fromUser, content,
 	}
 
 	// 明文模式
-	h.logger.Debug("企业微信发送明文回复", zap.String("ToUserName", toUser), zap.String("FromUserName", fromUser), zap.String("Content", content[:50]+"..."))
+	h.logger.Debug("企业微信发送明文回复", zap.String("ToUserName", toUser), zap.String("FromUserName", fromUser), zap.String("Content", safeTruncateString(content, 50)+"..."))
 
 	// 手动构造 XML 响应（使用 CDATA 包裹所有字段，并包含 AgentID）
 	xmlResp := fmt.Sprintf(`<xml>
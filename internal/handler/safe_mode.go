@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"cyberstrike-ai/internal/mcp/builtin"
+)
+
+// 仅信息收集安全模式（蓝队/演示场景）：请求带 safeMode 时只暴露非侵入性工具，
+// 并在本轮消息前注入边界声明。工具收敛复用 roleTools 的白名单机制——
+// 真正的限制在工具集裁剪，提示词只是让模型理解并解释这个边界。
+
+// safeModeAllowedTools 安全模式下允许的工具白名单：被动 OSINT / DNS 枚举 / 轻量端口与
+// 服务识别，加上纯记录与查询类内置工具。exec、sqlmap、metasploit、hydra、C2、webshell
+// 等可发起主动攻击或任意命令的工具一律不在名单内。
+var safeModeAllowedTools = []string{
+	// 信息收集类外部工具（与 tools/*.yaml 的 name 对应）
+	"nmap", // 仅用于服务版本识别；边界提示词限定 -sV/-sn，禁用漏洞类 NSE 脚本
+	"subdomain_enum",
+	"subfinder",
+	"amass",
+	"dnsenum",
+	"fierce",
+	"waybackurls",
+	"gau",
+	"fofa_search",
+	"shodan_search",
+	"quake_search",
+	"zoomeye_search",
+	"virustotal_search",
+	// 记录与查询类内置工具
+	builtin.ToolRecordVulnerability,
+	builtin.ToolListVulnerabilities,
+	builtin.ToolGetVulnerability,
+	builtin.ToolCreateAsset,
+	builtin.ToolGetAsset,
+	builtin.ToolQueryAssets,
+	builtin.ToolUpdateAsset,
+	builtin.ToolCompleteAssetScan,
+	builtin.ToolUpsertProjectFact,
+	builtin.ToolGetProjectFact,
+	builtin.ToolListProjectFacts,
+	builtin.ToolSearchProjectFacts,
+	builtin.ToolDeprecateProjectFact,
+	builtin.ToolRestoreProjectFact,
+	builtin.ToolListKnowledgeRiskTypes,
+	builtin.ToolSearchKnowledgeBase,
+	builtin.ToolAnalyzeImage,
+	builtin.ToolGetToolExecution,
+	builtin.ToolWaitToolExecution,
+	builtin.ToolCancelToolExecution,
+}
+
+// safeModeBoundaryPrompt 注入到本轮用户消息前的边界声明（与角色 user_prompt 同一注入位置）。
+const safeModeBoundaryPrompt = `[安全模式 - 仅信息收集] 本次会话处于仅信息收集安全模式，必须严格遵守以下边界：
+1. 只做被动信息收集与资产梳理：whois/DNS 枚举、子域名发现、网络空间测绘（FOFA 等）、历史 URL 归档查询。
+2. nmap 仅允许主机发现与服务版本识别（-sn / -sV），禁止漏洞类 NSE 脚本（--script vuln 等）与任何利用尝试。
+3. 绝不发起主动攻击：不做漏洞利用、SQL 注入、口令爆破、目录扫描轰炸或任何会修改目标状态的操作。
+4. 可用工具已收敛为非侵入性集合；若任务需要超出边界的操作，说明原因并停止，不要寻找替代手段绕过限制。`
+
+// applySafeModeTools 把工具集收敛到安全模式白名单：roleTools 为空（原本全量可用）时直接
+// 返回白名单副本，否则取与白名单的交集（保持角色配置的顺序）。
+func applySafeModeTools(roleTools []string) []string {
+	if len(roleTools) == 0 {
+		return append([]string(nil), safeModeAllowedTools...)
+	}
+	allowed := make(map[string]bool, len(safeModeAllowedTools))
+	for _, name := range safeModeAllowedTools {
+		allowed[name] = true
+	}
+	filtered := make([]string, 0, len(roleTools))
+	for _, name := range roleTools {
+		if allowed[name] {
+			filtered = append(filtered, name)
+		}
+	}
+	if len(filtered) == 0 {
+		// 空工具集会被下游当作"不过滤全量可用"（见 Agent.getAvailableTools），
+		// 角色工具与白名单无交集时必须退回整个白名单而不是空切片
+		return append([]string(nil), safeModeAllowedTools...)
+	}
+	return filtered
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	"cyberstrike-ai/internal/mcp/builtin"
+)
+
+func TestApplySafeModeTools(t *testing.T) {
+	// 未配角色（原本全量可用）→ 收敛到白名单副本
+	got := applySafeModeTools(nil)
+	if !reflect.DeepEqual(got, safeModeAllowedTools) {
+		t.Fatalf("empty roleTools should yield whitelist, got %v", got)
+	}
+	got[0] = "mutated"
+	if safeModeAllowedTools[0] == "mutated" {
+		t.Fatal("返回值必须是副本，不能让调用方改到白名单")
+	}
+
+	// 角色工具与白名单取交集，保持角色配置顺序
+	role := []string{"sqlmap", "fofa_search", "exec", builtin.ToolSearchKnowledgeBase, "nmap"}
+	want := []string{"fofa_search", builtin.ToolSearchKnowledgeBase, "nmap"}
+	if got := applySafeModeTools(role); !reflect.DeepEqual(got, want) {
+		t.Fatalf("intersection = %v, want %v", got, want)
+	}
+
+	// 交集为空时退回整个白名单：空切片会被下游当作不过滤全量可用
+	if got := applySafeModeTools([]string{"sqlmap", "exec", "metasploit"}); !reflect.DeepEqual(got, safeModeAllowedTools) {
+		t.Fatalf("empty intersection should fall back to whitelist, got %v", got)
+	}
+}
+
+func TestSafeModeWhitelistExcludesIntrusiveTools(t *testing.T) {
+	for _, name := range safeModeAllowedTools {
+		switch name {
+		case "exec", "sqlmap", "metasploit", "hydra",
+			builtin.ToolWebshellExec, builtin.ToolC2Task, builtin.ToolDeleteAsset:
+			t.Fatalf("侵入性工具 %s 不应出现在安全模式白名单", name)
+		}
+	}
+}
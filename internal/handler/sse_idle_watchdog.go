@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrTaskIdleTimeout 流式任务空闲超时：连续 N 分钟没有任何 progress 事件时由看门狗取消。
+var ErrTaskIdleTimeout = errors.New("agent task idle timeout: no progress events")
+
+// sseIdleTimeout 读取 agent.sse_idle_timeout_minutes 配置；未配置或 <=0 返回 0（关闭看门狗）。
+func (h *AgentHandler) sseIdleTimeout() time.Duration {
+	if h.config == nil || h.config.Agent.SSEIdleTimeoutMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(h.config.Agent.SSEIdleTimeoutMinutes) * time.Minute
+}
+
+// startSSEIdleWatchdog 启动空闲看门狗：每个 progress 事件调用 touch 续命，
+// 超过 idleTimeout 没有任何事件则以 ErrTaskIdleTimeout 取消任务。
+// idleTimeout <= 0 时看门狗不生效（touch/stop 均为 no-op）。
+// 与 600 分钟总超时独立，用于发现 LLM 或工具阶段性卡死而不报错的情况。
+func startSSEIdleWatchdog(ctx context.Context, idleTimeout time.Duration, cancel context.CancelCauseFunc) (touch func(), stop func()) {
+	if idleTimeout <= 0 || cancel == nil {
+		return func() {}, func() {}
+	}
+
+	var lastActivity atomic.Int64
+	lastActivity.Store(time.Now().UnixNano())
+	done := make(chan struct{})
+	var stopOnce atomic.Bool
+
+	checkInterval := idleTimeout / 4
+	if checkInterval > 30*time.Second {
+		checkInterval = 30 * time.Second
+	}
+	if checkInterval < time.Second {
+		checkInterval = time.Second
+	}
+
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				idle := time.Since(time.Unix(0, lastActivity.Load()))
+				if idle >= idleTimeout {
+					cancel(ErrTaskIdleTimeout)
+					return
+				}
+			}
+		}
+	}()
+
+	touch = func() {
+		lastActivity.Store(time.Now().UnixNano())
+	}
+	stop = func() {
+		if stopOnce.CompareAndSwap(false, true) {
+			close(done)
+		}
+	}
+	return touch, stop
+}
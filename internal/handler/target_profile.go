@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// targetProfile 会话目标档案（工作记忆）：工具执行中确认的目标主机与开放端口/服务。
+// 存 DB 跨轮持久，每轮作为结构化 system 上下文注入，不随对话历史裁剪丢失。
+type targetProfile struct {
+	Hosts []targetProfileHost `json:"hosts,omitempty"`
+}
+
+type targetProfileHost struct {
+	Host  string              `json:"host"`
+	Ports []targetProfilePort `json:"ports,omitempty"`
+}
+
+type targetProfilePort struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Service  string `json:"service,omitempty"`
+}
+
+// targetProfileFinding 单条提取结果：host 上发现 port/protocol 开放（service 可为空）。
+type targetProfileFinding struct {
+	Host     string
+	Port     int
+	Protocol string
+	Service  string
+}
+
+const (
+	targetProfileMaxHosts        = 50
+	targetProfileMaxPortsPerHost = 200
+)
+
+var (
+	// nmap 表格行：`80/tcp open http`（service 列可缺失）
+	targetProfilePortLineRe = regexp.MustCompile(`^(\d{1,5})/(tcp|udp)\s+open(?:\s+(\S+))?`)
+	// nmap -v 行：`Discovered open port 3306/tcp on 10.0.0.5`
+	targetProfileDiscoveredRe = regexp.MustCompile(`Discovered open port (\d{1,5})/(tcp|udp) on (\S+)`)
+	// nmap 报告头：`Nmap scan report for example.com (10.0.0.5)`，括号内 IP 优先
+	targetProfileScanReportRe = regexp.MustCompile(`Nmap scan report for (\S+)(?:\s+\(([^)]+)\))?`)
+)
+
+// targetProfileHostFromArguments 从工具调用参数中提取目标主机（target/host/ip/url/domain）。
+func targetProfileHostFromArguments(args map[string]interface{}) string {
+	if args == nil {
+		return ""
+	}
+	for _, key := range []string{"target", "host", "ip", "url", "domain"} {
+		v, ok := args[key].(string)
+		if !ok {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if strings.Contains(v, "://") {
+			if u, err := url.Parse(v); err == nil && u.Hostname() != "" {
+				return u.Hostname()
+			}
+			continue
+		}
+		// 去掉附带端口（host:port），IPv6 字面量除外
+		if host, _, found := strings.Cut(v, ":"); found && !strings.Contains(v, "::") && strings.Count(v, ":") == 1 {
+			v = host
+		}
+		return v
+	}
+	return ""
+}
+
+// extractTargetProfileFindings 从工具结果文本中提取开放端口。
+// argHost 作为初始归属主机；`Nmap scan report for` 行出现后切换归属。
+func extractTargetProfileFindings(argHost, resultText string) []targetProfileFinding {
+	if strings.TrimSpace(resultText) == "" {
+		return nil
+	}
+	var findings []targetProfileFinding
+	currentHost := strings.TrimSpace(argHost)
+	for _, line := range strings.Split(resultText, "\n") {
+		line = strings.TrimSpace(line)
+		if m := targetProfileScanReportRe.FindStringSubmatch(line); m != nil {
+			if m[2] != "" {
+				currentHost = m[2]
+			} else {
+				currentHost = m[1]
+			}
+			continue
+		}
+		if m := targetProfileDiscoveredRe.FindStringSubmatch(line); m != nil {
+			if port, err := strconv.Atoi(m[1]); err == nil && port > 0 && port <= 65535 {
+				findings = append(findings, targetProfileFinding{Host: m[3], Port: port, Protocol: m[2]})
+			}
+			continue
+		}
+		if m := targetProfilePortLineRe.FindStringSubmatch(line); m != nil && currentHost != "" {
+			if port, err := strconv.Atoi(m[1]); err == nil && port > 0 && port <= 65535 {
+				findings = append(findings, targetProfileFinding{Host: currentHost, Port: port, Protocol: m[2], Service: m[3]})
+			}
+		}
+	}
+	return findings
+}
+
+// mergeTargetProfileFindings 将提取结果合并进档案；返回是否有实际变更。
+// 端口按 host+port+protocol 去重；service 仅在原值为空时补全。
+func mergeTargetProfileFindings(p *targetProfile, findings []targetProfileFinding) bool {
+	changed := false
+	for _, f := range findings {
+		host := strings.TrimSpace(f.Host)
+		if host == "" {
+			continue
+		}
+		var entry *targetProfileHost
+		for i := range p.Hosts {
+			if strings.EqualFold(p.Hosts[i].Host, host) {
+				entry = &p.Hosts[i]
+				break
+			}
+		}
+		if entry == nil {
+			if len(p.Hosts) >= targetProfileMaxHosts {
+				continue
+			}
+			p.Hosts = append(p.Hosts, targetProfileHost{Host: host})
+			entry = &p.Hosts[len(p.Hosts)-1]
+			changed = true
+		}
+		exists := false
+		for i := range entry.Ports {
+			if entry.Ports[i].Port == f.Port && entry.Ports[i].Protocol == f.Protocol {
+				exists = true
+				if entry.Ports[i].Service == "" && f.Service != "" {
+					entry.Ports[i].Service = f.Service
+					changed = true
+				}
+				break
+			}
+		}
+		if !exists && len(entry.Ports) < targetProfileMaxPortsPerHost {
+			entry.Ports = append(entry.Ports, targetProfilePort{Port: f.Port, Protocol: f.Protocol, Service: f.Service})
+			changed = true
+		}
+	}
+	if changed {
+		for i := range p.Hosts {
+			sort.Slice(p.Hosts[i].Ports, func(a, b int) bool { return p.Hosts[i].Ports[a].Port < p.Hosts[i].Ports[b].Port })
+		}
+	}
+	return changed
+}
+
+// renderTargetProfileBlock 渲染注入块；空档案返回空串。
+func renderTargetProfileBlock(p *targetProfile) string {
+	if p == nil || len(p.Hosts) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## 会话目标档案（自动维护）\n")
+	b.WriteString("以下为本会话工具执行中确认的目标与开放端口，跨轮持久、不随上下文裁剪丢失；规划后续测试时直接引用，无需重复扫描：\n")
+	for _, h := range p.Hosts {
+		b.WriteString("- " + h.Host + "\n")
+		for _, port := range h.Ports {
+			if port.Service != "" {
+				b.WriteString(fmt.Sprintf("  - %d/%s open %s\n", port.Port, port.Protocol, port.Service))
+			} else {
+				b.WriteString(fmt.Sprintf("  - %d/%s open\n", port.Port, port.Protocol))
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// updateTargetProfileFromToolResult 从 tool_result 事件中提取目标信息并合并持久化（无发现时不写库）。
+func (h *AgentHandler) updateTargetProfileFromToolResult(conversationID string, dataMap map[string]interface{}) {
+	if h == nil || h.db == nil || strings.TrimSpace(conversationID) == "" || dataMap == nil {
+		return
+	}
+	resultText, _ := dataMap["result"].(string)
+	argsObj, _ := dataMap["argumentsObj"].(map[string]interface{})
+	findings := extractTargetProfileFindings(targetProfileHostFromArguments(argsObj), resultText)
+	if len(findings) == 0 {
+		return
+	}
+	profile := &targetProfile{}
+	if raw, err := h.db.GetConversationTargetProfileJSON(conversationID); err == nil && raw != "" {
+		// 解析失败按空档案重建（旧格式或损坏数据不阻塞更新）
+		_ = json.Unmarshal([]byte(raw), profile)
+	}
+	if !mergeTargetProfileFindings(profile, findings) {
+		return
+	}
+	raw, err := json.Marshal(profile)
+	if err != nil {
+		return
+	}
+	if err := h.db.SaveConversationTargetProfileJSON(conversationID, string(raw)); err != nil {
+		h.logger.Warn("保存会话目标档案失败", zap.String("conversationId", conversationID), zap.Error(err))
+	}
+}
+
+// targetProfileBlock 构建会话目标档案注入块；无档案或查询失败时返回空串。
+func (h *AgentHandler) targetProfileBlock(conversationID string) string {
+	if h == nil || h.db == nil {
+		return ""
+	}
+	raw, err := h.db.GetConversationTargetProfileJSON(conversationID)
+	if err != nil || raw == "" {
+		return ""
+	}
+	profile := &targetProfile{}
+	if err := json.Unmarshal([]byte(raw), profile); err != nil {
+		return ""
+	}
+	return renderTargetProfileBlock(profile)
+}
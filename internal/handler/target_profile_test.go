@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractTargetProfileFindingsNmapTable(t *testing.T) {
+	result := "Nmap scan report for example.com (10.0.0.5)\n" +
+		"Host is up (0.012s latency).\n" +
+		"PORT     STATE SERVICE\n" +
+		"22/tcp   open  ssh\n" +
+		"80/tcp   open  http\n" +
+		"443/tcp  closed https\n"
+	findings := extractTargetProfileFindings("", result)
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings: %#v", len(findings), findings)
+	}
+	if findings[0].Host != "10.0.0.5" || findings[0].Port != 22 || findings[0].Service != "ssh" {
+		t.Fatalf("first finding: %#v", findings[0])
+	}
+	if findings[1].Port != 80 || findings[1].Protocol != "tcp" {
+		t.Fatalf("second finding: %#v", findings[1])
+	}
+}
+
+func TestExtractTargetProfileFindingsDiscoveredAndArgHost(t *testing.T) {
+	findings := extractTargetProfileFindings("192.168.1.10", "Discovered open port 3306/tcp on 192.168.1.20\n3389/tcp open ms-wbt-server\n")
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings: %#v", len(findings), findings)
+	}
+	if findings[0].Host != "192.168.1.20" || findings[0].Port != 3306 {
+		t.Fatalf("discovered line: %#v", findings[0])
+	}
+	if findings[1].Host != "192.168.1.10" || findings[1].Port != 3389 {
+		t.Fatalf("arg host fallback: %#v", findings[1])
+	}
+}
+
+func TestTargetProfileHostFromArguments(t *testing.T) {
+	if got := targetProfileHostFromArguments(map[string]interface{}{"target": "10.0.0.1"}); got != "10.0.0.1" {
+		t.Fatalf("target: %q", got)
+	}
+	if got := targetProfileHostFromArguments(map[string]interface{}{"url": "https://example.com:8443/login"}); got != "example.com" {
+		t.Fatalf("url: %q", got)
+	}
+	if got := targetProfileHostFromArguments(map[string]interface{}{"host": "10.0.0.2:8080"}); got != "10.0.0.2" {
+		t.Fatalf("host:port: %q", got)
+	}
+	if got := targetProfileHostFromArguments(nil); got != "" {
+		t.Fatalf("nil args: %q", got)
+	}
+}
+
+func TestMergeTargetProfileFindings(t *testing.T) {
+	p := &targetProfile{}
+	changed := mergeTargetProfileFindings(p, []targetProfileFinding{
+		{Host: "10.0.0.5", Port: 80, Protocol: "tcp", Service: "http"},
+		{Host: "10.0.0.5", Port: 22, Protocol: "tcp"},
+	})
+	if !changed || len(p.Hosts) != 1 || len(p.Hosts[0].Ports) != 2 {
+		t.Fatalf("initial merge: %#v", p)
+	}
+	if p.Hosts[0].Ports[0].Port != 22 {
+		t.Fatalf("端口应按升序排列: %#v", p.Hosts[0].Ports)
+	}
+	// 重复发现不产生变更；service 空值可被补全
+	if mergeTargetProfileFindings(p, []targetProfileFinding{{Host: "10.0.0.5", Port: 80, Protocol: "tcp"}}) {
+		t.Fatal("重复端口不应视为变更")
+	}
+	if !mergeTargetProfileFindings(p, []targetProfileFinding{{Host: "10.0.0.5", Port: 22, Protocol: "tcp", Service: "ssh"}}) {
+		t.Fatal("补全 service 应视为变更")
+	}
+	if p.Hosts[0].Ports[0].Service != "ssh" {
+		t.Fatalf("service 未补全: %#v", p.Hosts[0].Ports[0])
+	}
+}
+
+func TestRenderTargetProfileBlock(t *testing.T) {
+	if renderTargetProfileBlock(nil) != "" || renderTargetProfileBlock(&targetProfile{}) != "" {
+		t.Fatal("空档案应返回空串")
+	}
+	p := &targetProfile{Hosts: []targetProfileHost{{
+		Host:  "10.0.0.5",
+		Ports: []targetProfilePort{{Port: 22, Protocol: "tcp", Service: "ssh"}, {Port: 8080, Protocol: "tcp"}},
+	}}}
+	block := renderTargetProfileBlock(p)
+	if !strings.Contains(block, "## 会话目标档案") || !strings.Contains(block, "22/tcp open ssh") || !strings.Contains(block, "8080/tcp open") {
+		t.Fatalf("block:\n%s", block)
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cyberstrike-ai/internal/database"
+	"cyberstrike-ai/internal/security"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TargetViewHandler 按目标聚合的资产视图：汇总同一目标主机的执行记录、
+// 端口/服务、漏洞与所属会话，避免渗透过程中信息散落在各条执行记录里。
+type TargetViewHandler struct {
+	db     *database.DB
+	logger *zap.Logger
+}
+
+// NewTargetViewHandler 创建目标视图处理器
+func NewTargetViewHandler(db *database.DB, logger *zap.Logger) *TargetViewHandler {
+	return &TargetViewHandler{db: db, logger: logger}
+}
+
+// GetTarget 获取指定目标主机的聚合视图
+// GET /api/targets/:host（host 支持 IP、域名或 URL，按归一后的主机标识匹配）
+func (h *TargetViewHandler) GetTarget(c *gin.Context) {
+	host := database.NormalizeTargetHost(c.Param("host"))
+	if host == "" {
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, "目标主机格式非法（支持 IP、域名或 URL）")
+		return
+	}
+
+	view, err := h.db.GetTargetView(host, targetViewAccessFromContext(c))
+	if err != nil {
+		h.logger.Error("获取目标聚合视图失败", zap.String("host", host), zap.Error(err))
+		RespondError(c, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// GetTargetDiff 对比同一 (tool+target) 最近两次执行结果，返回端口/服务与漏洞的变更摘要
+// GET /api/targets/:host/diff?tool=nmap
+func (h *TargetViewHandler) GetTargetDiff(c *gin.Context) {
+	host := database.NormalizeTargetHost(c.Param("host"))
+	if host == "" {
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, "目标主机格式非法（支持 IP、域名或 URL）")
+		return
+	}
+	toolName := strings.TrimSpace(c.Query("tool"))
+	if toolName == "" {
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, "缺少 tool 参数")
+		return
+	}
+
+	diff, err := h.db.GetTargetExecutionDiff(host, toolName, targetViewAccessFromContext(c))
+	if errors.Is(err, database.ErrInsufficientExecutionsForDiff) {
+		RespondError(c, http.StatusNotFound, ErrCodeNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("目标执行结果对比失败", zap.String("host", host), zap.String("tool", toolName), zap.Error(err))
+		RespondError(c, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+func targetViewAccessFromContext(c *gin.Context) database.RBACListAccess {
+	session, ok := security.CurrentSession(c)
+	if !ok {
+		return database.RBACListAccess{}
+	}
+	return database.RBACListAccess{UserID: session.UserID, Scope: session.Scope}
+}
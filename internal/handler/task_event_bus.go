@@ -1,14 +1,38 @@
 package handler
 
-import "sync"
+import (
+	"bytes"
+	"strconv"
+	"sync"
+)
 
 // TaskEventBus 将主 SSE 连接上的事件镜像给后订阅的客户端（例如刷新页面后、HITL 审批通过需继续收事件）。
-// 每个 payload 为完整 SSE 行： "data: {...}\n\n"
+// 每个事件分配会话内单调递增的序号并写入 SSE 的 id: 字段，完整帧格式为：
+//
+//	id: 42\ndata: {...}\n\n
+//
+// 最近的帧保留在环形缓冲里，断线客户端带 Last-Event-ID 重新订阅时可从该序号之后回放缺失事件。
 type TaskEventBus struct {
-	mu   sync.RWMutex
-	subs map[string]map[*taskEventSub]struct{}
+	mu      sync.RWMutex
+	subs    map[string]map[*taskEventSub]struct{}
+	streams map[string]*taskEventStream
 }
 
+// taskEventStream 单会话的序号计数与回放缓冲。
+type taskEventStream struct {
+	nextSeq uint64
+	buf     []taskEventFrame
+}
+
+type taskEventFrame struct {
+	seq  uint64
+	line []byte
+}
+
+// taskEventReplayBuffer 每会话保留的可回放帧数；不得超过订阅 channel 缓冲（256），
+// 否则原子回放时可能丢帧。
+const taskEventReplayBuffer = 256
+
 type taskEventSub struct {
 	mu     sync.Mutex
 	ch     chan []byte
@@ -47,15 +71,35 @@ func (s *taskEventSub) closeOnce() {
 
 func NewTaskEventBus() *TaskEventBus {
 	return &TaskEventBus{
-		subs: make(map[string]map[*taskEventSub]struct{}),
+		subs:    make(map[string]map[*taskEventSub]struct{}),
+		streams: make(map[string]*taskEventStream),
 	}
 }
 
-// Subscribe 注册订阅；cancel 时需调用 Unsubscribe。
+// Subscribe 注册订阅（只收后续事件）；cancel 时需调用 Unsubscribe。
 func (b *TaskEventBus) Subscribe(conversationID string) (sub *taskEventSub, ch <-chan []byte) {
+	return b.subscribe(conversationID, false, 0)
+}
+
+// SubscribeSince 注册订阅并回放缓冲里序号大于 afterSeq 的帧（客户端带 Last-Event-ID 重连用）。
+// 回放与注册在同一把锁内完成，不会漏掉回放与实时之间的事件。
+func (b *TaskEventBus) SubscribeSince(conversationID string, afterSeq uint64) (sub *taskEventSub, ch <-chan []byte) {
+	return b.subscribe(conversationID, true, afterSeq)
+}
+
+func (b *TaskEventBus) subscribe(conversationID string, replay bool, afterSeq uint64) (sub *taskEventSub, ch <-chan []byte) {
 	chBuf := make(chan []byte, 256)
 	sub = &taskEventSub{ch: chBuf}
 	b.mu.Lock()
+	if replay {
+		if st := b.streams[conversationID]; st != nil {
+			for _, f := range st.buf {
+				if f.seq > afterSeq {
+					chBuf <- f.line
+				}
+			}
+		}
+	}
 	if b.subs[conversationID] == nil {
 		b.subs[conversationID] = make(map[*taskEventSub]struct{})
 	}
@@ -82,26 +126,43 @@ func (b *TaskEventBus) Unsubscribe(conversationID string, sub *taskEventSub) {
 	sub.closeOnce()
 }
 
-// Publish 非阻塞投递；慢消费者丢帧（HITL 场景以最新状态为准，丢帧可接受）。
-func (b *TaskEventBus) Publish(conversationID string, line []byte) {
+// Publish 为事件分配序号、写入回放缓冲并非阻塞投递；慢消费者丢帧（HITL 场景以最新状态为准，
+// 丢帧可凭序号空洞被客户端察觉并重放）。line 为 "data: {...}\n\n" 帧；返回补上 id: 行的完整帧，
+// 主 SSE 连接直接写该返回值，保证主流与镜像流序号一致。
+func (b *TaskEventBus) Publish(conversationID string, line []byte) []byte {
 	if b == nil || conversationID == "" || len(line) == 0 {
-		return
+		return line
+	}
+	b.mu.Lock()
+	st := b.streams[conversationID]
+	if st == nil {
+		st = &taskEventStream{}
+		b.streams[conversationID] = st
+	}
+	st.nextSeq++
+	framed := make([]byte, 0, len(line)+16)
+	framed = append(framed, "id: "...)
+	framed = strconv.AppendUint(framed, st.nextSeq, 10)
+	framed = append(framed, '\n')
+	framed = append(framed, line...)
+	if len(st.buf) >= taskEventReplayBuffer {
+		st.buf = append(st.buf[:0], st.buf[1:]...)
 	}
-	b.mu.RLock()
+	st.buf = append(st.buf, taskEventFrame{seq: st.nextSeq, line: framed})
 	m := b.subs[conversationID]
 	subs := make([]*taskEventSub, 0, len(m))
 	for s := range m {
 		subs = append(subs, s)
 	}
-	b.mu.RUnlock()
+	b.mu.Unlock()
 
-	cp := append([]byte(nil), line...)
 	for _, s := range subs {
-		s.sendNonBlocking(cp)
+		s.sendNonBlocking(framed)
 	}
+	return framed
 }
 
-// CloseConversation 任务结束时关闭该会话所有订阅 channel。
+// CloseConversation 任务结束时关闭该会话所有订阅 channel 并清空回放缓冲。
 func (b *TaskEventBus) CloseConversation(conversationID string) {
 	if b == nil || conversationID == "" {
 		return
@@ -109,8 +170,22 @@ func (b *TaskEventBus) CloseConversation(conversationID string) {
 	b.mu.Lock()
 	m := b.subs[conversationID]
 	delete(b.subs, conversationID)
+	delete(b.streams, conversationID)
 	b.mu.Unlock()
 	for sub := range m {
 		sub.closeOnce()
 	}
 }
+
+// taskEventPayload 从 SSE 帧中取出 JSON 负载（剥掉可选的 id: 行与 data: 前缀），WebSocket 转发用。
+func taskEventPayload(chunk []byte) []byte {
+	trimmed := bytes.TrimSpace(chunk)
+	if bytes.HasPrefix(trimmed, []byte("id:")) {
+		i := bytes.IndexByte(trimmed, '\n')
+		if i < 0 {
+			return nil
+		}
+		trimmed = bytes.TrimSpace(trimmed[i+1:])
+	}
+	return bytes.TrimSpace(bytes.TrimPrefix(trimmed, []byte("data: ")))
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func taskEventFrameFor(t *testing.T, ch <-chan []byte) []byte {
+	t.Helper()
+	select {
+	case line := <-ch:
+		return line
+	default:
+		t.Fatal("expected a buffered frame")
+		return nil
+	}
+}
+
+func TestTaskEventBusAssignsSequentialIDs(t *testing.T) {
+	bus := NewTaskEventBus()
+	sub, ch := bus.Subscribe("c1")
+	defer bus.Unsubscribe("c1", sub)
+
+	payload := []byte("data: {\"type\":\"progress\"}\n\n")
+	for i := 1; i <= 3; i++ {
+		framed := bus.Publish("c1", payload)
+		wantPrefix := []byte(fmt.Sprintf("id: %d\ndata: ", i))
+		if !bytes.HasPrefix(framed, wantPrefix) {
+			t.Fatalf("framed = %q, want prefix %q", framed, wantPrefix)
+		}
+		if got := taskEventFrameFor(t, ch); !bytes.Equal(got, framed) {
+			t.Fatalf("镜像流与主流帧不一致: %q vs %q", got, framed)
+		}
+	}
+}
+
+func TestTaskEventBusReplaySince(t *testing.T) {
+	bus := NewTaskEventBus()
+	for i := 1; i <= 3; i++ {
+		bus.Publish("c1", []byte(fmt.Sprintf("data: {\"n\":%d}\n\n", i)))
+	}
+
+	sub, ch := bus.SubscribeSince("c1", 1)
+	defer bus.Unsubscribe("c1", sub)
+	for _, want := range []string{"id: 2\n", "id: 3\n"} {
+		if got := taskEventFrameFor(t, ch); !bytes.HasPrefix(got, []byte(want)) {
+			t.Fatalf("replay frame = %q, want prefix %q", got, want)
+		}
+	}
+	bus.Publish("c1", []byte("data: {\"n\":4}\n\n"))
+	if got := taskEventFrameFor(t, ch); !bytes.HasPrefix(got, []byte("id: 4\n")) {
+		t.Fatalf("live frame = %q, want id 4", got)
+	}
+
+	// 任务结束后缓冲清空，重订阅不应回放旧帧
+	bus.CloseConversation("c1")
+	sub2, ch2 := bus.SubscribeSince("c1", 0)
+	defer bus.Unsubscribe("c1", sub2)
+	select {
+	case line := <-ch2:
+		t.Fatalf("closed conversation replayed %q", line)
+	default:
+	}
+}
+
+func TestTaskEventPayload(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"data: {\"type\":\"done\"}\n\n", "{\"type\":\"done\"}"},
+		{"id: 7\ndata: {\"type\":\"progress\"}\n\n", "{\"type\":\"progress\"}"},
+		{"  \n", ""},
+	}
+	for _, tc := range cases {
+		if got := string(taskEventPayload([]byte(tc.in))); got != tc.want {
+			t.Fatalf("taskEventPayload(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
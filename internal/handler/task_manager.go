@@ -13,6 +13,10 @@ import (
 // ErrTaskCancelled 用户取消任务的错误
 var ErrTaskCancelled = errors.New("agent task cancelled by user")
 
+// ErrTaskResourceLimit 系统因资源/并发限流取消任务的错误（区别于用户取消，前端按 errorType=resource_limit 提示）。
+// 取消原因经 CancelTask cause → context.Cause 传递；空闲超时见 [ErrTaskIdleTimeout]。
+var ErrTaskResourceLimit = errors.New("agent task cancelled by resource limit")
+
 // ErrTaskAlreadyRunning 会话已有任务正在执行
 var ErrTaskAlreadyRunning = errors.New("agent task already running for conversation")
 
@@ -402,8 +406,9 @@ func (m *AgentTaskManager) CancelTask(conversationID string, cause error) (bool,
 	if cause == nil {
 		cause = ErrTaskCancelled
 	}
+	// 用户取消与系统取消（限流/空闲超时）均需掐断正在运行的工具；仅「中断并继续」保留工具由处理器接管。
 	var toolCanceler func(string)
-	if errors.Is(cause, ErrTaskCancelled) {
+	if errors.Is(cause, ErrTaskCancelled) || errors.Is(cause, ErrTaskResourceLimit) || errors.Is(cause, ErrTaskIdleTimeout) {
 		toolCanceler = m.toolCanceler
 	}
 	m.mu.Unlock()
@@ -34,8 +34,8 @@ func maskTerminalCommand(cmd string) string {
 	if strings.Contains(lower, "sudo") || strings.Contains(lower, "password") {
 		return "[masked sensitive terminal command]"
 	}
-	if len(trimmed) > 256 {
-		return trimmed[:256] + "..."
+	if t := safeTruncateString(trimmed, 256); t != trimmed {
+		return t + "..."
 	}
 	return trimmed
 }
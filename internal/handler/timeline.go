@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"cyberstrike-ai/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TimelineStep 时间线中的一个执行步骤（由 process_details 组织而来）。
+// ParentStep 指向上级步骤的 stepIndex：tool_result 挂在对应 tool_call 下，
+// 其余事件挂在所属迭代（iteration）下；顶层步骤为 null。
+type TimelineStep struct {
+	StepIndex  int       `json:"stepIndex"`
+	MessageID  string    `json:"messageId"`
+	EventType  string    `json:"eventType"`
+	Title      string    `json:"title"`
+	Tool       string    `json:"tool,omitempty"`
+	StartTime  time.Time `json:"startTime"`
+	DurationMs int64     `json:"durationMs"`
+	ParentStep *int      `json:"parentStep"`
+}
+
+// GetConversationTimeline GET /api/conversations/:id/timeline
+// 把零散的 process_details 组织成结构化时间线（分析→工具调用→结果→总结），
+// 每步带 stepIndex/startTime/durationMs/parentStep，前端无需自己拼接。
+func (h *ConversationHandler) GetConversationTimeline(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		RespondError(c, http.StatusBadRequest, ErrCodeBadRequest, "conversation id required")
+		return
+	}
+	if _, err := h.db.GetConversationLite(id); err != nil {
+		RespondError(c, http.StatusNotFound, ErrCodeNotFound, "对话不存在")
+		return
+	}
+
+	details, err := h.db.ListProcessDetailsByConversation(id)
+	if err != nil {
+		h.logger.Error("获取过程详情失败", zap.Error(err))
+		RespondError(c, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	steps := buildConversationTimeline(details)
+	c.JSON(http.StatusOK, gin.H{
+		"conversationId": id,
+		"steps":          steps,
+		"total":          len(steps),
+	})
+}
+
+// buildConversationTimeline 按到达顺序组织时间线：
+//   - tool_call 的 durationMs 由与之配对的 tool_result 时间差得出（优先按工具名配对，
+//     名字缺失时取最近一个未配对的 tool_call）；
+//   - 其余步骤的 durationMs 为到下一步骤的间隔（最后一步为 0）；
+//   - iteration 事件作为顶层分组，其后的事件挂在它下面。
+func buildConversationTimeline(details []database.ProcessDetail) []TimelineStep {
+	steps := make([]TimelineStep, 0, len(details))
+	var lastIteration *int
+	// 未配对的 tool_call：stepIndex 栈，按工具名分桶
+	pendingCalls := make(map[string][]int)
+
+	for _, d := range details {
+		idx := len(steps)
+		step := TimelineStep{
+			StepIndex: idx,
+			MessageID: d.MessageID,
+			EventType: d.EventType,
+			Title:     d.Message,
+			Tool:      timelineToolName(d.Data),
+			StartTime: d.CreatedAt,
+		}
+
+		switch d.EventType {
+		case "iteration":
+			iterIdx := idx
+			lastIteration = &iterIdx
+		case "tool_call":
+			if lastIteration != nil {
+				parent := *lastIteration
+				step.ParentStep = &parent
+			}
+			pendingCalls[step.Tool] = append(pendingCalls[step.Tool], idx)
+		case "tool_result":
+			if callIdx, ok := popPendingToolCall(pendingCalls, step.Tool); ok {
+				parent := callIdx
+				step.ParentStep = &parent
+				steps[callIdx].DurationMs = durationMsBetween(steps[callIdx].StartTime, d.CreatedAt)
+			} else if lastIteration != nil {
+				parent := *lastIteration
+				step.ParentStep = &parent
+			}
+		default:
+			if lastIteration != nil {
+				parent := *lastIteration
+				step.ParentStep = &parent
+			}
+		}
+
+		steps = append(steps, step)
+	}
+
+	// 未被配对赋值的步骤：durationMs 取到下一步骤的间隔
+	for i := range steps {
+		if steps[i].DurationMs != 0 || i+1 >= len(steps) {
+			continue
+		}
+		steps[i].DurationMs = durationMsBetween(steps[i].StartTime, steps[i+1].StartTime)
+	}
+	return steps
+}
+
+// timelineToolName 从事件 data JSON 中提取工具名（tool_call/tool_result 事件）。
+func timelineToolName(data string) string {
+	if strings.TrimSpace(data) == "" {
+		return ""
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &parsed); err != nil {
+		return ""
+	}
+	for _, key := range []string{"name", "tool", "toolName"} {
+		if v, ok := parsed[key].(string); ok && strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// popPendingToolCall 取出与结果配对的 tool_call：优先同名桶，其次任意桶中最近的一个。
+func popPendingToolCall(pending map[string][]int, tool string) (int, bool) {
+	if stack := pending[tool]; len(stack) > 0 {
+		idx := stack[len(stack)-1]
+		pending[tool] = stack[:len(stack)-1]
+		return idx, true
+	}
+	// 工具名缺失或不一致时，取全部未配对调用中最近的一个
+	best := -1
+	bestKey := ""
+	for key, stack := range pending {
+		if len(stack) == 0 {
+			continue
+		}
+		if idx := stack[len(stack)-1]; idx > best {
+			best = idx
+			bestKey = key
+		}
+	}
+	if best < 0 {
+		return 0, false
+	}
+	stack := pending[bestKey]
+	pending[bestKey] = stack[:len(stack)-1]
+	return best, true
+}
+
+func durationMsBetween(from, to time.Time) int64 {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+	return to.Sub(from).Milliseconds()
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"cyberstrike-ai/internal/database"
+)
+
+func TestBuildConversationTimeline(t *testing.T) {
+	base := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	details := []database.ProcessDetail{
+		{MessageID: "m1", EventType: "iteration", Message: "第 1 轮", CreatedAt: base},
+		{MessageID: "m1", EventType: "thinking", Message: "分析目标", CreatedAt: base.Add(100 * time.Millisecond)},
+		{MessageID: "m1", EventType: "tool_call", Message: "调用 nmap", Data: `{"name":"nmap_scan"}`, CreatedAt: base.Add(200 * time.Millisecond)},
+		{MessageID: "m1", EventType: "tool_result", Message: "扫描完成", Data: `{"name":"nmap_scan"}`, CreatedAt: base.Add(3200 * time.Millisecond)},
+		{MessageID: "m1", EventType: "tool_call", Message: "调用 nikto", Data: `{"name":"nikto"}`, CreatedAt: base.Add(3300 * time.Millisecond)},
+		{MessageID: "m1", EventType: "tool_result", Message: "nikto 完成", Data: `{"name":"nikto"}`, CreatedAt: base.Add(5300 * time.Millisecond)},
+		{MessageID: "m1", EventType: "progress", Message: "总结", CreatedAt: base.Add(5400 * time.Millisecond)},
+	}
+
+	steps := buildConversationTimeline(details)
+	if len(steps) != 7 {
+		t.Fatalf("steps = %d, want 7", len(steps))
+	}
+
+	// stepIndex 连续递增，startTime 跟随事件时间
+	for i, s := range steps {
+		if s.StepIndex != i {
+			t.Fatalf("steps[%d].StepIndex = %d", i, s.StepIndex)
+		}
+	}
+
+	// iteration 是顶层，后续事件挂在其下
+	if steps[0].ParentStep != nil {
+		t.Fatalf("iteration 应为顶层: %+v", steps[0])
+	}
+	if steps[1].ParentStep == nil || *steps[1].ParentStep != 0 {
+		t.Fatalf("thinking 应挂在 iteration 下: %+v", steps[1])
+	}
+
+	// tool_call 时长由配对的 tool_result 得出，tool_result 挂在 tool_call 下
+	if steps[2].Tool != "nmap_scan" || steps[2].DurationMs != 3000 {
+		t.Fatalf("nmap tool_call 配对时长错误: %+v", steps[2])
+	}
+	if steps[3].ParentStep == nil || *steps[3].ParentStep != 2 {
+		t.Fatalf("nmap tool_result 应挂在 tool_call 下: %+v", steps[3])
+	}
+	if steps[4].DurationMs != 2000 {
+		t.Fatalf("nikto tool_call 配对时长错误: %+v", steps[4])
+	}
+
+	// 最后一步时长为 0，其余无配对步骤取到下一步的间隔
+	if steps[1].DurationMs != 100 {
+		t.Fatalf("thinking 间隔时长错误: %+v", steps[1])
+	}
+	if steps[6].DurationMs != 0 {
+		t.Fatalf("最后一步时长应为 0: %+v", steps[6])
+	}
+}
+
+func TestBuildConversationTimelineUnnamedResultPairing(t *testing.T) {
+	base := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	details := []database.ProcessDetail{
+		{MessageID: "m1", EventType: "tool_call", Message: "调用 exec", Data: `{"name":"exec"}`, CreatedAt: base},
+		{MessageID: "m1", EventType: "tool_result", Message: "完成", CreatedAt: base.Add(500 * time.Millisecond)},
+	}
+	steps := buildConversationTimeline(details)
+	if len(steps) != 2 {
+		t.Fatalf("steps = %d, want 2", len(steps))
+	}
+	// 结果缺工具名时按最近未配对的 tool_call 兜底
+	if steps[1].ParentStep == nil || *steps[1].ParentStep != 0 {
+		t.Fatalf("匿名 tool_result 未配对: %+v", steps[1])
+	}
+	if steps[0].DurationMs != 500 {
+		t.Fatalf("配对时长错误: %+v", steps[0])
+	}
+}
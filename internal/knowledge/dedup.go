@@ -0,0 +1,130 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// DuplicateCandidate 入库查重命中的已有知识项；相似度取该项所有块中的最大值。
+type DuplicateCandidate struct {
+	ItemID     string  `json:"itemId"`
+	Category   string  `json:"category"`
+	Title      string  `json:"title"`
+	Similarity float64 `json:"similarity"`
+}
+
+// dedupEmbedContentMaxRunes 查重只嵌入内容前缀：近似重复的资料开头基本一致，
+// 同时避免超长内容超出嵌入模型的输入上限。
+const dedupEmbedContentMaxRunes = 4000
+
+// DedupThreshold 返回配置的入库查重阈值（indexing.dedup_similarity_threshold）；<=0 表示关闭。
+func (idx *Indexer) DedupThreshold() float64 {
+	if idx == nil || idx.indexingCfg == nil || idx.indexingCfg.DedupSimilarityThreshold <= 0 {
+		return 0
+	}
+	return idx.indexingCfg.DedupSimilarityThreshold
+}
+
+// FindSimilarItems 对新内容做一次嵌入，与库内已有向量按余弦相似度比对，
+// 返回最高相似度达到 threshold 的已有项（按相似度降序，至多 limit 个）。
+// 维度或嵌入模型不一致的旧向量行跳过，与检索路径的口径一致。
+func (idx *Indexer) FindSimilarItems(ctx context.Context, category, title, content string, threshold float64, limit int) ([]*DuplicateCandidate, error) {
+	if idx.embedder == nil {
+		return nil, fmt.Errorf("嵌入器未初始化")
+	}
+	if threshold <= 0 {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 3
+	}
+
+	text := content
+	if runes := []rune(text); len(runes) > dedupEmbedContentMaxRunes {
+		text = string(runes[:dedupEmbedContentMaxRunes])
+	}
+	vec, err := idx.embedder.EmbedText(ctx, FormatEmbeddingInput(category, title, text))
+	if err != nil {
+		return nil, fmt.Errorf("查重嵌入失败：%w", err)
+	}
+	expectedModel := idx.embedder.EmbeddingModelName()
+
+	rows, err := idx.db.QueryContext(ctx, `
+		SELECT e.item_id, e.embedding, e.embedding_model, e.embedding_dim, i.category, i.title
+		FROM knowledge_embeddings e
+		JOIN knowledge_base_items i ON e.item_id = i.id`)
+	if err != nil {
+		return nil, fmt.Errorf("查询向量失败：%w", err)
+	}
+	defer rows.Close()
+
+	best := make(map[string]*DuplicateCandidate)
+	rowNum := 0
+	for rows.Next() {
+		rowNum++
+		if rowNum%48 == 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			default:
+			}
+		}
+
+		var itemID, embeddingJSON, rowModel, itemCategory, itemTitle string
+		var rowDim int
+		if err := rows.Scan(&itemID, &embeddingJSON, &rowModel, &rowDim, &itemCategory, &itemTitle); err != nil {
+			if idx.logger != nil {
+				idx.logger.Warn("查重扫描向量失败", zap.Error(err))
+			}
+			continue
+		}
+
+		var embedding []float32
+		if err := json.Unmarshal([]byte(embeddingJSON), &embedding); err != nil {
+			continue
+		}
+		if rowDim > 0 && len(embedding) != rowDim {
+			continue
+		}
+		if len(embedding) != len(vec) {
+			continue
+		}
+		if expectedModel != "" && strings.TrimSpace(rowModel) != "" && strings.TrimSpace(rowModel) != expectedModel {
+			continue
+		}
+
+		similarity := cosineSimilarity(vec, embedding)
+		if similarity < threshold {
+			continue
+		}
+		if existing, ok := best[itemID]; ok && existing.Similarity >= similarity {
+			continue
+		}
+		best[itemID] = &DuplicateCandidate{
+			ItemID:     itemID,
+			Category:   itemCategory,
+			Title:      itemTitle,
+			Similarity: similarity,
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("查重扫描向量失败：%w", err)
+	}
+
+	candidates := make([]*DuplicateCandidate, 0, len(best))
+	for _, c := range best {
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Similarity > candidates[j].Similarity
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
@@ -10,8 +10,11 @@ const (
 	metaKBCategory   = "kb_category"
 	metaKBTitle      = "kb_title"
 	metaKBItemID     = "kb_item_id"
+	metaKBNamespace  = "kb_namespace"
 	metaKBChunkIndex = "kb_chunk_index"
 	metaSimilarity   = "similarity"
+	// metaAppliedThreshold 向量检索实际生效的相似度阈值（adaptive 降阈值后可能低于配置值）。
+	metaAppliedThreshold = "kb_applied_threshold"
 )
 
 // DSL keys for [VectorEinoRetriever.Retrieve] via [retriever.WithDSLInfo].
@@ -19,6 +22,7 @@ const (
 	DSLRiskType            = "risk_type"
 	DSLSimilarityThreshold = "similarity_threshold"
 	DSLSubIndexFilter      = "sub_index_filter"
+	DSLNamespace           = "namespace"
 )
 
 // FormatEmbeddingInput matches the historical indexing format so existing embeddings
@@ -68,6 +68,9 @@ func (h *VectorEinoRetriever) Retrieve(ctx context.Context, query string, opts .
 		if sf, ok := ro.DSLInfo[DSLSubIndexFilter].(string); ok {
 			req.SubIndexFilter = strings.TrimSpace(sf)
 		}
+		if ns, ok := ro.DSLInfo[DSLNamespace].(string); ok {
+			req.Namespace = strings.TrimSpace(ns)
+		}
 	}
 	if req.SubIndexFilter == "" && cfg != nil && strings.TrimSpace(cfg.SubIndexFilter) != "" {
 		req.SubIndexFilter = strings.TrimSpace(cfg.SubIndexFilter)
@@ -130,6 +133,12 @@ func retrievalResultsToDocuments(results []*RetrievalResult) []*schema.Document
 				metaSimilarity:   res.Similarity,
 			},
 		}
+		if res.Item.Namespace != "" {
+			d.MetaData[metaKBNamespace] = res.Item.Namespace
+		}
+		if res.AppliedThreshold > 0 {
+			d.MetaData[metaAppliedThreshold] = res.AppliedThreshold
+		}
 		d.WithScore(res.Score)
 		out = append(out, d)
 	}
@@ -153,7 +162,9 @@ func documentsToRetrievalResults(docs []*schema.Document) ([]*RetrievalResult, e
 			return nil, fmt.Errorf("document %d: %w", i, err)
 		}
 		sim, _ := MetaFloat64OK(d.MetaData, metaSimilarity)
-		item := &KnowledgeItem{ID: itemID, Category: cat, Title: title}
+		appliedTh, _ := MetaFloat64OK(d.MetaData, metaAppliedThreshold)
+		ns := MetaLookupString(d.MetaData, metaKBNamespace)
+		item := &KnowledgeItem{ID: itemID, Namespace: ns, Category: cat, Title: title}
 		chunk := &KnowledgeChunk{
 			ID:         d.ID,
 			ItemID:     itemID,
@@ -161,10 +172,11 @@ func documentsToRetrievalResults(docs []*schema.Document) ([]*RetrievalResult, e
 			ChunkText:  d.Content,
 		}
 		out = append(out, &RetrievalResult{
-			Chunk:      chunk,
-			Item:       item,
-			Similarity: sim,
-			Score:      d.Score(),
+			Chunk:            chunk,
+			Item:             item,
+			Similarity:       sim,
+			Score:            d.Score(),
+			AppliedThreshold: appliedTh,
 		})
 	}
 	return out, nil
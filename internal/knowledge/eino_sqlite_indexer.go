@@ -34,7 +34,8 @@ func (s *SQLiteIndexer) GetType() string {
 }
 
 // Store embeds documents and inserts rows. Each doc must carry MetaData:
-// kb_item_id, kb_category, kb_title, kb_chunk_index (int). Content is chunk text only.
+// kb_item_id, kb_category, kb_title, kb_chunk_index (int)，可选 kb_namespace（多知识库隔离）。
+// Content is chunk text only.
 func (s *SQLiteIndexer) Store(ctx context.Context, docs []*schema.Document, opts ...indexer.Option) (ids []string, err error) {
 	options := indexer.GetCommonOptions(nil, opts...)
 	if options.Embedding == nil {
@@ -71,7 +72,11 @@ func (s *SQLiteIndexer) Store(ctx context.Context, docs []*schema.Document, opts
 		bs = 64
 	}
 
-	var allVecs [][]float64
+	// 嵌入失败的块对应位置留 nil：登记到 knowledge_failed_chunks 后继续写入其余块，
+	// 避免个别块嵌入失败导致整个知识项检索不到
+	allVecs := make([][]float64, len(texts))
+	embedFailures := 0
+	var firstEmbedErr error
 	for start := 0; start < len(texts); start += bs {
 		end := start + bs
 		if end > len(texts) {
@@ -80,17 +85,43 @@ func (s *SQLiteIndexer) Store(ctx context.Context, docs []*schema.Document, opts
 		batch := texts[start:end]
 		vecs, embedErr := options.Embedding.EmbedStrings(ctx, batch)
 		if embedErr != nil {
-			return nil, fmt.Errorf("sqlite indexer: embed batch %d-%d: %w", start, end, embedErr)
+			// 整批失败常由个别 input 触发（如内容策略拒绝），降级为逐条重试：
+			// 只有真正失败的块进 knowledge_failed_chunks，其余块照常索引
+			for j := range batch {
+				single, singleErr := options.Embedding.EmbedStrings(ctx, batch[j:j+1])
+				if singleErr == nil && len(single) == 1 {
+					allVecs[start+j] = single[0]
+					continue
+				}
+				if singleErr == nil {
+					singleErr = fmt.Errorf("embed count mismatch: got %d want 1", len(single))
+				}
+				if firstEmbedErr == nil {
+					firstEmbedErr = singleErr
+				}
+				embedFailures++
+				if recErr := s.recordFailedChunks(ctx, docs[start+j:start+j+1], subIdxStr, singleErr); recErr != nil {
+					return nil, fmt.Errorf("sqlite indexer: record failed chunk %d: %w", start+j, recErr)
+				}
+			}
+			continue
 		}
 		if len(vecs) != len(batch) {
 			return nil, fmt.Errorf("sqlite indexer: embed count mismatch: got %d want %d", len(vecs), len(batch))
 		}
-		allVecs = append(allVecs, vecs...)
+		copy(allVecs[start:end], vecs)
+	}
+	if embedFailures == len(texts) {
+		// 全部批次失败多为配置问题（模型/密钥/余额），保持硬失败让上层熔断
+		return nil, fmt.Errorf("sqlite indexer: embed all %d chunks failed: %w", len(texts), firstEmbedErr)
 	}
 
 	embedDim := 0
-	if len(allVecs) > 0 {
-		embedDim = len(allVecs[0])
+	for _, vec := range allVecs {
+		if vec != nil {
+			embedDim = len(vec)
+			break
+		}
 	}
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -101,6 +132,9 @@ func (s *SQLiteIndexer) Store(ctx context.Context, docs []*schema.Document, opts
 
 	ids = make([]string, 0, len(docs))
 	for i, d := range docs {
+		if allVecs[i] == nil {
+			continue
+		}
 		chunkID := uuid.New().String()
 		itemID, metaErr := RequireMetaString(d.MetaData, metaKBItemID)
 		if metaErr != nil {
@@ -122,10 +156,11 @@ func (s *SQLiteIndexer) Store(ctx context.Context, docs []*schema.Document, opts
 		if jsonErr != nil {
 			return nil, fmt.Errorf("sqlite indexer: marshal embedding: %w", jsonErr)
 		}
+		namespace := strings.TrimSpace(MetaLookupString(d.MetaData, metaKBNamespace))
 		_, err = tx.ExecContext(ctx,
-			`INSERT INTO knowledge_embeddings (id, item_id, chunk_index, chunk_text, embedding, sub_indexes, embedding_model, embedding_dim, created_at)
-			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))`,
-			chunkID, itemID, chunkIdx, d.Content, string(embeddingJSON), subIdxStr, s.embeddingModel, embedDim,
+			`INSERT INTO knowledge_embeddings (id, item_id, chunk_index, chunk_text, embedding, namespace, sub_indexes, embedding_model, embedding_dim, created_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))`,
+			chunkID, itemID, chunkIdx, d.Content, string(embeddingJSON), namespace, subIdxStr, s.embeddingModel, embedDim,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("sqlite indexer: insert chunk %d: %w", i, err)
@@ -139,4 +174,34 @@ func (s *SQLiteIndexer) Store(ctx context.Context, docs []*schema.Document, opts
 	return ids, nil
 }
 
+// recordFailedChunks 将嵌入失败批次的 chunk 写入 knowledge_failed_chunks（同一 item_id+chunk_index 覆盖旧记录），
+// 保留重试所需的文本与元数据，供 [Indexer.RetryFailedChunks] 单独补齐。
+func (s *SQLiteIndexer) recordFailedChunks(ctx context.Context, docs []*schema.Document, subIdxStr string, cause error) error {
+	for i, d := range docs {
+		if d == nil {
+			continue
+		}
+		itemID, err := RequireMetaString(d.MetaData, metaKBItemID)
+		if err != nil {
+			return fmt.Errorf("doc %d: %w", i, err)
+		}
+		chunkIdx, err := RequireMetaInt(d.MetaData, metaKBChunkIndex)
+		if err != nil {
+			return fmt.Errorf("doc %d: %w", i, err)
+		}
+		if _, err := s.db.ExecContext(ctx,
+			`INSERT OR REPLACE INTO knowledge_failed_chunks (id, item_id, chunk_index, chunk_text, namespace, category, title, sub_indexes, error, created_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))`,
+			uuid.New().String(), itemID, chunkIdx, d.Content,
+			strings.TrimSpace(MetaLookupString(d.MetaData, metaKBNamespace)),
+			MetaLookupString(d.MetaData, metaKBCategory),
+			MetaLookupString(d.MetaData, metaKBTitle),
+			subIdxStr, cause.Error(),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 var _ indexer.Indexer = (*SQLiteIndexer)(nil)
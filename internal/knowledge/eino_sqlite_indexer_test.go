@@ -0,0 +1,111 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"cyberstrike-ai/internal/database"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+// batchRejectEmbedder 整批调用必失败（模拟批量请求里个别 input 触发内容策略被拒），
+// 逐条调用时仅拒绝 poison 文本，其余正常返回向量。
+type batchRejectEmbedder struct {
+	poison string
+	calls  int
+}
+
+func (e *batchRejectEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	e.calls++
+	if len(texts) > 1 {
+		return nil, fmt.Errorf("batch rejected by content policy")
+	}
+	for _, t := range texts {
+		if t == e.poison {
+			return nil, fmt.Errorf("input rejected by content policy")
+		}
+	}
+	vecs := make([][]float64, len(texts))
+	for i := range texts {
+		vecs[i] = []float64{0.1, 0.2}
+	}
+	return vecs, nil
+}
+
+func TestSQLiteIndexerBatchFailureFallsBackToSingle(t *testing.T) {
+	db, err := database.NewKnowledgeDB(filepath.Join(t.TempDir(), "indexer.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	poison := FormatEmbeddingInput("RCE", "item-1", "被拒绝的块")
+	docs := make([]*schema.Document, 3)
+	for i, text := range []string{"正常块一", "被拒绝的块", "正常块二"} {
+		docs[i] = &schema.Document{
+			Content: text,
+			MetaData: map[string]any{
+				metaKBItemID:     "item-1",
+				metaKBCategory:   "RCE",
+				metaKBTitle:      "item-1",
+				metaKBChunkIndex: i,
+			},
+		}
+	}
+
+	emb := &batchRejectEmbedder{poison: poison}
+	idx := NewSQLiteIndexer(db.DB, 64, "test-model")
+	ids, err := idx.Store(context.Background(), docs, indexer.WithEmbedding(emb))
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("整批失败后应逐条降级并索引 2 个块: %v", ids)
+	}
+
+	var indexed int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM knowledge_embeddings WHERE item_id = ?`, "item-1").Scan(&indexed); err != nil {
+		t.Fatalf("查询向量块: %v", err)
+	}
+	if indexed != 2 {
+		t.Fatalf("knowledge_embeddings 应有 2 行: %d", indexed)
+	}
+	var failed int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM knowledge_failed_chunks WHERE item_id = ?`, "item-1").Scan(&failed); err != nil {
+		t.Fatalf("查询失败块: %v", err)
+	}
+	if failed != 1 {
+		t.Fatalf("仅被拒绝的块应进 knowledge_failed_chunks: %d", failed)
+	}
+}
+
+func TestSQLiteIndexerAllChunksRejectedStillFails(t *testing.T) {
+	db, err := database.NewKnowledgeDB(filepath.Join(t.TempDir(), "indexer-all-fail.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	text := FormatEmbeddingInput("RCE", "item-2", "唯一的块")
+	docs := []*schema.Document{{
+		Content: "唯一的块",
+		MetaData: map[string]any{
+			metaKBItemID:     "item-2",
+			metaKBCategory:   "RCE",
+			metaKBTitle:      "item-2",
+			metaKBChunkIndex: 0,
+		},
+	}}
+
+	emb := &batchRejectEmbedder{poison: text}
+	idx := NewSQLiteIndexer(db.DB, 64, "test-model")
+	if _, err := idx.Store(context.Background(), docs, indexer.WithEmbedding(emb)); err == nil {
+		t.Fatal("全部块嵌入失败应保持硬失败")
+	}
+}
@@ -26,6 +26,7 @@ type Embedder struct {
 	rateLimitDelay time.Duration
 	maxRetries     int
 	retryDelay     time.Duration
+	breaker        *embedderBreaker // 连续失败熔断（见 embedder_breaker.go；nil 表示关闭）
 	mu             sync.Mutex
 }
 
@@ -34,6 +35,10 @@ func NewEmbedder(ctx context.Context, cfg *config.KnowledgeConfig, openAIConfig
 	if cfg == nil {
 		return nil, fmt.Errorf("knowledge config is nil")
 	}
+	// 嵌入服务与 chat 往往不是同一家，embedding 段完全缺失时明确报错，不再静默复用 chat 的 key
+	if !cfg.Embedding.IsConfigured() {
+		return nil, fmt.Errorf("未配置嵌入服务：请在 knowledge.embedding 下设置 provider/model/base_url/api_key")
+	}
 
 	var rateLimiter *rate.Limiter
 	var rateLimitDelay time.Duration
@@ -60,39 +65,75 @@ func NewEmbedder(ctx context.Context, cfg *config.KnowledgeConfig, openAIConfig
 	}
 
 	model := strings.TrimSpace(cfg.Embedding.Model)
-	if model == "" {
-		model = "text-embedding-3-small"
-	}
-
-	baseURL := strings.TrimSpace(cfg.Embedding.BaseURL)
-	baseURL = strings.TrimSuffix(baseURL, "/")
-	if baseURL == "" {
-		baseURL = "https://api.openai.com/v1"
-	}
-
-	apiKey := strings.TrimSpace(cfg.Embedding.APIKey)
-	if apiKey == "" && openAIConfig != nil {
-		apiKey = strings.TrimSpace(openAIConfig.APIKey)
-	}
-	if apiKey == "" {
-		return nil, fmt.Errorf("embedding API key 未配置")
-	}
 
 	timeout := 120 * time.Second
 	if cfg.Indexing.RequestTimeoutSeconds > 0 {
 		timeout = time.Duration(cfg.Indexing.RequestTimeoutSeconds) * time.Second
 	}
 	httpClient := &http.Client{Timeout: timeout}
+	if len(cfg.Embedding.Headers) > 0 {
+		// 自建嵌入服务可能需要额外 header（X-Api-Key、网关路由头等），通过 Transport 追加
+		httpClient.Transport = &headerInjectTransport{headers: cfg.Embedding.Headers}
+	}
 
-	inner, err := einoembedopenai.NewEmbedder(ctx, &einoembedopenai.EmbeddingConfig{
-		APIKey:     apiKey,
-		BaseURL:    baseURL,
-		ByAzure:    false,
-		Model:      model,
-		HTTPClient: httpClient,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("eino OpenAI embedder: %w", err)
+	// 按 provider 选择嵌入后端：openai（默认，云端/兼容网关）或本地离线服务（ollama | tei），
+	// EmbedText/EmbedStrings 背后统一是 [embedding.Embedder] 接口。
+	var inner embedding.Embedder
+	switch provider := strings.ToLower(strings.TrimSpace(cfg.Embedding.Provider)); provider {
+	case "ollama", "tei":
+		if model == "" && provider == "ollama" {
+			model = "nomic-embed-text"
+		}
+		// 本地嵌入服务无须 API Key（配置了则以 Bearer 带上，兼容网关转发）
+		inner = newLocalHTTPEmbedder(provider, model, cfg.Embedding.BaseURL, strings.TrimSpace(cfg.Embedding.APIKey), httpClient, logger)
+		if logger != nil {
+			logger.Info("知识库使用本地嵌入后端", zap.String("provider", provider), zap.String("model", model))
+		}
+	default:
+		if model == "" {
+			model = "text-embedding-3-small"
+		}
+		rawBaseURL := strings.TrimSpace(cfg.Embedding.BaseURL)
+		if rawBaseURL == "" && openAIConfig != nil && strings.TrimSpace(openAIConfig.BaseURL) != "" {
+			rawBaseURL = strings.TrimSpace(openAIConfig.BaseURL)
+			if logger != nil {
+				logger.Warn("knowledge.embedding.base_url 未配置，回退使用 chat 的 base_url",
+					zap.String("base_url", rawBaseURL))
+			}
+		}
+		baseURL := config.NormalizeOpenAIBaseURL(rawBaseURL)
+
+		apiKey := strings.TrimSpace(cfg.Embedding.APIKey)
+		if apiKey == "" && openAIConfig != nil {
+			apiKey = strings.TrimSpace(openAIConfig.APIKey)
+			if apiKey != "" && logger != nil {
+				// 回退仅为兼容旧配置；embedding 与 chat 不是同一家服务时请单独配置 api_key
+				logger.Warn("knowledge.embedding.api_key 未配置，回退使用 chat 的 API Key",
+					zap.String("base_url", baseURL))
+			}
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("embedding API key 未配置（knowledge.embedding.api_key，chat 配置中也无可回退的 key）")
+		}
+
+		openaiEmbedder, err := einoembedopenai.NewEmbedder(ctx, &einoembedopenai.EmbeddingConfig{
+			APIKey:     apiKey,
+			BaseURL:    baseURL,
+			ByAzure:    false,
+			Model:      model,
+			HTTPClient: httpClient,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("eino OpenAI embedder: %w", err)
+		}
+		inner = openaiEmbedder
+	}
+
+	// 熔断默认开启（阈值 5 次 / 冷却 30s），配置阈值为负时关闭
+	var breaker *embedderBreaker
+	if cfg.Indexing.BreakerFailureThreshold >= 0 {
+		breaker = newEmbedderBreaker(cfg.Indexing.BreakerFailureThreshold,
+			time.Duration(cfg.Indexing.BreakerCooldownSeconds)*time.Second)
 	}
 
 	return &Embedder{
@@ -103,9 +144,32 @@ func NewEmbedder(ctx context.Context, cfg *config.KnowledgeConfig, openAIConfig
 		rateLimitDelay: rateLimitDelay,
 		maxRetries:     maxRetries,
 		retryDelay:     retryDelay,
+		breaker:        breaker,
 	}, nil
 }
 
+// headerInjectTransport 为每个嵌入请求追加配置的自定义请求头；不覆盖已设置的标准头。
+type headerInjectTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerInjectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := req.Clone(req.Context())
+	for k, v := range t.headers {
+		k = strings.TrimSpace(k)
+		if k == "" || cloned.Header.Get(k) != "" {
+			continue
+		}
+		cloned.Header.Set(k, v)
+	}
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(cloned)
+}
+
 // EmbeddingModelName 返回配置的嵌入模型名（用于 tiktoken 分块与向量行元数据）。
 func (e *Embedder) EmbeddingModelName() string {
 	if e == nil || e.config == nil {
@@ -115,6 +179,9 @@ func (e *Embedder) EmbeddingModelName() string {
 	if s != "" {
 		return s
 	}
+	if strings.ToLower(strings.TrimSpace(e.config.Embedding.Provider)) == "ollama" {
+		return "nomic-embed-text"
+	}
 	return "text-embedding-3-small"
 }
 
@@ -153,6 +220,10 @@ func (e *Embedder) EmbedStrings(ctx context.Context, texts []string, opts ...emb
 	if len(texts) == 0 {
 		return nil, nil
 	}
+	// 熔断打开时快速失败，不再对挂掉的服务逐请求等超时
+	if err := e.breaker.allow(); err != nil {
+		return nil, err
+	}
 
 	var lastErr error
 	for attempt := 0; attempt < e.maxRetries; attempt++ {
@@ -172,6 +243,7 @@ func (e *Embedder) EmbedStrings(ctx context.Context, texts []string, opts ...emb
 
 		raw, err := e.eino.EmbedStrings(ctx, texts, opts...)
 		if err == nil {
+			e.breaker.onSuccess()
 			out := make([][]float32, len(raw))
 			for i, row := range raw {
 				out[i] = make([]float32, len(row))
@@ -183,12 +255,15 @@ func (e *Embedder) EmbedStrings(ctx context.Context, texts []string, opts ...emb
 		}
 		lastErr = err
 		if !e.isRetryableError(err) {
+			e.breaker.onFailure()
 			return nil, err
 		}
 		if e.logger != nil {
 			e.logger.Debug("嵌入失败，将重试", zap.Int("attempt", attempt+1), zap.Error(err))
 		}
 	}
+	// 重试耗尽才算一次熔断意义上的失败（单次抖动由重试吸收）
+	e.breaker.onFailure()
 	return nil, fmt.Errorf("达到最大重试次数 (%d): %v", e.maxRetries, lastErr)
 }
 
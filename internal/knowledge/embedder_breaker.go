@@ -0,0 +1,87 @@
+package knowledge
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrEmbedderCircuitOpen 熔断打开期间的快速失败错误；调用方可借此与普通嵌入失败区分
+// （如 RebuildIndex 遇到该错误时直接中止，而不是逐块记失败）。
+var ErrEmbedderCircuitOpen = errors.New("嵌入服务熔断中：连续失败过多，冷却期内不再发请求")
+
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerCooldown         = 30 * time.Second
+)
+
+// embedderBreaker 针对嵌入服务的简单熔断：连续失败达到阈值后打开，冷却期内直接快速失败，
+// 既不给挂掉的下游继续加压，也避免 RebuildIndex 对每个块都等一轮超时刷屏日志。
+// 冷却结束后进入半开态，只放行一次探测请求：成功则闭合归零，失败则重新开始冷却。
+type embedderBreaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	cooldown         time.Duration
+	consecutiveFails int
+	openedAt         time.Time
+	probing          bool
+}
+
+// newEmbedderBreaker threshold<=0 / cooldown<=0 时使用默认值（5 次 / 30s）。
+func newEmbedderBreaker(threshold int, cooldown time.Duration) *embedderBreaker {
+	if threshold <= 0 {
+		threshold = defaultBreakerFailureThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &embedderBreaker{failureThreshold: threshold, cooldown: cooldown}
+}
+
+// allow 判断当前请求是否放行：熔断打开且在冷却期内返回 ErrEmbedderCircuitOpen；
+// 冷却结束后第一个调用者作为半开探测放行，其余调用在探测结束前仍快速失败。
+func (b *embedderBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.consecutiveFails < b.failureThreshold {
+		return nil
+	}
+	remaining := b.cooldown - time.Since(b.openedAt)
+	if remaining > 0 {
+		return fmt.Errorf("%w（约 %s 后半开探测）", ErrEmbedderCircuitOpen, remaining.Round(time.Second))
+	}
+	if b.probing {
+		return fmt.Errorf("%w（半开探测进行中）", ErrEmbedderCircuitOpen)
+	}
+	b.probing = true
+	return nil
+}
+
+// onSuccess 请求成功：闭合熔断并清零计数。
+func (b *embedderBreaker) onSuccess() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.consecutiveFails = 0
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// onFailure 请求失败：累计连续失败数，达到阈值（或半开探测失败）时重新开始冷却计时。
+func (b *embedderBreaker) onFailure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.consecutiveFails++
+	b.probing = false
+	if b.consecutiveFails >= b.failureThreshold {
+		b.openedAt = time.Now()
+	}
+	b.mu.Unlock()
+}
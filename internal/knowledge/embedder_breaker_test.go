@@ -0,0 +1,83 @@
+package knowledge
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEmbedderBreakerOpensAfterThreshold(t *testing.T) {
+	t.Parallel()
+	b := newEmbedderBreaker(3, time.Hour)
+	for i := 0; i < 2; i++ {
+		if err := b.allow(); err != nil {
+			t.Fatalf("熔断不应在阈值前打开: %v", err)
+		}
+		b.onFailure()
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("第 3 次请求仍应放行: %v", err)
+	}
+	b.onFailure()
+	if err := b.allow(); !errors.Is(err, ErrEmbedderCircuitOpen) {
+		t.Fatalf("连续 3 次失败后应快速失败, got %v", err)
+	}
+}
+
+func TestEmbedderBreakerSuccessResetsCount(t *testing.T) {
+	t.Parallel()
+	b := newEmbedderBreaker(3, time.Hour)
+	b.onFailure()
+	b.onFailure()
+	b.onSuccess()
+	b.onFailure()
+	b.onFailure()
+	if err := b.allow(); err != nil {
+		t.Fatalf("成功应清零连续失败计数: %v", err)
+	}
+}
+
+func TestEmbedderBreakerHalfOpenProbe(t *testing.T) {
+	t.Parallel()
+	b := newEmbedderBreaker(2, 20*time.Millisecond)
+	b.onFailure()
+	b.onFailure()
+	if err := b.allow(); !errors.Is(err, ErrEmbedderCircuitOpen) {
+		t.Fatalf("冷却期内应快速失败, got %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	// 冷却结束：第一个调用者作为半开探测放行，其余仍被拒绝
+	if err := b.allow(); err != nil {
+		t.Fatalf("冷却后应放行半开探测: %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrEmbedderCircuitOpen) {
+		t.Fatalf("探测进行中其余请求应被拒绝, got %v", err)
+	}
+
+	// 探测失败：重新开始冷却
+	b.onFailure()
+	if err := b.allow(); !errors.Is(err, ErrEmbedderCircuitOpen) {
+		t.Fatalf("探测失败后应重新冷却, got %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := b.allow(); err != nil {
+		t.Fatalf("二次冷却后应再次放行探测: %v", err)
+	}
+	// 探测成功：熔断闭合，后续请求全部放行
+	b.onSuccess()
+	if err := b.allow(); err != nil {
+		t.Fatalf("探测成功后熔断应闭合: %v", err)
+	}
+}
+
+func TestEmbedderBreakerNilIsNoop(t *testing.T) {
+	t.Parallel()
+	var b *embedderBreaker
+	if err := b.allow(); err != nil {
+		t.Fatalf("nil 熔断器应始终放行: %v", err)
+	}
+	b.onSuccess()
+	b.onFailure()
+}
@@ -0,0 +1,164 @@
+package knowledge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"go.uber.org/zap"
+)
+
+// 本地/离线嵌入后端（knowledge.embedding.provider: ollama | tei）：
+// 内网隔离环境连不上云端嵌入 API 时，改走本地 Ollama embeddings 或
+// Text Embeddings Inference（TEI）的 HTTP 接口。实现 [embedding.Embedder]，
+// 在 NewEmbedder 中按 provider 与 OpenAI 路径可插拔切换；
+// 重试与速率限制仍由外层 Embedder 统一承担。
+type localHTTPEmbedder struct {
+	provider string // ollama | tei
+	model    string
+	baseURL  string
+	apiKey   string // 本地服务通常无鉴权；非空时以 Bearer 带上（网关场景）
+	client   *http.Client
+	logger   *zap.Logger
+}
+
+func newLocalHTTPEmbedder(provider, model, baseURL, apiKey string, client *http.Client, logger *zap.Logger) *localHTTPEmbedder {
+	base := strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
+	if base == "" {
+		if provider == "tei" {
+			base = "http://127.0.0.1:8080"
+		} else {
+			base = "http://127.0.0.1:11434"
+		}
+	}
+	return &localHTTPEmbedder{
+		provider: provider,
+		model:    model,
+		baseURL:  base,
+		apiKey:   apiKey,
+		client:   client,
+		logger:   logger,
+	}
+}
+
+func (l *localHTTPEmbedder) EmbedStrings(ctx context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	switch l.provider {
+	case "tei":
+		return l.embedTEI(ctx, texts)
+	default:
+		return l.embedOllama(ctx, texts)
+	}
+}
+
+func (l *localHTTPEmbedder) GetType() string {
+	return "LocalHTTPEmbedder"
+}
+
+func (l *localHTTPEmbedder) IsCallbacksEnabled() bool {
+	return false
+}
+
+func (l *localHTTPEmbedder) postJSON(ctx context.Context, url string, payload any) (int, []byte, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(raw))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+l.apiKey)
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s embedding request: %w", l.provider, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, body, nil
+}
+
+// embedOllama 走 Ollama 批量接口 POST /api/embed；旧版本无该接口（404）时
+// 回退逐条调用 POST /api/embeddings。
+func (l *localHTTPEmbedder) embedOllama(ctx context.Context, texts []string) ([][]float64, error) {
+	status, body, err := l.postJSON(ctx, l.baseURL+"/api/embed", map[string]any{
+		"model": l.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return l.embedOllamaLegacy(ctx, texts)
+	}
+	if status < 200 || status >= 300 {
+		return nil, fmt.Errorf("ollama embed http %d: %s", status, truncateForRerankLog(string(body)))
+	}
+	var parsed struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("ollama embed decode: %w", err)
+	}
+	if len(parsed.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama embed 返回向量数 %d 与输入数 %d 不符", len(parsed.Embeddings), len(texts))
+	}
+	return parsed.Embeddings, nil
+}
+
+func (l *localHTTPEmbedder) embedOllamaLegacy(ctx context.Context, texts []string) ([][]float64, error) {
+	out := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		status, body, err := l.postJSON(ctx, l.baseURL+"/api/embeddings", map[string]any{
+			"model":  l.model,
+			"prompt": text,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if status < 200 || status >= 300 {
+			return nil, fmt.Errorf("ollama embeddings http %d: %s", status, truncateForRerankLog(string(body)))
+		}
+		var parsed struct {
+			Embedding []float64 `json:"embedding"`
+		}
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			return nil, fmt.Errorf("ollama embeddings decode: %w", err)
+		}
+		out = append(out, parsed.Embedding)
+	}
+	return out, nil
+}
+
+// embedTEI TEI 的模型在服务端启动时固定，请求无 model 字段。
+func (l *localHTTPEmbedder) embedTEI(ctx context.Context, texts []string) ([][]float64, error) {
+	status, body, err := l.postJSON(ctx, l.baseURL+"/embed", map[string]any{
+		"inputs": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status < 200 || status >= 300 {
+		return nil, fmt.Errorf("tei embed http %d: %s", status, truncateForRerankLog(string(body)))
+	}
+	var parsed [][]float64
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("tei embed decode: %w", err)
+	}
+	if len(parsed) != len(texts) {
+		return nil, fmt.Errorf("tei embed 返回向量数 %d 与输入数 %d 不符", len(parsed), len(texts))
+	}
+	return parsed, nil
+}
+
+var _ embedding.Embedder = (*localHTTPEmbedder)(nil)
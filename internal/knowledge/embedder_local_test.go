@@ -0,0 +1,115 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestLocalHTTPEmbedder_OllamaBatch(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Fatalf("path %s", r.URL.Path)
+		}
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "nomic-embed-text" || len(body.Input) != 2 {
+			t.Fatalf("unexpected request: %+v", body)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"embeddings": [][]float64{{0.1, 0.2}, {0.3, 0.4}},
+		})
+	}))
+	defer srv.Close()
+
+	emb := newLocalHTTPEmbedder("ollama", "nomic-embed-text", srv.URL, "", &http.Client{Timeout: 5 * time.Second}, zap.NewNop())
+	vecs, err := emb.EmbedStrings(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs) != 2 || vecs[0][1] != 0.2 || vecs[1][0] != 0.3 {
+		t.Fatalf("unexpected vectors: %v", vecs)
+	}
+}
+
+func TestLocalHTTPEmbedder_OllamaLegacyFallback(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/embed":
+			// 旧版 Ollama 没有批量接口
+			http.NotFound(w, r)
+		case "/api/embeddings":
+			var body struct {
+				Prompt string `json:"prompt"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			vec := []float64{1}
+			if body.Prompt == "b" {
+				vec = []float64{2}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"embedding": vec})
+		default:
+			t.Fatalf("path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	emb := newLocalHTTPEmbedder("ollama", "nomic-embed-text", srv.URL, "", &http.Client{Timeout: 5 * time.Second}, zap.NewNop())
+	vecs, err := emb.EmbedStrings(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs) != 2 || vecs[0][0] != 1 || vecs[1][0] != 2 {
+		t.Fatalf("unexpected vectors: %v", vecs)
+	}
+}
+
+func TestLocalHTTPEmbedder_TEI(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embed" {
+			t.Fatalf("path %s", r.URL.Path)
+		}
+		var body struct {
+			Inputs []string `json:"inputs"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Inputs) != 1 {
+			t.Fatalf("unexpected inputs: %v", body.Inputs)
+		}
+		_ = json.NewEncoder(w).Encode([][]float64{{0.5, 0.6}})
+	}))
+	defer srv.Close()
+
+	emb := newLocalHTTPEmbedder("tei", "", srv.URL, "", &http.Client{Timeout: 5 * time.Second}, zap.NewNop())
+	vecs, err := emb.EmbedStrings(context.Background(), []string{"query"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs) != 1 || vecs[0][0] != 0.5 {
+		t.Fatalf("unexpected vectors: %v", vecs)
+	}
+}
+
+func TestLocalHTTPEmbedder_CountMismatch(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"embeddings": [][]float64{{1}}})
+	}))
+	defer srv.Close()
+
+	emb := newLocalHTTPEmbedder("ollama", "m", srv.URL, "", &http.Client{Timeout: 5 * time.Second}, zap.NewNop())
+	if _, err := emb.EmbedStrings(context.Background(), []string{"a", "b"}); err == nil {
+		t.Fatal("返回向量数与输入数不符时应报错")
+	}
+}
@@ -0,0 +1,40 @@
+package knowledge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cyberstrike-ai/internal/config"
+
+	"go.uber.org/zap"
+)
+
+func TestNewEmbedderRejectsMissingEmbeddingSection(t *testing.T) {
+	t.Parallel()
+	cfg := &config.KnowledgeConfig{Enabled: true}
+	openAI := &config.OpenAIConfig{APIKey: "sk-chat-key", BaseURL: "https://chat.example.com/v1"}
+	if _, err := NewEmbedder(context.Background(), cfg, openAI, zap.NewNop()); err == nil {
+		t.Fatal("embedding 段完全缺失时应报错，而非静默回退 chat 配置")
+	} else if !strings.Contains(err.Error(), "未配置嵌入服务") {
+		t.Fatalf("错误信息应说明未配置嵌入服务: %v", err)
+	}
+}
+
+func TestNewEmbedderLocalProviderWithoutKey(t *testing.T) {
+	t.Parallel()
+	cfg := &config.KnowledgeConfig{
+		Enabled: true,
+		Embedding: config.EmbeddingConfig{
+			Provider: "ollama",
+			BaseURL:  "http://127.0.0.1:11434",
+		},
+	}
+	emb, err := NewEmbedder(context.Background(), cfg, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("本地嵌入服务无须 API Key: %v", err)
+	}
+	if got := emb.EmbeddingModelName(); got != "nomic-embed-text" {
+		t.Fatalf("ollama 默认模型名 = %q", got)
+	}
+}
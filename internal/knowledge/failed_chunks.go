@@ -0,0 +1,138 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// FailedChunk 嵌入失败的知识块记录（knowledge_failed_chunks 行，列表展示不含 chunk 文本）。
+type FailedChunk struct {
+	ID         string    `json:"id"`
+	ItemID     string    `json:"itemId"`
+	ChunkIndex int       `json:"chunkIndex"`
+	Error      string    `json:"error"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// CountFailedChunks 返回待重试的失败块总数。
+func (idx *Indexer) CountFailedChunks() (int, error) {
+	var count int
+	if err := idx.db.QueryRow("SELECT COUNT(*) FROM knowledge_failed_chunks").Scan(&count); err != nil {
+		return 0, fmt.Errorf("统计失败块失败：%w", err)
+	}
+	return count, nil
+}
+
+// ListFailedChunks 按记录时间倒序返回失败块；limit <= 0 时默认 100。
+func (idx *Indexer) ListFailedChunks(limit int) ([]*FailedChunk, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	rows, err := idx.db.Query(`
+		SELECT id, item_id, chunk_index, error, created_at
+		FROM knowledge_failed_chunks
+		ORDER BY created_at DESC, item_id ASC, chunk_index ASC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询失败块失败：%w", err)
+	}
+	defer rows.Close()
+
+	chunks := make([]*FailedChunk, 0)
+	for rows.Next() {
+		var chunk FailedChunk
+		if err := rows.Scan(&chunk.ID, &chunk.ItemID, &chunk.ChunkIndex, &chunk.Error, &chunk.CreatedAt); err != nil {
+			return nil, fmt.Errorf("扫描失败块失败：%w", err)
+		}
+		chunks = append(chunks, &chunk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("扫描失败块失败：%w", err)
+	}
+	return chunks, nil
+}
+
+// RetryFailedChunks 只重试 knowledge_failed_chunks 中登记的块：逐块重新嵌入并写入向量表，
+// 成功后删除失败记录，仍失败的更新错误信息留待下次重试。返回成功与仍失败的数量。
+func (idx *Indexer) RetryFailedChunks(ctx context.Context) (succeeded, failed int, err error) {
+	if idx.embedder == nil {
+		return 0, 0, fmt.Errorf("嵌入器未初始化")
+	}
+
+	rows, err := idx.db.QueryContext(ctx, `
+		SELECT id, item_id, chunk_index, chunk_text, namespace, category, title, sub_indexes
+		FROM knowledge_failed_chunks
+		ORDER BY created_at ASC, item_id ASC, chunk_index ASC`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("查询失败块失败：%w", err)
+	}
+	defer rows.Close()
+
+	type retryChunk struct {
+		id, itemID, chunkText, namespace, category, title, subIndexes string
+		chunkIndex                                                    int
+	}
+	var chunks []retryChunk
+	for rows.Next() {
+		var chunk retryChunk
+		if err := rows.Scan(&chunk.id, &chunk.itemID, &chunk.chunkIndex, &chunk.chunkText,
+			&chunk.namespace, &chunk.category, &chunk.title, &chunk.subIndexes); err != nil {
+			return 0, 0, fmt.Errorf("扫描失败块失败：%w", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("扫描失败块失败：%w", err)
+	}
+	if len(chunks) == 0 {
+		return 0, 0, nil
+	}
+
+	embedModel := idx.embedder.EmbeddingModelName()
+	for _, chunk := range chunks {
+		vec, embErr := idx.embedder.EmbedText(ctx, FormatEmbeddingInput(chunk.category, chunk.title, chunk.chunkText))
+		if embErr != nil {
+			failed++
+			if _, uerr := idx.db.ExecContext(ctx,
+				"UPDATE knowledge_failed_chunks SET error = ? WHERE id = ?", embErr.Error(), chunk.id); uerr != nil && idx.logger != nil {
+				idx.logger.Warn("更新失败块错误信息失败", zap.String("id", chunk.id), zap.Error(uerr))
+			}
+			continue
+		}
+		embeddingJSON, jsonErr := json.Marshal(vec)
+		if jsonErr != nil {
+			return succeeded, failed, fmt.Errorf("序列化向量失败：%w", jsonErr)
+		}
+		// 覆盖同位置可能残留的旧向量后写入，再删除失败记录
+		if _, derr := idx.db.ExecContext(ctx,
+			"DELETE FROM knowledge_embeddings WHERE item_id = ? AND chunk_index = ?", chunk.itemID, chunk.chunkIndex); derr != nil {
+			return succeeded, failed, fmt.Errorf("清理旧向量失败：%w", derr)
+		}
+		if _, ierr := idx.db.ExecContext(ctx,
+			`INSERT INTO knowledge_embeddings (id, item_id, chunk_index, chunk_text, embedding, namespace, sub_indexes, embedding_model, embedding_dim, created_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))`,
+			uuid.New().String(), chunk.itemID, chunk.chunkIndex, chunk.chunkText, string(embeddingJSON),
+			chunk.namespace, chunk.subIndexes, embedModel, len(vec),
+		); ierr != nil {
+			return succeeded, failed, fmt.Errorf("写入向量失败：%w", ierr)
+		}
+		if _, derr := idx.db.ExecContext(ctx,
+			"DELETE FROM knowledge_failed_chunks WHERE id = ?", chunk.id); derr != nil {
+			return succeeded, failed, fmt.Errorf("删除失败块记录失败：%w", derr)
+		}
+		succeeded++
+	}
+
+	if idx.logger != nil {
+		idx.logger.Info("失败块重试完成", zap.Int("total", len(chunks)), zap.Int("succeeded", succeeded), zap.Int("failed", failed))
+	}
+	return succeeded, failed, nil
+}
@@ -3,6 +3,7 @@ package knowledge
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -133,15 +134,27 @@ func (idx *Indexer) IndexItem(ctx context.Context, itemID string) error {
 		return fmt.Errorf("嵌入器未初始化")
 	}
 
-	var content, category, title, filePath string
-	err := idx.db.QueryRow("SELECT content, category, title, file_path FROM knowledge_base_items WHERE id = ?", itemID).Scan(&content, &category, &title, &filePath)
+	var content, namespace, category, title, filePath string
+	err := idx.db.QueryRow("SELECT content, namespace, category, title, file_path FROM knowledge_base_items WHERE id = ?", itemID).Scan(&content, &namespace, &category, &title, &filePath)
 	if err != nil {
 		return fmt.Errorf("获取知识项失败：%w", err)
 	}
 
+	// 容量限制：单项内容与总 chunk 数超限时拒绝索引（排除本项即将删除重写的旧向量）
+	if err := checkItemContentLimit(idx.indexingCfg, content); err != nil {
+		return err
+	}
+	if err := checkTotalChunksLimit(idx.db, idx.indexingCfg, itemID); err != nil {
+		return err
+	}
+
 	if _, err := idx.db.Exec("DELETE FROM knowledge_embeddings WHERE item_id = ?", itemID); err != nil {
 		return fmt.Errorf("删除旧向量失败：%w", err)
 	}
+	// 旧的失败块记录一并清掉：本次重建中仍失败的块会重新登记
+	if _, err := idx.db.Exec("DELETE FROM knowledge_failed_chunks WHERE item_id = ?", itemID); err != nil {
+		return fmt.Errorf("清理失败块记录失败：%w", err)
+	}
 
 	body := strings.TrimSpace(content)
 	if idx.indexingCfg != nil && idx.indexingCfg.PreferSourceFile && strings.TrimSpace(filePath) != "" && idx.fileLoader != nil {
@@ -172,9 +185,10 @@ func (idx *Indexer) IndexItem(ctx context.Context, itemID string) error {
 		ID:      itemID,
 		Content: body,
 		MetaData: map[string]any{
-			metaKBCategory: category,
-			metaKBTitle:    title,
-			metaKBItemID:   itemID,
+			metaKBCategory:  category,
+			metaKBTitle:     title,
+			metaKBItemID:    itemID,
+			metaKBNamespace: namespace,
 		},
 	}
 
@@ -270,12 +284,18 @@ func (idx *Indexer) RebuildIndex(ctx context.Context) error {
 		return err
 	}
 	defer idx.FinishIndexRun()
-	return idx.runRebuildIndex(ctx)
+	return idx.runRebuildIndex(ctx, "")
 }
 
 // RunRebuildIndex 在已占用索引任务槽位后执行全量重建（供 HTTP handler 后台任务使用）。
 func (idx *Indexer) RunRebuildIndex(ctx context.Context) error {
-	return idx.runRebuildIndex(ctx)
+	return idx.runRebuildIndex(ctx, "")
+}
+
+// RunRebuildIndexNamespace 在已占用索引任务槽位后仅重建指定命名空间的知识项索引；
+// namespace 为空串时等价于 [Indexer.RunRebuildIndex] 全量重建。
+func (idx *Indexer) RunRebuildIndexNamespace(ctx context.Context, namespace string) error {
+	return idx.runRebuildIndex(ctx, namespace)
 }
 
 // RunIndexMissing 在已占用索引任务槽位后执行缺失索引补齐（供 HTTP handler 后台任务使用）。
@@ -283,10 +303,18 @@ func (idx *Indexer) RunIndexMissing(ctx context.Context) error {
 	return idx.runIndexMissing(ctx)
 }
 
-func (idx *Indexer) runRebuildIndex(ctx context.Context) error {
+func (idx *Indexer) runRebuildIndex(ctx context.Context, namespace string) error {
 	idx.resetLastError()
 
-	rows, err := idx.db.QueryContext(ctx, "SELECT id FROM knowledge_base_items ORDER BY updated_at ASC, id ASC")
+	query := "SELECT id FROM knowledge_base_items ORDER BY updated_at ASC, id ASC"
+	var args []interface{}
+	namespace = strings.TrimSpace(namespace)
+	if namespace != "" {
+		query = "SELECT id FROM knowledge_base_items WHERE TRIM(namespace) = TRIM(?) COLLATE NOCASE ORDER BY updated_at ASC, id ASC"
+		args = append(args, namespace)
+	}
+
+	rows, err := idx.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("查询知识项失败：%w", err)
 	}
@@ -298,7 +326,7 @@ func (idx *Indexer) runRebuildIndex(ctx context.Context) error {
 	}
 
 	idx.setIndexRunTotal(len(itemIDs))
-	idx.logger.Info("开始重建索引", zap.Int("totalItems", len(itemIDs)))
+	idx.logger.Info("开始重建索引", zap.Int("totalItems", len(itemIDs)), zap.String("namespace", namespace))
 
 	return idx.indexItemIDs(ctx, itemIDs, "索引重建完成")
 }
@@ -353,6 +381,20 @@ func (idx *Indexer) indexItemIDs(ctx context.Context, itemIDs []string, doneMess
 
 	for i, itemID := range itemIDs {
 		if err := idx.IndexItem(ctx, itemID); err != nil {
+			// 嵌入服务已熔断：剩余知识项只会快速失败，直接中止本轮索引
+			if errors.Is(err, ErrEmbedderCircuitOpen) {
+				errorMsg := fmt.Sprintf("嵌入服务熔断，索引中止（已处理 %d/%d 项）：%v", i, len(itemIDs), err)
+				idx.mu.Lock()
+				idx.lastError = errorMsg
+				idx.lastErrorTime = time.Now()
+				idx.mu.Unlock()
+				idx.logger.Error("嵌入服务熔断，停止索引",
+					zap.Int("processedItems", i),
+					zap.Int("totalItems", len(itemIDs)),
+					zap.Error(err),
+				)
+				return fmt.Errorf("嵌入服务熔断，索引中止：%w", err)
+			}
 			failedCount++
 			consecutiveFailures++
 
@@ -0,0 +1,35 @@
+package knowledge
+
+import (
+	"fmt"
+	"time"
+)
+
+// 知识项检索加权：SOP 等高价值文档可配置 boost 因子，向量检索排序与 TopK
+// 截断按 similarity × boost 计算，相近相似度下高 boost 文档优先被召回。
+// 阈值过滤仍按原始相似度，boost 不会把不相关内容拉进结果。
+
+const (
+	// itemBoostMin / itemBoostMax boost 合法区间；过大的因子会让单个文档垄断 TopK
+	itemBoostMin = 0.1
+	itemBoostMax = 10.0
+)
+
+// SetItemBoost 设置知识项的检索加权因子（默认 1.0，不加权）。
+// 只改元数据不动向量，无需重建索引，下次检索即生效。
+func (m *Manager) SetItemBoost(id string, boost float64) error {
+	if boost < itemBoostMin || boost > itemBoostMax {
+		return fmt.Errorf("boost 需在 %.1f ~ %.1f 之间（1.0 表示不加权）", itemBoostMin, itemBoostMax)
+	}
+	result, err := m.db.Exec(
+		"UPDATE knowledge_base_items SET boost = ?, updated_at = ? WHERE id = ?",
+		boost, time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("更新知识项 boost 失败: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("知识项不存在")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package knowledge
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cyberstrike-ai/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func TestSetItemBoost(t *testing.T) {
+	db, err := database.NewKnowledgeDB(filepath.Join(t.TempDir(), "boost.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now()
+	if _, err := db.Exec(
+		"INSERT INTO knowledge_base_items (id, category, title, file_path, content, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		"item-sop", "SOP", "提权 SOP", "/kb/SOP/item-sop.md", "标准提权流程", now, now,
+	); err != nil {
+		t.Fatalf("插入知识项: %v", err)
+	}
+
+	m := NewManager(db.DB, t.TempDir(), zap.NewNop())
+	item, err := m.GetItem("item-sop")
+	if err != nil {
+		t.Fatalf("GetItem: %v", err)
+	}
+	if item.Boost != 1.0 {
+		t.Fatalf("默认 boost 应为 1.0: %v", item.Boost)
+	}
+
+	if err := m.SetItemBoost("item-sop", 2.5); err != nil {
+		t.Fatalf("SetItemBoost: %v", err)
+	}
+	item, err = m.GetItem("item-sop")
+	if err != nil {
+		t.Fatalf("GetItem: %v", err)
+	}
+	if item.Boost != 2.5 {
+		t.Fatalf("boost 未生效: %v", item.Boost)
+	}
+
+	if err := m.SetItemBoost("item-sop", 0); err == nil {
+		t.Fatal("boost=0 应被拒绝")
+	}
+	if err := m.SetItemBoost("item-sop", 100); err == nil {
+		t.Fatal("boost 超上限应被拒绝")
+	}
+	if err := m.SetItemBoost("no-such-item", 2); err == nil {
+		t.Fatal("不存在的知识项应报错")
+	}
+}
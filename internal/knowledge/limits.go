@@ -0,0 +1,63 @@
+package knowledge
+
+import (
+	"database/sql"
+	"fmt"
+
+	"cyberstrike-ai/internal/config"
+)
+
+// 知识库容量限制：无限制导入会把 DB 撑爆、索引爆炸。
+// 上限在 indexing 配置里声明（0 = 不限制），导入入口（CreateItem/UpdateItem）
+// 与 IndexItem 统一校验，超限直接拒绝并给出明确提示。
+
+// checkItemContentLimit 校验单个知识项内容的字节数上限。
+func checkItemContentLimit(cfg *config.IndexingConfig, content string) error {
+	if cfg == nil || cfg.MaxItemContentBytes <= 0 {
+		return nil
+	}
+	if len(content) > cfg.MaxItemContentBytes {
+		return fmt.Errorf("知识项内容过大：%d 字节，超过上限 %d 字节（knowledge.indexing.max_item_content_bytes）",
+			len(content), cfg.MaxItemContentBytes)
+	}
+	return nil
+}
+
+// checkTotalItemsLimit 校验知识库总 item 数上限（新建前调用）。
+func checkTotalItemsLimit(db *sql.DB, cfg *config.IndexingConfig) error {
+	if db == nil || cfg == nil || cfg.MaxTotalItems <= 0 {
+		return nil
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM knowledge_base_items").Scan(&count); err != nil {
+		return fmt.Errorf("统计知识项数量失败: %w", err)
+	}
+	if count >= cfg.MaxTotalItems {
+		return fmt.Errorf("知识库已有 %d 个知识项，达到上限 %d（knowledge.indexing.max_total_items），请先清理后再导入",
+			count, cfg.MaxTotalItems)
+	}
+	return nil
+}
+
+// checkTotalChunksLimit 校验知识库总 chunk 数上限；excludeItemID 非空时
+// 排除该 item 自己的旧向量（重建索引前旧向量会被删除重写）。
+func checkTotalChunksLimit(db *sql.DB, cfg *config.IndexingConfig, excludeItemID string) error {
+	if db == nil || cfg == nil || cfg.MaxTotalChunks <= 0 {
+		return nil
+	}
+	var count int
+	var err error
+	if excludeItemID != "" {
+		err = db.QueryRow("SELECT COUNT(*) FROM knowledge_embeddings WHERE item_id != ?", excludeItemID).Scan(&count)
+	} else {
+		err = db.QueryRow("SELECT COUNT(*) FROM knowledge_embeddings").Scan(&count)
+	}
+	if err != nil {
+		return fmt.Errorf("统计知识库块数量失败: %w", err)
+	}
+	if count >= cfg.MaxTotalChunks {
+		return fmt.Errorf("知识库已有 %d 个索引块，达到上限 %d（knowledge.indexing.max_total_chunks），请先清理后再索引",
+			count, cfg.MaxTotalChunks)
+	}
+	return nil
+}
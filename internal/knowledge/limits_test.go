@@ -0,0 +1,74 @@
+package knowledge
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cyberstrike-ai/internal/config"
+	"cyberstrike-ai/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func TestCheckItemContentLimit(t *testing.T) {
+	cfg := &config.IndexingConfig{MaxItemContentBytes: 10}
+	if err := checkItemContentLimit(cfg, "short"); err != nil {
+		t.Errorf("未超限不应报错: %v", err)
+	}
+	if err := checkItemContentLimit(cfg, strings.Repeat("a", 11)); err == nil {
+		t.Error("超过单项字节上限应报错")
+	}
+	// 0 = 不限制
+	if err := checkItemContentLimit(&config.IndexingConfig{}, strings.Repeat("a", 1<<20)); err != nil {
+		t.Errorf("未配置上限不应报错: %v", err)
+	}
+}
+
+func TestKnowledgeCapacityLimits(t *testing.T) {
+	db, err := database.NewKnowledgeDB(filepath.Join(t.TempDir(), "limits.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now()
+	if _, err := db.Exec(
+		"INSERT INTO knowledge_base_items (id, category, title, file_path, content, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		"item-1", "web", "已有项", "/kb/web/a.md", "内容", now, now,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.IndexingConfig{MaxTotalItems: 1, MaxTotalChunks: 2}
+	if err := checkTotalItemsLimit(db.DB, cfg); err == nil {
+		t.Error("item 数已达上限应报错")
+	}
+	if err := checkTotalItemsLimit(db.DB, &config.IndexingConfig{MaxTotalItems: 5}); err != nil {
+		t.Errorf("未达上限不应报错: %v", err)
+	}
+
+	for i, chunk := range []string{"c0", "c1"} {
+		if _, err := db.Exec(
+			"INSERT INTO knowledge_embeddings (id, item_id, chunk_index, chunk_text, embedding, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+			chunk, "item-1", i, "内容"+chunk, "[]", now,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := checkTotalChunksLimit(db.DB, cfg, ""); err == nil {
+		t.Error("chunk 数已达上限应报错")
+	}
+	// 重建 item-1 自己的索引时排除其旧向量，不应被自己挡住
+	if err := checkTotalChunksLimit(db.DB, cfg, "item-1"); err != nil {
+		t.Errorf("排除自身旧向量后不应报错: %v", err)
+	}
+
+	// Manager 入口统一校验
+	m := NewManager(db.DB, t.TempDir(), zap.NewNop())
+	m.SetIndexingLimits(cfg)
+	if _, err := m.CreateItem("web", "新项", "内容"); err == nil {
+		t.Error("超过总 item 上限时 CreateItem 应被拒绝")
+	}
+}
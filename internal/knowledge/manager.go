@@ -10,15 +10,18 @@ import (
 	"strings"
 	"time"
 
+	"cyberstrike-ai/internal/config"
+
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 // Manager 知识库管理器
 type Manager struct {
-	db       *sql.DB
-	basePath string
-	logger   *zap.Logger
+	db          *sql.DB
+	basePath    string
+	logger      *zap.Logger
+	indexingCfg *config.IndexingConfig // 容量限制（可选，见 limits.go）
 }
 
 // NewManager 创建新的知识库管理器
@@ -30,6 +33,11 @@ func NewManager(db *sql.DB, basePath string, logger *zap.Logger) *Manager {
 	}
 }
 
+// SetIndexingLimits 设置容量限制配置；CreateItem/UpdateItem 按此校验单项大小与总量上限。
+func (m *Manager) SetIndexingLimits(cfg *config.IndexingConfig) {
+	m.indexingCfg = cfg
+}
+
 // ScanKnowledgeBase 扫描知识库目录，更新数据库
 // 返回需要索引的知识项ID列表（新添加的或更新的）
 func (m *Manager) ScanKnowledgeBase() ([]string, error) {
@@ -61,11 +69,13 @@ func (m *Manager) ScanKnowledgeBase() ([]string, error) {
 			return err
 		}
 
-		// 第一个目录名作为分类（风险类型）
+		// 第一个目录名作为分类（风险类型），trim 首尾空白避免与检索端匹配不上
 		parts := strings.Split(relPath, string(filepath.Separator))
 		category := "未分类"
 		if len(parts) > 1 {
-			category = parts[0]
+			if c := strings.TrimSpace(parts[0]); c != "" {
+				category = c
+			}
 		}
 
 		// 文件名为标题
@@ -259,9 +269,9 @@ func (m *Manager) GetItemsWithOptions(category string, limit, offset int, includ
 	var args []interface{}
 
 	if includeContent {
-		query = "SELECT id, category, title, file_path, content, created_at, updated_at FROM knowledge_base_items"
+		query = "SELECT id, namespace, category, title, file_path, content, created_at, updated_at FROM knowledge_base_items"
 	} else {
-		query = "SELECT id, category, title, file_path, created_at, updated_at FROM knowledge_base_items"
+		query = "SELECT id, namespace, category, title, file_path, created_at, updated_at FROM knowledge_base_items"
 	}
 
 	if category != "" {
@@ -292,11 +302,11 @@ func (m *Manager) GetItemsWithOptions(category string, limit, offset int, includ
 		var createdAt, updatedAt string
 
 		if includeContent {
-			if err := rows.Scan(&item.ID, &item.Category, &item.Title, &item.FilePath, &item.Content, &createdAt, &updatedAt); err != nil {
+			if err := rows.Scan(&item.ID, &item.Namespace, &item.Category, &item.Title, &item.FilePath, &item.Content, &createdAt, &updatedAt); err != nil {
 				return nil, fmt.Errorf("扫描知识项失败: %w", err)
 			}
 		} else {
-			if err := rows.Scan(&item.ID, &item.Category, &item.Title, &item.FilePath, &createdAt, &updatedAt); err != nil {
+			if err := rows.Scan(&item.ID, &item.Namespace, &item.Category, &item.Title, &item.FilePath, &createdAt, &updatedAt); err != nil {
 				return nil, fmt.Errorf("扫描知识项失败: %w", err)
 			}
 			// 不包含内容时，Content为空字符串
@@ -542,9 +552,9 @@ func (m *Manager) GetItem(id string) (*KnowledgeItem, error) {
 	item := &KnowledgeItem{}
 	var createdAt, updatedAt string
 	err := m.db.QueryRow(
-		"SELECT id, category, title, file_path, content, created_at, updated_at FROM knowledge_base_items WHERE id = ?",
+		"SELECT id, namespace, category, title, file_path, content, COALESCE(boost, 1.0), created_at, updated_at FROM knowledge_base_items WHERE id = ?",
 		id,
-	).Scan(&item.ID, &item.Category, &item.Title, &item.FilePath, &item.Content, &createdAt, &updatedAt)
+	).Scan(&item.ID, &item.Namespace, &item.Category, &item.Title, &item.FilePath, &item.Content, &item.Boost, &createdAt, &updatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("知识项不存在")
@@ -596,6 +606,14 @@ func (m *Manager) GetItem(id string) (*KnowledgeItem, error) {
 
 // CreateItem 创建知识项
 func (m *Manager) CreateItem(category, title, content string) (*KnowledgeItem, error) {
+	category = strings.TrimSpace(category)
+	title = strings.TrimSpace(title)
+	if err := checkItemContentLimit(m.indexingCfg, content); err != nil {
+		return nil, err
+	}
+	if err := checkTotalItemsLimit(m.db, m.indexingCfg); err != nil {
+		return nil, err
+	}
 	id := uuid.New().String()
 	now := time.Now()
 
@@ -634,6 +652,11 @@ func (m *Manager) CreateItem(category, title, content string) (*KnowledgeItem, e
 
 // UpdateItem 更新知识项
 func (m *Manager) UpdateItem(id, category, title, content string) (*KnowledgeItem, error) {
+	category = strings.TrimSpace(category)
+	title = strings.TrimSpace(title)
+	if err := checkItemContentLimit(m.indexingCfg, content); err != nil {
+		return nil, err
+	}
 	// 获取现有项
 	item, err := m.GetItem(id)
 	if err != nil {
@@ -0,0 +1,154 @@
+package knowledge
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 分类管理：KnowledgeItem.Category 由文件夹名决定，这里提供重命名/合并能力，
+// 批量更新条目的 category/file_path 并同步移动磁盘文件。
+// 检索过滤按 knowledge_base_items.category 实时 JOIN（见 knowledgeEmbeddingSelectSQL），
+// 向量按 item_id 关联且内容未变，因此改名/合并后无需重新索引。
+
+// validateCategoryName 规范化并校验分类名（即目录名，不允许路径分隔符）。
+func validateCategoryName(name string) (string, error) {
+	n := strings.TrimSpace(name)
+	if n == "" {
+		return "", fmt.Errorf("分类名不能为空")
+	}
+	if strings.ContainsAny(n, `/\`) || n == "." || n == ".." {
+		return "", fmt.Errorf("分类名不能包含路径分隔符: %q", name)
+	}
+	return n, nil
+}
+
+// GetCategoryStats 列出所有分类及条目数（不含条目明细，复用 CategoryWithItems 结构）。
+func (m *Manager) GetCategoryStats() ([]*CategoryWithItems, error) {
+	rows, err := m.db.Query(`
+		SELECT category, COUNT(*) as item_count
+		FROM knowledge_base_items
+		GROUP BY category
+		ORDER BY category
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("查询分类失败: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]*CategoryWithItems, 0)
+	for rows.Next() {
+		stat := &CategoryWithItems{}
+		if err := rows.Scan(&stat.Category, &stat.ItemCount); err != nil {
+			return nil, fmt.Errorf("扫描分类失败: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// RenameCategory 重命名分类，返回受影响条目数。目标分类已存在时报错（应使用 MergeCategories）。
+func (m *Manager) RenameCategory(oldName, newName string) (int, error) {
+	from, err := validateCategoryName(oldName)
+	if err != nil {
+		return 0, err
+	}
+	to, err := validateCategoryName(newName)
+	if err != nil {
+		return 0, err
+	}
+	if from == to {
+		return 0, fmt.Errorf("新旧分类名相同")
+	}
+	existing, err := m.GetItemsCount(to)
+	if err != nil {
+		return 0, err
+	}
+	if existing > 0 {
+		return 0, fmt.Errorf("目标分类 %q 已存在 %d 个条目，请使用合并接口", to, existing)
+	}
+	return m.moveCategoryItems(from, to)
+}
+
+// MergeCategories 将 source 分类的全部条目并入 target（target 可以已存在），返回移动条目数。
+func (m *Manager) MergeCategories(source, target string) (int, error) {
+	from, err := validateCategoryName(source)
+	if err != nil {
+		return 0, err
+	}
+	to, err := validateCategoryName(target)
+	if err != nil {
+		return 0, err
+	}
+	if from == to {
+		return 0, fmt.Errorf("源分类与目标分类相同")
+	}
+	return m.moveCategoryItems(from, to)
+}
+
+// moveCategoryItems 把 from 分类下的条目全部移入 to：移动磁盘文件（同名时标题追加序号），
+// 逐条更新数据库，最后清理变空的旧分类目录。
+func (m *Manager) moveCategoryItems(from, to string) (int, error) {
+	items, err := m.GetItemsWithOptions(from, 0, 0, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, fmt.Errorf("分类 %q 不存在或没有条目", from)
+	}
+
+	targetDir := filepath.Join(m.basePath, to)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return 0, fmt.Errorf("创建分类目录失败: %w", err)
+	}
+
+	moved := 0
+	for _, item := range items {
+		title := item.Title
+		newPath := filepath.Join(targetDir, title+".md")
+		// 目标目录同名冲突时给标题追加序号，保持一个条目一个文件
+		for i := 2; ; i++ {
+			if _, err := os.Stat(newPath); os.IsNotExist(err) {
+				break
+			}
+			title = fmt.Sprintf("%s-%d", item.Title, i)
+			newPath = filepath.Join(targetDir, title+".md")
+		}
+
+		if item.FilePath != newPath {
+			if err := os.Rename(item.FilePath, newPath); err != nil {
+				if os.IsNotExist(err) {
+					// 源文件缺失（磁盘与库不一致）不阻断，仅更新数据库
+					m.logger.Warn("知识项源文件缺失，仅更新数据库",
+						zap.String("id", item.ID), zap.String("path", item.FilePath))
+				} else {
+					return moved, fmt.Errorf("移动文件失败: %w", err)
+				}
+			}
+		}
+
+		if _, err := m.db.Exec(
+			"UPDATE knowledge_base_items SET category = ?, title = ?, file_path = ?, updated_at = ? WHERE id = ?",
+			to, title, newPath, time.Now(), item.ID,
+		); err != nil {
+			return moved, fmt.Errorf("更新知识项失败: %w", err)
+		}
+		moved++
+	}
+
+	// 删除变空的旧分类目录（不碰知识库根目录）
+	oldDir := filepath.Join(m.basePath, from)
+	if isEmpty, _ := isEmptyDir(oldDir); isEmpty && oldDir != m.basePath {
+		if err := os.Remove(oldDir); err != nil {
+			m.logger.Warn("删除空目录失败", zap.String("dir", oldDir), zap.Error(err))
+		}
+	}
+
+	m.logger.Info("知识库分类已调整",
+		zap.String("from", from), zap.String("to", to), zap.Int("moved", moved))
+	return moved, nil
+}
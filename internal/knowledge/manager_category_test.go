@@ -0,0 +1,108 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cyberstrike-ai/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func setupCategoryTestManager(t *testing.T) *Manager {
+	t.Helper()
+	db, err := database.NewKnowledgeDB(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return NewManager(db.DB, t.TempDir(), zap.NewNop())
+}
+
+func TestRenameCategory(t *testing.T) {
+	m := setupCategoryTestManager(t)
+	if _, err := m.CreateItem("sql注入", "union注入", "content-a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.CreateItem("sql注入", "盲注", "content-b"); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := m.RenameCategory("sql注入", "SQL注入")
+	if err != nil {
+		t.Fatalf("RenameCategory: %v", err)
+	}
+	if updated != 2 {
+		t.Fatalf("updated = %d, want 2", updated)
+	}
+	if n, _ := m.GetItemsCount("SQL注入"); n != 2 {
+		t.Fatalf("新分类条目数 = %d, want 2", n)
+	}
+	if n, _ := m.GetItemsCount("sql注入"); n != 0 {
+		t.Fatalf("旧分类仍有 %d 个条目", n)
+	}
+	// 磁盘文件已随分类移动，旧目录被清理
+	if _, err := os.Stat(filepath.Join(m.basePath, "SQL注入", "union注入.md")); err != nil {
+		t.Fatalf("新分类目录缺少文件: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.basePath, "sql注入")); !os.IsNotExist(err) {
+		t.Fatalf("旧分类目录应被删除: %v", err)
+	}
+
+	// 重命名到已存在的分类应报错（提示使用合并）
+	if _, err := m.CreateItem("XSS", "反射型", "content-c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.RenameCategory("XSS", "SQL注入"); err == nil {
+		t.Fatal("重命名到已有分类应报错")
+	}
+}
+
+func TestMergeCategoriesWithTitleCollision(t *testing.T) {
+	m := setupCategoryTestManager(t)
+	if _, err := m.CreateItem("cat-a", "绕过技巧", "from-a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.CreateItem("cat-b", "绕过技巧", "from-b"); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err := m.MergeCategories("cat-a", "cat-b")
+	if err != nil {
+		t.Fatalf("MergeCategories: %v", err)
+	}
+	if moved != 1 {
+		t.Fatalf("moved = %d, want 1", moved)
+	}
+	if n, _ := m.GetItemsCount("cat-b"); n != 2 {
+		t.Fatalf("目标分类条目数 = %d, want 2", n)
+	}
+	// 同名条目标题追加序号，各自保留一个文件
+	if _, err := os.Stat(filepath.Join(m.basePath, "cat-b", "绕过技巧.md")); err != nil {
+		t.Fatalf("原有文件丢失: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.basePath, "cat-b", "绕过技巧-2.md")); err != nil {
+		t.Fatalf("冲突文件未按序号重命名: %v", err)
+	}
+
+	// 源分类不存在时报错
+	if _, err := m.MergeCategories("不存在的分类", "cat-b"); err == nil {
+		t.Fatal("合并不存在的分类应报错")
+	}
+}
+
+func TestValidateCategoryName(t *testing.T) {
+	if _, err := validateCategoryName("  "); err == nil {
+		t.Fatal("空分类名应报错")
+	}
+	if _, err := validateCategoryName("a/b"); err == nil {
+		t.Fatal("带路径分隔符的分类名应报错")
+	}
+	if _, err := validateCategoryName(".."); err == nil {
+		t.Fatal("上级目录引用应报错")
+	}
+	if got, err := validateCategoryName(" SQL注入 "); err != nil || got != "SQL注入" {
+		t.Fatalf("规范化失败: %q, %v", got, err)
+	}
+}
@@ -0,0 +1,78 @@
+package knowledge
+
+import (
+	"strings"
+
+	"cyberstrike-ai/internal/config"
+)
+
+// queryStopPhrasesZH 内置中文填充短语：LLM 整段话里常见的礼貌/指令前后缀，
+// 对向量检索只贡献噪声，直接从 query 中移除。
+var queryStopPhrasesZH = []string{
+	"请帮我", "请问", "帮我", "麻烦", "谢谢", "我想知道", "我想了解",
+	"能不能", "可不可以", "有没有", "告诉我", "查一下", "检索一下", "搜索一下", "一下",
+}
+
+// queryStopwordsEN 内置英文停用词（完整 token 匹配，大小写不敏感）。
+var queryStopwordsEN = map[string]struct{}{
+	"a": {}, "an": {}, "the": {}, "of": {}, "to": {}, "for": {}, "in": {}, "on": {},
+	"is": {}, "are": {}, "was": {}, "be": {}, "do": {}, "does": {}, "how": {}, "what": {},
+	"please": {}, "could": {}, "would": {}, "can": {}, "you": {}, "me": {}, "i": {},
+	"tell": {}, "about": {}, "want": {}, "know": {},
+}
+
+// PreprocessQuery 对检索 query 做轻量去噪：移除中文填充短语与英文停用词、
+// 折叠空白并按 MaxQueryRunes 截断。清洗后为空时回退原始 query（只截断不去噪），
+// 保证总有可用的检索输入；关键词提取与变体扩展由 MultiQuery（LLM 改写）承担。
+func PreprocessQuery(query string, cfg config.QueryPreprocessConfig) string {
+	original := strings.TrimSpace(query)
+	if original == "" {
+		return original
+	}
+
+	cleaned := original
+	for _, p := range queryStopPhrasesZH {
+		cleaned = strings.ReplaceAll(cleaned, p, " ")
+	}
+
+	// 追加的停用词：中文短语无空格分词，直接按短语替换；英文词在 token 过滤中兜底
+	extra := make(map[string]struct{}, len(cfg.ExtraStopwords))
+	for _, w := range cfg.ExtraStopwords {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		extra[strings.ToLower(w)] = struct{}{}
+		cleaned = strings.ReplaceAll(cleaned, w, " ")
+	}
+
+	tokens := strings.Fields(cleaned)
+	kept := make([]string, 0, len(tokens))
+	for _, tok := range tokens {
+		lower := strings.ToLower(strings.Trim(tok, ",.!?;:，。！？；："))
+		if _, stop := queryStopwordsEN[lower]; stop {
+			continue
+		}
+		if _, stop := extra[lower]; stop {
+			continue
+		}
+		kept = append(kept, tok)
+	}
+
+	cleaned = strings.TrimSpace(strings.Join(kept, " "))
+	if cleaned == "" {
+		cleaned = original
+	}
+	return truncateQueryRunes(cleaned, cfg.MaxQueryRunesEffective())
+}
+
+func truncateQueryRunes(s string, max int) string {
+	if max <= 0 {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max]))
+}
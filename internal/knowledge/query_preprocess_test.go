@@ -0,0 +1,59 @@
+package knowledge
+
+import (
+	"strings"
+	"testing"
+
+	"cyberstrike-ai/internal/config"
+)
+
+func TestPreprocessQueryRemovesStopwords(t *testing.T) {
+	cfg := config.QueryPreprocessConfig{Enabled: true}
+
+	got := PreprocessQuery("请帮我查一下 SQL 注入的绕过方法 谢谢", cfg)
+	if strings.Contains(got, "请帮我") || strings.Contains(got, "谢谢") {
+		t.Fatalf("中文填充短语未移除: %q", got)
+	}
+	if !strings.Contains(got, "SQL") || !strings.Contains(got, "注入") {
+		t.Fatalf("关键内容被误删: %q", got)
+	}
+
+	got = PreprocessQuery("please tell me how to bypass the WAF", cfg)
+	if strings.Contains(strings.ToLower(got), "please") || strings.Contains(strings.ToLower(got), "the") {
+		t.Fatalf("英文停用词未移除: %q", got)
+	}
+	if !strings.Contains(got, "bypass") || !strings.Contains(got, "WAF") {
+		t.Fatalf("关键词被误删: %q", got)
+	}
+}
+
+func TestPreprocessQueryExtraStopwords(t *testing.T) {
+	cfg := config.QueryPreprocessConfig{
+		Enabled:        true,
+		ExtraStopwords: []string{"帮忙看看", "kindly"},
+	}
+	got := PreprocessQuery("帮忙看看 redis 未授权访问 kindly", cfg)
+	if strings.Contains(got, "帮忙看看") || strings.Contains(strings.ToLower(got), "kindly") {
+		t.Fatalf("追加停用词未移除: %q", got)
+	}
+	if !strings.Contains(got, "redis") {
+		t.Fatalf("关键词被误删: %q", got)
+	}
+}
+
+func TestPreprocessQueryTruncatesLongQuery(t *testing.T) {
+	cfg := config.QueryPreprocessConfig{Enabled: true, MaxQueryRunes: 10}
+	got := PreprocessQuery(strings.Repeat("漏洞", 50), cfg)
+	if n := len([]rune(got)); n > 10 {
+		t.Fatalf("query 未按 MaxQueryRunes 截断: %d runes", n)
+	}
+}
+
+func TestPreprocessQueryFallsBackWhenCleanedEmpty(t *testing.T) {
+	cfg := config.QueryPreprocessConfig{Enabled: true}
+	// 全部为停用词时回退原始 query，保证检索总有输入
+	got := PreprocessQuery("please tell me", cfg)
+	if got == "" {
+		t.Fatal("清洗为空时应回退原始 query")
+	}
+}
@@ -75,23 +75,28 @@ func (r *HTTPReranker) Rerank(ctx context.Context, query string, docs []*schema.
 		}
 		texts = append(texts, d.Content)
 	}
-	var order []int
+	var hits []rerankHit
 	var err error
 	switch r.provider {
 	case "dashscope":
-		order, err = r.rerankDashScope(ctx, q, texts, len(docs))
+		hits, err = r.rerankDashScope(ctx, q, texts, len(docs))
 	default:
-		order, err = r.rerankCohere(ctx, q, texts, len(docs))
+		hits, err = r.rerankCohere(ctx, q, texts, len(docs))
 	}
 	if err != nil {
 		return nil, err
 	}
-	out := make([]*schema.Document, 0, len(order))
-	for _, idx := range order {
-		if idx < 0 || idx >= len(docs) || docs[idx] == nil {
+	out := make([]*schema.Document, 0, len(hits))
+	for _, hit := range hits {
+		if hit.index < 0 || hit.index >= len(docs) || docs[hit.index] == nil {
 			continue
 		}
-		out = append(out, docs[idx])
+		doc := docs[hit.index]
+		if hit.hasScore {
+			// 精排相关性分写回文档分数，经 documentsToRetrievalResults 暴露为 Score
+			doc.WithScore(hit.score)
+		}
+		out = append(out, doc)
 	}
 	if len(out) == 0 {
 		return docs, nil
@@ -99,7 +104,30 @@ func (r *HTTPReranker) Rerank(ctx context.Context, query string, docs []*schema.
 	return out, nil
 }
 
-func (r *HTTPReranker) rerankCohere(ctx context.Context, query string, documents []string, topN int) ([]int, error) {
+// rerankHit 精排 API 返回的单条命中：原候选下标与（可选的）相关性分。
+type rerankHit struct {
+	index    int
+	score    float64
+	hasScore bool
+}
+
+func rerankHitsFromResults(results []struct {
+	Index          int      `json:"index"`
+	RelevanceScore *float64 `json:"relevance_score"`
+}) []rerankHit {
+	hits := make([]rerankHit, 0, len(results))
+	for _, row := range results {
+		hit := rerankHit{index: row.Index}
+		if row.RelevanceScore != nil {
+			hit.score = *row.RelevanceScore
+			hit.hasScore = true
+		}
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+func (r *HTTPReranker) rerankCohere(ctx context.Context, query string, documents []string, topN int) ([]rerankHit, error) {
 	url := r.cohereRerankURL()
 	body := map[string]any{
 		"model":     r.model,
@@ -128,20 +156,17 @@ func (r *HTTPReranker) rerankCohere(ctx context.Context, query string, documents
 	}
 	var parsed struct {
 		Results []struct {
-			Index int `json:"index"`
+			Index          int      `json:"index"`
+			RelevanceScore *float64 `json:"relevance_score"`
 		} `json:"results"`
 	}
 	if err := json.Unmarshal(respBody, &parsed); err != nil {
 		return nil, fmt.Errorf("rerank decode: %w", err)
 	}
-	order := make([]int, 0, len(parsed.Results))
-	for _, row := range parsed.Results {
-		order = append(order, row.Index)
-	}
-	return order, nil
+	return rerankHitsFromResults(parsed.Results), nil
 }
 
-func (r *HTTPReranker) rerankDashScope(ctx context.Context, query string, documents []string, topN int) ([]int, error) {
+func (r *HTTPReranker) rerankDashScope(ctx context.Context, query string, documents []string, topN int) ([]rerankHit, error) {
 	url := r.dashscopeRerankURL()
 	body := map[string]any{
 		"model": r.model,
@@ -176,18 +201,15 @@ func (r *HTTPReranker) rerankDashScope(ctx context.Context, query string, docume
 	var parsed struct {
 		Output struct {
 			Results []struct {
-				Index int `json:"index"`
+				Index          int      `json:"index"`
+				RelevanceScore *float64 `json:"relevance_score"`
 			} `json:"results"`
 		} `json:"output"`
 	}
 	if err := json.Unmarshal(respBody, &parsed); err != nil {
 		return nil, fmt.Errorf("dashscope rerank decode: %w", err)
 	}
-	order := make([]int, 0, len(parsed.Output.Results))
-	for _, row := range parsed.Output.Results {
-		order = append(order, row.Index)
-	}
-	return order, nil
+	return rerankHitsFromResults(parsed.Output.Results), nil
 }
 
 func (r *HTTPReranker) cohereRerankURL() string {
@@ -217,8 +239,8 @@ func (r *HTTPReranker) dashscopeRerankURL() string {
 
 func truncateForRerankLog(s string) string {
 	s = strings.TrimSpace(s)
-	if len(s) > 512 {
-		return s[:512] + "..."
+	if rs := []rune(s); len(rs) > 512 {
+		return string(rs[:512]) + "..."
 	}
 	return s
 }
@@ -48,6 +48,9 @@ func TestHTTPReranker_CohereOrder(t *testing.T) {
 	if len(out) != 2 || out[0].ID != "c" || out[1].ID != "a" {
 		t.Fatalf("order wrong: %#v", out)
 	}
+	if out[0].Score() != 0.9 || out[1].Score() != 0.5 {
+		t.Fatalf("relevance_score 未写回文档分数: %v / %v", out[0].Score(), out[1].Score())
+	}
 }
 
 func TestHTTPReranker_DashScopeOrder(t *testing.T) {
@@ -80,6 +83,9 @@ func TestHTTPReranker_DashScopeOrder(t *testing.T) {
 	if len(out) != 1 || out[0].ID != "b" {
 		t.Fatalf("got %#v", out)
 	}
+	if out[0].Score() != 0.88 {
+		t.Fatalf("relevance_score 未写回文档分数: %v", out[0].Score())
+	}
 }
 
 func TestRerankConfigDefaults(t *testing.T) {
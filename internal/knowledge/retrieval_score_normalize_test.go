@@ -0,0 +1,45 @@
+package knowledge
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeRetrievalScores(t *testing.T) {
+	results := []*RetrievalResult{
+		{Similarity: 0.82},
+		{Similarity: 0.70},
+		{Similarity: 0.58},
+	}
+	normalizeRetrievalScores(results)
+	if results[0].NormalizedScore != 1 {
+		t.Fatalf("max similarity should normalize to 1, got %v", results[0].NormalizedScore)
+	}
+	if results[2].NormalizedScore != 0 {
+		t.Fatalf("min similarity should normalize to 0, got %v", results[2].NormalizedScore)
+	}
+	if math.Abs(results[1].NormalizedScore-0.5) > 1e-9 {
+		t.Fatalf("middle similarity should normalize to 0.5, got %v", results[1].NormalizedScore)
+	}
+	// 原始相似度保持不变
+	if results[0].Similarity != 0.82 {
+		t.Fatalf("similarity should stay untouched, got %v", results[0].Similarity)
+	}
+}
+
+func TestNormalizeRetrievalScoresDegenerateSets(t *testing.T) {
+	// 空集不 panic
+	normalizeRetrievalScores(nil)
+
+	single := []*RetrievalResult{{Similarity: 0.42}}
+	normalizeRetrievalScores(single)
+	if single[0].NormalizedScore != 1 {
+		t.Fatalf("single candidate should normalize to 1, got %v", single[0].NormalizedScore)
+	}
+
+	equal := []*RetrievalResult{{Similarity: 0.6}, {Similarity: 0.6}}
+	normalizeRetrievalScores(equal)
+	if equal[0].NormalizedScore != 1 || equal[1].NormalizedScore != 1 {
+		t.Fatalf("all-equal candidates should normalize to 1, got %v / %v", equal[0].NormalizedScore, equal[1].NormalizedScore)
+	}
+}
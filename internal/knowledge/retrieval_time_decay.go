@@ -0,0 +1,26 @@
+package knowledge
+
+import (
+	"math"
+	"time"
+
+	"cyberstrike-ai/internal/config"
+)
+
+// timeDecayFactor 按知识项 updated_at 距 now 的时长计算指数衰减因子（0.5^(age/halfLife)，
+// 下限 MinFactor）。未启用、updated_at 为零值或不早于 now 时返回 1，保持现有打分不变。
+func timeDecayFactor(updatedAt, now time.Time, cfg config.TimeDecayConfig) float64 {
+	if !cfg.Enabled || updatedAt.IsZero() {
+		return 1
+	}
+	age := now.Sub(updatedAt)
+	if age <= 0 {
+		return 1
+	}
+	halfLife := cfg.HalfLifeDaysEffective() * 24 * float64(time.Hour)
+	factor := math.Pow(0.5, float64(age)/halfLife)
+	if min := cfg.MinFactorEffective(); factor < min {
+		return min
+	}
+	return factor
+}
@@ -0,0 +1,38 @@
+package knowledge
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"cyberstrike-ai/internal/config"
+)
+
+func TestTimeDecayFactor(t *testing.T) {
+	now := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	enabled := config.TimeDecayConfig{Enabled: true, HalfLifeDays: 180, MinFactor: 0.3}
+
+	// 默认关闭：任何时间都不衰减
+	if got := timeDecayFactor(now.AddDate(-2, 0, 0), now, config.TimeDecayConfig{}); got != 1 {
+		t.Fatalf("disabled factor = %v, want 1", got)
+	}
+	// 零值时间（旧数据缺 updated_at）与未来时间不衰减
+	if got := timeDecayFactor(time.Time{}, now, enabled); got != 1 {
+		t.Fatalf("zero time factor = %v, want 1", got)
+	}
+	if got := timeDecayFactor(now.Add(time.Hour), now, enabled); got != 1 {
+		t.Fatalf("future time factor = %v, want 1", got)
+	}
+	// 恰好一个半衰期 → 0.5
+	if got := timeDecayFactor(now.AddDate(0, 0, -180), now, enabled); math.Abs(got-0.5) > 1e-9 {
+		t.Fatalf("one half-life factor = %v, want 0.5", got)
+	}
+	// 足够老时落在下限上
+	if got := timeDecayFactor(now.AddDate(-10, 0, 0), now, enabled); got != 0.3 {
+		t.Fatalf("floor factor = %v, want 0.3", got)
+	}
+	// 未配置半衰期与下限时取默认值（180 天 / 0.3）
+	if got := timeDecayFactor(now.AddDate(0, 0, -180), now, config.TimeDecayConfig{Enabled: true}); math.Abs(got-0.5) > 1e-9 {
+		t.Fatalf("default half-life factor = %v, want 0.5", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"cyberstrike-ai/internal/config"
 
@@ -28,27 +29,39 @@ type Retriever struct {
 	rerankMu sync.RWMutex
 	reranker DocumentReranker
 
-	pipeline retriever.Retriever
+	pipeline   retriever.Retriever
 	wireOpenAI *config.OpenAIConfig
+
+	searchCache *searchResultCache
 }
 
 // RetrievalConfig 检索配置
 type RetrievalConfig struct {
 	TopK                int
 	SimilarityThreshold float64
-	SubIndexFilter      string
-	MultiQuery          config.MultiQueryConfig
-	Rerank              config.RerankConfig
-	PostRetrieve        config.PostRetrieveConfig
+	// ThresholdMode 阈值策略：fixed / adaptive / topk，语义见 [config.RetrievalConfig]。
+	ThresholdMode string
+	// MinSimilarityThreshold adaptive 模式降阈值的下限；<=0 时取 0.3。
+	MinSimilarityThreshold float64
+	SubIndexFilter         string
+	// CacheTTLSeconds 相同查询条件的检索结果缓存秒数；<=0 不缓存。
+	CacheTTLSeconds   int
+	QueryPreprocess   config.QueryPreprocessConfig
+	MultiQuery        config.MultiQueryConfig
+	Rerank            config.RerankConfig
+	PostRetrieve      config.PostRetrieveConfig
+	NeighborExpansion config.NeighborExpansionConfig
+	TimeDecay         config.TimeDecayConfig
 }
 
 // NewRetriever 创建新的检索器
 func NewRetriever(db *sql.DB, embedder *Embedder, config *RetrievalConfig, logger *zap.Logger) *Retriever {
 	return &Retriever{
-		db:       db,
-		embedder: embedder,
-		config:   config,
-		logger:   logger,
+		db:          db,
+		embedder:    embedder,
+		config:      config,
+		logger:      logger,
+		searchCache: newSearchResultCache(),
 	}
 }
 
@@ -122,12 +135,95 @@ func (r *Retriever) Search(ctx context.Context, req *SearchRequest) ([]*Retrieva
 	if q == "" {
 		return nil, fmt.Errorf("查询不能为空")
 	}
+	mode, err := normalizeSearchMode(req.Mode)
+	if err != nil {
+		return nil, err
+	}
+	// 可选检索结果缓存：同查询条件短期内直接复用，省掉嵌入与全表相似度计算
+	cacheTTL := r.searchCacheTTL()
+	cacheKey := ""
+	if cacheTTL > 0 {
+		cacheKey = searchCacheKey(req, q, mode)
+		if cached, ok := r.searchCache.get(cacheKey); ok {
+			if r.logger != nil {
+				r.logger.Debug("知识库检索命中缓存",
+					zap.String("mode", mode),
+					zap.Int("hits", len(cached)))
+			}
+			return cached, nil
+		}
+	}
+	// keyword 模式不走向量流水线，直接对 chunk_text 精确匹配（原词不做预处理）
+	if mode == SearchModeKeyword {
+		results, err := r.keywordSearch(ctx, req, q)
+		if err != nil {
+			return nil, err
+		}
+		normalizeRetrievalScores(results)
+		r.searchCache.put(cacheKey, results, cacheTTL)
+		return results, nil
+	}
+	// 可选查询预处理：LLM 传来的整段话先去噪/截断再进检索流水线
+	if r.config != nil && r.config.QueryPreprocess.Enabled {
+		if cleaned := PreprocessQuery(q, r.config.QueryPreprocess); cleaned != q {
+			if r.logger != nil {
+				r.logger.Debug("检索 query 已预处理",
+					zap.Int("rawRunes", len([]rune(q))),
+					zap.Int("cleanedRunes", len([]rune(cleaned))))
+			}
+			q = cleaned
+		}
+	}
 	opts := r.einoRetrieverOptions(req)
 	docs, err := r.activeEinoRetriever().Retrieve(ctx, q, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return documentsToRetrievalResults(docs)
+	results, err := documentsToRetrievalResults(docs)
+	if err != nil {
+		return nil, err
+	}
+	if mode == SearchModeHybrid {
+		// 融合关键词精确命中：没有精确匹配时与纯语义结果一致
+		results = r.mergeKeywordResults(ctx, req, q, results)
+	}
+	results = r.expandNeighborChunks(ctx, results)
+	normalizeRetrievalScores(results)
+	r.searchCache.put(cacheKey, results, cacheTTL)
+	return results, nil
+}
+
+// searchCacheTTL 返回检索结果缓存时长；未配置时为 0（不缓存）。
+func (r *Retriever) searchCacheTTL() time.Duration {
+	if r.config == nil || r.config.CacheTTLSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(r.config.CacheTTLSeconds) * time.Second
+}
+
+// normalizeRetrievalScores 对本次候选集的余弦相似度做 min-max 归一化，写入 NormalizedScore。
+// 候选集只有一条或分数全部相同时归一化无意义，统一置 1。
+func normalizeRetrievalScores(results []*RetrievalResult) {
+	if len(results) == 0 {
+		return
+	}
+	minSim, maxSim := results[0].Similarity, results[0].Similarity
+	for _, res := range results[1:] {
+		if res.Similarity < minSim {
+			minSim = res.Similarity
+		}
+		if res.Similarity > maxSim {
+			maxSim = res.Similarity
+		}
+	}
+	span := maxSim - minSim
+	for _, res := range results {
+		if span <= 0 {
+			res.NormalizedScore = 1
+			continue
+		}
+		res.NormalizedScore = (res.Similarity - minSim) / span
+	}
 }
 
 func (r *Retriever) einoRetrieverOptions(req *SearchRequest) []retriever.Option {
@@ -145,6 +241,9 @@ func (r *Retriever) einoRetrieverOptions(req *SearchRequest) []retriever.Option
 	if strings.TrimSpace(req.SubIndexFilter) != "" {
 		dsl[DSLSubIndexFilter] = strings.TrimSpace(req.SubIndexFilter)
 	}
+	if strings.TrimSpace(req.Namespace) != "" {
+		dsl[DSLNamespace] = strings.TrimSpace(req.Namespace)
+	}
 	if len(dsl) > 0 {
 		opts = append(opts, retriever.WithDSLInfo(dsl))
 	}
@@ -168,12 +267,17 @@ func (r *Retriever) AsEinoRetriever() retriever.Retriever {
 	return r.activeEinoRetriever()
 }
 
-func (r *Retriever) knowledgeEmbeddingSelectSQL(riskType, subIndexFilter string) (string, []interface{}) {
-	q := `SELECT e.id, e.item_id, e.chunk_index, e.chunk_text, e.embedding, e.embedding_model, e.embedding_dim, i.category, i.title
+func (r *Retriever) knowledgeEmbeddingSelectSQL(riskType, subIndexFilter, namespace string) (string, []interface{}) {
+	q := `SELECT e.id, e.item_id, e.chunk_index, e.chunk_text, e.embedding, e.embedding_model, e.embedding_dim, i.namespace, i.category, i.title, COALESCE(i.boost, 1.0), i.updated_at
 FROM knowledge_embeddings e
 JOIN knowledge_base_items i ON e.item_id = i.id
 WHERE 1=1`
 	var args []interface{}
+	if ns := strings.TrimSpace(namespace); ns != "" {
+		// 命名空间隔离：仅命中指定 namespace 的知识项（空串表示不限制）
+		q += ` AND TRIM(i.namespace) = TRIM(?) COLLATE NOCASE`
+		args = append(args, ns)
+	}
 	if strings.TrimSpace(riskType) != "" {
 		q += ` AND TRIM(i.category) = TRIM(?) COLLATE NOCASE`
 		args = append(args, riskType)
@@ -186,6 +290,45 @@ WHERE 1=1`
 	return q, args
 }
 
+// knowledgeEmbeddingRow knowledgeEmbeddingSelectSQL 选出的一行。向量检索与关键词检索
+// 共用 scanKnowledgeEmbeddingRow 扫描，SELECT 列有增减时只需同步这一处，
+// 不会再出现一条路径加列、另一条路径漏扫导致 Scan 整行失败的情况。
+type knowledgeEmbeddingRow struct {
+	chunkID       string
+	itemID        string
+	chunkIndex    int
+	chunkText     string
+	embeddingJSON string
+	model         string
+	dim           int
+	namespace     string
+	category      string
+	title         string
+	boost         float64
+	updatedAt     time.Time
+}
+
+func scanKnowledgeEmbeddingRow(rows *sql.Rows) (*knowledgeEmbeddingRow, error) {
+	var row knowledgeEmbeddingRow
+	if err := rows.Scan(&row.chunkID, &row.itemID, &row.chunkIndex, &row.chunkText, &row.embeddingJSON,
+		&row.model, &row.dim, &row.namespace, &row.category, &row.title, &row.boost, &row.updatedAt); err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// item 把行内知识项字段转为 KnowledgeItem（两条读路径共用）。
+func (row *knowledgeEmbeddingRow) item() *KnowledgeItem {
+	return &KnowledgeItem{
+		ID:        row.itemID,
+		Namespace: row.namespace,
+		Category:  row.category,
+		Title:     row.title,
+		Boost:     row.boost,
+		UpdatedAt: row.updatedAt,
+	}
+}
+
 // vectorSearch 纯向量检索：余弦相似度排序，按相似度阈值与 TopK 截断（无 BM25、无混合分、无邻块扩展）。
 func (r *Retriever) vectorSearch(ctx context.Context, req *SearchRequest) ([]*RetrievalResult, error) {
 	if req.Query == "" {
@@ -224,7 +367,7 @@ func (r *Retriever) vectorSearch(ctx context.Context, req *SearchRequest) ([]*Re
 		expectedModel = r.embedder.EmbeddingModelName()
 	}
 
-	sqlStr, sqlArgs := r.knowledgeEmbeddingSelectSQL(strings.TrimSpace(req.RiskType), subIdxFilter)
+	sqlStr, sqlArgs := r.knowledgeEmbeddingSelectSQL(strings.TrimSpace(req.RiskType), subIdxFilter, strings.TrimSpace(req.Namespace))
 	rows, err := r.db.QueryContext(ctx, sqlStr, sqlArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("查询向量失败: %w", err)
@@ -235,7 +378,14 @@ func (r *Retriever) vectorSearch(ctx context.Context, req *SearchRequest) ([]*Re
 		chunk      *KnowledgeChunk
 		item       *KnowledgeItem
 		similarity float64
+		score      float64 // similarity × 知识项 boost × 时间衰减因子，用于排序与 TopK
+	}
+
+	var decayCfg config.TimeDecayConfig
+	if r.config != nil {
+		decayCfg = r.config.TimeDecay
 	}
+	now := time.Now()
 
 	candidates := make([]candidate, 0)
 	rowNum := 0
@@ -249,59 +399,94 @@ func (r *Retriever) vectorSearch(ctx context.Context, req *SearchRequest) ([]*Re
 			}
 		}
 
-		var chunkID, itemID, chunkText, embeddingJSON, category, title, rowModel string
-		var chunkIndex, rowDim int
-
-		if err := rows.Scan(&chunkID, &itemID, &chunkIndex, &chunkText, &embeddingJSON, &rowModel, &rowDim, &category, &title); err != nil {
+		row, err := scanKnowledgeEmbeddingRow(rows)
+		if err != nil {
 			r.logger.Warn("扫描向量失败", zap.Error(err))
 			continue
 		}
 
 		var embedding []float32
-		if err := json.Unmarshal([]byte(embeddingJSON), &embedding); err != nil {
+		if err := json.Unmarshal([]byte(row.embeddingJSON), &embedding); err != nil {
 			r.logger.Warn("解析向量失败", zap.Error(err))
 			continue
 		}
 
-		if rowDim > 0 && len(embedding) != rowDim {
-			r.logger.Debug("跳过维度不一致的向量行", zap.String("chunkId", chunkID), zap.Int("rowDim", rowDim), zap.Int("got", len(embedding)))
+		if row.dim > 0 && len(embedding) != row.dim {
+			r.logger.Debug("跳过维度不一致的向量行", zap.String("chunkId", row.chunkID), zap.Int("rowDim", row.dim), zap.Int("got", len(embedding)))
 			continue
 		}
 		if queryDim > 0 && len(embedding) != queryDim {
-			r.logger.Debug("跳过与查询维度不一致的向量", zap.String("chunkId", chunkID), zap.Int("queryDim", queryDim), zap.Int("got", len(embedding)))
+			r.logger.Debug("跳过与查询维度不一致的向量", zap.String("chunkId", row.chunkID), zap.Int("queryDim", queryDim), zap.Int("got", len(embedding)))
 			continue
 		}
-		if expectedModel != "" && strings.TrimSpace(rowModel) != "" && strings.TrimSpace(rowModel) != expectedModel {
-			r.logger.Debug("跳过嵌入模型不一致的行", zap.String("chunkId", chunkID), zap.String("rowModel", rowModel), zap.String("expected", expectedModel))
+		if expectedModel != "" && strings.TrimSpace(row.model) != "" && strings.TrimSpace(row.model) != expectedModel {
+			r.logger.Debug("跳过嵌入模型不一致的行", zap.String("chunkId", row.chunkID), zap.String("rowModel", row.model), zap.String("expected", expectedModel))
 			continue
 		}
 
 		similarity := cosineSimilarity(queryEmbedding, embedding)
+		if row.boost <= 0 {
+			// 非法取值按不加权处理，避免脏数据把文档彻底打压消失
+			row.boost = 1
+		}
+		decay := timeDecayFactor(row.updatedAt, now, decayCfg)
 		candidates = append(candidates, candidate{
 			chunk: &KnowledgeChunk{
-				ID:         chunkID,
-				ItemID:     itemID,
-				ChunkIndex: chunkIndex,
-				ChunkText:  chunkText,
+				ID:         row.chunkID,
+				ItemID:     row.itemID,
+				ChunkIndex: row.chunkIndex,
+				ChunkText:  row.chunkText,
 				Embedding:  embedding,
 			},
-			item: &KnowledgeItem{
-				ID:       itemID,
-				Category: category,
-				Title:    title,
-			},
+			item:       row.item(),
 			similarity: similarity,
+			score:      similarity * row.boost * decay,
 		})
 	}
 
+	// 排序与 TopK 用加权分（高 boost 的 SOP 文档在相近相似度下优先进 TopK，
+	// 启用时间衰减后越新的知识项分越高）；阈值过滤仍按原始余弦相似度，
+	// boost 与衰减都不改变相关性判定
 	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].similarity > candidates[j].similarity
+		return candidates[i].score > candidates[j].score
 	})
 
-	filtered := make([]candidate, 0, len(candidates))
-	for _, c := range candidates {
-		if c.similarity >= threshold {
-			filtered = append(filtered, c)
+	mode := "fixed"
+	minThreshold := 0.3
+	if r.config != nil {
+		if m := strings.ToLower(strings.TrimSpace(r.config.ThresholdMode)); m == "adaptive" || m == "topk" {
+			mode = m
+		}
+		if r.config.MinSimilarityThreshold > 0 {
+			minThreshold = r.config.MinSimilarityThreshold
+		}
+	}
+	appliedThreshold := threshold
+	if mode == "topk" {
+		// topk 模式：忽略阈值，直接取相似度最高的 TopK。
+		appliedThreshold = 0
+	}
+
+	filterByThreshold := func(th float64) []candidate {
+		filtered := make([]candidate, 0, len(candidates))
+		for _, c := range candidates {
+			if c.similarity >= th {
+				filtered = append(filtered, c)
+			}
+		}
+		return filtered
+	}
+
+	filtered := filterByThreshold(appliedThreshold)
+	if len(filtered) == 0 && mode == "adaptive" && appliedThreshold > minThreshold {
+		// 自适应：按配置阈值无结果时降到下限重试一次，结果里通过 AppliedThreshold 标注实际阈值。
+		appliedThreshold = minThreshold
+		filtered = filterByThreshold(appliedThreshold)
+		if len(filtered) > 0 && r.logger != nil {
+			r.logger.Info("知识库检索自适应降阈值命中",
+				zap.Float64("configuredThreshold", threshold),
+				zap.Float64("appliedThreshold", appliedThreshold),
+				zap.Int("hits", len(filtered)))
 		}
 	}
 
@@ -312,10 +497,11 @@ func (r *Retriever) vectorSearch(ctx context.Context, req *SearchRequest) ([]*Re
 	results := make([]*RetrievalResult, len(filtered))
 	for i, c := range filtered {
 		results[i] = &RetrievalResult{
-			Chunk:      c.chunk,
-			Item:       c.item,
-			Similarity: c.similarity,
-			Score:      c.similarity,
+			Chunk:            c.chunk,
+			Item:             c.item,
+			Similarity:       c.similarity,
+			Score:            c.score,
+			AppliedThreshold: appliedThreshold,
 		}
 	}
 	return results, nil
@@ -324,11 +510,17 @@ func (r *Retriever) vectorSearch(ctx context.Context, req *SearchRequest) ([]*Re
 // RetrievalConfigFromYAML maps API/YAML retrieval settings into the knowledge package.
 func RetrievalConfigFromYAML(r config.RetrievalConfig) *RetrievalConfig {
 	return &RetrievalConfig{
-		TopK:                r.TopK,
-		SimilarityThreshold: r.SimilarityThreshold,
-		SubIndexFilter:      r.SubIndexFilter,
-		MultiQuery:          r.MultiQuery,
-		Rerank:              r.Rerank,
-		PostRetrieve:        r.PostRetrieve,
+		TopK:                   r.TopK,
+		SimilarityThreshold:    r.SimilarityThreshold,
+		ThresholdMode:          r.ThresholdModeEffective(),
+		MinSimilarityThreshold: r.MinSimilarityThresholdEffective(),
+		SubIndexFilter:         r.SubIndexFilter,
+		CacheTTLSeconds:        r.CacheTTLSeconds,
+		QueryPreprocess:        r.QueryPreprocess,
+		MultiQuery:             r.MultiQuery,
+		Rerank:                 r.Rerank,
+		PostRetrieve:           r.PostRetrieve,
+		NeighborExpansion:      r.NeighborExpansion,
+		TimeDecay:              r.TimeDecay,
 	}
 }
@@ -0,0 +1,117 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// 邻块扩展（retrieval.neighbor_expansion，small-to-big 检索）：
+// 命中一个小 chunk 时单独看缺乏上下文，这里把同一知识项的 chunk_index±window
+// 相邻块一并取回，tool 层按 chunk_index 排序展示时自然拼接在主命中前后。
+// 相邻块继承源命中的相似度（仅作上下文，不影响排序与归一化），并以
+// NeighborContext 标记区分。
+
+// expandNeighborChunks 对命中结果补充相邻块；未启用或无结果时原样返回。
+func (r *Retriever) expandNeighborChunks(ctx context.Context, results []*RetrievalResult) []*RetrievalResult {
+	if r == nil || r.config == nil || !r.config.NeighborExpansion.Enabled || len(results) == 0 {
+		return results
+	}
+	window := r.config.NeighborExpansion.WindowEffective()
+
+	// 已有的 (itemID, chunkIndex)，避免把已命中的块重复补进来
+	present := make(map[string]map[int]bool)
+	for _, res := range results {
+		if res == nil || res.Chunk == nil || res.Item == nil {
+			continue
+		}
+		if present[res.Item.ID] == nil {
+			present[res.Item.ID] = make(map[int]bool)
+		}
+		present[res.Item.ID][res.Chunk.ChunkIndex] = true
+	}
+
+	// 每个待取邻块记住来源命中（继承相似度与条目信息）；results 已按分数降序，
+	// 先到先得即窗口重叠时归属分数更高的命中
+	type neighborSource struct {
+		source *RetrievalResult
+	}
+	wanted := make(map[string]map[int]neighborSource)
+	for _, res := range results {
+		if res == nil || res.Chunk == nil || res.Item == nil || res.NeighborContext {
+			continue
+		}
+		for offset := 1; offset <= window; offset++ {
+			for _, idx := range []int{res.Chunk.ChunkIndex - offset, res.Chunk.ChunkIndex + offset} {
+				if idx < 0 || present[res.Item.ID][idx] {
+					continue
+				}
+				if wanted[res.Item.ID] == nil {
+					wanted[res.Item.ID] = make(map[int]neighborSource)
+				}
+				if _, exists := wanted[res.Item.ID][idx]; !exists {
+					wanted[res.Item.ID][idx] = neighborSource{source: res}
+				}
+			}
+		}
+	}
+	if len(wanted) == 0 {
+		return results
+	}
+
+	for itemID, indexes := range wanted {
+		placeholders := make([]string, 0, len(indexes))
+		args := make([]interface{}, 0, len(indexes)+1)
+		args = append(args, itemID)
+		for idx := range indexes {
+			placeholders = append(placeholders, "?")
+			args = append(args, idx)
+		}
+		query := fmt.Sprintf(
+			"SELECT id, chunk_index, chunk_text FROM knowledge_embeddings WHERE item_id = ? AND chunk_index IN (%s)",
+			strings.Join(placeholders, ","),
+		)
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			if r.logger != nil {
+				r.logger.Warn("邻块扩展查询失败", zap.String("itemId", itemID), zap.Error(err))
+			}
+			continue
+		}
+		for rows.Next() {
+			var chunkID, chunkText string
+			var chunkIndex int
+			if err := rows.Scan(&chunkID, &chunkIndex, &chunkText); err != nil {
+				if r.logger != nil {
+					r.logger.Warn("扫描邻块失败", zap.Error(err))
+				}
+				continue
+			}
+			src, ok := indexes[chunkIndex]
+			if !ok || present[itemID][chunkIndex] {
+				continue
+			}
+			if present[itemID] == nil {
+				present[itemID] = make(map[int]bool)
+			}
+			present[itemID][chunkIndex] = true
+			results = append(results, &RetrievalResult{
+				Chunk: &KnowledgeChunk{
+					ID:         chunkID,
+					ItemID:     itemID,
+					ChunkIndex: chunkIndex,
+					ChunkText:  chunkText,
+				},
+				Item:             src.source.Item,
+				Similarity:       src.source.Similarity,
+				Score:            src.source.Score,
+				AppliedThreshold: src.source.AppliedThreshold,
+				NeighborContext:  true,
+			})
+		}
+		rows.Close()
+	}
+	return results
+}
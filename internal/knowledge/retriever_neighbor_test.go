@@ -0,0 +1,115 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cyberstrike-ai/internal/config"
+	"cyberstrike-ai/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func setupNeighborTestRetriever(t *testing.T, window int) (*Retriever, string) {
+	t.Helper()
+	db, err := database.NewKnowledgeDB(filepath.Join(t.TempDir(), "neighbor.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now()
+	itemID := "item-1"
+	if _, err := db.Exec(
+		"INSERT INTO knowledge_base_items (id, category, title, file_path, content, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		itemID, "SQL注入", "联合注入", "/kb/SQL注入/联合注入.md", "全文", now, now,
+	); err != nil {
+		t.Fatalf("插入知识项: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := db.Exec(
+			"INSERT INTO knowledge_embeddings (id, item_id, chunk_index, chunk_text, embedding, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+			fmt.Sprintf("chunk-%d", i), itemID, i, fmt.Sprintf("第 %d 块内容", i), "[]", now,
+		); err != nil {
+			t.Fatalf("插入向量块: %v", err)
+		}
+	}
+
+	cfg := &RetrievalConfig{
+		NeighborExpansion: config.NeighborExpansionConfig{Enabled: true, Window: window},
+	}
+	return NewRetriever(db.DB, nil, cfg, zap.NewNop()), itemID
+}
+
+func TestExpandNeighborChunks(t *testing.T) {
+	r, itemID := setupNeighborTestRetriever(t, 1)
+	hit := &RetrievalResult{
+		Chunk:      &KnowledgeChunk{ID: "chunk-2", ItemID: itemID, ChunkIndex: 2, ChunkText: "第 2 块内容"},
+		Item:       &KnowledgeItem{ID: itemID, Category: "SQL注入", Title: "联合注入"},
+		Similarity: 0.9,
+		Score:      0.9,
+	}
+
+	results := r.expandNeighborChunks(context.Background(), []*RetrievalResult{hit})
+	if len(results) != 3 {
+		t.Fatalf("results = %d, want 3（命中 + 前后各 1）", len(results))
+	}
+
+	gotIndexes := map[int]bool{}
+	for _, res := range results[1:] {
+		if !res.NeighborContext {
+			t.Fatalf("扩展块应标记 NeighborContext: %+v", res)
+		}
+		if res.Similarity != 0.9 {
+			t.Fatalf("扩展块应继承源命中相似度: %+v", res)
+		}
+		if res.Item != hit.Item {
+			t.Fatalf("扩展块应复用源命中的条目信息")
+		}
+		gotIndexes[res.Chunk.ChunkIndex] = true
+	}
+	if !gotIndexes[1] || !gotIndexes[3] {
+		t.Fatalf("应扩展 chunk_index 1 和 3，实际: %v", gotIndexes)
+	}
+}
+
+func TestExpandNeighborChunksWindowAndDedupe(t *testing.T) {
+	r, itemID := setupNeighborTestRetriever(t, 2)
+	item := &KnowledgeItem{ID: itemID}
+	// 命中 1 和 2：窗口重叠与已命中的块都不应重复补充
+	hits := []*RetrievalResult{
+		{Chunk: &KnowledgeChunk{ID: "chunk-1", ItemID: itemID, ChunkIndex: 1}, Item: item, Similarity: 0.9, Score: 0.9},
+		{Chunk: &KnowledgeChunk{ID: "chunk-2", ItemID: itemID, ChunkIndex: 2}, Item: item, Similarity: 0.8, Score: 0.8},
+	}
+
+	results := r.expandNeighborChunks(context.Background(), hits)
+	// 补充 0、3、4（1、2 已命中，负索引不存在）
+	if len(results) != 5 {
+		t.Fatalf("results = %d, want 5: %+v", len(results), results)
+	}
+	seen := map[int]int{}
+	for _, res := range results {
+		seen[res.Chunk.ChunkIndex]++
+	}
+	for idx, n := range seen {
+		if n != 1 {
+			t.Fatalf("chunk_index %d 出现 %d 次，应去重", idx, n)
+		}
+	}
+}
+
+func TestExpandNeighborChunksDisabled(t *testing.T) {
+	r, itemID := setupNeighborTestRetriever(t, 1)
+	r.config.NeighborExpansion.Enabled = false
+	hit := &RetrievalResult{
+		Chunk: &KnowledgeChunk{ID: "chunk-2", ItemID: itemID, ChunkIndex: 2},
+		Item:  &KnowledgeItem{ID: itemID},
+	}
+	results := r.expandNeighborChunks(context.Background(), []*RetrievalResult{hit})
+	if len(results) != 1 {
+		t.Fatalf("未启用时不应扩展: %d", len(results))
+	}
+}
@@ -5,36 +5,61 @@ import (
 	"fmt"
 )
 
-// EnsureKnowledgeEmbeddingsSchema migrates knowledge_embeddings for sub_indexes + embedding metadata.
+// EnsureKnowledgeEmbeddingsSchema migrates knowledge_embeddings for namespace + sub_indexes +
+// embedding metadata, and knowledge_base_items for namespace.
 func EnsureKnowledgeEmbeddingsSchema(db *sql.DB) error {
 	if db == nil {
 		return fmt.Errorf("db is nil")
 	}
-	var n int
-	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='knowledge_embeddings'`).Scan(&n); err != nil {
+	if err := addKnowledgeColumnIfMissing(db, "knowledge_embeddings", "namespace",
+		`ALTER TABLE knowledge_embeddings ADD COLUMN namespace TEXT NOT NULL DEFAULT ''`); err != nil {
 		return err
 	}
-	if n == 0 {
-		return nil
-	}
-	if err := addKnowledgeEmbeddingsColumnIfMissing(db, "sub_indexes",
+	if err := addKnowledgeColumnIfMissing(db, "knowledge_embeddings", "sub_indexes",
 		`ALTER TABLE knowledge_embeddings ADD COLUMN sub_indexes TEXT NOT NULL DEFAULT ''`); err != nil {
 		return err
 	}
-	if err := addKnowledgeEmbeddingsColumnIfMissing(db, "embedding_model",
+	if err := addKnowledgeColumnIfMissing(db, "knowledge_embeddings", "embedding_model",
 		`ALTER TABLE knowledge_embeddings ADD COLUMN embedding_model TEXT NOT NULL DEFAULT ''`); err != nil {
 		return err
 	}
-	if err := addKnowledgeEmbeddingsColumnIfMissing(db, "embedding_dim",
+	if err := addKnowledgeColumnIfMissing(db, "knowledge_embeddings", "embedding_dim",
 		`ALTER TABLE knowledge_embeddings ADD COLUMN embedding_dim INTEGER NOT NULL DEFAULT 0`); err != nil {
 		return err
 	}
+	if err := addKnowledgeColumnIfMissing(db, "knowledge_base_items", "namespace",
+		`ALTER TABLE knowledge_base_items ADD COLUMN namespace TEXT NOT NULL DEFAULT ''`); err != nil {
+		return err
+	}
+	// 嵌入失败的知识块登记表：保留重试所需的文本与元数据，供 retry-failed 单独补齐
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS knowledge_failed_chunks (
+		id TEXT PRIMARY KEY,
+		item_id TEXT NOT NULL,
+		chunk_index INTEGER NOT NULL,
+		chunk_text TEXT NOT NULL,
+		namespace TEXT NOT NULL DEFAULT '',
+		category TEXT NOT NULL DEFAULT '',
+		title TEXT NOT NULL DEFAULT '',
+		sub_indexes TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+		UNIQUE(item_id, chunk_index)
+	)`); err != nil {
+		return fmt.Errorf("创建 knowledge_failed_chunks 表失败: %w", err)
+	}
 	return nil
 }
 
-func addKnowledgeEmbeddingsColumnIfMissing(db *sql.DB, column, alterSQL string) error {
+func addKnowledgeColumnIfMissing(db *sql.DB, table, column, alterSQL string) error {
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`, table).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
 	var colCount int
-	q := `SELECT COUNT(*) FROM pragma_table_info('knowledge_embeddings') WHERE name = ?`
+	q := fmt.Sprintf(`SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name = ?`, table)
 	if err := db.QueryRow(q, column).Scan(&colCount); err != nil {
 		return err
 	}
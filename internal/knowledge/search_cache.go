@@ -0,0 +1,76 @@
+package knowledge
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// searchResultCache 按 (query + 过滤条件 + TopK 等) 做带 TTL 的检索结果缓存：
+// 同一会话里 LLM 常用几乎相同的 query 反复检索，每次都要重新嵌入 + 全表算相似度。
+// 命中判定目前是精确匹配（query 已 TrimSpace、mode 已归一化后参与键）；
+// 只有在检索配置里显式设置 cache_ttl_seconds 时才会进缓存。
+type searchResultCache struct {
+	mu      sync.Mutex
+	entries map[string]searchCacheEntry
+}
+
+type searchCacheEntry struct {
+	results   []*RetrievalResult
+	expiresAt time.Time
+}
+
+func newSearchResultCache() *searchResultCache {
+	return &searchResultCache{entries: make(map[string]searchCacheEntry)}
+}
+
+// searchCacheKey 生成缓存键；query/mode 以调用方归一化后的值传入，
+// 保证 " nmap 提权 " 与 "nmap 提权" 命中同一条目。
+func searchCacheKey(req *SearchRequest, query, mode string) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%d\x00%g\x00%s",
+		query, req.Namespace, req.RiskType, req.SubIndexFilter, req.TopK, req.Threshold, mode)
+}
+
+func (c *searchResultCache) get(key string) ([]*RetrievalResult, bool) {
+	if c == nil || key == "" {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return copyRetrievalResults(entry.results), true
+}
+
+func (c *searchResultCache) put(key string, results []*RetrievalResult, ttl time.Duration) {
+	if c == nil || key == "" || ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 顺带清理已过期条目，避免长期运行下无界增长
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = searchCacheEntry{results: copyRetrievalResults(results), expiresAt: now.Add(ttl)}
+}
+
+// copyRetrievalResults 逐条复制结果结构体，避免调用方改动分数等字段时污染缓存。
+// Chunk/Item 指针仍共享底层对象：检索链路只读它们，复制收益不大。
+func copyRetrievalResults(results []*RetrievalResult) []*RetrievalResult {
+	out := make([]*RetrievalResult, len(results))
+	for i, res := range results {
+		cloned := *res
+		out[i] = &cloned
+	}
+	return out
+}
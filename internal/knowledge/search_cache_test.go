@@ -0,0 +1,102 @@
+package knowledge
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cyberstrike-ai/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func TestSearchCacheKeyNormalized(t *testing.T) {
+	a := searchCacheKey(&SearchRequest{RiskType: "RCE", TopK: 5}, "nmap 提权", SearchModeKeyword)
+	b := searchCacheKey(&SearchRequest{RiskType: "RCE", TopK: 5}, "nmap 提权", SearchModeKeyword)
+	if a != b {
+		t.Fatal("相同归一化条件应生成相同缓存键")
+	}
+	if c := searchCacheKey(&SearchRequest{RiskType: "SQLi", TopK: 5}, "nmap 提权", SearchModeKeyword); c == a {
+		t.Fatal("riskType 不同不应命中同一键")
+	}
+	if c := searchCacheKey(&SearchRequest{RiskType: "RCE", TopK: 3}, "nmap 提权", SearchModeKeyword); c == a {
+		t.Fatal("topK 不同不应命中同一键")
+	}
+}
+
+func TestSearchResultCacheTTLAndIsolation(t *testing.T) {
+	cache := newSearchResultCache()
+	key := "k"
+	cache.put(key, []*RetrievalResult{{Similarity: 0.9, Score: 0.9}}, 50*time.Millisecond)
+
+	got, ok := cache.get(key)
+	if !ok || len(got) != 1 {
+		t.Fatalf("应命中缓存: %v, %v", got, ok)
+	}
+	// 调用方改动返回值不应污染缓存内容
+	got[0].Score = 0
+	again, ok := cache.get(key)
+	if !ok || again[0].Score != 0.9 {
+		t.Fatalf("缓存内容被调用方改动污染: %+v", again)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, ok := cache.get(key); ok {
+		t.Fatal("过期条目不应命中")
+	}
+}
+
+func TestSearchUsesCacheWhenConfigured(t *testing.T) {
+	db, err := database.NewKnowledgeDB(filepath.Join(t.TempDir(), "cache.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now()
+	if _, err := db.Exec(
+		"INSERT INTO knowledge_base_items (id, category, title, file_path, content, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		"item-1", "RCE", "item-1", "/kb/RCE/item-1.md", "含 CVE-2021-44228 的内容", now, now,
+	); err != nil {
+		t.Fatalf("插入知识项: %v", err)
+	}
+	if _, err := db.Exec(
+		"INSERT INTO knowledge_embeddings (id, item_id, chunk_index, chunk_text, embedding, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		"chunk-1", "item-1", 0, "含 CVE-2021-44228 的内容", "[]", now,
+	); err != nil {
+		t.Fatalf("插入向量块: %v", err)
+	}
+
+	r := NewRetriever(db.DB, nil, &RetrievalConfig{TopK: 5, CacheTTLSeconds: 60}, zap.NewNop())
+	req := &SearchRequest{Query: " CVE-2021-44228 ", Mode: SearchModeKeyword}
+	results, err := r.Search(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("首次检索应命中 1 条: %d", len(results))
+	}
+
+	// 删掉底层数据后再查：TTL 内应直接复用缓存结果
+	if _, err := db.Exec("DELETE FROM knowledge_embeddings"); err != nil {
+		t.Fatalf("清空向量块: %v", err)
+	}
+	cached, err := r.Search(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Search（缓存）: %v", err)
+	}
+	if len(cached) != 1 || cached[0].Chunk.ItemID != "item-1" {
+		t.Fatalf("TTL 内应复用缓存结果: %+v", cached)
+	}
+
+	// 未配置 cache_ttl_seconds 时不缓存
+	plain := NewRetriever(db.DB, nil, &RetrievalConfig{TopK: 5}, zap.NewNop())
+	fresh, err := plain.Search(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Search（无缓存）: %v", err)
+	}
+	if len(fresh) != 0 {
+		t.Fatalf("未开启缓存时应返回当前数据: %+v", fresh)
+	}
+}
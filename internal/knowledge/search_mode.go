@@ -0,0 +1,119 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// 检索模式：纯向量检索对精确术语（CVE 编号、函数名等）容易召回一堆语义相近但
+// 不含该词的内容。keyword 模式直接用 SQL LIKE 对 chunk_text 精确匹配，
+// hybrid（默认）在语义检索结果上融合关键词精确命中。
+
+const (
+	SearchModeSemantic = "semantic" // 仅语义（向量）检索，保持原有流水线
+	SearchModeKeyword  = "keyword"  // 仅关键词精确匹配（chunk_text LIKE）
+	SearchModeHybrid   = "hybrid"   // 语义检索 + 关键词精确命中融合（默认）
+)
+
+// normalizeSearchMode 归一检索模式；空串按 hybrid 处理，未知模式报错
+func normalizeSearchMode(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", SearchModeHybrid:
+		return SearchModeHybrid, nil
+	case SearchModeSemantic:
+		return SearchModeSemantic, nil
+	case SearchModeKeyword:
+		return SearchModeKeyword, nil
+	default:
+		return "", fmt.Errorf("不支持的检索模式: %q（支持 semantic/keyword/hybrid）", mode)
+	}
+}
+
+// escapeSearchLikePattern 转义 LIKE 模式中的通配符（配合 ESCAPE '\' 使用）
+func escapeSearchLikePattern(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	s = strings.ReplaceAll(s, "_", `\_`)
+	return s
+}
+
+// keywordSearch 关键词精确匹配：对 chunk_text 做大小写不敏感的 LIKE 检索，
+// 命中即为精确包含，相似度统一记 1。
+func (r *Retriever) keywordSearch(ctx context.Context, req *SearchRequest, query string) ([]*RetrievalResult, error) {
+	topK := req.TopK
+	if topK <= 0 && r.config != nil {
+		topK = r.config.TopK
+	}
+	if topK <= 0 {
+		topK = 5
+	}
+	subIdxFilter := strings.TrimSpace(req.SubIndexFilter)
+	if subIdxFilter == "" && r.config != nil {
+		subIdxFilter = strings.TrimSpace(r.config.SubIndexFilter)
+	}
+
+	sqlStr, sqlArgs := r.knowledgeEmbeddingSelectSQL(strings.TrimSpace(req.RiskType), subIdxFilter, strings.TrimSpace(req.Namespace))
+	sqlStr += ` AND LOWER(e.chunk_text) LIKE LOWER(?) ESCAPE '\' ORDER BY e.item_id, e.chunk_index LIMIT ?`
+	sqlArgs = append(sqlArgs, "%"+escapeSearchLikePattern(query)+"%", topK)
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, sqlArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("关键词检索失败: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]*RetrievalResult, 0)
+	for rows.Next() {
+		row, err := scanKnowledgeEmbeddingRow(rows)
+		if err != nil {
+			r.logger.Warn("扫描关键词命中失败", zap.Error(err))
+			continue
+		}
+		results = append(results, &RetrievalResult{
+			Chunk: &KnowledgeChunk{
+				ID:         row.chunkID,
+				ItemID:     row.itemID,
+				ChunkIndex: row.chunkIndex,
+				ChunkText:  row.chunkText,
+			},
+			Item:         row.item(),
+			Similarity:   1,
+			Score:        1,
+			KeywordMatch: true,
+		})
+	}
+	return results, nil
+}
+
+// mergeKeywordResults hybrid 模式融合：语义结果保序，追加未命中的关键词精确匹配块；
+// 同一块同时被两路命中时仅标注 KeywordMatch。关键词检索失败不影响语义结果。
+func (r *Retriever) mergeKeywordResults(ctx context.Context, req *SearchRequest, query string, semantic []*RetrievalResult) []*RetrievalResult {
+	keyword, err := r.keywordSearch(ctx, req, query)
+	if err != nil {
+		if r.logger != nil {
+			r.logger.Warn("hybrid 关键词检索失败，仅返回语义结果", zap.Error(err))
+		}
+		return semantic
+	}
+	if len(keyword) == 0 {
+		return semantic
+	}
+	seen := make(map[string]*RetrievalResult, len(semantic))
+	for _, res := range semantic {
+		if res.Chunk != nil {
+			seen[res.Chunk.ID] = res
+		}
+	}
+	merged := semantic
+	for _, res := range keyword {
+		if existing, ok := seen[res.Chunk.ID]; ok {
+			existing.KeywordMatch = true
+			continue
+		}
+		merged = append(merged, res)
+	}
+	return merged
+}
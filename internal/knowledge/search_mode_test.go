@@ -0,0 +1,130 @@
+package knowledge
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cyberstrike-ai/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func TestNormalizeSearchMode(t *testing.T) {
+	for input, want := range map[string]string{
+		"":         SearchModeHybrid,
+		"hybrid":   SearchModeHybrid,
+		"Semantic": SearchModeSemantic,
+		" keyword": SearchModeKeyword,
+	} {
+		got, err := normalizeSearchMode(input)
+		if err != nil || got != want {
+			t.Errorf("normalizeSearchMode(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := normalizeSearchMode("fulltext"); err == nil {
+		t.Fatal("未知模式应报错")
+	}
+}
+
+func TestKeywordSearchExactMatch(t *testing.T) {
+	db, err := database.NewKnowledgeDB(filepath.Join(t.TempDir(), "keyword.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now()
+	items := map[string]string{
+		"item-cve":  "Log4Shell 漏洞编号为 CVE-2021-44228，利用 JNDI 注入。",
+		"item-near": "这是语义相近但不含该编号的远程代码执行描述。",
+	}
+	for id, text := range items {
+		if _, err := db.Exec(
+			"INSERT INTO knowledge_base_items (id, category, title, file_path, content, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			id, "RCE", id, "/kb/RCE/"+id+".md", text, now, now,
+		); err != nil {
+			t.Fatalf("插入知识项: %v", err)
+		}
+		if _, err := db.Exec(
+			"INSERT INTO knowledge_embeddings (id, item_id, chunk_index, chunk_text, embedding, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+			"chunk-"+id, id, 0, text, "[]", now,
+		); err != nil {
+			t.Fatalf("插入向量块: %v", err)
+		}
+	}
+
+	if _, err := db.Exec("UPDATE knowledge_base_items SET boost = 2.5 WHERE id = ?", "item-cve"); err != nil {
+		t.Fatalf("设置 boost: %v", err)
+	}
+
+	r := NewRetriever(db.DB, nil, &RetrievalConfig{TopK: 5}, zap.NewNop())
+	results, err := r.keywordSearch(context.Background(), &SearchRequest{Query: "cve-2021-44228"}, "cve-2021-44228")
+	if err != nil {
+		t.Fatalf("keywordSearch: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.ItemID != "item-cve" {
+		t.Fatalf("results = %+v", results)
+	}
+	if !results[0].KeywordMatch || results[0].Similarity != 1 {
+		t.Fatalf("关键词命中应标注 KeywordMatch 且相似度为 1: %+v", results[0])
+	}
+	// 回归：两条读路径共用 knowledgeEmbeddingSelectSQL，任一路径漏扫新增列会让
+	// 整行 Scan 失败并被 warn+continue 吞掉，表现为关键词检索静默返回空集
+	if results[0].Item.Boost != 2.5 {
+		t.Fatalf("关键词命中应带出知识项 boost: %+v", results[0].Item)
+	}
+	if results[0].Item.UpdatedAt.IsZero() {
+		t.Fatalf("关键词命中应带出知识项 updated_at: %+v", results[0].Item)
+	}
+
+	// LIKE 通配符按字面匹配，不应命中所有行
+	results, err = r.keywordSearch(context.Background(), &SearchRequest{Query: "%"}, "%")
+	if err != nil {
+		t.Fatalf("keywordSearch(%%): %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("通配符应被转义: %+v", results)
+	}
+}
+
+func TestMergeKeywordResultsDeduplicates(t *testing.T) {
+	db, err := database.NewKnowledgeDB(filepath.Join(t.TempDir(), "hybrid.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now()
+	if _, err := db.Exec(
+		"INSERT INTO knowledge_base_items (id, category, title, file_path, content, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		"item-1", "RCE", "item-1", "/kb/RCE/item-1.md", "含 CVE-2021-44228 的内容", now, now,
+	); err != nil {
+		t.Fatalf("插入知识项: %v", err)
+	}
+	if _, err := db.Exec(
+		"INSERT INTO knowledge_embeddings (id, item_id, chunk_index, chunk_text, embedding, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		"chunk-1", "item-1", 0, "含 CVE-2021-44228 的内容", "[]", now,
+	); err != nil {
+		t.Fatalf("插入向量块: %v", err)
+	}
+
+	r := NewRetriever(db.DB, nil, &RetrievalConfig{TopK: 5}, zap.NewNop())
+	semantic := []*RetrievalResult{{
+		Chunk:      &KnowledgeChunk{ID: "chunk-1", ItemID: "item-1", ChunkText: "含 CVE-2021-44228 的内容"},
+		Item:       &KnowledgeItem{ID: "item-1"},
+		Similarity: 0.8,
+		Score:      0.8,
+	}}
+	merged := r.mergeKeywordResults(context.Background(), &SearchRequest{}, "CVE-2021-44228", semantic)
+	if len(merged) != 1 {
+		t.Fatalf("两路命中同一块应去重: %d", len(merged))
+	}
+	if !merged[0].KeywordMatch {
+		t.Fatal("两路命中的块应标注 KeywordMatch")
+	}
+	if merged[0].Similarity != 0.8 {
+		t.Fatalf("去重时应保留语义相似度: %v", merged[0].Similarity)
+	}
+}
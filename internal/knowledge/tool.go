@@ -94,6 +94,15 @@ func RegisterKnowledgeTool(
 					"type":        "string",
 					"description": "可选：指定风险类型（如：SQL注入、XSS、文件上传等）。建议先调用 " + builtin.ToolListKnowledgeRiskTypes + " 工具获取可用的风险类型列表，然后使用正确的风险类型进行精确搜索，这样可以大幅减少检索时间。如果不指定则搜索所有类型。",
 				},
+				"namespace": map[string]interface{}{
+					"type":        "string",
+					"description": "可选：指定知识库命名空间（namespace），仅检索该命名空间下的知识。不同 namespace 的知识库互相隔离。如果不指定则搜索所有命名空间。",
+				},
+				"mode": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{SearchModeSemantic, SearchModeKeyword, SearchModeHybrid},
+					"description": "可选：检索模式。semantic 为纯向量语义检索；keyword 对知识原文做精确词匹配（适合 CVE 编号等精确术语）；hybrid（默认）融合两者。",
+				},
 			},
 			"required": []string{"query"},
 		},
@@ -114,20 +123,54 @@ func RegisterKnowledgeTool(
 		}
 
 		riskType := ""
-		if rt, ok := args["risk_type"].(string); ok && rt != "" {
-			riskType = rt
+		if rt, ok := args["risk_type"].(string); ok {
+			// trim 归一，大小写差异由检索 SQL 端 COLLATE NOCASE 处理
+			riskType = strings.TrimSpace(rt)
+		}
+
+		namespace := ""
+		if ns, ok := args["namespace"].(string); ok && ns != "" {
+			namespace = ns
+		}
+
+		mode := ""
+		if m, ok := args["mode"].(string); ok {
+			mode = strings.TrimSpace(m)
+		}
+
+		// risk_type 不是已知分类时直接返回可用列表，避免 LLM 拿零结果反复试错
+		if riskType != "" {
+			if categories, catErr := manager.GetCategories(); catErr == nil && len(categories) > 0 && !containsCategoryFold(categories, riskType) {
+				var hint strings.Builder
+				hint.WriteString(fmt.Sprintf("风险类型 '%s' 在知识库中不存在。当前可用的风险类型：\n", riskType))
+				for i, category := range categories {
+					hint.WriteString(fmt.Sprintf("%d. %s\n", i+1, category))
+				}
+				hint.WriteString("\n请从上述列表中选择一个风险类型，或不指定 risk_type 以搜索所有类型。")
+				return &mcp.ToolResult{
+					Content: []mcp.Content{
+						{
+							Type: "text",
+							Text: hint.String(),
+						},
+					},
+				}, nil
+			}
 		}
 
 		logger.Info("执行知识库检索",
 			zap.String("query", query),
 			zap.String("riskType", riskType),
+			zap.String("namespace", namespace),
 		)
 
 		// 检索统一走 Retriever.Search → VectorEinoRetriever（Eino retriever 语义）。
 		searchReq := &SearchRequest{
-			Query:    query,
-			RiskType: riskType,
-			TopK:     5,
+			Query:     query,
+			Namespace: namespace,
+			RiskType:  riskType,
+			TopK:      5,
+			Mode:      mode,
 		}
 
 		results, err := retriever.Search(ctx, searchReq)
@@ -274,6 +317,17 @@ func RegisterKnowledgeTool(
 	logger.Debug("知识检索工具已注册", zap.String("toolName", searchTool.Name))
 }
 
+// containsCategoryFold 忽略大小写与首尾空白比较分类名（与检索 SQL 的归一规则一致）
+func containsCategoryFold(categories []string, name string) bool {
+	name = strings.TrimSpace(name)
+	for _, c := range categories {
+		if strings.EqualFold(strings.TrimSpace(c), name) {
+			return true
+		}
+	}
+	return false
+}
+
 // contains 检查切片是否包含元素
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -0,0 +1,26 @@
+package knowledge
+
+import "testing"
+
+func TestContainsCategoryFold(t *testing.T) {
+	categories := []string{"SQL注入", " XSS ", "File-Upload"}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"SQL注入", true},
+		{"  SQL注入  ", true},
+		{"xss", true},
+		{"file-upload", true},
+		{"FILE-UPLOAD", true},
+		{"SSRF", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := containsCategoryFold(categories, tt.name); got != tt.want {
+			t.Errorf("containsCategoryFold(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
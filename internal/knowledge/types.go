@@ -16,10 +16,12 @@ func formatTime(t time.Time) string {
 // KnowledgeItem 知识库项
 type KnowledgeItem struct {
 	ID        string    `json:"id"`
-	Category  string    `json:"category"` // 风险类型（文件夹名）
-	Title     string    `json:"title"`    // 标题（文件名）
-	FilePath  string    `json:"filePath"` // 文件路径
-	Content   string    `json:"content"`  // 文件内容
+	Namespace string    `json:"namespace,omitempty"` // 所属命名空间（多知识库隔离；空串为默认知识库）
+	Category  string    `json:"category"`            // 风险类型（文件夹名）
+	Title     string    `json:"title"`               // 标题（文件名）
+	FilePath  string    `json:"filePath"`            // 文件路径
+	Content   string    `json:"content"`             // 文件内容
+	Boost     float64   `json:"boost,omitempty"`     // 检索加权因子（默认 1.0；>1 更容易进 TopK，见 Manager.SetItemBoost）
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
@@ -81,6 +83,16 @@ type RetrievalResult struct {
 	Item       *KnowledgeItem  `json:"item"`
 	Similarity float64         `json:"similarity"` // 相似度分数
 	Score      float64         `json:"score"`      // 与 Similarity 相同：余弦相似度
+	// AppliedThreshold 本次检索实际生效的相似度阈值（adaptive 模式降阈值后与配置值可能不同；topk 模式为 0）。
+	AppliedThreshold float64 `json:"appliedThreshold,omitempty"`
+	// NormalizedScore 对本次候选集做 min-max 归一化后的分数（0~1）。不同嵌入模型的
+	// 余弦相似度分布差异大，前端百分比展示用该值更有可比性；原始值仍在 Similarity。
+	NormalizedScore float64 `json:"normalizedScore"`
+	// NeighborContext true 表示该块并非直接命中，而是邻块扩展补充的上下文
+	// （retrieval.neighbor_expansion，small-to-big 检索）。
+	NeighborContext bool `json:"neighborContext,omitempty"`
+	// KeywordMatch true 表示 chunk_text 精确包含查询词（keyword/hybrid 模式的关键词命中）。
+	KeywordMatch bool `json:"keywordMatch,omitempty"`
 }
 
 // RetrievalLog 检索日志
@@ -116,8 +128,10 @@ type CategoryWithItems struct {
 // SearchRequest 搜索请求
 type SearchRequest struct {
 	Query          string  `json:"query"`
+	Namespace      string  `json:"namespace,omitempty"`      // 可选：仅检索该命名空间的知识库（空串表示不限制）
 	RiskType       string  `json:"riskType,omitempty"`       // 可选：指定风险类型
 	SubIndexFilter string  `json:"subIndexFilter,omitempty"` // 可选：仅保留 sub_indexes 含该标签的行（含未打标旧数据）
 	TopK           int     `json:"topK,omitempty"`           // 返回 Top-K 结果，默认 5
 	Threshold      float64 `json:"threshold,omitempty"`      // 相似度阈值，默认 0.7
+	Mode           string  `json:"mode,omitempty"`           // 检索模式：semantic | keyword | hybrid（默认 hybrid）
 }
@@ -243,6 +243,9 @@ func (c *sdkClient) ListTools(ctx context.Context) ([]Tool, error) {
 	return sdkToolsToOur(res.Tools), nil
 }
 
+// callToolCancelGrace ctx 取消后等待 SDK 发出 notifications/cancelled 并收尾的宽限时间。
+const callToolCancelGrace = 5 * time.Second
+
 func (c *sdkClient) CallTool(ctx context.Context, name string, args map[string]interface{}) (*ToolResult, error) {
 	if c.session == nil {
 		return nil, fmt.Errorf("未连接")
@@ -251,11 +254,45 @@ func (c *sdkClient) CallTool(ctx context.Context, name string, args map[string]i
 		Name:      name,
 		Arguments: args,
 	}
-	res, err := c.session.CallTool(ctx, params)
-	if err != nil {
-		return nil, err
+
+	// ctx 取消时官方 SDK 会按 MCP 规范向远端发送 notifications/cancelled，远端据此
+	// 终止正在执行的调用（与本地进程 kill 行为对齐）。取消后再等一段宽限期让通知
+	// 发出、调用收尾；若远端/传输层不响应取消，则放弃等待，避免 worker 和
+	// 每服务并发槽位被一个挂死的远端调用永久占住。
+	type callToolOutcome struct {
+		res *mcp.CallToolResult
+		err error
+	}
+	resultCh := make(chan callToolOutcome, 1)
+	go func() {
+		res, err := c.session.CallTool(ctx, params)
+		resultCh <- callToolOutcome{res: res, err: err}
+	}()
+
+	select {
+	case outcome := <-resultCh:
+		if outcome.err != nil {
+			return nil, outcome.err
+		}
+		return sdkCallToolResultToOurs(outcome.res), nil
+	case <-ctx.Done():
+	}
+
+	grace := time.NewTimer(callToolCancelGrace)
+	defer grace.Stop()
+	select {
+	case outcome := <-resultCh:
+		if outcome.err != nil {
+			return nil, outcome.err
+		}
+		return sdkCallToolResultToOurs(outcome.res), nil
+	case <-grace.C:
+		c.logger.Warn("外部MCP工具取消后未在宽限期内返回，放弃等待（取消通知已随 ctx 发出）",
+			zap.String("tool", name),
+			zap.Duration("grace", callToolCancelGrace),
+		)
+		return nil, ctx.Err()
 	}
-	return sdkCallToolResultToOurs(res), nil
 }
 
 func (c *sdkClient) Close() error {
@@ -56,13 +56,14 @@ type ExecutionSnapshot struct {
 }
 
 type executionEntry struct {
-	exec   *ToolExecution
-	cancel context.CancelFunc
-	done   chan struct{}
-	preRun ExecutionPreRunFunc
-	run    ExecutionRunFunc
-	result *ToolResult
-	err    error
+	exec    *ToolExecution
+	cancel  context.CancelFunc
+	done    chan struct{}
+	preRun  ExecutionPreRunFunc
+	run     ExecutionRunFunc
+	cmdline *CommandLineRecorder
+	result  *ToolResult
+	err     error
 }
 
 // ExecutionService keeps Eino-facing tool calls synchronous while moving the
@@ -77,6 +78,7 @@ type ExecutionService struct {
 	maxInMemory    int
 	resultMaxBytes int
 	spillRootDir   string
+	redactor       *SensitiveRedactor
 }
 
 func NewExecutionService(storage MonitorStorage, logger *zap.Logger) *ExecutionService {
@@ -90,6 +92,7 @@ func NewExecutionService(storage MonitorStorage, logger *zap.Logger) *ExecutionS
 		abortUserNotes: make(map[string]string),
 		maxInMemory:    1000,
 		resultMaxBytes: DefaultToolResultMaxBytes,
+		redactor:       NewSensitiveRedactor(logger),
 	}
 }
 
@@ -113,6 +116,14 @@ func (s *ExecutionService) ConfigureToolResultSpillRoot(rootDir string) {
 	s.spillRootDir = strings.TrimSpace(rootDir)
 }
 
+// ConfigureSensitiveRedaction 启停工具结果敏感信息脱敏（security.sensitive_redaction）。
+func (s *ExecutionService) ConfigureSensitiveRedaction(enabled bool, extraPatterns []string) {
+	if s == nil {
+		return
+	}
+	s.redactor.Configure(enabled, extraPatterns)
+}
+
 func (s *ExecutionService) Submit(ctx context.Context, req ExecutionRequest) (*ExecutionHandle, error) {
 	if s == nil {
 		return nil, fmt.Errorf("execution service is nil")
@@ -142,6 +153,10 @@ func (s *ExecutionService) Submit(ctx context.Context, req ExecutionRequest) (*E
 			exec.OwnerUserID = principal.UserID
 		}
 	}
+	// 记录本次调用的上下文来源：模型是基于哪个前序执行的结果发起的本次调用
+	if tracker := ParentExecutionTrackerFromContext(ctx); tracker != nil {
+		exec.ParentExecutionID = tracker.Current()
+	}
 
 	runCtx := detachedExecutionContext(ctx)
 	var cancel context.CancelFunc
@@ -150,7 +165,7 @@ func (s *ExecutionService) Submit(ctx context.Context, req ExecutionRequest) (*E
 	} else {
 		runCtx, cancel = context.WithCancel(runCtx)
 	}
-	entry := &executionEntry{exec: exec, cancel: cancel, done: make(chan struct{}), preRun: req.PreRun, run: req.Run}
+	entry := &executionEntry{exec: exec, cancel: cancel, done: make(chan struct{}), preRun: req.PreRun, run: req.Run, cmdline: &CommandLineRecorder{}}
 
 	s.mu.Lock()
 	if _, exists := s.entries[id]; exists {
@@ -179,6 +194,7 @@ func (s *ExecutionService) runWorker(ctx context.Context, entry *executionEntry,
 	if conv := strings.TrimSpace(entry.exec.ConversationID); conv != "" {
 		ctx = WithMCPConversationID(ctx, conv)
 	}
+	ctx = WithCommandLineRecorder(ctx, entry.cmdline)
 	var release func()
 	defer func() {
 		if release != nil {
@@ -241,9 +257,14 @@ func (s *ExecutionService) finishEntry(ctx context.Context, entry *executionEntr
 			spill.ConversationID = conv
 		}
 	}
+	// 脱敏在截断/spill 之前执行，保证落盘全文同样不含敏感原文
+	result = s.redactor.RedactToolResult(result)
 	result = NormalizeToolResultForStorageWithSpill(result, s.resultMaxBytes, spill)
 	entry.result = result
 	entry.err = err
+	if cl := entry.cmdline.Value(); cl != "" {
+		entry.exec.CommandLine = cl
+	}
 	entry.exec.EndTime = &now
 	entry.exec.Duration = now.Sub(entry.exec.StartTime)
 	if err != nil {
@@ -277,6 +298,7 @@ func (s *ExecutionService) finishEntry(ctx context.Context, entry *executionEntr
 			entry.result = result
 		}
 		entry.exec.Result = result
+		entry.exec.StructuredResult = ParseStructuredToolResult(entry.exec.ToolName, result)
 	}
 	finalExec := cloneToolExecution(entry.exec)
 	s.mu.Unlock()
@@ -289,6 +311,10 @@ func (s *ExecutionService) finishEntry(ctx context.Context, entry *executionEntr
 	if onDone != nil {
 		onDone(finalExec)
 	}
+	// 执行结束后登记为后续调用的候选 parent（失败/取消的结果同样会反馈给模型并触发下一步）
+	if tracker := ParentExecutionTrackerFromContext(ctx); tracker != nil {
+		tracker.noteFinished(id)
+	}
 }
 
 func nilSafeRun(ctx context.Context, entry *executionEntry) (*ToolResult, error) {
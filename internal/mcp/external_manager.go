@@ -78,9 +78,12 @@ type ExternalMCPManager struct {
 	toolWaitTimeout    time.Duration
 	toolResultMaxBytes int
 	spillRootDir       string
-	resilience         ExternalMCPResilienceConfig
-	serverRuntimes     map[string]*externalMCPServerRuntime
-	globalSemaphore    chan struct{}
+
+	redactSensitive     bool
+	redactExtraPatterns []string
+	resilience          ExternalMCPResilienceConfig
+	serverRuntimes      map[string]*externalMCPServerRuntime
+	globalSemaphore     chan struct{}
 }
 
 // NewExternalMCPManager 创建外部MCP管理器
@@ -158,6 +161,20 @@ func (m *ExternalMCPManager) ConfigureToolResultSpillRoot(rootDir string) {
 	}
 }
 
+// ConfigureSensitiveRedaction 启停外部MCP工具结果敏感信息脱敏（security.sensitive_redaction）。
+func (m *ExternalMCPManager) ConfigureSensitiveRedaction(enabled bool, extraPatterns []string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.redactSensitive = enabled
+	m.redactExtraPatterns = extraPatterns
+	m.mu.Unlock()
+	if m.executionService != nil {
+		m.executionService.ConfigureSensitiveRedaction(enabled, extraPatterns)
+	}
+}
+
 // ConfigureToolWaitTimeoutSeconds controls how long an agent-facing tool call
 // waits for an external MCP execution before returning an execution_id that can
 // be polled with wait_tool_execution. seconds<=0 waits until completion.
@@ -678,6 +695,7 @@ func (m *ExternalMCPManager) CallTool(ctx context.Context, toolName string, args
 		m.executionService = NewExecutionService(m.storage, m.logger)
 		m.executionService.ConfigureToolResultMaxBytes(m.toolResultMaxBytes)
 		m.executionService.ConfigureToolResultSpillRoot(m.spillRootDir)
+		m.executionService.ConfigureSensitiveRedaction(m.redactSensitive, m.redactExtraPatterns)
 	}
 	var ownerUserID string
 	if principal, ok := authctx.PrincipalFromContext(ctx); ok {
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+)
+
+func submitAndWait(t *testing.T, service *ExecutionService, ctx context.Context, toolName string) *ToolExecution {
+	t.Helper()
+	handle, err := service.Submit(ctx, ExecutionRequest{
+		ToolName: toolName,
+		Run: func(context.Context) (*ToolResult, error) {
+			return &ToolResult{Content: []Content{{Type: "text", Text: "ok"}}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Submit %s: %v", toolName, err)
+	}
+	snap, err := service.Wait(context.Background(), handle.ID, 0)
+	if err != nil {
+		t.Fatalf("Wait %s: %v", toolName, err)
+	}
+	if snap == nil || snap.Execution == nil {
+		t.Fatalf("missing execution snapshot for %s", toolName)
+	}
+	return snap.Execution
+}
+
+func TestParentExecutionTrackedAcrossSequentialCalls(t *testing.T) {
+	service := NewExecutionService(nil, nil)
+	ctx := WithParentExecutionTracker(context.Background(), &ParentExecutionTracker{})
+
+	first := submitAndWait(t, service, ctx, "nmap")
+	if first.ParentExecutionID != "" {
+		t.Fatalf("first execution parent = %q, want empty", first.ParentExecutionID)
+	}
+
+	second := submitAndWait(t, service, ctx, "nikto")
+	if second.ParentExecutionID != first.ID {
+		t.Fatalf("second execution parent = %q, want %q", second.ParentExecutionID, first.ID)
+	}
+}
+
+func TestParentExecutionEmptyWithoutTracker(t *testing.T) {
+	service := NewExecutionService(nil, nil)
+	ctx := context.Background()
+
+	first := submitAndWait(t, service, ctx, "nmap")
+	second := submitAndWait(t, service, ctx, "nikto")
+	if first.ParentExecutionID != "" || second.ParentExecutionID != "" {
+		t.Fatalf("executions without tracker should have no parent, got %q / %q",
+			first.ParentExecutionID, second.ParentExecutionID)
+	}
+}
+
+func TestParentExecutionTrackerIsolatedPerTask(t *testing.T) {
+	service := NewExecutionService(nil, nil)
+	ctxA := WithParentExecutionTracker(context.Background(), &ParentExecutionTracker{})
+	ctxB := WithParentExecutionTracker(context.Background(), &ParentExecutionTracker{})
+
+	a1 := submitAndWait(t, service, ctxA, "nmap")
+	b1 := submitAndWait(t, service, ctxB, "subfinder")
+	if b1.ParentExecutionID != "" {
+		t.Fatalf("other task's execution leaked as parent: %q", b1.ParentExecutionID)
+	}
+	a2 := submitAndWait(t, service, ctxA, "nikto")
+	if a2.ParentExecutionID != a1.ID {
+		t.Fatalf("parent = %q, want %q", a2.ParentExecutionID, a1.ID)
+	}
+}
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"strings"
+	"sync"
 )
 
 // ToolRunRegistry 在工具开始/结束时登记当前 executionId，供对话页「仅终止当前工具」与监控页共用取消逻辑。
@@ -24,6 +25,103 @@ type einoExecuteRunRegistryCtxKey struct{}
 type mcpConversationIDCtxKey struct{}
 type mcpExecutionIDCtxKey struct{}
 type mcpProjectIDCtxKey struct{}
+type commandLineRecorderCtxKey struct{}
+type parentExecutionTrackerCtxKey struct{}
+
+// ParentExecutionTracker 在单个 Agent 任务内跟踪工具调用的上下文来源：
+// 新调用提交时以「最近一次已结束的执行」为 parent（模型是看到该结果后才发起本次调用），
+// 同一轮并行的多个调用共享同一个 parent。每个 Agent 任务挂一个独立跟踪器。
+type ParentExecutionTracker struct {
+	mu     sync.Mutex
+	lastID string
+}
+
+// Current 返回当前应作为 parent 的执行 ID（任务内尚无已结束的执行时为空串）。
+func (t *ParentExecutionTracker) Current() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastID
+}
+
+func (t *ParentExecutionTracker) noteFinished(executionID string) {
+	if t == nil {
+		return
+	}
+	executionID = strings.TrimSpace(executionID)
+	if executionID == "" {
+		return
+	}
+	t.mu.Lock()
+	t.lastID = executionID
+	t.mu.Unlock()
+}
+
+// WithParentExecutionTracker 将 parent 跟踪器注入 ctx（Agent 任务 ctx，每任务一个）。
+func WithParentExecutionTracker(ctx context.Context, tracker *ParentExecutionTracker) context.Context {
+	if ctx == nil || tracker == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, parentExecutionTrackerCtxKey{}, tracker)
+}
+
+// ParentExecutionTrackerFromContext 取出 parent 跟踪器（无则 nil）。
+func ParentExecutionTrackerFromContext(ctx context.Context) *ParentExecutionTracker {
+	if ctx == nil {
+		return nil
+	}
+	v, _ := ctx.Value(parentExecutionTrackerCtxKey{}).(*ParentExecutionTracker)
+	return v
+}
+
+// CommandLineRecorder 收集单次工具执行实际拼接出的完整命令行，供执行记录展示与人工复现。
+type CommandLineRecorder struct {
+	mu    sync.Mutex
+	value string
+}
+
+// Value 返回已记录的命令行（未记录时为空串）。
+func (r *CommandLineRecorder) Value() string {
+	if r == nil {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.value
+}
+
+func (r *CommandLineRecorder) record(cmdline string) {
+	if r == nil {
+		return
+	}
+	cmdline = strings.TrimSpace(cmdline)
+	if cmdline == "" {
+		return
+	}
+	r.mu.Lock()
+	r.value = cmdline
+	r.mu.Unlock()
+}
+
+// WithCommandLineRecorder 将命令行记录器注入 ctx；每次工具执行挂一个独立记录器。
+func WithCommandLineRecorder(ctx context.Context, rec *CommandLineRecorder) context.Context {
+	if ctx == nil || rec == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, commandLineRecorderCtxKey{}, rec)
+}
+
+// RecordCommandLine 由执行器侧在拼接出最终命令后登记；ctx 未挂记录器时为 no-op。
+// 重复登记以最后一次为准（如 PTY 重试时命令不变）。
+func RecordCommandLine(ctx context.Context, cmdline string) {
+	if ctx == nil {
+		return
+	}
+	rec, _ := ctx.Value(commandLineRecorderCtxKey{}).(*CommandLineRecorder)
+	rec.record(cmdline)
+}
 
 // WithToolRunRegistry 将登记器注入 ctx（Eino / 原生 Agent 任务 ctx）。
 func WithToolRunRegistry(ctx context.Context, reg ToolRunRegistry) context.Context {
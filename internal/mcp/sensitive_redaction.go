@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// 工具执行结果敏感信息脱敏：扫描输出里可能混入凭证、token、私钥、信用卡号等，
+// 落库与返回前端前按正则打码，避免在监控面板、会话历史与导出报告中扩散泄露面。
+// 打码发生在截断/spill 之前，因此落盘的全量文本同样是脱敏后的版本，原文不保留。
+
+// sensitiveRedactionMask 打码后的替换文本
+const sensitiveRedactionMask = "[REDACTED]"
+
+// builtinSensitivePatterns 内置脱敏模式；security.sensitive_redaction.extra_patterns
+// 在此基础上追加自定义正则（RE2 语法，不支持反向引用）
+var builtinSensitivePatterns = []string{
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`, // PEM 私钥
+	`\bAKIA[0-9A-Z]{16}\b`,                                                           // AWS Access Key ID
+	`\bghp_[A-Za-z0-9]{36}\b`,                                                        // GitHub personal access token
+	`\bxox[baprs]-[A-Za-z0-9-]{10,}\b`,                                               // Slack token
+	`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{5,}\b`,               // JWT
+	`(?i)\b(password|passwd|pwd|secret|api[_-]?key|access[_-]?token)\b\s*[:=]\s*\S+`, // 键值型凭证字段
+	`(?i)authorization:\s*(bearer|basic)\s+\S+`,                                      // HTTP 认证头
+	`\b(?:4\d{3}|5[1-5]\d{2})[ -]?\d{4}[ -]?\d{4}[ -]?\d{4}\b`,                       // Visa/MasterCard 卡号
+}
+
+// SensitiveRedactor 对工具结果文本做正则脱敏；零值等价于未启用
+type SensitiveRedactor struct {
+	mu       sync.RWMutex
+	enabled  bool
+	patterns []*regexp.Regexp
+	logger   *zap.Logger
+}
+
+// NewSensitiveRedactor 创建脱敏器（默认关闭，需 Configure 启用）
+func NewSensitiveRedactor(logger *zap.Logger) *SensitiveRedactor {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &SensitiveRedactor{logger: logger}
+}
+
+// Configure 启停脱敏并编译模式（内置 + 自定义）；非法自定义正则跳过并告警
+func (r *SensitiveRedactor) Configure(enabled bool, extraPatterns []string) {
+	if r == nil {
+		return
+	}
+	var compiled []*regexp.Regexp
+	if enabled {
+		for _, p := range builtinSensitivePatterns {
+			compiled = append(compiled, regexp.MustCompile(p))
+		}
+		for _, p := range extraPatterns {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			re, err := regexp.Compile(p)
+			if err != nil {
+				r.logger.Warn("敏感信息脱敏自定义正则非法，已跳过",
+					zap.String("pattern", p),
+					zap.Error(err),
+				)
+				continue
+			}
+			compiled = append(compiled, re)
+		}
+	}
+	r.mu.Lock()
+	r.enabled = enabled
+	r.patterns = compiled
+	r.mu.Unlock()
+}
+
+// RedactText 对文本打码；未启用时原样返回
+func (r *SensitiveRedactor) RedactText(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	r.mu.RLock()
+	enabled := r.enabled
+	patterns := r.patterns
+	r.mu.RUnlock()
+	if !enabled {
+		return text
+	}
+	for _, re := range patterns {
+		text = re.ReplaceAllString(text, sensitiveRedactionMask)
+	}
+	return text
+}
+
+// RedactToolResult 对结果中的文本内容打码；无命中时返回原指针，有命中时返回副本
+func (r *SensitiveRedactor) RedactToolResult(result *ToolResult) *ToolResult {
+	if r == nil || result == nil {
+		return result
+	}
+	r.mu.RLock()
+	enabled := r.enabled
+	r.mu.RUnlock()
+	if !enabled {
+		return result
+	}
+	out := cloneToolResult(result)
+	changed := false
+	for i, c := range out.Content {
+		if c.Type != "text" {
+			continue
+		}
+		if redacted := r.RedactText(c.Text); redacted != c.Text {
+			out.Content[i].Text = redacted
+			changed = true
+		}
+	}
+	if !changed {
+		return result
+	}
+	return out
+}
@@ -57,6 +57,7 @@ type Server struct {
 	toolWaitTimeout        time.Duration
 	toolResultMaxBytes     int
 	spillRootDir           string
+	redactor               *SensitiveRedactor
 }
 
 const defaultPartialOutputMaxBytes = 64 * 1024
@@ -84,6 +85,9 @@ func executionStatusAndMessage(err error) (status string, errMsg string) {
 	if errors.Is(err, context.Canceled) {
 		return "cancelled", "已手动终止（MCP 监控）"
 	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ToolExecutionStatusHardTimeout, "工具执行超过硬超时限制"
+	}
 	return "failed", err.Error()
 }
 
@@ -109,6 +113,7 @@ func NewServerWithStorage(logger *zap.Logger, storage MonitorStorage) *Server {
 		abortUserNotes:        make(map[string]string),
 		toolWaitTimeout:       60 * time.Second,
 		toolResultMaxBytes:    DefaultToolResultMaxBytes,
+		redactor:              NewSensitiveRedactor(logger),
 	}
 	s.executionService = NewExecutionService(storage, logger)
 
@@ -145,9 +150,22 @@ func (s *Server) ConfigureToolResultSpillRoot(rootDir string) {
 	}
 }
 
-// ConfigureHTTPToolCallTimeoutFromAgentMinutes 将 agent.tool_timeout_minutes 同步到经 HTTP POST /api/mcp 触发的 tools/call。
-// minutes<=0 表示不设置硬性截止时间（与配置「0 不限制」一致）；minutes>0 为该次调用的最长等待时间。
-// 未调用前对 tools/call 使用默认 30 分钟（与历史硬编码一致）。
+// ConfigureSensitiveRedaction 启停工具结果敏感信息脱敏（security.sensitive_redaction）：
+// 启用后结果按内置+自定义正则打码后再落库/返回前端。
+func (s *Server) ConfigureSensitiveRedaction(enabled bool, extraPatterns []string) {
+	if s == nil {
+		return
+	}
+	s.redactor.Configure(enabled, extraPatterns)
+	if s.executionService != nil {
+		s.executionService.ConfigureSensitiveRedaction(enabled, extraPatterns)
+	}
+}
+
+// ConfigureHTTPToolCallTimeoutFromAgentMinutes 将 agent.tool_timeout_minutes 同步为 MCP 层统一的单次工具硬超时，
+// 同时约束 HTTP POST /api/mcp 的 tools/call 与 [Server.CallTool] 的后台 worker 执行。
+// minutes<=0 表示不设置硬性截止时间（与配置「0 不限制」一致）；minutes>0 为该次调用的最长执行时间。
+// 未调用前使用默认 30 分钟（与历史硬编码一致）。
 func (s *Server) ConfigureHTTPToolCallTimeoutFromAgentMinutes(minutes int) {
 	if s == nil {
 		return
@@ -175,23 +193,32 @@ func (s *Server) ConfigureToolWaitTimeoutSeconds(seconds int) {
 }
 
 func (s *Server) effectiveHTTPToolCallDeadline(parent context.Context) (context.Context, context.CancelFunc) {
-	const defaultDur = 30 * time.Minute
 	if parent == nil {
 		parent = context.Background()
 	}
+	if d := s.effectiveToolHardTimeout(); d > 0 {
+		return context.WithTimeout(parent, d)
+	}
+	return context.WithCancel(parent)
+}
+
+// effectiveToolHardTimeout 统一的单次工具执行硬超时（同步 agent.tool_timeout_minutes）。
+// 未配置沿用默认 30 分钟；配置为 0 表示不限制（返回 0）。
+func (s *Server) effectiveToolHardTimeout() time.Duration {
+	const defaultDur = 30 * time.Minute
 	if s == nil {
-		return context.WithTimeout(parent, defaultDur)
+		return defaultDur
 	}
 	s.httpToolTimeoutMu.RLock()
 	mPtr := s.httpToolTimeoutMinutes
 	s.httpToolTimeoutMu.RUnlock()
 	if mPtr == nil {
-		return context.WithTimeout(parent, defaultDur)
+		return defaultDur
 	}
 	if *mPtr <= 0 {
-		return context.WithCancel(parent)
+		return 0
 	}
-	return context.WithTimeout(parent, time.Duration(*mPtr)*time.Minute)
+	return time.Duration(*mPtr) * time.Minute
 }
 
 // RegisterTool 注册工具
@@ -590,7 +617,13 @@ func (s *Server) handleCallTool(requestCtx context.Context, msg *Message) *Messa
 		zap.Any("arguments", req.Arguments),
 	)
 
-	result, err := handler(execCtx, req.Arguments)
+	cmdlineRecorder := &CommandLineRecorder{}
+	execCtx = WithCommandLineRecorder(execCtx, cmdlineRecorder)
+
+	// 与 executionService worker 一致的 panic recover：单个工具 panic 不拖垮整个服务
+	result, err := entryResultRecover(execCtx, req.Name, s.logger, func() (*ToolResult, error) {
+		return handler(execCtx, req.Arguments)
+	})
 	cancelledWithUserNote := s.applyAbortUserNoteToCancelledToolResult(executionID, &result, &err)
 	now := time.Now()
 	var failed bool
@@ -599,6 +632,9 @@ func (s *Server) handleCallTool(requestCtx context.Context, msg *Message) *Messa
 	s.mu.Lock()
 	execution.EndTime = &now
 	execution.Duration = now.Sub(execution.StartTime)
+	if cl := cmdlineRecorder.Value(); cl != "" {
+		execution.CommandLine = cl
+	}
 
 	if err != nil {
 		st, msg := executionStatusAndMessage(err)
@@ -631,6 +667,7 @@ func (s *Server) handleCallTool(requestCtx context.Context, msg *Message) *Messa
 			}
 		}
 		execution.Result = result
+		execution.StructuredResult = ParseStructuredToolResult(execution.ToolName, result)
 		failed = false
 	}
 
@@ -893,12 +930,23 @@ func (s *Server) GetAllTools() []Tool {
 	return tools
 }
 
+// GetTool 按名称获取已注册的工具定义（供Agent在执行前做参数校验等）
+func (s *Server) GetTool(name string) (Tool, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tool, ok := s.toolDefs[name]
+	return tool, ok
+}
+
 // CallTool 直接调用工具（用于内部调用）
 func (s *Server) CallTool(ctx context.Context, toolName string, args map[string]interface{}) (*ToolResult, string, error) {
 	if s.executionService == nil {
 		s.executionService = NewExecutionService(s.storage, s.logger)
 		s.executionService.ConfigureToolResultMaxBytes(s.toolResultMaxBytes)
 		s.executionService.ConfigureToolResultSpillRoot(s.spillRootDir)
+		// 复用同一脱敏器，避免懒初始化丢失已配置的脱敏规则
+		s.executionService.redactor = s.redactor
 	}
 	var ownerUserID string
 	if principal, ok := authctx.PrincipalFromContext(ctx); ok {
@@ -909,6 +957,8 @@ func (s *Server) CallTool(ctx context.Context, toolName string, args map[string]
 		Arguments:      args,
 		ConversationID: MCPConversationIDFromContext(ctx),
 		OwnerUserID:    ownerUserID,
+		// 统一硬超时：与 HTTP 直调路径一样受 agent.tool_timeout_minutes 约束
+		HardTimeout: s.effectiveToolHardTimeout(),
 		Run: func(runCtx context.Context) (*ToolResult, error) {
 			_, authenticated := authctx.PrincipalFromContext(runCtx)
 			s.mu.RLock()
@@ -956,11 +1006,32 @@ func (s *Server) CallTool(ctx context.Context, toolName string, args map[string]
 		return snapshot.Execution.Result, handle.ID, nil
 	}
 	if snapshot.Execution.Error != "" {
-		return nil, handle.ID, errors.New(snapshot.Execution.Error)
+		// 鉴权拒绝是安全决策而非工具输出，不降级成 IsError 结果交给模型继续推理，
+		// 仍按错误向上传播；执行记录保留（失败状态），供前端/审计查询。
+		if strings.Contains(snapshot.Execution.Error, "tool authorization denied") {
+			return nil, handle.ID, errors.New(snapshot.Execution.Error)
+		}
+		return normalizedToolErrorResult(snapshot.Execution), handle.ID, nil
 	}
 	return &ToolResult{Content: []Content{{Type: "text", Text: "工具执行完成，但未返回结果"}}, IsError: false}, handle.ID, nil
 }
 
+// normalizedToolErrorResult 把执行异常（panic/硬超时/取消/失败）统一转成规范的 MCP error content，
+// 上层拿到的始终是可携带继续推理的工具结果，无需各自把 error 再转成消息。
+func normalizedToolErrorResult(exec *ToolExecution) *ToolResult {
+	prefix := "工具执行失败"
+	switch exec.Status {
+	case ToolExecutionStatusHardTimeout:
+		prefix = "工具执行超过硬超时限制被自动终止（可在 config.yaml 的 agent.tool_timeout_minutes 中调整）"
+	case ToolExecutionStatusCancelled:
+		prefix = "工具执行已被终止"
+	}
+	return &ToolResult{
+		Content: []Content{{Type: "text", Text: fmt.Sprintf("%s\ntool: %s\nstatus: %s\nerror: %s", prefix, exec.ToolName, exec.Status, exec.Error)}},
+		IsError: true,
+	}
+}
+
 func internalMCPWaitTimeoutResult(snapshot *ExecutionSnapshot, waitTimeout time.Duration) *ToolResult {
 	execID := ""
 	status := ToolExecutionStatusRunning
@@ -1046,6 +1117,7 @@ func (s *Server) FinishToolExecution(ctx context.Context, executionID, toolName
 
 	now := time.Now()
 	failed := invokeErr != nil
+	resultText = s.redactor.RedactText(resultText)
 	var finalResult *ToolResult
 
 	s.mu.Lock()
@@ -1155,6 +1227,7 @@ func (s *Server) UpdateToolExecutionResult(executionID string, result *ToolResul
 	if executionID == "" || result == nil {
 		return nil
 	}
+	result = s.redactor.RedactToolResult(result)
 	s.mu.Lock()
 	spill := ToolResultSpillConfig{
 		RootDir:     s.spillRootDir,
@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParseStructuredToolResult 从工具文本输出中提取机器可读结构，供攻击链生成与
+// 漏洞展示直接消费，避免下游对纯文本再做正则抽取。
+// 目前内置 nmap（开放端口列表）与 sqlmap（注入点）两个 parser；
+// 其他工具若整体输出为 JSON 对象则原样透传。无法解析时返回 nil（不落库）。
+func ParseStructuredToolResult(toolName string, result *ToolResult) map[string]interface{} {
+	if result == nil || len(result.Content) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	for _, c := range result.Content {
+		if c.Type == "text" && c.Text != "" {
+			b.WriteString(c.Text)
+			b.WriteString("\n")
+		}
+	}
+	text := strings.TrimSpace(b.String())
+	if text == "" {
+		return nil
+	}
+
+	name := strings.ToLower(strings.TrimSpace(toolName))
+	switch {
+	case name == "nmap" || strings.HasPrefix(name, "nmap"):
+		return parseNmapStructuredResult(text)
+	case name == "sqlmap" || strings.HasPrefix(name, "sqlmap"):
+		return parseSqlmapStructuredResult(text)
+	}
+
+	// 通用兜底：输出本身就是 JSON 对象的工具（如多数内部工具）直接透传
+	if strings.HasPrefix(text, "{") {
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &obj); err == nil && len(obj) > 0 {
+			return obj
+		}
+	}
+	return nil
+}
+
+// nmapPortLineRe 匹配 nmap 端口行，如 "80/tcp  open  http  nginx 1.18.0"。
+// 行内空白只能用 [ \t]：\s 会匹配换行，无版本号的端口行会把下一行吞进 version 捕获组。
+var nmapPortLineRe = regexp.MustCompile(`(?m)^(\d{1,5})/(tcp|udp)[ \t]+(open|closed|filtered|open\|filtered|unfiltered)[ \t]*(\S+)?[ \t]*(.*)$`)
+
+func parseNmapStructuredResult(text string) map[string]interface{} {
+	matches := nmapPortLineRe.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	ports := make([]map[string]interface{}, 0, len(matches))
+	openCount := 0
+	for _, m := range matches {
+		port, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		entry := map[string]interface{}{
+			"port":     port,
+			"protocol": m[2],
+			"state":    m[3],
+		}
+		if svc := strings.TrimSpace(m[4]); svc != "" {
+			entry["service"] = svc
+		}
+		if ver := strings.TrimSpace(m[5]); ver != "" {
+			entry["version"] = ver
+		}
+		if m[3] == "open" {
+			openCount++
+		}
+		ports = append(ports, entry)
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"tool":          "nmap",
+		"ports":         ports,
+		"openPortCount": openCount,
+	}
+}
+
+var (
+	// sqlmapParamRe 匹配注入点头部，如 "Parameter: id (GET)"。
+	sqlmapParamRe = regexp.MustCompile(`(?m)^Parameter:\s+(.+?)\s+\((\w+)\)`)
+	// sqlmapFieldRe 匹配注入点明细行，如 "    Type: boolean-based blind"。
+	sqlmapFieldRe = regexp.MustCompile(`(?m)^\s+(Type|Title|Payload):\s+(.+)$`)
+	// sqlmapDBMSRe 匹配后端数据库识别行。
+	sqlmapDBMSRe = regexp.MustCompile(`(?mi)back-end DBMS:\s+(.+)$`)
+)
+
+func parseSqlmapStructuredResult(text string) map[string]interface{} {
+	paramLocs := sqlmapParamRe.FindAllStringSubmatchIndex(text, -1)
+	if len(paramLocs) == 0 {
+		return nil
+	}
+	points := make([]map[string]interface{}, 0, len(paramLocs))
+	for i, loc := range paramLocs {
+		segEnd := len(text)
+		if i+1 < len(paramLocs) {
+			segEnd = paramLocs[i+1][0]
+		}
+		segment := text[loc[0]:segEnd]
+		point := map[string]interface{}{
+			"parameter": strings.TrimSpace(text[loc[2]:loc[3]]),
+			"place":     strings.TrimSpace(text[loc[4]:loc[5]]),
+		}
+		techniques := make([]map[string]interface{}, 0, 4)
+		var current map[string]interface{}
+		for _, fm := range sqlmapFieldRe.FindAllStringSubmatch(segment, -1) {
+			key := strings.ToLower(fm[1])
+			val := strings.TrimSpace(fm[2])
+			if key == "type" {
+				current = map[string]interface{}{"type": val}
+				techniques = append(techniques, current)
+				continue
+			}
+			if current != nil {
+				current[key] = val
+			}
+		}
+		if len(techniques) > 0 {
+			point["techniques"] = techniques
+		}
+		points = append(points, point)
+	}
+	out := map[string]interface{}{
+		"tool":            "sqlmap",
+		"injectionPoints": points,
+	}
+	if dbms := sqlmapDBMSRe.FindStringSubmatch(text); len(dbms) > 1 {
+		out["dbms"] = strings.TrimSpace(dbms[1])
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import "testing"
+
+func textOnlyToolResult(text string) *ToolResult {
+	return &ToolResult{Content: []Content{{Type: "text", Text: text}}}
+}
+
+func TestParseStructuredToolResultNmap(t *testing.T) {
+	out := `Starting Nmap 7.94 ( https://nmap.org )
+Nmap scan report for example.com (93.184.216.34)
+PORT     STATE  SERVICE VERSION
+22/tcp   open   ssh     OpenSSH 8.9p1
+80/tcp   open   http    nginx 1.18.0
+443/tcp  open   https
+8080/tcp closed http-proxy
+`
+	structured := ParseStructuredToolResult("nmap", textOnlyToolResult(out))
+	if structured == nil {
+		t.Fatal("expected structured result for nmap output")
+	}
+	ports, ok := structured["ports"].([]map[string]interface{})
+	if !ok || len(ports) != 4 {
+		t.Fatalf("ports = %v, want 4 entries", structured["ports"])
+	}
+	if structured["openPortCount"] != 3 {
+		t.Errorf("openPortCount = %v, want 3", structured["openPortCount"])
+	}
+	if ports[1]["port"] != 80 || ports[1]["service"] != "http" || ports[1]["version"] != "nginx 1.18.0" {
+		t.Errorf("unexpected second port entry: %v", ports[1])
+	}
+}
+
+func TestParseStructuredToolResultSqlmap(t *testing.T) {
+	out := `sqlmap identified the following injection point(s):
+---
+Parameter: id (GET)
+    Type: boolean-based blind
+    Title: AND boolean-based blind - WHERE or HAVING clause
+    Payload: id=1 AND 5751=5751
+
+    Type: time-based blind
+    Title: MySQL >= 5.0.12 AND time-based blind
+    Payload: id=1 AND SLEEP(5)
+---
+[INFO] the back-end DBMS is MySQL
+back-end DBMS: MySQL >= 5.0.12
+`
+	structured := ParseStructuredToolResult("sqlmap", textOnlyToolResult(out))
+	if structured == nil {
+		t.Fatal("expected structured result for sqlmap output")
+	}
+	points, ok := structured["injectionPoints"].([]map[string]interface{})
+	if !ok || len(points) != 1 {
+		t.Fatalf("injectionPoints = %v, want 1 entry", structured["injectionPoints"])
+	}
+	if points[0]["parameter"] != "id" || points[0]["place"] != "GET" {
+		t.Errorf("unexpected injection point: %v", points[0])
+	}
+	techniques, ok := points[0]["techniques"].([]map[string]interface{})
+	if !ok || len(techniques) != 2 {
+		t.Fatalf("techniques = %v, want 2 entries", points[0]["techniques"])
+	}
+	if techniques[1]["type"] != "time-based blind" {
+		t.Errorf("second technique type = %v", techniques[1]["type"])
+	}
+	if structured["dbms"] != "MySQL >= 5.0.12" {
+		t.Errorf("dbms = %v", structured["dbms"])
+	}
+}
+
+func TestParseStructuredToolResultFallbacks(t *testing.T) {
+	if got := ParseStructuredToolResult("nmap", textOnlyToolResult("no ports here")); got != nil {
+		t.Errorf("nmap without port lines should return nil, got %v", got)
+	}
+	if got := ParseStructuredToolResult("whois", textOnlyToolResult("Domain Name: EXAMPLE.COM")); got != nil {
+		t.Errorf("plain text should return nil, got %v", got)
+	}
+	got := ParseStructuredToolResult("query_assets", textOnlyToolResult(`{"total": 2, "assets": []}`))
+	if got == nil || got["total"] != float64(2) {
+		t.Errorf("JSON output should pass through, got %v", got)
+	}
+}
@@ -195,10 +195,20 @@ type ToolExecution struct {
 	Arguments map[string]interface{} `json:"arguments"`
 	Status    string                 `json:"status"` // pending, running, completed, failed, cancelled
 	Result    *ToolResult            `json:"result,omitempty"`
-	Error     string                 `json:"error,omitempty"`
-	StartTime time.Time              `json:"startTime"`
-	EndTime   *time.Time             `json:"endTime,omitempty"`
-	Duration  time.Duration          `json:"duration,omitempty"`
+	// StructuredResult 工具 parser 从文本输出提取的机器可读结构（如 nmap 端口列表、
+	// sqlmap 注入点），可选；见 [ParseStructuredToolResult]。
+	StructuredResult map[string]interface{} `json:"structuredResult,omitempty"`
+	Error            string                 `json:"error,omitempty"`
+	StartTime        time.Time              `json:"startTime"`
+	EndTime          *time.Time             `json:"endTime,omitempty"`
+	Duration         time.Duration          `json:"duration,omitempty"`
+	// CommandLine 执行器实际拼接出的完整命令行（含所有 flag/参数），供交付时人工复现；
+	// 内部工具等无外部进程的执行为空。
+	CommandLine string `json:"commandLine,omitempty"`
+	// ParentExecutionID 触发本次调用的前序执行 ID（即本次调用基于哪个工具结果发起，
+	// 如 nikto 基于 nmap 发现的端口）。由 ParentExecutionTracker 在 Agent 任务内跟踪，
+	// 攻击链生成据此连边而非纯按时间顺序；任务首个调用或未挂跟踪器时为空。
+	ParentExecutionID string `json:"parentExecutionId,omitempty"`
 	// PartialOutput is a bounded tail preview of output produced by a running tool.
 	// It is intentionally separate from Result, which remains the final canonical tool result.
 	PartialOutput          string     `json:"partialOutput,omitempty"`
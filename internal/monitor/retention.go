@@ -31,26 +31,67 @@ func (s *Service) RetentionDays() int {
 	return s.cfg.Monitor.RetentionDaysEffective()
 }
 
-// PurgeExpired deletes tool execution rows older than retention_days when configured.
+// ProcessDetailRetentionDays returns configured process_details retention; 0 means keep forever.
+func (s *Service) ProcessDetailRetentionDays() int {
+	if s == nil || s.cfg == nil {
+		return config.MonitorConfig{}.ProcessDetailRetentionDaysEffective()
+	}
+	return s.cfg.Monitor.ProcessDetailRetentionDaysEffective()
+}
+
+// PurgeResult 单次清理的删除行数（手动触发接口返回用）。
+type PurgeResult struct {
+	ToolExecutions int64 `json:"toolExecutions"`
+	ProcessDetails int64 `json:"processDetails"`
+}
+
+// PurgeExpired deletes tool execution and process detail rows older than their
+// retention windows when configured.
 func (s *Service) PurgeExpired() {
+	_, _ = s.PurgeExpiredNow()
+}
+
+// PurgeExpiredNow 立即按保留策略清理并返回删除行数（后台定时与手动触发共用）。
+// 关联的会话消息保留，不受清理影响。
+func (s *Service) PurgeExpiredNow() (PurgeResult, error) {
+	var result PurgeResult
 	if s == nil || s.db == nil || s.cfg == nil {
-		return
+		return result, nil
 	}
-	days := s.cfg.Monitor.RetentionDaysEffective()
-	if days <= 0 {
-		return
-	}
-	cutoff := time.Now().AddDate(0, 0, -days)
-	n, err := s.db.PurgeToolExecutionsBefore(cutoff)
-	if err != nil {
-		if s.logger != nil {
-			s.logger.Warn("清理过期 MCP 执行记录失败", zap.Error(err))
+	var firstErr error
+	if days := s.cfg.Monitor.RetentionDaysEffective(); days > 0 {
+		cutoff := time.Now().AddDate(0, 0, -days)
+		n, err := s.db.PurgeToolExecutionsBefore(cutoff)
+		if err != nil {
+			firstErr = err
+			if s.logger != nil {
+				s.logger.Warn("清理过期 MCP 执行记录失败", zap.Error(err))
+			}
+		} else {
+			result.ToolExecutions = n
+			if n > 0 && s.logger != nil {
+				s.logger.Info("已清理过期 MCP 执行记录", zap.Int64("deleted", n), zap.Int("retention_days", days))
+			}
 		}
-		return
 	}
-	if n > 0 && s.logger != nil {
-		s.logger.Info("已清理过期 MCP 执行记录", zap.Int64("deleted", n), zap.Int("retention_days", days))
+	if days := s.cfg.Monitor.ProcessDetailRetentionDaysEffective(); days > 0 {
+		cutoff := time.Now().AddDate(0, 0, -days)
+		n, err := s.db.PurgeProcessDetailsBefore(cutoff)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			if s.logger != nil {
+				s.logger.Warn("清理过期过程详情失败", zap.Error(err))
+			}
+		} else {
+			result.ProcessDetails = n
+			if n > 0 && s.logger != nil {
+				s.logger.Info("已清理过期过程详情", zap.Int64("deleted", n), zap.Int("retention_days", days))
+			}
+		}
 	}
+	return result, firstErr
 }
 
 // StartRetentionLoop periodically purges expired tool execution rows.
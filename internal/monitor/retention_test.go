@@ -84,6 +84,102 @@ func TestRetentionDaysEffective_defaults(t *testing.T) {
 	}
 }
 
+func TestServicePurgeExpiredNow_purgesProcessDetailsKeepsMessages(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "monitor.db")
+	db, err := database.NewDB(dbPath, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	conv, err := db.CreateConversation("保留策略测试", database.ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	msg, err := db.AddMessage(conv.ID, "assistant", "扫描完成", nil)
+	if err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+	// 直接插入一条过期的过程详情（AddProcessDetail 总是用当前时间）
+	if _, err := db.Exec(
+		"INSERT INTO process_details (id, message_id, conversation_id, event_type, message, data, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		"pd-old", msg.ID, conv.ID, "tool_result", "旧记录", "", mustParseTime(t, "2020-01-01T00:00:00Z"),
+	); err != nil {
+		t.Fatalf("insert old process detail: %v", err)
+	}
+	if _, err := db.AddProcessDetailWithID(msg.ID, conv.ID, "tool_result", "新记录", nil); err != nil {
+		t.Fatalf("AddProcessDetailWithID: %v", err)
+	}
+
+	days := 90
+	svc := NewService(db, &config.Config{
+		Monitor: config.MonitorConfig{RetentionDays: &days},
+	}, zap.NewNop())
+	result, err := svc.PurgeExpiredNow()
+	if err != nil {
+		t.Fatalf("PurgeExpiredNow: %v", err)
+	}
+	if result.ProcessDetails != 1 {
+		t.Fatalf("deleted process details = %d, want 1", result.ProcessDetails)
+	}
+
+	details, err := db.GetProcessDetails(msg.ID)
+	if err != nil {
+		t.Fatalf("GetProcessDetails: %v", err)
+	}
+	if len(details) != 1 || details[0].Message != "新记录" {
+		t.Fatalf("only the fresh detail should remain, got %+v", details)
+	}
+	// 关联的会话消息保留
+	msgs, err := db.GetMessages(conv.ID)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("messages should be untouched, got %d", len(msgs))
+	}
+}
+
+func TestServicePurgeExpiredNow_zeroProcessDetailRetentionKeepsRows(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "monitor.db")
+	db, err := database.NewDB(dbPath, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	defer db.Close()
+
+	conv, err := db.CreateConversation("保留策略测试", database.ConversationCreateMeta{})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	msg, err := db.AddMessage(conv.ID, "assistant", "扫描完成", nil)
+	if err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+	if _, err := db.Exec(
+		"INSERT INTO process_details (id, message_id, conversation_id, event_type, message, data, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		"pd-old", msg.ID, conv.ID, "tool_result", "旧记录", "", mustParseTime(t, "2020-01-01T00:00:00Z"),
+	); err != nil {
+		t.Fatalf("insert old process detail: %v", err)
+	}
+
+	days := 90
+	zero := 0
+	svc := NewService(db, &config.Config{
+		Monitor: config.MonitorConfig{RetentionDays: &days, ProcessDetailRetentionDays: &zero},
+	}, zap.NewNop())
+	if _, err := svc.PurgeExpiredNow(); err != nil {
+		t.Fatalf("PurgeExpiredNow: %v", err)
+	}
+	details, err := db.GetProcessDetails(msg.ID)
+	if err != nil {
+		t.Fatalf("GetProcessDetails: %v", err)
+	}
+	if len(details) != 1 {
+		t.Fatalf("process_detail_retention_days=0 时不应清理, got %d", len(details))
+	}
+}
+
 func mustParseTime(t *testing.T, value string) time.Time {
 	t.Helper()
 	parsed, err := time.Parse(time.RFC3339, value)
@@ -313,8 +313,8 @@ func runEinoADKAgentLoop(ctx context.Context, args *einoADKRunLoopArgs, baseMsgs
 			toolName = "unknown"
 		}
 		preview := content
-		if len(preview) > 200 {
-			preview = preview[:200] + "..."
+		if utf8.RuneCountInString(preview) > 200 {
+			preview = truncateRunes(preview, 200) + "..."
 		}
 		backgroundRunning := isErr && isMCPBackgroundWaitResult(content)
 		displayIsErr := isErr && !backgroundRunning
@@ -640,10 +640,13 @@ func runEinoADKAgentLoop(ctx context.Context, args *einoADKRunLoopArgs, baseMsgs
 			return nil, runErr
 		}
 		ids := snapshotMCPIDs()
-		return buildEinoRunResultFromAccumulated(
+		partial := buildEinoRunResultFromAccumulated(
 			orchMode, runAccumulatedMsgs, modelFacingTraceSnapshot(args),
 			lastAssistant, lastPlanExecuteExecutor, emptyHint, ids, true,
-		), runErr
+		)
+		partial.ToolCallCount = toolCallBudgetUsed(ctx)
+		partial.TokenUsage = tokenBudgetUsage(ctx)
+		return partial, runErr
 	}
 
 	for {
@@ -1199,6 +1202,8 @@ func runEinoADKAgentLoop(ctx context.Context, args *einoADKRunLoopArgs, baseMsgs
 		orchMode, runAccumulatedMsgs, modelFacingTraceSnapshot(args),
 		lastAssistant, lastPlanExecuteExecutor, emptyHint, ids, false,
 	)
+	out.ToolCallCount = toolCallBudgetUsed(ctx)
+	out.TokenUsage = tokenBudgetUsage(ctx)
 	return out, nil
 }
 
@@ -13,16 +13,19 @@ import (
 // Order (best practice):
 //  1. system merge — accurate token count for summarization
 //  2. continuation user dedup — drop stale session-resume injections
-//  3. malformed tool-call arguments repair
-//  4. pre-summarization tool-call/result reconciliation
-//  5. summarization
-//  6. soft model-input budget (warn/compact only, never fail locally)
-//  7. final malformed tool-call arguments repair
-//  8. final tool-call/result reconciliation
-//  9. orphan tool prune (defense in depth)
-//  10. malformed tool_search history repair
-//  11. telemetry
-//  12. model-facing trace snapshot
+//  3. tool failure diagnosis — summarize consecutive tool failures for the model
+//  4. early tool-result compaction — progressively shrink old verbose tool outputs
+//  5. malformed tool-call arguments repair
+//  6. pre-summarization tool-call/result reconciliation
+//  7. summarization
+//  8. soft model-input budget (warn/compact only, never fail locally)
+//  9. final malformed tool-call arguments repair
+//  10. final tool-call/result reconciliation
+//  11. orphan tool prune (defense in depth)
+//  12. malformed tool_search history repair
+//  13. telemetry
+//  14. model-facing trace snapshot
+//  15. task token/cost budget guard — accumulate usage, abort when over budget
 type einoChatModelTailConfig struct {
 	logger           *zap.Logger
 	phase            string
@@ -41,6 +44,8 @@ type einoChatModelTailConfig struct {
 func appendEinoChatModelTailMiddlewares(handlers []adk.ChatModelAgentMiddleware, cfg einoChatModelTailConfig) []adk.ChatModelAgentMiddleware {
 	handlers = append(handlers, newSystemMessageNormalizerMiddleware(cfg.logger, cfg.phase))
 	handlers = append(handlers, newContinuationUserDedupMiddleware(cfg.logger, cfg.phase))
+	handlers = append(handlers, newToolFailureDiagnosisMiddleware(cfg.logger, cfg.phase))
+	handlers = append(handlers, newToolResultCompactionMiddleware(cfg.logger, cfg.phase))
 	handlers = append(handlers, newToolCallArgumentsSanitizerMiddleware(cfg.logger, cfg.phase+"_pre_summarization"))
 	if cfg.summarization != nil {
 		// Summarization invokes the model internally, so its input needs the same
@@ -66,6 +71,7 @@ func appendEinoChatModelTailMiddlewares(handlers []adk.ChatModelAgentMiddleware,
 		}
 	}
 	handlers = append(handlers, newModelOutputGuardMiddleware(cfg.middlewareConfig, cfg.logger, cfg.phase))
+	handlers = append(handlers, newTokenBudgetGuardMiddleware(cfg.logger, cfg.phase))
 	return handlers
 }
 
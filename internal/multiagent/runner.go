@@ -38,8 +38,10 @@ import (
 type RunResult struct {
 	Response             string
 	MCPExecutionIDs      []string
-	LastAgentTraceInput  string // 已序列化的消息带（JSON）：原生循环或 Eino 均写入，供续跑/攻击链等恢复上下文
-	LastAgentTraceOutput string // 本轮助手侧对外展示文本（摘要或最终回复）
+	LastAgentTraceInput  string           // 已序列化的消息带（JSON）：原生循环或 Eino 均写入，供续跑/攻击链等恢复上下文
+	LastAgentTraceOutput string           // 本轮助手侧对外展示文本（摘要或最终回复）
+	ToolCallCount        int              // 本轮登记的工具调用总数（不含 transfer/task 等元工具；未挂预算时为 0）
+	TokenUsage           TokenBudgetUsage // 本轮累计模型 usage 与折算金额（未挂预算时为零值）
 }
 
 // toolCallPendingInfo tracks a tool_call emitted to the UI so we can later
@@ -147,6 +149,7 @@ func RunDeepAgent(
 	}
 
 	toolInvokeNotify := einomcp.NewToolInvokeNotifyHolder()
+	toolOutputChunk := newToolOutputChunkEmitter(progress)
 	mainDefs := ag.ToolsForRole(roleTools)
 
 	httpClient := &http.Client{
@@ -217,7 +220,7 @@ func RunDeepAgent(
 			}
 
 			subDefs := ag.ToolsForRole(roleTools)
-			subTools, err := einomcp.ToolsFromDefinitions(ag, holder, subDefs, recorder, nil, toolInvokeNotify, id)
+			subTools, err := einomcp.ToolsFromDefinitions(ag, holder, subDefs, recorder, toolOutputChunk, toolInvokeNotify, id)
 			if err != nil {
 				return nil, fmt.Errorf("子代理 %q 工具: %w", id, err)
 			}
@@ -240,7 +243,7 @@ func RunDeepAgent(
 			}
 			if einoSkillMW != nil {
 				if einoFSTools && einoLoc != nil {
-					subFs, fsErr := subAgentFilesystemMiddleware(ctx, einoLoc, toolInvokeNotify, id, einoExecBegin, einoExecAppendPartial, einoExecRegisterCancel, einoExecUnregisterCancel, einoExecFinish, agentToolTimeoutMinutes(appCfg), agentToolWaitTimeoutSeconds(appCfg), agentShellNoOutputTimeoutSeconds(appCfg), nil)
+					subFs, fsErr := subAgentFilesystemMiddleware(ctx, einoLoc, toolInvokeNotify, id, einoExecBegin, einoExecAppendPartial, einoExecRegisterCancel, einoExecUnregisterCancel, einoExecFinish, agentToolTimeoutMinutes(appCfg), agentToolWaitTimeoutSeconds(appCfg), agentShellNoOutputTimeoutSeconds(appCfg), toolOutputChunk)
 					if fsErr != nil {
 						return nil, fmt.Errorf("子代理 %q filesystem 中间件: %w", id, fsErr)
 					}
@@ -285,6 +288,7 @@ func RunDeepAgent(
 						ToolCallMiddlewares: []compose.ToolMiddleware{
 							modelOutputExecutionGuardMiddleware(),
 							localToolRBACMiddleware(),
+							toolCallBudgetMiddleware(),
 							hitlToolCallMiddleware(),
 							softRecoveryToolMiddleware(),
 						},
@@ -333,7 +337,7 @@ func RunDeepAgent(
 		}
 	}
 
-	mainTools, err := einomcp.ToolsFromDefinitions(ag, holder, mainDefs, recorder, nil, toolInvokeNotify, orchestratorName)
+	mainTools, err := einomcp.ToolsFromDefinitions(ag, holder, mainDefs, recorder, toolOutputChunk, toolInvokeNotify, orchestratorName)
 	if err != nil {
 		return nil, err
 	}
@@ -385,7 +389,7 @@ func RunDeepAgent(
 			inner:                   security.NewEinoStreamingShell(),
 			invokeNotify:            toolInvokeNotify,
 			einoAgentName:           orchestratorName,
-			outputChunk:             nil,
+			outputChunk:             toolOutputChunk,
 			beginMonitor:            einoExecBegin,
 			appendPartialMonitor:    einoExecAppendPartial,
 			registerCancelMonitor:   einoExecRegisterCancel,
@@ -454,6 +458,7 @@ func RunDeepAgent(
 			ToolCallMiddlewares: []compose.ToolMiddleware{
 				modelOutputExecutionGuardMiddleware(),
 				localToolRBACMiddleware(),
+				toolCallBudgetMiddleware(),
 				hitlToolCallMiddleware(),
 				softRecoveryToolMiddleware(),
 			},
@@ -490,7 +495,7 @@ func RunDeepAgent(
 		// 构建 filesystem 中间件（与 Deep sub-agent 一致）
 		var peFsMw adk.ChatModelAgentMiddleware
 		if einoSkillMW != nil && einoFSTools && einoLoc != nil {
-			peFsMw, err = subAgentFilesystemMiddleware(ctx, einoLoc, toolInvokeNotify, "executor", einoExecBegin, einoExecAppendPartial, einoExecRegisterCancel, einoExecUnregisterCancel, einoExecFinish, agentToolTimeoutMinutes(appCfg), agentToolWaitTimeoutSeconds(appCfg), agentShellNoOutputTimeoutSeconds(appCfg), nil)
+			peFsMw, err = subAgentFilesystemMiddleware(ctx, einoLoc, toolInvokeNotify, "executor", einoExecBegin, einoExecAppendPartial, einoExecRegisterCancel, einoExecUnregisterCancel, einoExecFinish, agentToolTimeoutMinutes(appCfg), agentToolWaitTimeoutSeconds(appCfg), agentShellNoOutputTimeoutSeconds(appCfg), toolOutputChunk)
 			if err != nil {
 				return nil, fmt.Errorf("plan_execute filesystem 中间件: %w", err)
 			}
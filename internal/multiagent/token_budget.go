@@ -0,0 +1,201 @@
+package multiagent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+type tokenBudgetKey struct{}
+
+// TokenBudgetLimits 预算上限与金额折算单价。MaxTokens 与 MaxCost 均 <=0 时不限制（仅统计）。
+type TokenBudgetLimits struct {
+	MaxTokens            int     // 累计 usage.total_tokens 上限
+	MaxCost              float64 // 累计金额上限（按下面单价折算，币种随配置自定）
+	PromptPricePer1M     float64 // 每百万 prompt token 单价
+	CompletionPricePer1M float64 // 每百万 completion token 单价
+}
+
+// TokenBudgetUsage 预算已消耗量快照。
+type TokenBudgetUsage struct {
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	TotalTokens      int     `json:"totalTokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// TokenBudget 单次任务的成本预算：同一请求内全部模型调用（主/子代理、分段续跑）共享累计，
+// 防止单个会话因 LLM 反复调用烧掉大量 token。超限由 tokenBudgetGuardMiddleware 中止循环。
+// 与 ToolCallBudget 同构：不限制时仅统计，供 RunResult.TokenUsage 使用。
+type TokenBudget struct {
+	limits     TokenBudgetLimits
+	onExceeded func(usage TokenBudgetUsage)
+
+	mu               sync.Mutex
+	promptTokens     int
+	completionTokens int
+	totalTokens      int
+	exceededFired    bool
+}
+
+// NewTokenBudget 构建预算；onExceeded 仅在首次超限时回调一次（用于向前端发说明事件），可为 nil。
+func NewTokenBudget(limits TokenBudgetLimits, onExceeded func(usage TokenBudgetUsage)) *TokenBudget {
+	return &TokenBudget{limits: limits, onExceeded: onExceeded}
+}
+
+// WithTokenBudget 将预算挂入 ctx；b 为 nil 时原样返回。
+func WithTokenBudget(ctx context.Context, b *TokenBudget) context.Context {
+	if b == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tokenBudgetKey{}, b)
+}
+
+func tokenBudgetFromContext(ctx context.Context) *TokenBudget {
+	b, _ := ctx.Value(tokenBudgetKey{}).(*TokenBudget)
+	return b
+}
+
+// tokenBudgetUsage 返回 ctx 中预算已累计的 usage；未挂预算时为零值。
+func tokenBudgetUsage(ctx context.Context) TokenBudgetUsage {
+	if b := tokenBudgetFromContext(ctx); b != nil {
+		return b.Usage()
+	}
+	return TokenBudgetUsage{}
+}
+
+// addUsage 累计一轮模型调用的 usage；total 为 0 时按 prompt+completion 补齐。
+func (b *TokenBudget) addUsage(prompt, completion, total int) {
+	if b == nil {
+		return
+	}
+	if total <= 0 {
+		total = prompt + completion
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.promptTokens += prompt
+	b.completionTokens += completion
+	b.totalTokens += total
+}
+
+func (b *TokenBudget) costLocked() float64 {
+	return float64(b.promptTokens)/1e6*b.limits.PromptPricePer1M +
+		float64(b.completionTokens)/1e6*b.limits.CompletionPricePer1M
+}
+
+// Usage 返回当前累计消耗快照。
+func (b *TokenBudget) Usage() TokenBudgetUsage {
+	if b == nil {
+		return TokenBudgetUsage{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return TokenBudgetUsage{
+		PromptTokens:     b.promptTokens,
+		CompletionTokens: b.completionTokens,
+		TotalTokens:      b.totalTokens,
+		Cost:             b.costLocked(),
+	}
+}
+
+// exceededError 超限时返回 *TokenBudgetExceededError（首次超限触发 onExceeded），未超限返回 nil。
+func (b *TokenBudget) exceededError() *TokenBudgetExceededError {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	usage := TokenBudgetUsage{
+		PromptTokens:     b.promptTokens,
+		CompletionTokens: b.completionTokens,
+		TotalTokens:      b.totalTokens,
+		Cost:             b.costLocked(),
+	}
+	over := (b.limits.MaxTokens > 0 && usage.TotalTokens > b.limits.MaxTokens) ||
+		(b.limits.MaxCost > 0 && usage.Cost > b.limits.MaxCost)
+	fire := over && !b.exceededFired
+	if fire {
+		b.exceededFired = true
+	}
+	b.mu.Unlock()
+	if !over {
+		return nil
+	}
+	if fire && b.onExceeded != nil {
+		b.onExceeded(usage)
+	}
+	return &TokenBudgetExceededError{Usage: usage, Limits: b.limits}
+}
+
+// TokenBudgetExceededError 表示任务累计消耗已超过预算，循环被中止。
+// Error 文本面向用户（handler 的通用错误路径会原样透出）。
+type TokenBudgetExceededError struct {
+	Usage  TokenBudgetUsage
+	Limits TokenBudgetLimits
+}
+
+func (e *TokenBudgetExceededError) Error() string {
+	if e == nil {
+		return "已达本次预算上限"
+	}
+	limit := ""
+	if e.Limits.MaxTokens > 0 {
+		limit = fmt.Sprintf("token 上限 %d", e.Limits.MaxTokens)
+	}
+	if e.Limits.MaxCost > 0 {
+		if limit != "" {
+			limit += "，"
+		}
+		limit += fmt.Sprintf("金额上限 %.4f", e.Limits.MaxCost)
+	}
+	return fmt.Sprintf("已达本次预算上限：累计 %d tokens（prompt %d / completion %d），折算金额 %.4f；%s。任务已中止，可提高 agent.max_task_tokens / max_task_cost 或在请求 budget 字段覆盖后重试。",
+		e.Usage.TotalTokens, e.Usage.PromptTokens, e.Usage.CompletionTokens, e.Usage.Cost, limit)
+}
+
+// tokenBudgetGuardMiddleware 每轮模型调用后把 ResponseMeta.Usage 累入 ctx 中的预算；
+// 超限即返回 *TokenBudgetExceededError 中止循环（区别于工具预算的软拒收尾：
+// 成本超限后再让模型收尾还会继续烧 token，因此硬中止）。
+type tokenBudgetGuardMiddleware struct {
+	adk.BaseChatModelAgentMiddleware
+	logger *zap.Logger
+	phase  string
+}
+
+func newTokenBudgetGuardMiddleware(logger *zap.Logger, phase string) adk.ChatModelAgentMiddleware {
+	return &tokenBudgetGuardMiddleware{logger: logger, phase: phase}
+}
+
+func (m *tokenBudgetGuardMiddleware) AfterModelRewriteState(
+	ctx context.Context,
+	state *adk.ChatModelAgentState,
+	mc *adk.ModelContext,
+) (context.Context, *adk.ChatModelAgentState, error) {
+	_ = mc
+	b := tokenBudgetFromContext(ctx)
+	if m == nil || b == nil || state == nil || len(state.Messages) == 0 {
+		return ctx, state, nil
+	}
+	last := state.Messages[len(state.Messages)-1]
+	if last == nil || last.Role != schema.Assistant || last.ResponseMeta == nil || last.ResponseMeta.Usage == nil {
+		return ctx, state, nil
+	}
+	u := last.ResponseMeta.Usage
+	b.addUsage(u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+	if exceeded := b.exceededError(); exceeded != nil {
+		if m.logger != nil {
+			m.logger.Warn("任务累计消耗超过预算，中止循环",
+				zap.String("phase", m.phase),
+				zap.Int("totalTokens", exceeded.Usage.TotalTokens),
+				zap.Float64("cost", exceeded.Usage.Cost),
+				zap.Int("maxTokens", exceeded.Limits.MaxTokens),
+				zap.Float64("maxCost", exceeded.Limits.MaxCost),
+			)
+		}
+		return ctx, state, exceeded
+	}
+	return ctx, state, nil
+}
@@ -0,0 +1,107 @@
+package multiagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestTokenBudgetAccumulatesAndExceeds(t *testing.T) {
+	fired := 0
+	b := NewTokenBudget(TokenBudgetLimits{MaxTokens: 1000}, func(usage TokenBudgetUsage) { fired++ })
+	b.addUsage(300, 200, 500)
+	if err := b.exceededError(); err != nil {
+		t.Fatalf("limit 内不应超限: %v", err)
+	}
+	b.addUsage(400, 200, 0) // total 缺省时按 prompt+completion 补齐
+	err := b.exceededError()
+	if err == nil {
+		t.Fatal("累计 1100 tokens 应超过 1000 上限")
+	}
+	if err.Usage.TotalTokens != 1100 || err.Usage.PromptTokens != 700 {
+		t.Fatalf("usage 快照错误: %+v", err.Usage)
+	}
+	if !strings.Contains(err.Error(), "已达本次预算上限") {
+		t.Fatalf("错误文本应面向用户: %s", err.Error())
+	}
+	// 再次检查仍超限，但 onExceeded 只回调一次
+	if b.exceededError() == nil {
+		t.Fatal("超限状态应保持")
+	}
+	if fired != 1 {
+		t.Fatalf("onExceeded 应仅回调一次: got %d", fired)
+	}
+}
+
+func TestTokenBudgetCostLimit(t *testing.T) {
+	b := NewTokenBudget(TokenBudgetLimits{
+		MaxCost:              1.0,
+		PromptPricePer1M:     2.0,
+		CompletionPricePer1M: 8.0,
+	}, nil)
+	b.addUsage(100000, 50000, 150000) // 0.2 + 0.4 = 0.6
+	if err := b.exceededError(); err != nil {
+		t.Fatalf("金额 0.6 未超 1.0 上限: %v", err)
+	}
+	if got := b.Usage().Cost; got < 0.59 || got > 0.61 {
+		t.Fatalf("Cost: got %v want 0.6", got)
+	}
+	b.addUsage(100000, 50000, 150000) // 累计 1.2
+	if b.exceededError() == nil {
+		t.Fatal("金额 1.2 应超过 1.0 上限")
+	}
+}
+
+func TestTokenBudgetUnlimitedCountsOnly(t *testing.T) {
+	b := NewTokenBudget(TokenBudgetLimits{}, func(usage TokenBudgetUsage) { t.Fatal("不限制时不应触发超限回调") })
+	b.addUsage(1000000, 1000000, 2000000)
+	if err := b.exceededError(); err != nil {
+		t.Fatalf("无上限不应超限: %v", err)
+	}
+	if b.Usage().TotalTokens != 2000000 {
+		t.Fatalf("统计应照常累计: %+v", b.Usage())
+	}
+}
+
+func TestTokenBudgetContext(t *testing.T) {
+	if got := tokenBudgetUsage(context.Background()); got.TotalTokens != 0 {
+		t.Fatalf("未挂预算应为零值: %+v", got)
+	}
+	b := NewTokenBudget(TokenBudgetLimits{}, nil)
+	ctx := WithTokenBudget(context.Background(), b)
+	b.addUsage(10, 5, 15)
+	if got := tokenBudgetUsage(ctx); got.TotalTokens != 15 {
+		t.Fatalf("got %+v want 15 total", got)
+	}
+	if WithTokenBudget(context.Background(), nil) != context.Background() {
+		t.Fatal("nil 预算应原样返回 ctx")
+	}
+}
+
+func TestTokenBudgetGuardMiddlewareAbortsWhenOver(t *testing.T) {
+	b := NewTokenBudget(TokenBudgetLimits{MaxTokens: 100}, nil)
+	ctx := WithTokenBudget(context.Background(), b)
+	mw := newTokenBudgetGuardMiddleware(nil, "test").(*tokenBudgetGuardMiddleware)
+
+	state := &adk.ChatModelAgentState{Messages: []adk.Message{&schema.Message{
+		Role: schema.Assistant,
+		ResponseMeta: &schema.ResponseMeta{
+			Usage: &schema.TokenUsage{PromptTokens: 40, CompletionTokens: 20, TotalTokens: 60},
+		},
+	}}}
+	if _, _, err := mw.AfterModelRewriteState(ctx, state, nil); err != nil {
+		t.Fatalf("limit 内不应中止: %v", err)
+	}
+	if _, _, err := mw.AfterModelRewriteState(ctx, state, nil); err == nil {
+		t.Fatal("累计 120 tokens 应中止")
+	} else if !strings.Contains(err.Error(), "已达本次预算上限") {
+		t.Fatalf("错误文本应面向用户: %v", err)
+	}
+	// 未挂预算的 ctx 原样放行
+	if _, _, err := mw.AfterModelRewriteState(context.Background(), state, nil); err != nil {
+		t.Fatalf("未挂预算不应中止: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package multiagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+type toolCallBudgetKey struct{}
+
+// ToolCallBudget 单次任务的工具调用总预算：主/子代理共享同一计数，分段续跑（中断继续、空响应续跑）不清零。
+// 超限后不再执行真实工具，改以软结果提示模型基于已有信息收尾，循环随之自然收敛；
+// transfer_to_agent / task / tool_search 等编排元工具不计入（与 HitlExemptMetaTools 同一份名单）。
+// limit<=0 表示不限制，此时仅统计调用总数供 RunResult.ToolCallCount 使用。
+type ToolCallBudget struct {
+	limit      int
+	used       atomic.Int64
+	exceedOnce sync.Once
+	onExceeded func(used, limit int)
+}
+
+// NewToolCallBudget 构建预算；onExceeded 仅在首次超限时回调一次（用于向前端发说明事件），可为 nil。
+func NewToolCallBudget(limit int, onExceeded func(used, limit int)) *ToolCallBudget {
+	return &ToolCallBudget{limit: limit, onExceeded: onExceeded}
+}
+
+// WithToolCallBudget 将预算挂入 ctx；b 为 nil 时原样返回。
+func WithToolCallBudget(ctx context.Context, b *ToolCallBudget) context.Context {
+	if b == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, toolCallBudgetKey{}, b)
+}
+
+func toolCallBudgetFromContext(ctx context.Context) *ToolCallBudget {
+	b, _ := ctx.Value(toolCallBudgetKey{}).(*ToolCallBudget)
+	return b
+}
+
+// toolCallBudgetUsed 返回 ctx 中预算已登记的工具调用总数；未挂预算时为 0。
+func toolCallBudgetUsed(ctx context.Context) int {
+	if b := toolCallBudgetFromContext(ctx); b != nil {
+		return b.Used()
+	}
+	return 0
+}
+
+// Used 返回已登记的工具调用总数（不含元工具）。
+func (b *ToolCallBudget) Used() int {
+	if b == nil {
+		return 0
+	}
+	return int(b.used.Load())
+}
+
+// consume 登记一次工具调用；返回 false 表示预算已用尽，本次调用不应执行。
+func (b *ToolCallBudget) consume(toolName string) bool {
+	if b == nil {
+		return true
+	}
+	if isToolCallBudgetExemptTool(toolName) {
+		return true
+	}
+	n := b.used.Add(1)
+	if b.limit <= 0 || n <= int64(b.limit) {
+		return true
+	}
+	b.exceedOnce.Do(func() {
+		if b.onExceeded != nil {
+			b.onExceeded(int(n), b.limit)
+		}
+	})
+	return false
+}
+
+func isToolCallBudgetExemptTool(toolName string) bool {
+	name := strings.TrimSpace(toolName)
+	for _, t := range HitlExemptMetaTools {
+		if strings.EqualFold(name, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func toolCallBudgetExhaustedResult(toolName string, limit int) string {
+	if IsToolSearchTool(toolName) {
+		// tool_search 结果须保持 JSON，否则 Eino toolsearch 中间件解析历史时会硬崩 ChatModel。
+		return fmt.Sprintf(`{"selectedTools":[],"reason":"tool call budget exhausted (max %d)"}`, limit)
+	}
+	return fmt.Sprintf("[Tool Budget] Tool '%s' was not executed: this task has reached its tool call limit (%d, agent.max_tool_calls).\n"+
+		"Do not call any more tools. Produce your final conclusion now from the results already gathered.",
+		strings.TrimSpace(toolName), limit)
+}
+
+// toolCallBudgetMiddleware 同时注册 Invokable 与 Streamable（execute 为流式工具，仅挂 Invokable 会漏计）。
+// 放在 HITL 中间件之前：预算用尽的调用直接软拒，不再触发人工审批中断。
+func toolCallBudgetMiddleware() compose.ToolMiddleware {
+	return compose.ToolMiddleware{
+		Invokable: func(next compose.InvokableToolEndpoint) compose.InvokableToolEndpoint {
+			return func(ctx context.Context, input *compose.ToolInput) (*compose.ToolOutput, error) {
+				if input != nil {
+					if b := toolCallBudgetFromContext(ctx); b != nil && !b.consume(input.Name) {
+						return &compose.ToolOutput{Result: toolCallBudgetExhaustedResult(input.Name, b.limit)}, nil
+					}
+				}
+				return next(ctx, input)
+			}
+		},
+		Streamable: func(next compose.StreamableToolEndpoint) compose.StreamableToolEndpoint {
+			return func(ctx context.Context, input *compose.ToolInput) (*compose.StreamToolOutput, error) {
+				if input != nil {
+					if b := toolCallBudgetFromContext(ctx); b != nil && !b.consume(input.Name) {
+						return &compose.StreamToolOutput{
+							Result: schema.StreamReaderFromArray([]string{toolCallBudgetExhaustedResult(input.Name, b.limit)}),
+						}, nil
+					}
+				}
+				return next(ctx, input)
+			}
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package multiagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestToolCallBudgetConsume(t *testing.T) {
+	exceeded := 0
+	b := NewToolCallBudget(2, func(used, limit int) { exceeded++ })
+	if !b.consume("nmap") || !b.consume("sqlmap") {
+		t.Fatal("limit 内的调用不应被拒")
+	}
+	if b.consume("nuclei") {
+		t.Fatal("超限调用应被拒")
+	}
+	if b.consume("nuclei") {
+		t.Fatal("超限后继续拒绝")
+	}
+	if exceeded != 1 {
+		t.Fatalf("onExceeded 应仅回调一次: got %d", exceeded)
+	}
+	if b.Used() != 4 {
+		t.Fatalf("Used: got %d want 4", b.Used())
+	}
+}
+
+func TestToolCallBudgetExemptMetaTools(t *testing.T) {
+	b := NewToolCallBudget(1, nil)
+	if !b.consume("transfer_to_agent") || !b.consume("write_todos") || !b.consume("task") {
+		t.Fatal("元工具不应占用预算")
+	}
+	if b.Used() != 0 {
+		t.Fatalf("元工具不应计数: got %d", b.Used())
+	}
+	if !b.consume("nmap") {
+		t.Fatal("首个真实工具应放行")
+	}
+	if b.consume("nmap") {
+		t.Fatal("第二个真实工具应被拒")
+	}
+}
+
+func TestToolCallBudgetUnlimitedCountsOnly(t *testing.T) {
+	b := NewToolCallBudget(0, func(used, limit int) { t.Fatal("不限制时不应触发超限回调") })
+	for i := 0; i < 10; i++ {
+		if !b.consume("nmap") {
+			t.Fatal("limit<=0 不应拒绝")
+		}
+	}
+	if b.Used() != 10 {
+		t.Fatalf("Used: got %d want 10", b.Used())
+	}
+}
+
+func TestToolCallBudgetContext(t *testing.T) {
+	if got := toolCallBudgetUsed(context.Background()); got != 0 {
+		t.Fatalf("未挂预算应为 0: got %d", got)
+	}
+	b := NewToolCallBudget(5, nil)
+	ctx := WithToolCallBudget(context.Background(), b)
+	b.consume("nmap")
+	if got := toolCallBudgetUsed(ctx); got != 1 {
+		t.Fatalf("got %d want 1", got)
+	}
+	if WithToolCallBudget(context.Background(), nil) != context.Background() {
+		t.Fatal("nil 预算应原样返回 ctx")
+	}
+}
+
+func TestToolCallBudgetExhaustedResult(t *testing.T) {
+	msg := toolCallBudgetExhaustedResult("nmap", 30)
+	if !strings.Contains(msg, "nmap") || !strings.Contains(msg, "30") {
+		t.Fatalf("提示应包含工具名与上限: %s", msg)
+	}
+	ts := toolCallBudgetExhaustedResult(toolSearchToolName, 30)
+	if !strings.HasPrefix(ts, `{"selectedTools":[]`) {
+		t.Fatalf("tool_search 结果须保持 JSON: %s", ts)
+	}
+}
@@ -0,0 +1,168 @@
+package multiagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+// toolFailureDiagnosisMarker 注入消息的标记，用于识别并替换旧的诊断注入（避免堆叠）。
+const toolFailureDiagnosisMarker = "[工具失败诊断]"
+
+// toolFailureDiagnosisMinFailures 末尾连续失败的工具调用达到该数量才注入诊断提示。
+const toolFailureDiagnosisMinFailures = 3
+
+// toolFailureContentMarkers 与 soft error 路径产出的失败文案保持一致
+// （security executor 的 "工具执行失败"、softRecoveryToolCallMiddleware 的 "[Tool Error]" 等），
+// 另补充几类常见的系统级失败特征。
+var toolFailureContentMarkers = []string{
+	"[Tool Error]",
+	"[工具错误]",
+	"工具执行失败",
+	"工具调用失败",
+	"command not found",
+	"executable file not found",
+	"no such host",
+	"connection refused",
+	"no route to host",
+	"operation timed out",
+	"context deadline exceeded",
+}
+
+// toolFailureDiagnosisMiddleware 在工具接连失败时，于模型调用前注入一条失败汇总，
+// 引导模型给出明确诊断（工具未安装、目标不可达、参数错误等）和下一步建议，
+// 而不是在最终回答里含糊其辞。只改写模型输入，不落历史。
+type toolFailureDiagnosisMiddleware struct {
+	adk.BaseChatModelAgentMiddleware
+	logger *zap.Logger
+	phase  string
+}
+
+func newToolFailureDiagnosisMiddleware(logger *zap.Logger, phase string) adk.ChatModelAgentMiddleware {
+	return &toolFailureDiagnosisMiddleware{logger: logger, phase: phase}
+}
+
+func (m *toolFailureDiagnosisMiddleware) BeforeModelRewriteState(
+	ctx context.Context,
+	state *adk.ChatModelAgentState,
+	mc *adk.ModelContext,
+) (context.Context, *adk.ChatModelAgentState, error) {
+	_ = mc
+	if m == nil || state == nil || len(state.Messages) == 0 {
+		return ctx, state, nil
+	}
+	summary := buildToolFailureDiagnosis(state.Messages)
+	kept := stripToolFailureDiagnosis(state.Messages)
+	if summary == "" {
+		if len(kept) == len(state.Messages) {
+			return ctx, state, nil
+		}
+		// 失败已被后续成功打断：撤掉旧注入即可
+		out := *state
+		out.Messages = kept
+		return ctx, &out, nil
+	}
+	if m.logger != nil {
+		m.logger.Info("eino tool failure diagnosis injected",
+			zap.String("phase", m.phase),
+			zap.Int("messages", len(state.Messages)),
+		)
+	}
+	out := *state
+	out.Messages = append(kept, schema.UserMessage(summary))
+	return ctx, &out, nil
+}
+
+// isFailedToolMessage 判断一条 tool 结果消息是否为失败结果。
+func isFailedToolMessage(msg adk.Message) bool {
+	if msg == nil || msg.Role != schema.Tool {
+		return false
+	}
+	content := msg.Content
+	for _, marker := range toolFailureContentMarkers {
+		if strings.Contains(content, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildToolFailureDiagnosis 统计末尾连续失败的工具调用；达到阈值时返回按工具汇总的
+// 诊断提示文本，否则返回空串。
+func buildToolFailureDiagnosis(msgs []adk.Message) string {
+	type failure struct {
+		name  string
+		count int
+		last  string
+	}
+	var failures []*failure
+	index := map[string]*failure{}
+	total := 0
+	// 从末尾往前扫，遇到成功的 tool 结果即停（只关心"接连失败"）
+	for i := len(msgs) - 1; i >= 0; i-- {
+		msg := msgs[i]
+		if msg == nil || msg.Role != schema.Tool {
+			continue
+		}
+		if !isFailedToolMessage(msg) {
+			break
+		}
+		total++
+		name := strings.TrimSpace(msg.ToolName)
+		if name == "" {
+			name = "(未知工具)"
+		}
+		f, ok := index[name]
+		if !ok {
+			f = &failure{name: name}
+			index[name] = f
+			failures = append(failures, f)
+		}
+		f.count++
+		if f.last == "" {
+			f.last = toolFailureSnippet(msg.Content)
+		}
+	}
+	if total < toolFailureDiagnosisMinFailures {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s 本次会话最近 %d 次工具调用全部失败：\n", toolFailureDiagnosisMarker, total)
+	for _, f := range failures {
+		fmt.Fprintf(&b, "- %s：失败 %d 次，最近错误：%s\n", f.name, f.count, f.last)
+	}
+	b.WriteString("\n请基于上述失败原因给出明确诊断（如工具未安装、目标不可达、参数错误），" +
+		"并提出可执行的下一步建议（安装缺失的工具、换用其他工具、调整目标或参数），不要给出含糊其辞的回答。")
+	return b.String()
+}
+
+// toolFailureSnippet 取失败内容的首个非空行并截断，避免诊断注入再次撑爆上下文。
+func toolFailureSnippet(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if runes := []rune(line); len(runes) > 160 {
+			line = string(runes[:160]) + "..."
+		}
+		return line
+	}
+	return "(无输出)"
+}
+
+// stripToolFailureDiagnosis 移除此前注入的诊断消息，保证每次模型调用至多一条且内容最新。
+func stripToolFailureDiagnosis(msgs []adk.Message) []adk.Message {
+	out := make([]adk.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg != nil && msg.Role == schema.User && strings.Contains(msg.Content, toolFailureDiagnosisMarker) {
+			continue
+		}
+		out = append(out, msg)
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package multiagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+)
+
+func failedToolMsg(toolName, content string) adk.Message {
+	return &schema.Message{Role: schema.Tool, ToolName: toolName, Content: content}
+}
+
+func TestBuildToolFailureDiagnosis_ConsecutiveFailures(t *testing.T) {
+	msgs := []adk.Message{
+		schema.UserMessage("扫描 10.0.0.1"),
+		failedToolMsg("nmap_scan", "工具执行失败: exec: \"nmap\": executable file not found in $PATH"),
+		failedToolMsg("nmap_scan", "工具执行失败: exec: \"nmap\": executable file not found in $PATH"),
+		failedToolMsg("httpx_probe", "[Tool Error] Tool 'httpx_probe' execution failed: context deadline exceeded"),
+	}
+	summary := buildToolFailureDiagnosis(msgs)
+	if summary == "" {
+		t.Fatal("连续 3 次失败应生成诊断")
+	}
+	if !strings.Contains(summary, toolFailureDiagnosisMarker) {
+		t.Errorf("缺少标记: %q", summary)
+	}
+	if !strings.Contains(summary, "nmap_scan：失败 2 次") {
+		t.Errorf("缺少按工具汇总: %q", summary)
+	}
+	if !strings.Contains(summary, "httpx_probe") {
+		t.Errorf("缺少 httpx_probe: %q", summary)
+	}
+}
+
+func TestBuildToolFailureDiagnosis_SuccessBreaksStreak(t *testing.T) {
+	msgs := []adk.Message{
+		failedToolMsg("nmap_scan", "工具执行失败: 连接超时"),
+		failedToolMsg("nmap_scan", "工具执行失败: 连接超时"),
+		failedToolMsg("nmap_scan", "工具执行失败: 连接超时"),
+		failedToolMsg("httpx_probe", "Host: 10.0.0.1\nStatus: 200"),
+	}
+	if got := buildToolFailureDiagnosis(msgs); got != "" {
+		t.Errorf("末尾是成功结果时不应注入: %q", got)
+	}
+}
+
+func TestBuildToolFailureDiagnosis_BelowThreshold(t *testing.T) {
+	msgs := []adk.Message{
+		failedToolMsg("nmap_scan", "工具执行失败: 连接超时"),
+		failedToolMsg("nmap_scan", "工具执行失败: 连接超时"),
+	}
+	if got := buildToolFailureDiagnosis(msgs); got != "" {
+		t.Errorf("未达阈值不应注入: %q", got)
+	}
+}
+
+func TestToolFailureDiagnosisMiddleware_InjectsAndReplaces(t *testing.T) {
+	mw := newToolFailureDiagnosisMiddleware(nil, "test")
+	state := &adk.ChatModelAgentState{Messages: []adk.Message{
+		schema.UserMessage("扫描"),
+		schema.UserMessage(toolFailureDiagnosisMarker + " 旧的诊断"),
+		failedToolMsg("nmap_scan", "工具执行失败: a"),
+		failedToolMsg("nmap_scan", "工具执行失败: b"),
+		failedToolMsg("nmap_scan", "工具执行失败: c"),
+	}}
+	_, out, err := mw.(*toolFailureDiagnosisMiddleware).BeforeModelRewriteState(context.Background(), state, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	injected := 0
+	for _, msg := range out.Messages {
+		if msg.Role == schema.User && strings.Contains(msg.Content, toolFailureDiagnosisMarker) {
+			injected++
+			if !strings.Contains(msg.Content, "失败 3 次") {
+				t.Errorf("诊断内容不是最新的: %q", msg.Content)
+			}
+		}
+	}
+	if injected != 1 {
+		t.Fatalf("诊断注入应恰好 1 条，got %d", injected)
+	}
+	if last := out.Messages[len(out.Messages)-1]; last.Role != schema.User || !strings.Contains(last.Content, toolFailureDiagnosisMarker) {
+		t.Error("诊断应追加在消息末尾")
+	}
+}
@@ -0,0 +1,24 @@
+package multiagent
+
+import "strings"
+
+// newToolOutputChunkEmitter 把工具执行中的流式输出片段映射为 tool_output_chunk 进度事件。
+// 并行工具执行时多个进程共用同一个 progress 回调，事件必须带 toolCallId 供前端按 id 归并展示，
+// 否则不同工具的输出会串台。单个工具的片段在 executor 侧经同一读取循环串行回调，先后顺序有保证；
+// 缺少有效 toolCallId 的片段不发（无法归属，宁可只落 partial_output）。
+func newToolOutputChunkEmitter(progress func(eventType, message string, data interface{})) func(toolName, toolCallID, chunk string) {
+	if progress == nil {
+		return nil
+	}
+	return func(toolName, toolCallID, chunk string) {
+		toolCallID = strings.TrimSpace(toolCallID)
+		if toolCallID == "" || chunk == "" {
+			return
+		}
+		progress("tool_output_chunk", "", map[string]interface{}{
+			"toolName":   toolName,
+			"toolCallId": toolCallID,
+			"chunk":      chunk,
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package multiagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+// 工具结果渐进压缩：迭代轮次多了之后，messages 里堆着大量冗长的 tool 原文，
+// 后续轮次其实只需要结论。较早的 tool 消息在模型调用前做规则化"结论化压缩"
+// （保留头部概要、关键发现行和尾部），最近几条保留原文。只改写模型输入，不落历史。
+
+// toolResultCompactionMarker 压缩后的内容前缀，兼做防二次压缩的标记。
+const toolResultCompactionMarker = "[早期工具结果已压缩，保留关键行]"
+
+const (
+	// compactionKeepRecentToolResults 末尾保留原文的 tool 消息条数（约等于最近一两轮）。
+	compactionKeepRecentToolResults = 4
+	// compactionThresholdRunes 超过该长度的早期 tool 消息才压缩。
+	compactionThresholdRunes = 2000
+	// compactionHeadLines / compactionTailLines 无条件保留的头尾行数。
+	compactionHeadLines = 8
+	compactionTailLines = 4
+	// compactionMaxKeyLines 关键发现行的保留上限。
+	compactionMaxKeyLines = 40
+)
+
+// toolResultKeyLineMarkers 关键发现行的特征（端口/漏洞/状态/错误等），命中即保留。
+var toolResultKeyLineMarkers = []string{
+	"open", "port", "cve-", "vulnerab", "exploit",
+	"漏洞", "发现", "开放", "成功", "失败", "错误",
+	"error", "fail", "warning", "critical", "high", "medium",
+	"http/", "status", "title:", "server:",
+}
+
+type toolResultCompactionMiddleware struct {
+	adk.BaseChatModelAgentMiddleware
+	logger *zap.Logger
+	phase  string
+}
+
+func newToolResultCompactionMiddleware(logger *zap.Logger, phase string) adk.ChatModelAgentMiddleware {
+	return &toolResultCompactionMiddleware{logger: logger, phase: phase}
+}
+
+func (m *toolResultCompactionMiddleware) BeforeModelRewriteState(
+	ctx context.Context,
+	state *adk.ChatModelAgentState,
+	mc *adk.ModelContext,
+) (context.Context, *adk.ChatModelAgentState, error) {
+	_ = mc
+	if m == nil || state == nil || len(state.Messages) == 0 {
+		return ctx, state, nil
+	}
+	compacted, changed := compactEarlyToolResults(state.Messages)
+	if changed == 0 {
+		return ctx, state, nil
+	}
+	if m.logger != nil {
+		m.logger.Info("eino early tool results compacted",
+			zap.String("phase", m.phase),
+			zap.Int("compacted", changed),
+		)
+	}
+	out := *state
+	out.Messages = compacted
+	return ctx, &out, nil
+}
+
+// compactEarlyToolResults 压缩除最近 compactionKeepRecentToolResults 条之外的超长 tool 消息；
+// 返回改写后的消息切片与压缩条数（0 表示原样）。
+func compactEarlyToolResults(msgs []adk.Message) ([]adk.Message, int) {
+	var toolIdxs []int
+	for i, msg := range msgs {
+		if msg != nil && msg.Role == schema.Tool {
+			toolIdxs = append(toolIdxs, i)
+		}
+	}
+	if len(toolIdxs) <= compactionKeepRecentToolResults {
+		return msgs, 0
+	}
+	out := make([]adk.Message, len(msgs))
+	copy(out, msgs)
+	changed := 0
+	for _, i := range toolIdxs[:len(toolIdxs)-compactionKeepRecentToolResults] {
+		msg := msgs[i]
+		summary := compactToolResultContent(msg.Content)
+		if summary == msg.Content {
+			continue
+		}
+		clone := *msg
+		clone.Content = summary
+		out[i] = &clone
+		changed++
+	}
+	if changed == 0 {
+		return msgs, 0
+	}
+	return out, changed
+}
+
+// compactToolResultContent 对单条工具结果做规则化压缩：保留头部概要、关键发现行与尾部，
+// 并标注原始长度。长度未超阈值或已压缩过的内容原样返回。
+func compactToolResultContent(content string) string {
+	if strings.HasPrefix(content, toolResultCompactionMarker) {
+		return content
+	}
+	totalRunes := utf8.RuneCountInString(content)
+	if totalRunes <= compactionThresholdRunes {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	head := compactionHeadLines
+	if head > len(lines) {
+		head = len(lines)
+	}
+	tailStart := len(lines) - compactionTailLines
+	if tailStart < head {
+		tailStart = head
+	}
+
+	var kept []string
+	kept = append(kept, lines[:head]...)
+	keyCount := 0
+	skipped := 0
+	for _, line := range lines[head:tailStart] {
+		if keyCount < compactionMaxKeyLines && isToolResultKeyLine(line) {
+			kept = append(kept, line)
+			keyCount++
+			continue
+		}
+		skipped++
+	}
+	if skipped > 0 {
+		kept = append(kept, fmt.Sprintf("……（省略 %d 行非关键输出）", skipped))
+	}
+	kept = append(kept, lines[tailStart:]...)
+
+	return fmt.Sprintf("%s（原文 %d 字符）\n%s", toolResultCompactionMarker, totalRunes, strings.Join(kept, "\n"))
+}
+
+func isToolResultKeyLine(line string) bool {
+	trimmed := strings.ToLower(strings.TrimSpace(line))
+	if trimmed == "" {
+		return false
+	}
+	for _, marker := range toolResultKeyLineMarkers {
+		if strings.Contains(trimmed, marker) {
+			return true
+		}
+	}
+	return false
+}
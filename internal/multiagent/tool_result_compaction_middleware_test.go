@@ -0,0 +1,79 @@
+package multiagent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+)
+
+func longToolResult() string {
+	var b strings.Builder
+	b.WriteString("Starting Nmap 7.94\nScan report for 10.0.0.1\n")
+	for i := 0; i < 300; i++ {
+		b.WriteString("uninteresting filler output line with nothing useful at all ....\n")
+	}
+	b.WriteString("22/tcp open ssh OpenSSH 8.9\n")
+	b.WriteString("80/tcp open http nginx\n")
+	for i := 0; i < 100; i++ {
+		b.WriteString("more filler output that adds no conclusions whatsoever ....\n")
+	}
+	b.WriteString("Nmap done: 1 host up\n")
+	return b.String()
+}
+
+func TestCompactToolResultContent(t *testing.T) {
+	compacted := compactToolResultContent(longToolResult())
+	if !strings.HasPrefix(compacted, toolResultCompactionMarker) {
+		t.Fatalf("缺少压缩标记: %q", compacted[:80])
+	}
+	if !strings.Contains(compacted, "22/tcp open ssh") || !strings.Contains(compacted, "80/tcp open http") {
+		t.Error("关键发现行（开放端口）应被保留")
+	}
+	if !strings.Contains(compacted, "Nmap done") {
+		t.Error("尾部结论行应被保留")
+	}
+	if len(compacted) >= len(longToolResult())/2 {
+		t.Errorf("压缩后长度 %d 未明显小于原文 %d", len(compacted), len(longToolResult()))
+	}
+	// 幂等：已压缩的内容不再二次处理
+	if again := compactToolResultContent(compacted); again != compacted {
+		t.Error("压缩应幂等")
+	}
+	// 短内容原样返回
+	if got := compactToolResultContent("22/tcp open"); got != "22/tcp open" {
+		t.Errorf("短内容被改写: %q", got)
+	}
+}
+
+func TestCompactEarlyToolResults_KeepsRecent(t *testing.T) {
+	long := longToolResult()
+	var msgs []adk.Message
+	// 6 条 tool 结果：前 2 条应被压缩，后 4 条保留原文
+	for i := 0; i < 6; i++ {
+		msgs = append(msgs, &schema.Message{Role: schema.Tool, ToolName: "nmap_scan", Content: long})
+	}
+	out, changed := compactEarlyToolResults(msgs)
+	if changed != 2 {
+		t.Fatalf("changed = %d, want 2", changed)
+	}
+	for i := 0; i < 2; i++ {
+		if !strings.HasPrefix(out[i].Content, toolResultCompactionMarker) {
+			t.Errorf("第 %d 条早期结果应被压缩", i)
+		}
+	}
+	for i := 2; i < 6; i++ {
+		if out[i].Content != long {
+			t.Errorf("第 %d 条近期结果不应被改写", i)
+		}
+	}
+	// 原始切片不受影响
+	if msgs[0].Content != long {
+		t.Error("不应原地修改原消息")
+	}
+	// 不足保留数时整体不动
+	if _, changed := compactEarlyToolResults(msgs[:3]); changed != 0 {
+		t.Error("tool 消息数不超过保留数时不应压缩")
+	}
+}
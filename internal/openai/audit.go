@@ -0,0 +1,98 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cyberstrike-ai/internal/mcp"
+
+	"go.uber.org/zap"
+)
+
+// 审计落盘（openai.audit_dir，默认关闭）：开启时把每次 chat/completions 的完整请求体
+// 与响应体写入 <audit_dir>/<conversationId>/<时间戳>_<序号>_request.json / _response.json
+// （流式响应为原始 SSE，落 _response.sse），供排查模型行为时复盘，避免依赖日志里的截断预览。
+// 只写 body，不写任何 HTTP 头：Authorization / X-Api-Key 等凭据不会落盘。
+// 序号为该对话内的调用次序；ctx 中无对话 ID 时归入 no-conversation 目录。
+
+var (
+	auditSeqMu sync.Mutex
+	auditSeq   = make(map[string]int)
+)
+
+func auditNextSeq(conversationID string) int {
+	auditSeqMu.Lock()
+	defer auditSeqMu.Unlock()
+	auditSeq[conversationID]++
+	return auditSeq[conversationID]
+}
+
+// sanitizeAuditPathComponent 把对话 ID 归一化成安全的目录名（防止路径穿越）。
+func sanitizeAuditPathComponent(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	out := strings.Trim(b.String(), ".")
+	if out == "" {
+		out = "no-conversation"
+	}
+	return out
+}
+
+// auditCallPaths 为一次模型调用分配请求/响应文件路径；audit_dir 未配置或目录创建失败时 ok=false。
+func (c *Client) auditCallPaths(ctx context.Context) (reqPath, respPath string, ok bool) {
+	if c == nil || c.config == nil {
+		return "", "", false
+	}
+	dir := strings.TrimSpace(c.config.AuditDir)
+	if dir == "" {
+		return "", "", false
+	}
+	conv := strings.TrimSpace(mcp.MCPConversationIDFromContext(ctx))
+	if conv == "" {
+		conv = "no-conversation"
+	}
+	convDir := filepath.Join(dir, sanitizeAuditPathComponent(conv))
+	if err := os.MkdirAll(convDir, 0o755); err != nil {
+		c.logger.Warn("openai audit: 创建审计目录失败，本次调用不落盘", zap.String("dir", convDir), zap.Error(err))
+		return "", "", false
+	}
+	prefix := fmt.Sprintf("%s_%04d", time.Now().UTC().Format("20060102T150405"), auditNextSeq(conv))
+	return filepath.Join(convDir, prefix+"_request.json"), filepath.Join(convDir, prefix+"_response.json"), true
+}
+
+// auditWriteFile 落盘单个审计文件；失败只告警，不影响调用本身。
+func (c *Client) auditWriteFile(path string, body []byte) {
+	if path == "" {
+		return
+	}
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		c.logger.Warn("openai audit: 写入审计文件失败", zap.String("path", path), zap.Error(err))
+	}
+}
+
+// auditOpenStreamFile 为流式响应打开 _response.sse 文件（原始 SSE 经 TeeReader 边读边写）；
+// 失败返回 nil，调用方退化为不落盘。
+func (c *Client) auditOpenStreamFile(respPath string) *os.File {
+	if respPath == "" {
+		return nil
+	}
+	path := strings.TrimSuffix(respPath, ".json") + ".sse"
+	f, err := os.Create(path)
+	if err != nil {
+		c.logger.Warn("openai audit: 创建流式响应审计文件失败", zap.String("path", path), zap.Error(err))
+		return nil
+	}
+	return f
+}
@@ -853,6 +853,7 @@ func (c *Client) setClaudeHeaders(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-api-key", c.config.APIKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
+	c.applyCustomHeaders(req.Header)
 }
 
 // isClaude 判断当前配置是否为 Claude provider。
@@ -0,0 +1,142 @@
+package openai
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 多 API Key 轮询与故障转移（openai.api_keys）：配置多个 key 时每次调用轮询选一个，
+// 某个 key 连续 429/401 达到阈值后临时摘除冷却，期间请求自动落到其余 key 上，对调用方透明。
+// 状态放在包级 map（按 key 维度），handler 里临时 NewClient 的调用也共享同一份健康记录。
+// 仅 OpenAI 兼容端点生效；Claude 桥接与 Eino ChatModel 路径仍使用 api_key。
+
+const (
+	// apiKeyFailureThreshold 连续失败多少次后摘除该 key
+	apiKeyFailureThreshold = 2
+	// apiKeyCooldownRateLimited 429 触发的冷却时长（限流通常很快恢复）
+	apiKeyCooldownRateLimited = time.Minute
+	// apiKeyCooldownUnauthorized 401 触发的冷却时长（key 大概率失效，摘久一点）
+	apiKeyCooldownUnauthorized = 15 * time.Minute
+)
+
+type apiKeyHealthState struct {
+	consecutiveFailures int
+	disabledUntil       time.Time
+}
+
+var (
+	apiKeyPoolMu     sync.Mutex
+	apiKeyRoundRobin = make(map[string]int)                // keyset 签名 → 轮询游标
+	apiKeyHealth     = make(map[string]*apiKeyHealthState) // key → 健康状态
+)
+
+// candidateAPIKeys 返回本配置可用的 key 列表：api_keys 非空时用列表（去空白、去重），
+// 否则退回单个 api_key。
+func (c *Client) candidateAPIKeys() []string {
+	if c == nil || c.config == nil {
+		return nil
+	}
+	if len(c.config.APIKeys) > 0 {
+		seen := make(map[string]bool, len(c.config.APIKeys))
+		keys := make([]string, 0, len(c.config.APIKeys))
+		for _, k := range c.config.APIKeys {
+			k = strings.TrimSpace(k)
+			if k == "" || seen[k] {
+				continue
+			}
+			seen[k] = true
+			keys = append(keys, k)
+		}
+		if len(keys) > 0 {
+			return keys
+		}
+	}
+	if k := strings.TrimSpace(c.config.APIKey); k != "" {
+		return []string{k}
+	}
+	return nil
+}
+
+// pickAPIKey 轮询选取一个未处于冷却期的 key；全部冷却时仍按轮询取下一个（不至于无 key 可用）。
+func (c *Client) pickAPIKey() string {
+	keys := c.candidateAPIKeys()
+	switch len(keys) {
+	case 0:
+		return ""
+	case 1:
+		return keys[0]
+	}
+
+	sig := strings.Join(keys, "\n")
+	now := time.Now()
+
+	apiKeyPoolMu.Lock()
+	defer apiKeyPoolMu.Unlock()
+	start := apiKeyRoundRobin[sig] % len(keys)
+	for i := 0; i < len(keys); i++ {
+		key := keys[(start+i)%len(keys)]
+		if st := apiKeyHealth[key]; st != nil && now.Before(st.disabledUntil) {
+			continue
+		}
+		apiKeyRoundRobin[sig] = (start + i + 1) % len(keys)
+		return key
+	}
+	// 全部冷却中：取轮询位置上的 key 硬试一次
+	apiKeyRoundRobin[sig] = (start + 1) % len(keys)
+	c.logger.Warn("OpenAI API Key 池全部处于冷却期，仍按轮询使用",
+		zap.Int("keys", len(keys)))
+	return keys[start]
+}
+
+// reportAPIKeyStatus 按响应状态码更新 key 健康状态：429/401 累计连续失败并在达到阈值后
+// 摘除冷却；2xx 清零。其余状态码（如 5xx）与 key 本身无关，不计入。
+func (c *Client) reportAPIKeyStatus(key string, statusCode int) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
+	}
+	apiKeyPoolMu.Lock()
+	defer apiKeyPoolMu.Unlock()
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusUnauthorized:
+		st := apiKeyHealth[key]
+		if st == nil {
+			st = &apiKeyHealthState{}
+			apiKeyHealth[key] = st
+		}
+		st.consecutiveFailures++
+		if st.consecutiveFailures >= apiKeyFailureThreshold {
+			cooldown := apiKeyCooldownRateLimited
+			if statusCode == http.StatusUnauthorized {
+				cooldown = apiKeyCooldownUnauthorized
+			}
+			st.disabledUntil = time.Now().Add(cooldown)
+			c.logger.Warn("OpenAI API Key 连续失败，临时摘除轮询池",
+				zap.String("key", maskAPIKey(key)),
+				zap.Int("status", statusCode),
+				zap.Int("consecutiveFailures", st.consecutiveFailures),
+				zap.Duration("cooldown", cooldown),
+			)
+		}
+	default:
+		if statusCode >= 200 && statusCode < 300 {
+			if st := apiKeyHealth[key]; st != nil {
+				st.consecutiveFailures = 0
+				st.disabledUntil = time.Time{}
+			}
+		}
+	}
+}
+
+// maskAPIKey 日志用脱敏：只保留首尾少量字符。
+func maskAPIKey(key string) string {
+	runes := []rune(key)
+	if len(runes) <= 8 {
+		return "****"
+	}
+	return string(runes[:4]) + "****" + string(runes[len(runes)-4:])
+}
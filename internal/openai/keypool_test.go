@@ -0,0 +1,74 @@
+package openai
+
+import (
+	"net/http"
+	"testing"
+
+	"cyberstrike-ai/internal/config"
+)
+
+func TestPickAPIKeyRoundRobin(t *testing.T) {
+	c := NewClient(&config.OpenAIConfig{
+		APIKeys: []string{"sk-rr-a", "sk-rr-b", "sk-rr-c"},
+	}, nil, nil)
+
+	got := []string{c.pickAPIKey(), c.pickAPIKey(), c.pickAPIKey(), c.pickAPIKey()}
+	if got[0] == got[1] || got[1] == got[2] {
+		t.Fatalf("连续调用应轮询不同 key: %v", got)
+	}
+	if got[3] != got[0] {
+		t.Fatalf("第 4 次应轮回到第 1 个 key: %v", got)
+	}
+}
+
+func TestPickAPIKeySkipsCooledDownKey(t *testing.T) {
+	c := NewClient(&config.OpenAIConfig{
+		APIKeys: []string{"sk-cool-a", "sk-cool-b"},
+	}, nil, nil)
+
+	// 连续 429 达到阈值后摘除 sk-cool-a
+	for i := 0; i < apiKeyFailureThreshold; i++ {
+		c.reportAPIKeyStatus("sk-cool-a", http.StatusTooManyRequests)
+	}
+	for i := 0; i < 4; i++ {
+		if key := c.pickAPIKey(); key != "sk-cool-b" {
+			t.Fatalf("冷却中的 key 不应被选中, got %q", key)
+		}
+	}
+
+	// 成功响应恢复健康状态
+	c.reportAPIKeyStatus("sk-cool-a", http.StatusOK)
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		seen[c.pickAPIKey()] = true
+	}
+	if !seen["sk-cool-a"] {
+		t.Fatalf("恢复后的 key 应重新进入轮询: %v", seen)
+	}
+}
+
+func TestPickAPIKeyFallsBackToSingleAPIKey(t *testing.T) {
+	c := NewClient(&config.OpenAIConfig{APIKey: " sk-single "}, nil, nil)
+	if key := c.pickAPIKey(); key != "sk-single" {
+		t.Fatalf("未配置 api_keys 时应退回 api_key, got %q", key)
+	}
+}
+
+func TestReportAPIKeyStatusRequiresConsecutiveFailures(t *testing.T) {
+	c := NewClient(&config.OpenAIConfig{
+		APIKeys: []string{"sk-seq-a", "sk-seq-b"},
+	}, nil, nil)
+
+	// 失败-成功交替不应摘除
+	c.reportAPIKeyStatus("sk-seq-a", http.StatusTooManyRequests)
+	c.reportAPIKeyStatus("sk-seq-a", http.StatusOK)
+	c.reportAPIKeyStatus("sk-seq-a", http.StatusTooManyRequests)
+
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		seen[c.pickAPIKey()] = true
+	}
+	if !seen["sk-seq-a"] {
+		t.Fatalf("未连续失败的 key 不应被摘除: %v", seen)
+	}
+}
@@ -81,6 +81,32 @@ func (c *Client) UpdateConfig(cfg *config.OpenAIConfig) {
 	c.config = cfg
 }
 
+// applyCustomHeaders 追加 openai.headers 配置的额外请求头（自建网关路由头、X-Api-Key 等）；
+// 不覆盖已设置的标准头。
+func (c *Client) applyCustomHeaders(h http.Header) {
+	if c.config == nil {
+		return
+	}
+	for k, v := range c.config.Headers {
+		k = strings.TrimSpace(k)
+		if k == "" || h.Get(k) != "" {
+			continue
+		}
+		h.Set(k, v)
+	}
+}
+
+// setAuthorizationHeader 仅在配置了 APIKey 时设置 Authorization 头，并返回实际使用的 key
+// （配置多个 api_keys 时由轮询池选取，供响应后上报健康状态）；
+// Ollama 等本地 OpenAI 兼容端点无需鉴权，部分实现收到空 Bearer 会直接报错。
+func (c *Client) setAuthorizationHeader(h http.Header) string {
+	key := c.pickAPIKey()
+	if key != "" {
+		h.Set("Authorization", "Bearer "+key)
+	}
+	return key
+}
+
 // ChatCompletion 调用 /chat/completions 接口。
 func (c *Client) ChatCompletion(ctx context.Context, payload interface{}, out interface{}) error {
 	if c == nil {
@@ -89,17 +115,15 @@ func (c *Client) ChatCompletion(ctx context.Context, payload interface{}, out in
 	if c.config == nil {
 		return fmt.Errorf("openai config is nil")
 	}
-	if strings.TrimSpace(c.config.APIKey) == "" {
-		return fmt.Errorf("openai api key is empty")
-	}
+	// APIKey 为空不报错：Ollama 等本地 OpenAI 兼容端点无需鉴权；Claude 桥接仍必填
 	if c.isClaude() {
+		if strings.TrimSpace(c.config.APIKey) == "" {
+			return fmt.Errorf("openai api key is empty")
+		}
 		return c.claudeChatCompletion(ctx, payload, out)
 	}
 
-	baseURL := strings.TrimSuffix(c.config.BaseURL, "/")
-	if baseURL == "" {
-		baseURL = "https://api.openai.com/v1"
-	}
+	baseURL := config.NormalizeOpenAIBaseURL(c.config.BaseURL)
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -109,12 +133,18 @@ func (c *Client) ChatCompletion(ctx context.Context, payload interface{}, out in
 	c.logger.Debug("sending OpenAI chat completion request",
 		zap.Int("payloadSizeKB", len(body)/1024))
 
+	auditReqPath, auditRespPath, auditOn := c.auditCallPaths(ctx)
+	if auditOn {
+		c.auditWriteFile(auditReqPath, body)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/chat/completions", bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("build openai request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	apiKey := c.setAuthorizationHeader(req.Header)
+	c.applyCustomHeaders(req.Header)
 
 	requestStart := time.Now()
 	resp, err := c.httpClient.Do(req)
@@ -122,6 +152,7 @@ func (c *Client) ChatCompletion(ctx context.Context, payload interface{}, out in
 		return fmt.Errorf("call openai api: %w", err)
 	}
 	defer resp.Body.Close()
+	c.reportAPIKeyStatus(apiKey, resp.StatusCode)
 
 	bodyChan := make(chan []byte, 1)
 	errChan := make(chan error, 1)
@@ -151,6 +182,10 @@ func (c *Client) ChatCompletion(ctx context.Context, payload interface{}, out in
 		zap.Int("responseSizeKB", len(respBody)/1024),
 	)
 
+	if auditOn {
+		c.auditWriteFile(auditRespPath, respBody)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		c.logger.Warn("OpenAI chat completion returned non-200",
 			zap.Int("status", resp.StatusCode),
@@ -184,29 +219,33 @@ func (c *Client) ChatCompletionStream(ctx context.Context, payload interface{},
 	if c.config == nil {
 		return "", fmt.Errorf("openai config is nil")
 	}
-	if strings.TrimSpace(c.config.APIKey) == "" {
-		return "", fmt.Errorf("openai api key is empty")
-	}
+	// APIKey 为空不报错：Ollama 等本地 OpenAI 兼容端点无需鉴权；Claude 桥接仍必填
 	if c.isClaude() {
+		if strings.TrimSpace(c.config.APIKey) == "" {
+			return "", fmt.Errorf("openai api key is empty")
+		}
 		return c.claudeChatCompletionStream(ctx, payload, onDelta)
 	}
 
-	baseURL := strings.TrimSuffix(c.config.BaseURL, "/")
-	if baseURL == "" {
-		baseURL = "https://api.openai.com/v1"
-	}
+	baseURL := config.NormalizeOpenAIBaseURL(c.config.BaseURL)
 
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return "", fmt.Errorf("marshal openai payload: %w", err)
 	}
 
+	auditReqPath, auditRespPath, auditOn := c.auditCallPaths(ctx)
+	if auditOn {
+		c.auditWriteFile(auditReqPath, body)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/chat/completions", bytes.NewReader(body))
 	if err != nil {
 		return "", fmt.Errorf("build openai request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	apiKey := c.setAuthorizationHeader(req.Header)
+	c.applyCustomHeaders(req.Header)
 
 	requestStart := time.Now()
 	resp, err := c.httpClient.Do(req)
@@ -214,6 +253,7 @@ func (c *Client) ChatCompletionStream(ctx context.Context, payload interface{},
 		return "", fmt.Errorf("call openai api: %w", err)
 	}
 	defer resp.Body.Close()
+	c.reportAPIKeyStatus(apiKey, resp.StatusCode)
 
 	// 非200：读完 body 返回
 	if resp.StatusCode != http.StatusOK {
@@ -221,12 +261,24 @@ func (c *Client) ChatCompletionStream(ctx context.Context, payload interface{},
 		if readErr != nil {
 			c.logger.Warn("failed to read OpenAI error response body", zap.Error(readErr))
 		}
+		if auditOn {
+			c.auditWriteFile(auditRespPath, respBody)
+		}
 		return "", &APIError{
 			StatusCode: resp.StatusCode,
 			Body:       string(respBody),
 		}
 	}
 
+	// 流式响应审计：原始 SSE 经 TeeReader 边读边写，完整落盘
+	var streamBody io.Reader = resp.Body
+	if auditOn {
+		if f := c.auditOpenStreamFile(auditRespPath); f != nil {
+			defer f.Close()
+			streamBody = io.TeeReader(resp.Body, f)
+		}
+	}
+
 	type streamDelta struct {
 		// OpenAI 兼容流式通常使用 content；但部分兼容实现可能用 text。
 		Content string `json:"content,omitempty"`
@@ -245,7 +297,7 @@ func (c *Client) ChatCompletionStream(ctx context.Context, payload interface{},
 		} `json:"error,omitempty"`
 	}
 
-	reader := bufio.NewReader(resp.Body)
+	reader := bufio.NewReader(streamBody)
 	var full strings.Builder
 	fullText := ""
 
@@ -334,29 +386,33 @@ func (c *Client) ChatCompletionStreamWithToolCalls(
 	if c.config == nil {
 		return "", nil, "", fmt.Errorf("openai config is nil")
 	}
-	if strings.TrimSpace(c.config.APIKey) == "" {
-		return "", nil, "", fmt.Errorf("openai api key is empty")
-	}
+	// APIKey 为空不报错：Ollama 等本地 OpenAI 兼容端点无需鉴权；Claude 桥接仍必填
 	if c.isClaude() {
+		if strings.TrimSpace(c.config.APIKey) == "" {
+			return "", nil, "", fmt.Errorf("openai api key is empty")
+		}
 		return c.claudeChatCompletionStreamWithToolCalls(ctx, payload, onContentDelta)
 	}
 
-	baseURL := strings.TrimSuffix(c.config.BaseURL, "/")
-	if baseURL == "" {
-		baseURL = "https://api.openai.com/v1"
-	}
+	baseURL := config.NormalizeOpenAIBaseURL(c.config.BaseURL)
 
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return "", nil, "", fmt.Errorf("marshal openai payload: %w", err)
 	}
 
+	auditReqPath, auditRespPath, auditOn := c.auditCallPaths(ctx)
+	if auditOn {
+		c.auditWriteFile(auditReqPath, body)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/chat/completions", bytes.NewReader(body))
 	if err != nil {
 		return "", nil, "", fmt.Errorf("build openai request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	apiKey := c.setAuthorizationHeader(req.Header)
+	c.applyCustomHeaders(req.Header)
 
 	requestStart := time.Now()
 	resp, err := c.httpClient.Do(req)
@@ -364,18 +420,31 @@ func (c *Client) ChatCompletionStreamWithToolCalls(
 		return "", nil, "", fmt.Errorf("call openai api: %w", err)
 	}
 	defer resp.Body.Close()
+	c.reportAPIKeyStatus(apiKey, resp.StatusCode)
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, readErr := io.ReadAll(resp.Body)
 		if readErr != nil {
 			c.logger.Warn("failed to read OpenAI error response body", zap.Error(readErr))
 		}
+		if auditOn {
+			c.auditWriteFile(auditRespPath, respBody)
+		}
 		return "", nil, "", &APIError{
 			StatusCode: resp.StatusCode,
 			Body:       string(respBody),
 		}
 	}
 
+	// 流式响应审计：原始 SSE 经 TeeReader 边读边写，完整落盘
+	var streamBody io.Reader = resp.Body
+	if auditOn {
+		if f := c.auditOpenStreamFile(auditRespPath); f != nil {
+			defer f.Close()
+			streamBody = io.TeeReader(resp.Body, f)
+		}
+	}
+
 	// delta tool_calls 的增量结构
 	type toolCallFunctionDelta struct {
 		Name      string `json:"name,omitempty"`
@@ -412,7 +481,7 @@ func (c *Client) ChatCompletionStreamWithToolCalls(
 	}
 	toolCallAccums := make(map[int]*toolCallAccum)
 
-	reader := bufio.NewReader(resp.Body)
+	reader := bufio.NewReader(streamBody)
 	var full strings.Builder
 	fullText := ""
 	finishReason := ""
@@ -555,29 +624,26 @@ func (c *Client) ListModels(ctx context.Context) ([]string, error) {
 	if c.config == nil {
 		return nil, fmt.Errorf("openai config is nil")
 	}
-	if strings.TrimSpace(c.config.APIKey) == "" {
-		return nil, fmt.Errorf("openai api key is empty")
-	}
+	// APIKey 为空不报错：Ollama 等本地 OpenAI 兼容端点无需鉴权
 	if c.isClaude() {
 		return nil, fmt.Errorf("claude provider does not support models list API")
 	}
 
-	baseURL := strings.TrimSuffix(c.config.BaseURL, "/")
-	if baseURL == "" {
-		baseURL = "https://api.openai.com/v1"
-	}
+	baseURL := config.NormalizeOpenAIBaseURL(c.config.BaseURL)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/models", nil)
 	if err != nil {
 		return nil, fmt.Errorf("build openai models request: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	apiKey := c.setAuthorizationHeader(req.Header)
+	c.applyCustomHeaders(req.Header)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("call openai models api: %w", err)
 	}
 	defer resp.Body.Close()
+	c.reportAPIKeyStatus(apiKey, resp.StatusCode)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -0,0 +1,23 @@
+package openai
+
+import (
+	"net/http"
+	"testing"
+
+	"cyberstrike-ai/internal/config"
+)
+
+func TestSetAuthorizationHeaderSkipsEmptyKey(t *testing.T) {
+	c := NewClient(&config.OpenAIConfig{APIKey: ""}, nil, nil)
+	h := http.Header{}
+	c.setAuthorizationHeader(h)
+	if got := h.Get("Authorization"); got != "" {
+		t.Fatalf("空 APIKey 不应设置 Authorization 头: %q", got)
+	}
+
+	c = NewClient(&config.OpenAIConfig{APIKey: " sk-test "}, nil, nil)
+	c.setAuthorizationHeader(h)
+	if got := h.Get("Authorization"); got != "Bearer sk-test" {
+		t.Fatalf("got %q", got)
+	}
+}
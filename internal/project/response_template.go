@@ -0,0 +1,23 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuildResponseTemplateBlock 构建「最终回答格式约束」system prompt 块：
+// 配置了 agent.response_template 时要求模型按固定结构输出最终回答，
+// 便于前端结构化渲染与报告生成。模板为空时返回空串（不约束格式）。
+func BuildResponseTemplateBlock(template string) string {
+	template = strings.TrimSpace(template)
+	if template == "" {
+		return ""
+	}
+	return fmt.Sprintf(`## 最终回答格式要求
+
+完成任务后的**最终回答**必须严格按以下模板组织（保留模板中的标题层级与顺序，缺少内容的小节写「无」，不要增删小节）：
+
+%s
+
+该约束只作用于最终回答；过程中的工具调用说明、中间分析不受此模板限制。`, template)
+}
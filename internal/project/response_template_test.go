@@ -0,0 +1,24 @@
+package project
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildResponseTemplateBlock(t *testing.T) {
+	if got := BuildResponseTemplateBlock("  "); got != "" {
+		t.Fatalf("空模板应返回空串: %q", got)
+	}
+
+	tpl := "## 概述\n## 发现\n## 风险\n## 建议"
+	block := BuildResponseTemplateBlock(tpl)
+	if !strings.HasPrefix(block, "## 最终回答格式要求") {
+		t.Fatalf("块标题不符: %q", block)
+	}
+	if !strings.Contains(block, tpl) {
+		t.Fatalf("块内应包含模板原文:\n%s", block)
+	}
+	if !strings.Contains(block, "最终回答") {
+		t.Fatalf("应说明仅约束最终回答:\n%s", block)
+	}
+}
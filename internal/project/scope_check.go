@@ -0,0 +1,107 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// CheckTargetInScope 校验目标主机是否落在项目 scope_json 的授权范围内：
+// 命中 exclude 直接拒绝；配置了 targets 但未命中任何一条也拒绝。
+// scope_json 为空、非法或未配置 targets 时视为未限制（返回 nil），
+// 与 [BuildScopeBlock] 的"提示级约束"语义保持一致。
+func CheckTargetInScope(scopeJSON, host string) error {
+	host = normalizeScopeHost(host)
+	if host == "" {
+		return fmt.Errorf("目标主机为空")
+	}
+	raw := strings.TrimSpace(scopeJSON)
+	if raw == "" {
+		return nil
+	}
+	var payload projectScopePayload
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		return nil
+	}
+	for _, entry := range payload.Exclude {
+		if matchScopeEntry(entry, host) {
+			return fmt.Errorf("目标 %s 命中项目排除范围（exclude: %s）", host, strings.TrimSpace(entry))
+		}
+	}
+	if len(payload.Targets) == 0 {
+		return nil
+	}
+	for _, entry := range payload.Targets {
+		if matchScopeEntry(entry, host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("目标 %s 不在项目授权范围（targets）内", host)
+}
+
+// matchScopeEntry 判断 host 是否命中单条 scope 条目。
+// 条目可以是域名（含子域后缀匹配）、IP、CIDR 或带协议的 URL（取其 host 部分）。
+func matchScopeEntry(entry, host string) bool {
+	entry = normalizeScopeHost(entry)
+	if entry == "" || host == "" {
+		return false
+	}
+	if entry == host {
+		return true
+	}
+	// CIDR：目标为 IP 时按网段匹配
+	if strings.Contains(entry, "/") {
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			if ip := net.ParseIP(host); ip != nil {
+				return ipNet.Contains(ip)
+			}
+		}
+		return false
+	}
+	// 域名条目：允许其子域（*.example.com 与 example.com 等价）
+	entry = strings.TrimPrefix(entry, "*.")
+	if net.ParseIP(entry) == nil && net.ParseIP(host) == nil {
+		return host == entry || strings.HasSuffix(host, "."+entry)
+	}
+	return false
+}
+
+// normalizeScopeHost 归一化比较键：去协议/路径/端口、小写、去空白。
+func normalizeScopeHost(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return ""
+	}
+	if strings.Contains(s, "://") {
+		if u, err := url.Parse(s); err == nil && u.Hostname() != "" {
+			return u.Hostname()
+		}
+	}
+	// 去掉路径部分（裸域名 example.com/path）
+	if idx := strings.IndexAny(s, "/"); idx > 0 && !strings.Contains(s, "://") {
+		// CIDR 形如 10.0.0.0/24，保留斜杠
+		if net.ParseIP(s[:idx]) == nil || !isCIDRSuffix(s[idx:]) {
+			s = s[:idx]
+		}
+	}
+	// 去端口（排除 IPv6 字面量）
+	if h, _, err := net.SplitHostPort(s); err == nil && h != "" {
+		s = h
+	}
+	return strings.TrimSuffix(s, ".")
+}
+
+func isCIDRSuffix(s string) bool {
+	s = strings.TrimPrefix(s, "/")
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package project
+
+import "testing"
+
+func TestCheckTargetInScope(t *testing.T) {
+	scope := `{"targets":["example.com","10.0.0.0/24","https://app.demo.org:8443/login","192.168.1.5"],"exclude":["admin.example.com"]}`
+
+	allowed := []string{
+		"example.com",
+		"api.example.com",
+		"EXAMPLE.COM",
+		"10.0.0.42",
+		"app.demo.org",
+		"192.168.1.5",
+	}
+	for _, host := range allowed {
+		if err := CheckTargetInScope(scope, host); err != nil {
+			t.Errorf("CheckTargetInScope(%q) = %v, want nil", host, err)
+		}
+	}
+
+	denied := []string{
+		"admin.example.com", // exclude 优先于 targets
+		"evil.com",
+		"10.0.1.1",
+		"192.168.1.6",
+	}
+	for _, host := range denied {
+		if err := CheckTargetInScope(scope, host); err == nil {
+			t.Errorf("CheckTargetInScope(%q) = nil, want error", host)
+		}
+	}
+}
+
+func TestCheckTargetInScopeUnrestricted(t *testing.T) {
+	// 空 scope、非法 JSON、仅 notes：都视为未限制
+	for _, scope := range []string{"", "not-json", `{"notes":"仅内网"}`} {
+		if err := CheckTargetInScope(scope, "anything.example"); err != nil {
+			t.Errorf("scope=%q: got %v, want nil", scope, err)
+		}
+	}
+	// 配置了 targets 但主机为空
+	if err := CheckTargetInScope(`{"targets":["example.com"]}`, ""); err == nil {
+		t.Error("empty host should be rejected")
+	}
+}
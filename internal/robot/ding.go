@@ -117,8 +117,8 @@ func handleDingMessage(ctx context.Context, msg *chatbot.BotCallbackDataModel, c
 	if idx := strings.IndexAny(reply, "\n"); idx > 0 {
 		title = strings.TrimSpace(reply[:idx])
 	}
-	if len(title) > 50 {
-		title = title[:50] + "…"
+	if t := truncateRunes(title, 50); t != title {
+		title = t + "…"
 	}
 	if title == "" {
 		title = "回复"
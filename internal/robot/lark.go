@@ -3,6 +3,7 @@ package robot
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
@@ -19,6 +20,10 @@ import (
 const (
 	larkReconnectInitial = 5 * time.Second  // 首次重连间隔
 	larkReconnectMax     = 60 * time.Second // 最大重连间隔
+
+	larkMaxMessageRunes  = 4000            // 单条文本消息长度上限（按 rune 留足余量），超长回复自动分段
+	larkReplyMaxAttempts = 3               // 单段回复最大尝试次数（网络抖动、限流等临时失败）
+	larkReplyRetryDelay  = 2 * time.Second // 非 token 类失败的重试间隔
 )
 
 type larkTextContent struct {
@@ -77,8 +82,13 @@ func handleLarkMessage(ctx context.Context, event *larkim.P2MessageReceiveV1, cf
 	}
 	msg := event.Event.Message
 	msgType := larkcore.StringValue(msg.MessageType)
+	messageID := larkcore.StringValue(msg.MessageId)
 	if msgType != larkim.MsgTypeText {
-		logger.Debug("飞书暂仅处理文本消息", zap.String("msg_type", msgType))
+		// 静默忽略会让用户以为机器人没响应，回复明确的不支持提示
+		logger.Debug("飞书收到非文本消息，回复不支持提示", zap.String("msg_type", msgType))
+		if err := sendLarkReply(ctx, client, messageID, larkUnsupportedMessageReply(msgType), logger); err != nil {
+			logger.Warn("飞书不支持提示回复失败", zap.String("message_id", messageID), zap.Error(err))
+		}
 		return
 	}
 	var textBody larkTextContent
@@ -95,23 +105,95 @@ func handleLarkMessage(ctx context.Context, event *larkim.P2MessageReceiveV1, cf
 		logger.Warn("飞书消息缺少可用用户标识，已忽略")
 		return
 	}
-	messageID := larkcore.StringValue(msg.MessageId)
 	reply := h.HandleMessage("lark", userID, text)
-	contentBytes, _ := json.Marshal(larkTextContent{Text: reply})
-	_, err := client.Im.Message.Reply(ctx, larkim.NewReplyMessageReqBuilder().
-		MessageId(messageID).
-		Body(larkim.NewReplyMessageReqBodyBuilder().
-			MsgType(larkim.MsgTypeText).
-			Content(string(contentBytes)).
-			Build()).
-		Build())
-	if err != nil {
-		logger.Warn("飞书回复失败", zap.String("message_id", messageID), zap.Error(err))
+	if err := sendLarkReply(ctx, client, messageID, reply, logger); err != nil {
+		logger.Warn("飞书回复失败（重试后仍失败）", zap.String("message_id", messageID), zap.Error(err))
 		return
 	}
 	logger.Debug("飞书已回复", zap.String("message_id", messageID))
 }
 
+// larkUnsupportedMessageReply 非文本消息的提示文案；图片 OCR/语音转写待接入多模态接口后支持。
+func larkUnsupportedMessageReply(msgType string) string {
+	switch msgType {
+	case larkim.MsgTypeImage:
+		return "暂仅支持文本消息，图片内容请以文字描述后发送。"
+	case larkim.MsgTypeAudio:
+		return "暂仅支持文本消息，请将语音内容转为文字后发送。"
+	default:
+		return "暂仅支持文本消息，请用文字描述您的问题。"
+	}
+}
+
+// sendLarkReply 回复飞书消息：超过单条消息长度上限时按 larkMaxMessageRunes 分段逐条发送，
+// 每段失败时有限重试，避免一次网络抖动就丢掉整条回复。
+func sendLarkReply(ctx context.Context, client *lark.Client, messageID, reply string, logger *zap.Logger) error {
+	reply = trimReply(reply)
+	if reply == "" {
+		return nil
+	}
+	for _, chunk := range splitTextChunks(reply, larkMaxMessageRunes) {
+		contentBytes, _ := json.Marshal(larkTextContent{Text: chunk})
+		if err := replyLarkChunkWithRetry(ctx, client, messageID, string(contentBytes), logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replyLarkChunkWithRetry 发送单段回复，最多尝试 larkReplyMaxAttempts 次：
+// token 失效类错误立即重试（SDK 下次请求会重新获取 token），其余失败按固定间隔重试。
+func replyLarkChunkWithRetry(ctx context.Context, client *lark.Client, messageID, content string, logger *zap.Logger) error {
+	var lastErr error
+	for attempt := 1; attempt <= larkReplyMaxAttempts; attempt++ {
+		resp, err := client.Im.Message.Reply(ctx, larkim.NewReplyMessageReqBuilder().
+			MessageId(messageID).
+			Body(larkim.NewReplyMessageReqBodyBuilder().
+				MsgType(larkim.MsgTypeText).
+				Content(content).
+				Build()).
+			Build())
+		switch {
+		case err != nil:
+			lastErr = err
+		case resp != nil && !resp.Success():
+			lastErr = fmt.Errorf("飞书接口返回错误 code=%d msg=%s", resp.Code, resp.Msg)
+			if isLarkTokenError(resp.Code) && attempt < larkReplyMaxAttempts {
+				logger.Warn("飞书回复遇到 token 失效，刷新后立即重试",
+					zap.String("message_id", messageID),
+					zap.Int("code", resp.Code),
+					zap.Int("attempt", attempt),
+				)
+				continue
+			}
+		default:
+			return nil
+		}
+		if attempt < larkReplyMaxAttempts {
+			logger.Warn("飞书回复失败，准备重试",
+				zap.String("message_id", messageID),
+				zap.Int("attempt", attempt),
+				zap.Error(lastErr),
+			)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(larkReplyRetryDelay):
+			}
+		}
+	}
+	return lastErr
+}
+
+// isLarkTokenError 是否为 token 失效/过期类错误码；此类错误无需等待，SDK 会在下次请求时重新获取 token。
+func isLarkTokenError(code int) bool {
+	switch code {
+	case 99991661, 99991663, 99991664, 99991665, 99991668:
+		return true
+	}
+	return false
+}
+
 // resolveLarkUserID 提取飞书会话隔离键：
 // tenant_key + 稳定用户标识（user_id/open_id/union_id）；按配置可选 chat_id 兜底。
 func resolveLarkUserID(event *larkim.P2MessageReceiveV1, allowChatIDFallback bool) string {
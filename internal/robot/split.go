@@ -24,6 +24,18 @@ func splitTextChunks(text string, maxRunes int) []string {
 	return out
 }
 
+// truncateRunes 按 rune 安全截断，避免在多字节字符中间切断产生乱码。
+func truncateRunes(s string, max int) string {
+	rs := []rune(s)
+	if len(rs) <= max {
+		return s
+	}
+	if max <= 0 {
+		return ""
+	}
+	return string(rs[:max])
+}
+
 func trimReply(s string) string {
 	return strings.TrimSpace(s)
 }
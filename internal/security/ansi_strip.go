@@ -0,0 +1,38 @@
+package security
+
+import "regexp"
+
+// 工具输出 ANSI 清理：sqlmap、nmap 脚本等工具的彩色输出带大量 ANSI 转义序列，
+// 直接进 LLM 上下文浪费 token 且干扰解析，前端展示也是乱码。executor 在收齐
+// stdout/stderr 后统一 strip（security.keep_ansi_output: true 可保留原始输出）。
+// 只清理收齐后的完整输出；增量回调的 chunk 不处理，避免转义序列被 chunk 边界截断。
+
+// ansiEscapePattern 匹配 CSI 序列（颜色、光标控制）、OSC 序列（终端标题等）
+// 与其余两字符 ESC 序列。
+var ansiEscapePattern = regexp.MustCompile(`\x1b(\[[0-9;?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)?|[()#][0-9A-Za-z]|[@-Z\\-_])`)
+
+// StripANSI 去除字符串中的 ANSI 转义序列，保留纯文本。
+func StripANSI(s string) string {
+	if !containsESC(s) {
+		return s
+	}
+	return ansiEscapePattern.ReplaceAllString(s, "")
+}
+
+// containsESC 快速路径：绝大多数输出不含 ESC，避免无谓的正则扫描。
+func containsESC(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x1b {
+			return true
+		}
+	}
+	return false
+}
+
+// maybeStripANSI 按配置清理工具输出中的 ANSI 转义序列（默认清理）。
+func (e *Executor) maybeStripANSI(output string) string {
+	if e != nil && e.config != nil && e.config.KeepANSIOutput {
+		return output
+	}
+	return StripANSI(output)
+}
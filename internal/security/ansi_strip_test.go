@@ -0,0 +1,42 @@
+package security
+
+import (
+	"testing"
+
+	"cyberstrike-ai/internal/config"
+)
+
+func TestStripANSI(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "no escapes here", "no escapes here"},
+		{"sgr-color", "\x1b[1;31m[CRITICAL]\x1b[0m sqli found", "[CRITICAL] sqli found"},
+		{"sqlmap-style", "[\x1b[36m12:00:01\x1b[0m] [\x1b[32mINFO\x1b[0m] testing connection", "[12:00:01] [INFO] testing connection"},
+		{"cursor-control", "progress\x1b[2K\x1b[1Gdone", "progressdone"},
+		{"osc-title", "\x1b]0;window title\x07output", "output"},
+		{"private-mode", "\x1b[?25lspinner\x1b[?25h", "spinner"},
+		{"charset-designation", "a\x1b(Bb", "ab"},
+	}
+	for _, tc := range cases {
+		if got := StripANSI(tc.input); got != tc.want {
+			t.Errorf("%s: StripANSI(%q) = %q, want %q", tc.name, tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestMaybeStripANSIRespectsKeepSwitch(t *testing.T) {
+	colored := "\x1b[31mred\x1b[0m"
+
+	e := &Executor{config: &config.SecurityConfig{}}
+	if got := e.maybeStripANSI(colored); got != "red" {
+		t.Fatalf("default should strip ANSI, got %q", got)
+	}
+
+	e = &Executor{config: &config.SecurityConfig{KeepANSIOutput: true}}
+	if got := e.maybeStripANSI(colored); got != colored {
+		t.Fatalf("keep_ansi_output=true should preserve output, got %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package security
 
 import (
+	"crypto/subtle"
 	"database/sql"
 	"errors"
 	"strings"
@@ -35,8 +36,9 @@ type AuthManager struct {
 	sessionDuration time.Duration
 	db              *database.DB
 
-	mu       sync.RWMutex
-	sessions map[string]Session
+	mu        sync.RWMutex
+	sessions  map[string]Session
+	apiTokens []string
 }
 
 // NewAuthManager creates a new AuthManager instance.
@@ -173,6 +175,58 @@ func (a *AuthManager) ValidateToken(token string) (Session, bool) {
 	return session, true
 }
 
+// ConfigureAPITokens sets the static API token list (auth.api_tokens).
+// An empty list disables token-based access and keeps session-only auth.
+func (a *AuthManager) ConfigureAPITokens(tokens []string) {
+	cleaned := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			cleaned = append(cleaned, token)
+		}
+	}
+	a.mu.Lock()
+	a.apiTokens = cleaned
+	a.mu.Unlock()
+}
+
+// APITokenSession validates a static API token and, on match, returns a synthetic
+// full-permission service session for programmatic callers (scripts, CI).
+// Returns false when no tokens are configured, preserving the previous behavior.
+func (a *AuthManager) APITokenSession(token string) (Session, bool) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return Session{}, false
+	}
+
+	a.mu.RLock()
+	tokens := a.apiTokens
+	a.mu.RUnlock()
+
+	matched := false
+	for _, candidate := range tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			matched = true
+		}
+	}
+	if !matched {
+		return Session{}, false
+	}
+
+	permissions := make(map[string]bool, len(PermissionCatalog))
+	for permission := range PermissionCatalog {
+		permissions[permission] = true
+	}
+	return Session{
+		Token:       token,
+		ExpiresAt:   time.Now().Add(a.sessionDuration),
+		UserID:      "service:api-token",
+		Username:    "api-token",
+		DisplayName: "API Token",
+		Permissions: permissions,
+		Scope:       database.RBACScopeAll,
+	}, true
+}
+
 // CheckPassword verifies whether the provided password matches the current password.
 func (a *AuthManager) CheckPassword(password string) bool {
 	return a.CheckUserPassword("admin", password)
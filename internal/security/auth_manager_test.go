@@ -92,3 +92,32 @@ func TestQueryTokenOnlyAllowedForSSEAndWebSocketGET(t *testing.T) {
 		t.Fatalf("WebSocket token = %q", got)
 	}
 }
+
+func TestAPITokenSessionGrantsFullPermissionServiceIdentity(t *testing.T) {
+	manager := NewAuthManager(12)
+	if _, ok := manager.APITokenSession("any-token"); ok {
+		t.Fatal("unconfigured manager accepted an API token")
+	}
+
+	manager.ConfigureAPITokens([]string{" static-token-1 ", "", "static-token-2"})
+	if _, ok := manager.APITokenSession("wrong-token"); ok {
+		t.Fatal("mismatched API token accepted")
+	}
+	session, ok := manager.APITokenSession("static-token-1")
+	if !ok {
+		t.Fatal("configured API token rejected")
+	}
+	if session.UserID != "service:api-token" || session.Scope != database.RBACScopeAll {
+		t.Fatalf("unexpected service identity: %+v", session)
+	}
+	for permission := range PermissionCatalog {
+		if !session.Permissions[permission] {
+			t.Fatalf("permission %q missing from API token session", permission)
+		}
+	}
+
+	manager.ConfigureAPITokens(nil)
+	if _, ok := manager.APITokenSession("static-token-1"); ok {
+		t.Fatal("cleared API token still accepted")
+	}
+}
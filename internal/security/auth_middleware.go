@@ -24,6 +24,10 @@ func AuthMiddleware(manager *AuthManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := extractTokenFromRequest(c)
 		session, ok := manager.ValidateToken(token)
+		if !ok {
+			// 静态 API Token（auth.api_tokens）兜底：供脚本/CI 等程序化调用
+			session, ok = manager.APITokenSession(token)
+		}
 		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error": "未授权访问，请先登录",
@@ -0,0 +1,34 @@
+package security
+
+import "strings"
+
+// shellSafeArgChars 不需要引号即可原样粘贴进 POSIX shell 的字符集合。
+const shellSafeArgChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,@+%"
+
+// formatCommandLine 将命令与参数拼接为可直接粘贴到 shell 复现的完整命令行。
+func formatCommandLine(command string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, shellQuoteArg(command))
+	for _, arg := range args {
+		parts = append(parts, shellQuoteArg(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuoteArg 按需为单个参数加单引号（参数内的单引号做闭合转义），安全字符集内的参数原样返回。
+func shellQuoteArg(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range arg {
+		if !strings.ContainsRune(shellSafeArgChars, r) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,30 @@
+package security
+
+import "testing"
+
+func TestFormatCommandLine(t *testing.T) {
+	cases := []struct {
+		command string
+		args    []string
+		want    string
+	}{
+		{"nmap", []string{"-sV", "-p", "1-1000", "10.0.0.5"}, "nmap -sV -p 1-1000 10.0.0.5"},
+		{"sqlmap", []string{"-u", "http://example.com/?id=1", "--batch"}, `sqlmap -u 'http://example.com/?id=1' --batch`},
+		{"sh", []string{"-c", "echo hello && id"}, `sh -c 'echo hello && id'`},
+		{"tool", []string{""}, "tool ''"},
+	}
+	for _, c := range cases {
+		if got := formatCommandLine(c.command, c.args); got != c.want {
+			t.Errorf("formatCommandLine(%q, %v) = %q, want %q", c.command, c.args, got, c.want)
+		}
+	}
+}
+
+func TestShellQuoteArgEscapesSingleQuote(t *testing.T) {
+	if got := shellQuoteArg(`it's`); got != `'it'\''s'` {
+		t.Fatalf("单引号应按 '\\'' 转义: %q", got)
+	}
+	if got := shellQuoteArg("plain-arg_1.0"); got != "plain-arg_1.0" {
+		t.Fatalf("安全字符集内参数不应加引号: %q", got)
+	}
+}
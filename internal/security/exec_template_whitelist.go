@@ -0,0 +1,59 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+)
+
+// exec 命令模板白名单（security.exec_command_templates）：强管控场景下比黑名单
+// 更安全的方案——LLM 只能从预定义模板里选命令，如 "whois {domain}"、"dig {domain}"。
+// 模板按空白切分为 token，{xxx} 占位符匹配一个实参 token，其余 token 需逐字相同；
+// 为防止占位符夹带注入，整条命令不允许出现 shell 控制字符。列表为空时不启用。
+
+// execTemplateForbiddenChars 白名单模式下命令中一律禁止的 shell 控制字符。
+const execTemplateForbiddenChars = ";|&`$><\n\r"
+
+// checkExecCommandTemplates 校验 exec 命令是否匹配白名单模板；未配置模板时直接放行。
+func (e *Executor) checkExecCommandTemplates(command string) error {
+	if e == nil || e.config == nil {
+		return nil
+	}
+	return validateCommandAgainstTemplates(command, e.config.ExecCommandTemplates)
+}
+
+func validateCommandAgainstTemplates(command string, templates []string) error {
+	if len(templates) == 0 {
+		return nil
+	}
+	cmd := strings.TrimSpace(command)
+	if strings.ContainsAny(cmd, execTemplateForbiddenChars) {
+		return fmt.Errorf("命令包含 shell 控制字符，白名单模板模式下不允许: %q", command)
+	}
+	fields := strings.Fields(cmd)
+	for _, tpl := range templates {
+		if matchCommandTemplate(fields, strings.Fields(strings.TrimSpace(tpl))) {
+			return nil
+		}
+	}
+	return fmt.Errorf("命令不匹配任何白名单模板（security.exec_command_templates），已拒绝: %q", command)
+}
+
+// matchCommandTemplate 逐 token 比对：{xxx} 占位符匹配任意一个实参 token。
+func matchCommandTemplate(fields, tplFields []string) bool {
+	if len(tplFields) == 0 || len(fields) != len(tplFields) {
+		return false
+	}
+	for i, tok := range tplFields {
+		if isTemplatePlaceholder(tok) {
+			continue
+		}
+		if fields[i] != tok {
+			return false
+		}
+	}
+	return true
+}
+
+func isTemplatePlaceholder(tok string) bool {
+	return len(tok) >= 3 && strings.HasPrefix(tok, "{") && strings.HasSuffix(tok, "}")
+}
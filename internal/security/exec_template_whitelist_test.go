@@ -0,0 +1,37 @@
+package security
+
+import "testing"
+
+func TestValidateCommandAgainstTemplates(t *testing.T) {
+	templates := []string{"whois {domain}", "dig {domain}", "nmap -sV {target}"}
+
+	tests := []struct {
+		name    string
+		command string
+		wantErr bool
+	}{
+		{"占位符填充匹配", "whois example.com", false},
+		{"多 token 模板匹配", "nmap -sV 10.0.0.1", false},
+		{"字面 token 不同被拒", "nmap -sS 10.0.0.1", true},
+		{"参数个数不符被拒", "whois example.com extra", true},
+		{"不在白名单的命令被拒", "curl http://example.com", true},
+		{"shell 控制字符被拒", "whois example.com;id", true},
+		{"管道被拒", "dig example.com | tee out", true},
+		{"首尾空白不影响匹配", "  dig example.com  ", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCommandAgainstTemplates(tt.command, templates)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateCommandAgainstTemplates(%q) err = %v, wantErr = %v", tt.command, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCommandAgainstTemplatesEmptyAllowsAll(t *testing.T) {
+	if err := validateCommandAgainstTemplates("rm -rf /tmp/scratch", nil); err != nil {
+		t.Fatalf("未配置模板时应放行: %v", err)
+	}
+}
@@ -41,15 +41,21 @@ type Executor struct {
 	shellNoOutputTimeoutSec int // execute/exec 无新输出空闲秒数；0=默认 300；-1=关闭（见 SetShellNoOutputTimeoutSeconds）
 	toolOutputMaxBytes      int
 	spillRootDir            string
+	resultCache             *toolResultCache        // 按工具 cache_ttl_seconds 复用近期结果
+	httpScopeChecker        HTTPRequestScopeChecker // http_request 工具目标范围校验（可选）
+	versionCache            sync.Map                // command → 探测到的版本输出（见 tool_version.go）
+	targetLocks             *targetLockRegistry     // 同目标调用串行化（见 target_lock.go）
 }
 
 // NewExecutor 创建新的执行器
 func NewExecutor(cfg *config.SecurityConfig, mcpServer *mcp.Server, logger *zap.Logger) *Executor {
 	executor := &Executor{
-		config:    cfg,
-		toolIndex: make(map[string]*config.ToolConfig),
-		mcpServer: mcpServer,
-		logger:    logger,
+		config:      cfg,
+		toolIndex:   make(map[string]*config.ToolConfig),
+		mcpServer:   mcpServer,
+		logger:      logger,
+		resultCache: newToolResultCache(),
+		targetLocks: newTargetLockRegistry(),
 	}
 	// 构建工具索引
 	executor.buildToolIndex()
@@ -150,6 +156,34 @@ func (e *Executor) ExecuteTool(ctx context.Context, toolName string, args map[st
 		zap.Strings("args", toolConfig.Args),
 	)
 
+	// 幂等工具的结果缓存：命中时跳过真实执行，直接返回并标注来自缓存
+	var cacheKey string
+	cacheTTL := time.Duration(toolConfig.CacheTTLSeconds) * time.Second
+	if cacheTTL > 0 {
+		cacheKey = toolResultCacheKey(toolName, args)
+		if cached, ok := e.resultCache.get(cacheKey); ok {
+			e.logger.Info("工具结果缓存命中",
+				zap.String("tool", toolName),
+				zap.Duration("ttl", cacheTTL),
+			)
+			return cachedToolResult(cached, cacheTTL), nil
+		}
+	}
+
+	// 同一目标主机的并发调用串行化：避免多个工具互相干扰或触发目标防护，
+	// 不同目标仍真正并行；等待期间 ctx 取消（任务终止/超时）则直接返回
+	lockedHosts, releaseTargets, lockErr := e.targetLocks.lockTargets(ctx, args)
+	if lockErr != nil {
+		return nil, fmt.Errorf("等待目标串行锁时任务已取消: %w", lockErr)
+	}
+	defer releaseTargets()
+	if len(lockedHosts) > 0 {
+		e.logger.Debug("已获取目标串行锁",
+			zap.String("tool", toolName),
+			zap.Strings("hosts", lockedHosts),
+		)
+	}
+
 	// 特殊处理：内部工具（command 以 "internal:" 开头）
 	if strings.HasPrefix(toolConfig.Command, "internal:") {
 		e.logger.Debug("执行内部工具",
@@ -159,6 +193,26 @@ func (e *Executor) ExecuteTool(ctx context.Context, toolName string, args map[st
 		return e.executeInternalTool(ctx, toolName, toolConfig.Command, args)
 	}
 
+	// 命令未安装时直接给出明确提示，避免 LLM 拿到难懂的 exit status 后继续重试
+	if !ToolCommandInstalled(toolConfig.Command) {
+		e.logger.Warn("工具未安装",
+			zap.String("tool", toolName),
+			zap.String("command", toolConfig.Command),
+		)
+		return &mcp.ToolResult{
+			Content: []mcp.Content{
+				{
+					Type: "text",
+					Text: fmt.Sprintf("工具 %s 未安装（命令 %q 不在 PATH 中），请先安装后再调用", toolName, toolConfig.Command),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// 按探测到的工具版本选择参数 profile（未配置 version_profiles 时为 no-op）
+	toolConfig = e.resolveToolConfigForVersion(ctx, toolConfig)
+
 	// 构建命令 - 根据工具类型使用不同的参数格式
 	cmdArgs := e.buildCommandArgs(toolName, toolConfig, args)
 
@@ -185,6 +239,9 @@ func (e *Executor) ExecuteTool(ctx context.Context, toolName string, args map[st
 		}, nil
 	}
 
+	// 登记最终拼接出的完整命令行，落到执行记录供人工复现
+	mcp.RecordCommandLine(ctx, formatCommandLine(toolConfig.Command, cmdArgs))
+
 	// 执行命令
 	cmd := exec.CommandContext(ctx, toolConfig.Command, cmdArgs...)
 	applyDefaultTerminalEnv(cmd)
@@ -202,7 +259,7 @@ func (e *Executor) ExecuteTool(ctx context.Context, toolName string, args map[st
 	// 如果上层提供了 stdout/stderr 增量回调，或当前处于 MCP execution 中，则边执行边读取并回调。
 	if cb, ok := ctx.Value(ToolOutputCallbackCtxKey).(ToolOutputCallback); (ok && cb != nil) || mcp.MCPExecutionIDFromContext(ctx) != "" {
 		cb = e.wrapToolOutputCallback(ctx, cb)
-		output, err = streamCommandOutput(ctx, cmd, cb, ResolveShellNoOutputTimeoutSeconds(e.shellNoOutputTimeoutSec), e.toolOutputMaxBytes, spill)
+		output, err = streamCommandOutput(ctx, cmd, cb, nil, ResolveShellNoOutputTimeoutSeconds(e.shellNoOutputTimeoutSec), e.toolOutputMaxBytes, spill)
 		if err != nil && shouldRetryWithPTY(output) {
 			e.logger.Info("检测到工具需要 TTY，使用 PTY 重试",
 				zap.String("tool", toolName),
@@ -225,6 +282,9 @@ func (e *Executor) ExecuteTool(ctx context.Context, toolName string, args map[st
 			output, err = runCommandWithPTY(ctx, cmd2, nil, e.toolOutputMaxBytes, spill)
 		}
 	}
+	// 按工具声明的输出编码转成 UTF-8（GBK 工具在中文 locale 下直接读会乱码进 LLM）
+	output = decodeToolOutput(output, toolConfig.OutputEncoding)
+	output = e.maybeStripANSI(output)
 	if err != nil {
 		// 检查退出码是否在允许列表中
 		exitCode := getExitCode(err)
@@ -236,7 +296,7 @@ func (e *Executor) ExecuteTool(ctx context.Context, toolName string, args map[st
 						zap.Int("exitCode", *exitCode),
 						zap.String("output", string(output)),
 					)
-					return &mcp.ToolResult{
+					result := &mcp.ToolResult{
 						Content: []mcp.Content{
 							{
 								Type: "text",
@@ -244,7 +304,9 @@ func (e *Executor) ExecuteTool(ctx context.Context, toolName string, args map[st
 							},
 						},
 						IsError: false,
-					}, nil
+					}
+					e.resultCache.put(cacheKey, result, cacheTTL)
+					return result, nil
 				}
 			}
 		}
@@ -271,7 +333,7 @@ func (e *Executor) ExecuteTool(ctx context.Context, toolName string, args map[st
 		zap.String("output", string(output)),
 	)
 
-	return &mcp.ToolResult{
+	result := &mcp.ToolResult{
 		Content: []mcp.Content{
 			{
 				Type: "text",
@@ -279,7 +341,9 @@ func (e *Executor) ExecuteTool(ctx context.Context, toolName string, args map[st
 			},
 		},
 		IsError: false,
-	}, nil
+	}
+	e.resultCache.put(cacheKey, result, cacheTTL)
+	return result, nil
 }
 
 // RegisterTools 注册工具到MCP服务器
@@ -313,8 +377,11 @@ func (e *Executor) RegisterTools(mcpServer *mcp.Server) {
 			if len(desc) > 10000 {
 				if idx := strings.Index(desc, "\n"); idx > 0 && idx < 10000 {
 					shortDesc = strings.TrimSpace(desc[:idx])
+				} else if rs := []rune(desc); len(rs) > 10000 {
+					// 按 rune 截断，避免把多字节字符切出乱码
+					shortDesc = string(rs[:10000]) + "..."
 				} else {
-					shortDesc = desc[:10000] + "..."
+					shortDesc = desc
 				}
 			} else {
 				shortDesc = desc
@@ -724,7 +791,7 @@ func (e *Executor) formatParamValue(param config.ParameterConfig, value interfac
 		if arr, ok := value.([]interface{}); ok {
 			strs := make([]string, 0, len(arr))
 			for _, item := range arr {
-				strs = append(strs, fmt.Sprintf("%v", item))
+				strs = append(strs, NormalizeTarget(fmt.Sprintf("%v", item), param.TargetFormat))
 			}
 			return strings.Join(strs, ",")
 		}
@@ -744,7 +811,8 @@ func (e *Executor) formatParamValue(param config.ParameterConfig, value interfac
 			// 移除所有空格，但保留逗号和其他字符
 			formattedValue = strings.ReplaceAll(formattedValue, " ", "")
 		}
-		return formattedValue
+		// 目标参数规范化（target_format 未配置时原样返回）
+		return NormalizeTarget(formattedValue, param.TargetFormat)
 	}
 }
 
@@ -801,7 +869,28 @@ func (e *Executor) executeSystemCommand(ctx context.Context, args map[string]int
 		zap.String("command", command),
 	)
 
-	command = PrepareShellCommandForExecute(command)
+	// stdin/auto_answers 参数存在时保留标准输入通道（不注入 exec </dev/null），命令可从 stdin 读数据
+	stdinInput, _ := args["stdin"].(string)
+	autoAnswers := parseExecAutoAnswers(args["auto_answers"])
+	keepStdin := stdinInput != "" || len(autoAnswers) > 0
+	if keepStdin {
+		command = PrepareShellCommandForExecuteKeepStdin(command)
+	} else {
+		command = PrepareShellCommandForExecute(command)
+	}
+
+	// 命令模板白名单（security.exec_command_templates）：非空时只放行匹配模板的命令
+	if err := e.checkExecCommandTemplates(command); err != nil {
+		return &mcp.ToolResult{
+			Content: []mcp.Content{
+				{
+					Type: "text",
+					Text: err.Error(),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
 
 	// 获取shell类型（可选，默认为sh）
 	shell := "sh"
@@ -818,6 +907,9 @@ func (e *Executor) executeSystemCommand(ctx context.Context, args map[string]int
 	// 检测是否为后台命令（包含 & 符号，但不在引号内）
 	isBackground := IsBackgroundShellCommand(command)
 
+	// exec 工具直接记录实际执行的 shell 命令，供执行记录复现
+	mcp.RecordCommandLine(ctx, formatCommandLine(shell, []string{"-c", command}))
+
 	// 构建命令
 	var cmd *exec.Cmd
 	if workDir != "" {
@@ -826,6 +918,24 @@ func (e *Executor) executeSystemCommand(ctx context.Context, args map[string]int
 	} else {
 		cmd = exec.CommandContext(ctx, shell, "-c", command)
 	}
+	// stdin 参数：写入子进程标准输入后关闭；有自动应答规则时经 promptAutoResponder 保持可写。
+	// 须在 ConfigureShellCmdForAgentExecute 之前设置，否则会被 /dev/null 占位（后台命令忽略 stdin）。
+	var responder *promptAutoResponder
+	if keepStdin && !isBackground {
+		if len(autoAnswers) > 0 {
+			r, rErr := newPromptAutoResponder(stdinInput, autoAnswers, e.logger)
+			if rErr != nil {
+				e.logger.Warn("创建自动应答管道失败，stdin 降级为一次性写入", zap.Error(rErr))
+				cmd.Stdin = strings.NewReader(stdinInput)
+			} else {
+				responder = r
+				cmd.Stdin = responder.stdin()
+				defer responder.Close()
+			}
+		} else {
+			cmd.Stdin = strings.NewReader(stdinInput)
+		}
+	}
 	ConfigureShellCmdForAgentExecute(cmd)
 
 	// 执行命令
@@ -964,10 +1074,14 @@ func (e *Executor) executeSystemCommand(ctx context.Context, args map[string]int
 	var output string
 	var err error
 	spill := e.spillOptsFromContext(ctx)
-	// 若上层提供工具输出增量回调，或当前处于 MCP execution 中，则边执行边流式读取。
-	if cb, ok := ctx.Value(ToolOutputCallbackCtxKey).(ToolOutputCallback); (ok && cb != nil) || mcp.MCPExecutionIDFromContext(ctx) != "" {
+	// 若上层提供工具输出增量回调、处于 MCP execution 中或需要自动应答，则边执行边流式读取。
+	if cb, ok := ctx.Value(ToolOutputCallbackCtxKey).(ToolOutputCallback); (ok && cb != nil) || mcp.MCPExecutionIDFromContext(ctx) != "" || responder != nil {
 		cb = e.wrapToolOutputCallback(ctx, cb)
-		output, err = streamCommandOutput(ctx, cmd, cb, ResolveShellNoOutputTimeoutSeconds(e.shellNoOutputTimeoutSec), e.toolOutputMaxBytes, spill)
+		var observe func(chunk string)
+		if responder != nil {
+			observe = responder.Observe
+		}
+		output, err = streamCommandOutput(ctx, cmd, cb, observe, ResolveShellNoOutputTimeoutSeconds(e.shellNoOutputTimeoutSec), e.toolOutputMaxBytes, spill)
 		if err != nil && shouldRetryWithPTY(output) {
 			e.logger.Info("检测到系统命令需要 TTY，使用 PTY 重试")
 			cmd2 := exec.CommandContext(ctx, shell, "-c", command)
@@ -989,6 +1103,7 @@ func (e *Executor) executeSystemCommand(ctx context.Context, args map[string]int
 			output, err = runCommandWithPTY(ctx, cmd2, nil, e.toolOutputMaxBytes, spill)
 		}
 	}
+	output = e.maybeStripANSI(output)
 	if err != nil {
 		e.logger.Error("系统命令执行失败",
 			zap.String("command", command),
@@ -1238,7 +1353,8 @@ func truncateStringBytes(s string, maxBytes int) string {
 
 // streamCommandOutput 以“边读边回调”的方式读取命令 stdout/stderr。
 // 使用定长块读取，避免按行读取在无换行输出时永久阻塞；ctx 取消时终止进程树。
-func streamCommandOutput(ctx context.Context, cmd *exec.Cmd, cb ToolOutputCallback, noOutputSec int, maxBytes int, spill tooloutput.SpillOpts) (string, error) {
+// observe 非空时同步接收未经截断的原始 chunk（exec 自动应答用），不受 cb 的批量 flush 影响。
+func streamCommandOutput(ctx context.Context, cmd *exec.Cmd, cb ToolOutputCallback, observe func(chunk string), noOutputSec int, maxBytes int, spill tooloutput.SpillOpts) (string, error) {
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
 		return "", err
@@ -1315,6 +1431,9 @@ func streamCommandOutput(ctx context.Context, cmd *exec.Cmd, cb ToolOutputCallba
 		defer idleWatch.Stop()
 	}
 
+	outputKillBytes := resolveToolMaxOutputKillBytes()
+	var rawOutputBytes int64
+
 	fireInactivity := func() {
 		TerminateShellCmdSession(session)
 		msg := ShellNoOutputTimeoutMessage(idleWatch.Sec)
@@ -1347,8 +1466,21 @@ chunksLoop:
 			if chunk != "" && idleWatch != nil {
 				idleWatch.Bump()
 			}
+			if observe != nil && chunk != "" {
+				observe(chunk)
+			}
 			keptChunk := outBuilder.WriteStringLimited(chunk)
 			deltaBuilder.WriteString(keptChunk)
+			rawOutputBytes += int64(len(chunk))
+			if outputKillBytes > 0 && rawOutputBytes > outputKillBytes {
+				// 输出字节超限：直接终止进程树（agent.tool_max_output_bytes）
+				TerminateShellCmdSession(session)
+				msg := outBuilder.WriteStringLimited(ToolOutputLimitMessage(outputKillBytes))
+				deltaBuilder.WriteString(msg)
+				flush()
+				_ = session.Wait()
+				return finalizeBoundedOutput(outBuilder, maxBytes, tee), errToolOutputLimitExceeded(outputKillBytes)
+			}
 			if deltaBuilder.Len() >= 2048 || time.Since(lastFlush) >= 200*time.Millisecond {
 				flush()
 			}
@@ -1415,7 +1547,7 @@ func runCommandWithPTY(ctx context.Context, cmd *exec.Cmd, cb ToolOutputCallback
 	if runtime.GOOS == "windows" {
 		// PTY 方案为类 Unix；Windows 走原逻辑
 		if cb != nil {
-			return streamCommandOutput(ctx, cmd, cb, 0, maxBytes, spill)
+			return streamCommandOutput(ctx, cmd, cb, nil, 0, maxBytes, spill)
 		}
 		_ = prepareShellCmdSession(cmd)
 		return combinedOutputCancellableWithLimit(ctx, cmd, maxBytes, spill)
@@ -1432,6 +1564,7 @@ func runCommandWithPTY(ctx context.Context, cmd *exec.Cmd, cb ToolOutputCallback
 	if cmd.Process != nil {
 		rootPID = cmd.Process.Pid
 	}
+	applyToolCPULimit(rootPID, resolveToolMaxCPUSeconds())
 
 	// ctx 取消时尽快终止子进程
 	done := make(chan struct{})
@@ -1464,6 +1597,8 @@ func runCommandWithPTY(ctx context.Context, cmd *exec.Cmd, cb ToolOutputCallback
 		lastFlush = time.Now()
 	}
 
+	outputKillBytes := resolveToolMaxOutputKillBytes()
+	var rawOutputBytes int64
 	buf := make([]byte, 4096)
 	for {
 		n, readErr := ptmx.Read(buf)
@@ -1474,6 +1609,17 @@ func runCommandWithPTY(ctx context.Context, cmd *exec.Cmd, cb ToolOutputCallback
 			chunk = strings.ReplaceAll(chunk, "\r", "\n")
 			keptChunk := outBuilder.WriteStringLimited(chunk)
 			deltaBuilder.WriteString(keptChunk)
+			rawOutputBytes += int64(len(chunk))
+			if outputKillBytes > 0 && rawOutputBytes > outputKillBytes {
+				// 输出字节超限：直接终止进程树（agent.tool_max_output_bytes）
+				msg := outBuilder.WriteStringLimited(ToolOutputLimitMessage(outputKillBytes))
+				deltaBuilder.WriteString(msg)
+				flush()
+				_ = ptmx.Close()
+				terminateProcessGroup(rootPID, cmd)
+				_ = cmd.Wait()
+				return finalizeBoundedOutput(outBuilder, maxBytes, tee), errToolOutputLimitExceeded(outputKillBytes)
+			}
 			if deltaBuilder.Len() >= 2048 || time.Since(lastFlush) >= 200*time.Millisecond {
 				flush()
 			}
@@ -1491,6 +1637,12 @@ func runCommandWithPTY(ctx context.Context, cmd *exec.Cmd, cb ToolOutputCallback
 // executeInternalTool 执行内部工具（不执行外部命令）
 func (e *Executor) executeInternalTool(ctx context.Context, toolName string, command string, args map[string]interface{}) (*mcp.ToolResult, error) {
 	internalToolType := strings.TrimPrefix(command, "internal:")
+	switch internalToolType {
+	case "http_request":
+		return e.executeHTTPRequestTool(ctx, args)
+	case "subdomain_enum":
+		return e.executeSubdomainEnumTool(ctx, args)
+	}
 	e.logger.Warn("未知的内部工具",
 		zap.String("toolName", toolName),
 		zap.String("internalToolType", internalToolType),
@@ -0,0 +1,174 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"cyberstrike-ai/internal/mcp"
+
+	"go.uber.org/zap"
+)
+
+// HTTPRequestScopeChecker 校验 http_request 工具的目标主机是否在授权范围内；
+// 返回非 nil 错误时拒绝请求。由上层（app 装配）按项目 scope_json 注入。
+type HTTPRequestScopeChecker func(ctx context.Context, host string) error
+
+// SetHTTPRequestScopeChecker 注入 http_request 工具的目标范围校验；nil 表示不校验。
+func (e *Executor) SetHTTPRequestScopeChecker(checker HTTPRequestScopeChecker) {
+	e.httpScopeChecker = checker
+}
+
+const (
+	// httpRequestDefaultTimeout / httpRequestMaxTimeout 限定单次请求耗时，
+	// 避免 LLM 传入超长超时拖住整个工具调用。
+	httpRequestDefaultTimeout = 30 * time.Second
+	httpRequestMaxTimeout     = 120 * time.Second
+	// httpRequestMaxBodyBytes 响应体截断上限；完整验证大响应请改用 exec + curl -o。
+	httpRequestMaxBodyBytes = 64 * 1024
+)
+
+// executeHTTPRequestTool 内置 http_request 工具：用 Go http.Client 发送自定义请求，
+// 返回状态码、响应头与（截断后的）响应体，替代让 LLM 拼 curl 走 exec 的脆弱路径。
+func (e *Executor) executeHTTPRequestTool(ctx context.Context, args map[string]interface{}) (*mcp.ToolResult, error) {
+	rawURL, _ := args["url"].(string)
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return httpRequestErrorResult("缺少 url 参数"), nil
+	}
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Host == "" {
+		return httpRequestErrorResult(fmt.Sprintf("url 非法: %s", rawURL)), nil
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return httpRequestErrorResult(fmt.Sprintf("仅支持 http/https，当前 scheme: %s", parsedURL.Scheme)), nil
+	}
+
+	if e.httpScopeChecker != nil {
+		if err := e.httpScopeChecker(ctx, parsedURL.Hostname()); err != nil {
+			e.logger.Warn("http_request 目标超出授权范围",
+				zap.String("url", rawURL),
+				zap.Error(err),
+			)
+			return httpRequestErrorResult("目标超出项目授权范围，已拒绝: " + err.Error()), nil
+		}
+	}
+
+	method := strings.ToUpper(strings.TrimSpace(stringArg(args, "method")))
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var bodyReader io.Reader
+	if body := stringArg(args, "body"); body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+
+	timeout := httpRequestDefaultTimeout
+	if v, ok := args["timeout_seconds"].(float64); ok && v > 0 {
+		timeout = time.Duration(v) * time.Second
+		if timeout > httpRequestMaxTimeout {
+			timeout = httpRequestMaxTimeout
+		}
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, method, rawURL, bodyReader)
+	if err != nil {
+		return httpRequestErrorResult("构建请求失败: " + err.Error()), nil
+	}
+	if headers, ok := args["headers"].(map[string]interface{}); ok {
+		for k, v := range headers {
+			k = strings.TrimSpace(k)
+			if k == "" {
+				continue
+			}
+			val := fmt.Sprintf("%v", v)
+			if strings.EqualFold(k, "Host") {
+				req.Host = val
+				continue
+			}
+			req.Header.Set(k, val)
+		}
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", "CyberStrikeAI-http-request/1.0")
+	}
+
+	client := &http.Client{}
+	if follow, ok := args["follow_redirects"].(bool); ok && !follow {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return httpRequestErrorResult("请求失败: " + err.Error()), nil
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, readErr := io.ReadAll(io.LimitReader(resp.Body, httpRequestMaxBodyBytes+1))
+	truncated := false
+	if len(bodyBytes) > httpRequestMaxBodyBytes {
+		bodyBytes = bodyBytes[:httpRequestMaxBodyBytes]
+		truncated = true
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", method, rawURL)
+	fmt.Fprintf(&b, "状态: %s（耗时 %s）\n\n", resp.Status, time.Since(start).Round(time.Millisecond))
+	b.WriteString("响应头:\n")
+	headerKeys := make([]string, 0, len(resp.Header))
+	for k := range resp.Header {
+		headerKeys = append(headerKeys, k)
+	}
+	sort.Strings(headerKeys)
+	for _, k := range headerKeys {
+		fmt.Fprintf(&b, "%s: %s\n", k, strings.Join(resp.Header[k], ", "))
+	}
+	b.WriteString("\n响应体:\n")
+	b.Write(bodyBytes)
+	if readErr != nil && readErr != io.EOF {
+		fmt.Fprintf(&b, "\n（读取响应体中断: %v）", readErr)
+	}
+	if truncated {
+		fmt.Fprintf(&b, "\n（响应体超过 %d 字节，已截断）", httpRequestMaxBodyBytes)
+	}
+
+	return &mcp.ToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: b.String(),
+			},
+		},
+		IsError: false,
+	}, nil
+}
+
+func httpRequestErrorResult(msg string) *mcp.ToolResult {
+	return &mcp.ToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: "错误: " + msg,
+			},
+		},
+		IsError: true,
+	}
+}
+
+func stringArg(args map[string]interface{}, key string) string {
+	if v, ok := args[key].(string); ok {
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
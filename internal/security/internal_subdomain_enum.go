@@ -0,0 +1,191 @@
+package security
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cyberstrike-ai/internal/mcp"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// subdomainEnumDefaultConcurrency / Max 控制并发 DNS 解析数，避免打爆本地 resolver。
+	subdomainEnumDefaultConcurrency = 20
+	subdomainEnumMaxConcurrency     = 100
+	// subdomainEnumDefaultTimeout / Max 为整体枚举超时；单条解析固定 3 秒。
+	subdomainEnumDefaultTimeout = 60 * time.Second
+	subdomainEnumMaxTimeout     = 300 * time.Second
+	subdomainEnumLookupTimeout  = 3 * time.Second
+	// subdomainEnumMaxWordlist 限制字典规模，内置字典远小于该值，自定义字典超出部分忽略。
+	subdomainEnumMaxWordlist = 100000
+)
+
+// subdomainEnumBuiltinWordlist 内置常见子域名字典，覆盖信息收集阶段的高频前缀；
+// 需要更大字典时通过 wordlist 参数传入文件路径（每行一个前缀）。
+var subdomainEnumBuiltinWordlist = []string{
+	"www", "mail", "ftp", "smtp", "pop", "imap", "webmail", "ns1", "ns2", "ns3",
+	"dns", "mx", "mx1", "mx2", "email", "remote", "vpn", "ssh", "admin", "portal",
+	"api", "api-dev", "app", "apps", "web", "blog", "shop", "store", "m", "mobile",
+	"dev", "test", "testing", "staging", "stage", "uat", "demo", "beta", "alpha", "preview",
+	"cdn", "static", "assets", "img", "images", "media", "upload", "download", "files", "docs",
+	"git", "gitlab", "github", "svn", "jenkins", "ci", "build", "deploy", "registry", "docker",
+	"db", "mysql", "redis", "mongo", "es", "elastic", "kibana", "grafana", "prometheus", "monitor",
+	"log", "logs", "status", "health", "metrics", "stats", "analytics", "tracking", "data", "bi",
+	"auth", "sso", "login", "oauth", "id", "account", "accounts", "user", "users", "my",
+	"support", "help", "wiki", "forum", "bbs", "news", "cms", "oa", "crm", "erp",
+	"proxy", "gateway", "gw", "lb", "cache", "search", "pay", "payment", "billing", "invoice",
+	"intranet", "internal", "corp", "office", "it", "hr", "sec", "security", "backup", "old",
+}
+
+// executeSubdomainEnumTool 内置 subdomain_enum 工具：基于字典做并发 DNS 解析枚举，
+// 返回解析成功的子域名及其 IP 列表，结果可直接喂给后续 nmap 等扫描工具。
+func (e *Executor) executeSubdomainEnumTool(ctx context.Context, args map[string]interface{}) (*mcp.ToolResult, error) {
+	domain := strings.ToLower(strings.Trim(stringArg(args, "domain"), "."))
+	if domain == "" {
+		return httpRequestErrorResult("缺少 domain 参数"), nil
+	}
+	if strings.ContainsAny(domain, " /\\:") || !strings.Contains(domain, ".") {
+		return httpRequestErrorResult(fmt.Sprintf("domain 非法: %s（应为裸域名，如 example.com）", domain)), nil
+	}
+
+	words := subdomainEnumBuiltinWordlist
+	wordlistLabel := fmt.Sprintf("内置字典（%d 条）", len(words))
+	if path := stringArg(args, "wordlist"); path != "" {
+		loaded, err := loadSubdomainWordlist(path)
+		if err != nil {
+			return httpRequestErrorResult("加载字典失败: " + err.Error()), nil
+		}
+		words = loaded
+		wordlistLabel = fmt.Sprintf("%s（%d 条）", path, len(words))
+	}
+
+	concurrency := subdomainEnumDefaultConcurrency
+	if v, ok := args["concurrency"].(float64); ok && v > 0 {
+		concurrency = int(v)
+		if concurrency > subdomainEnumMaxConcurrency {
+			concurrency = subdomainEnumMaxConcurrency
+		}
+	}
+	timeout := subdomainEnumDefaultTimeout
+	if v, ok := args["timeout_seconds"].(float64); ok && v > 0 {
+		timeout = time.Duration(v) * time.Second
+		if timeout > subdomainEnumMaxTimeout {
+			timeout = subdomainEnumMaxTimeout
+		}
+	}
+	enumCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type hit struct {
+		name string
+		ips  []string
+	}
+
+	var (
+		mu   sync.Mutex
+		hits []hit
+		wg   sync.WaitGroup
+	)
+	sem := make(chan struct{}, concurrency)
+	resolver := &net.Resolver{}
+
+	start := time.Now()
+	tried := 0
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		if enumCtx.Err() != nil {
+			break
+		}
+		tried++
+		fqdn := word + "." + domain
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(fqdn string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			lookupCtx, lookupCancel := context.WithTimeout(enumCtx, subdomainEnumLookupTimeout)
+			defer lookupCancel()
+			addrs, err := resolver.LookupHost(lookupCtx, fqdn)
+			if err != nil || len(addrs) == 0 {
+				return
+			}
+			sort.Strings(addrs)
+			mu.Lock()
+			hits = append(hits, hit{name: fqdn, ips: addrs})
+			mu.Unlock()
+		}(fqdn)
+	}
+	wg.Wait()
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].name < hits[j].name })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "子域名枚举: %s\n", domain)
+	fmt.Fprintf(&b, "字典: %s，尝试 %d 条，耗时 %s，并发 %d\n", wordlistLabel, tried, time.Since(start).Round(time.Millisecond), concurrency)
+	if enumCtx.Err() != nil {
+		fmt.Fprintf(&b, "（整体超时 %s 已到，结果可能不完整）\n", timeout)
+	}
+	if len(hits) == 0 {
+		b.WriteString("\n未发现可解析的子域名。可尝试更大的字典（wordlist 参数）或改用 subfinder/amass 等被动收集工具。\n")
+	} else {
+		fmt.Fprintf(&b, "\n解析成功 %d 个:\n", len(hits))
+		for _, h := range hits {
+			fmt.Fprintf(&b, "%s -> %s\n", h.name, strings.Join(h.ips, ", "))
+		}
+	}
+
+	e.logger.Info("subdomain_enum 完成",
+		zap.String("domain", domain),
+		zap.Int("tried", tried),
+		zap.Int("found", len(hits)),
+	)
+	return &mcp.ToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: b.String(),
+			},
+		},
+		IsError: false,
+	}, nil
+}
+
+// loadSubdomainWordlist 读取自定义字典（每行一个前缀，# 开头为注释），超出上限的行忽略。
+func loadSubdomainWordlist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	words := make([]string, 0, 1024)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+		if len(words) >= subdomainEnumMaxWordlist {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("字典 %s 为空", path)
+	}
+	return words, nil
+}
@@ -0,0 +1,80 @@
+package security
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// 目标参数规范化：LLM 常把 https://a.com/path 直接喂给 nmap 导致失败。
+// 各工具按自己需要在参数上声明 target_format（见 config.ParameterConfig.TargetFormat），
+// 执行前统一走 NormalizeTarget 转换。
+
+// 目标参数规范化格式
+const (
+	TargetFormatHost     = "host"      // 仅主机：去 scheme/端口/路径，IPv6 去方括号（nmap、ping 类）
+	TargetFormatHostPort = "host_port" // 主机[:端口]：去 scheme/路径，保留端口（nc、masscan 类）
+	TargetFormatURL      = "url"       // 完整 URL：裸主机补 http://（sqlmap、nikto 类）
+)
+
+// NormalizeTarget 按 format 规范化目标值；无法解析时原样返回（不吞掉用户输入）
+func NormalizeTarget(raw, format string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return raw
+	}
+
+	switch format {
+	case TargetFormatHost, TargetFormatHostPort:
+		host, port, ok := splitTargetHostPort(raw)
+		if !ok {
+			return raw
+		}
+		if format == TargetFormatHost || port == "" {
+			return host
+		}
+		if strings.Contains(host, ":") {
+			// IPv6 带端口时必须加方括号
+			return "[" + host + "]:" + port
+		}
+		return host + ":" + port
+	case TargetFormatURL:
+		if strings.Contains(raw, "://") {
+			return raw
+		}
+		if strings.Contains(raw, ":") && net.ParseIP(strings.Trim(raw, "[]")) != nil {
+			// 裸 IPv6 地址做 URL 时需要方括号
+			return "http://[" + strings.Trim(raw, "[]") + "]"
+		}
+		return "http://" + raw
+	default:
+		return raw
+	}
+}
+
+// splitTargetHostPort 从 URL / host:port / 裸主机中拆出主机与端口；
+// 返回的主机不带方括号（IPv6 为原始地址形式）
+func splitTargetHostPort(raw string) (host, port string, ok bool) {
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			return "", "", false
+		}
+		return u.Hostname(), u.Port(), true
+	}
+	// 裸 IPv6（无端口）：整体就是地址，不能按 ":" 拆
+	if ip := net.ParseIP(strings.Trim(raw, "[]")); ip != nil {
+		return strings.Trim(raw, "[]"), "", true
+	}
+	// 可能带路径（a.com/path、a.com:8080/path），借助 URL 解析剥掉
+	if strings.ContainsAny(raw, "/?#") {
+		if u, err := url.Parse("scheme://" + raw); err == nil && u.Hostname() != "" {
+			return u.Hostname(), u.Port(), true
+		}
+		return "", "", false
+	}
+	if h, p, err := net.SplitHostPort(raw); err == nil && h != "" {
+		return strings.Trim(h, "[]"), p, true
+	}
+	return raw, "", true
+}
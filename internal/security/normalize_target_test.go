@@ -0,0 +1,38 @@
+package security
+
+import "testing"
+
+func TestNormalizeTarget(t *testing.T) {
+	tests := []struct {
+		raw    string
+		format string
+		want   string
+	}{
+		// host：nmap 类，去 scheme/端口/路径
+		{"https://a.com/path?x=1", TargetFormatHost, "a.com"},
+		{"https://a.com:8443/admin", TargetFormatHost, "a.com"},
+		{"a.com:8080/path", TargetFormatHost, "a.com"},
+		{"a.com:8080", TargetFormatHost, "a.com"},
+		{"10.0.0.1", TargetFormatHost, "10.0.0.1"},
+		{"[2001:db8::1]:443", TargetFormatHost, "2001:db8::1"},
+		{"2001:db8::1", TargetFormatHost, "2001:db8::1"},
+		// host_port：保留端口
+		{"https://a.com:8443/admin", TargetFormatHostPort, "a.com:8443"},
+		{"http://a.com/path", TargetFormatHostPort, "a.com"},
+		{"a.com:8080", TargetFormatHostPort, "a.com:8080"},
+		{"[2001:db8::1]:443", TargetFormatHostPort, "[2001:db8::1]:443"},
+		// url：sqlmap/nikto 类，裸主机补 scheme
+		{"https://a.com/path", TargetFormatURL, "https://a.com/path"},
+		{"a.com/login.php", TargetFormatURL, "http://a.com/login.php"},
+		{"10.0.0.1:8080", TargetFormatURL, "http://10.0.0.1:8080"},
+		{"2001:db8::1", TargetFormatURL, "http://[2001:db8::1]"},
+		// 未声明 target_format 或无法解析时原样返回
+		{"https://a.com/path", "", "https://a.com/path"},
+		{" a.com ", TargetFormatHost, "a.com"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTarget(tt.raw, tt.format); got != tt.want {
+			t.Errorf("NormalizeTarget(%q, %q) = %q, want %q", tt.raw, tt.format, got, tt.want)
+		}
+	}
+}
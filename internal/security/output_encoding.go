@@ -0,0 +1,39 @@
+package security
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// 工具输出编码转换：部分工具在中文 Windows / 特定 locale 下输出 GBK，
+// 直接当 UTF-8 读会乱码进 LLM。工具可在 YAML 里声明 output_encoding
+// （见 config.ToolConfig.OutputEncoding），executor 读完输出后统一转成 UTF-8。
+
+// decodeToolOutput 按声明的编码把工具原始输出转为 UTF-8；
+// 未声明或声明为 utf-8 时仅做非法字节替换（U+FFFD），未知编码按 utf-8 兜底。
+func decodeToolOutput(output, encoding string) string {
+	if output == "" {
+		return output
+	}
+	switch normalizeEncodingName(encoding) {
+	case "gbk", "gb2312", "gb18030":
+		// GB18030 是 GBK/GB2312 的超集，解码器对非法字节自动替换为 U+FFFD
+		if out, _, err := transform.String(simplifiedchinese.GB18030.NewDecoder(), output); err == nil {
+			return out
+		}
+		return strings.ToValidUTF8(output, "�")
+	default:
+		if utf8.ValidString(output) {
+			return output
+		}
+		return strings.ToValidUTF8(output, "�")
+	}
+}
+
+// normalizeEncodingName 统一编码名写法：小写、去空白与连字符（UTF-8 / utf8 / GBK 等价归一）
+func normalizeEncodingName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "")
+}
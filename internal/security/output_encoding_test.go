@@ -0,0 +1,40 @@
+package security
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+func TestDecodeToolOutputGBK(t *testing.T) {
+	gbk, _, err := transform.String(simplifiedchinese.GBK.NewEncoder(), "扫描完成：发现 3 个开放端口")
+	if err != nil {
+		t.Fatalf("构造 GBK 样本失败: %v", err)
+	}
+
+	got := decodeToolOutput(gbk, "gbk")
+	if got != "扫描完成：发现 3 个开放端口" {
+		t.Errorf("decodeToolOutput(gbk) = %q", got)
+	}
+	// 编码名大小写/连字符等价
+	if decodeToolOutput(gbk, "GB-2312") != got {
+		t.Error("GB-2312 应与 gbk 归一到同一解码路径")
+	}
+}
+
+func TestDecodeToolOutputDefaultUTF8(t *testing.T) {
+	// 未声明编码时 UTF-8 原样返回
+	if got := decodeToolOutput("plain utf-8 输出", ""); got != "plain utf-8 输出" {
+		t.Errorf("utf-8 输出被改写: %q", got)
+	}
+	// 非法字节做安全替换而不是带进 LLM（ToValidUTF8 按连续非法段替换）
+	got := decodeToolOutput("ok\xff\xfe", "utf-8")
+	if got != "ok�" {
+		t.Errorf("非法字节未替换: %q", got)
+	}
+	// 未知编码按 utf-8 兜底
+	if got := decodeToolOutput("hello", "ebcdic"); got != "hello" {
+		t.Errorf("未知编码兜底失败: %q", got)
+	}
+}
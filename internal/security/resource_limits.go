@@ -0,0 +1,54 @@
+package security
+
+import (
+	"fmt"
+	"sync"
+)
+
+// 工具进程资源限制（agent.tool_max_output_bytes / tool_max_cpu_seconds）：
+// 失控命令可能产出海量输出或吃满 CPU，输出字节超限时直接终止进程树并返回
+// 「工具输出超限已终止」；CPU 时间上限在 Linux 下通过 RLIMIT_CPU 下发给子进程，
+// 超限由内核终止。与 tool_output_max_bytes 的内存截断/落盘独立：那是「留多少」，
+// 这里是「产出多少就杀」。包级存放，与 ResolveShellNoOutputTimeoutSeconds 一样
+// 对 streamCommandOutput 等包函数直接可见。
+
+var (
+	toolResourceLimitMu    sync.RWMutex
+	toolMaxOutputKillBytes int64
+	toolMaxCPUSeconds      int
+)
+
+// SetToolResourceLimits 配置工具进程资源限制（0 表示对应项不限制）。
+func SetToolResourceLimits(maxOutputBytes int64, maxCPUSeconds int) {
+	toolResourceLimitMu.Lock()
+	defer toolResourceLimitMu.Unlock()
+	if maxOutputBytes < 0 {
+		maxOutputBytes = 0
+	}
+	if maxCPUSeconds < 0 {
+		maxCPUSeconds = 0
+	}
+	toolMaxOutputKillBytes = maxOutputBytes
+	toolMaxCPUSeconds = maxCPUSeconds
+}
+
+func resolveToolMaxOutputKillBytes() int64 {
+	toolResourceLimitMu.RLock()
+	defer toolResourceLimitMu.RUnlock()
+	return toolMaxOutputKillBytes
+}
+
+func resolveToolMaxCPUSeconds() int {
+	toolResourceLimitMu.RLock()
+	defer toolResourceLimitMu.RUnlock()
+	return toolMaxCPUSeconds
+}
+
+// ToolOutputLimitMessage 输出超限终止时附加到输出末尾的提示。
+func ToolOutputLimitMessage(limitBytes int64) string {
+	return fmt.Sprintf("\n\n[工具输出超限已终止：stdout/stderr 总量超过 %d 字节上限（agent.tool_max_output_bytes），进程已被终止]", limitBytes)
+}
+
+func errToolOutputLimitExceeded(limitBytes int64) error {
+	return fmt.Errorf("tool output limit exceeded (%d bytes)", limitBytes)
+}
@@ -0,0 +1,24 @@
+//go:build linux
+
+package security
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// applyToolCPULimit 通过 prlimit64 给已启动的子进程设置 RLIMIT_CPU：
+// 软限超限收到 SIGXCPU（默认终止），硬限再宽限 5 秒兜底 SIGKILL。
+// 在 Start 之后按 pid 下发，无需修改子进程自身代码。
+func applyToolCPULimit(pid, cpuSeconds int) {
+	if pid <= 0 || cpuSeconds <= 0 {
+		return
+	}
+	rl := syscall.Rlimit{
+		Cur: uint64(cpuSeconds),
+		Max: uint64(cpuSeconds + 5),
+	}
+	_, _, _ = syscall.Syscall6(syscall.SYS_PRLIMIT64,
+		uintptr(pid), uintptr(syscall.RLIMIT_CPU),
+		uintptr(unsafe.Pointer(&rl)), 0, 0, 0)
+}
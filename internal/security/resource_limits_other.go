@@ -0,0 +1,6 @@
+//go:build !linux
+
+package security
+
+// applyToolCPULimit 非 Linux 平台不支持按 pid 下发 RLIMIT_CPU，空实现。
+func applyToolCPULimit(pid, cpuSeconds int) {}
@@ -0,0 +1,54 @@
+package security
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cyberstrike-ai/internal/mcp"
+)
+
+func TestSetToolResourceLimits(t *testing.T) {
+	t.Cleanup(func() { SetToolResourceLimits(0, 0) })
+
+	SetToolResourceLimits(1024, 30)
+	if got := resolveToolMaxOutputKillBytes(); got != 1024 {
+		t.Fatalf("resolveToolMaxOutputKillBytes = %d, want 1024", got)
+	}
+	if got := resolveToolMaxCPUSeconds(); got != 30 {
+		t.Fatalf("resolveToolMaxCPUSeconds = %d, want 30", got)
+	}
+
+	// 负值按不限制处理
+	SetToolResourceLimits(-1, -1)
+	if got := resolveToolMaxOutputKillBytes(); got != 0 {
+		t.Fatalf("negative maxOutputBytes should normalize to 0, got %d", got)
+	}
+	if got := resolveToolMaxCPUSeconds(); got != 0 {
+		t.Fatalf("negative maxCPUSeconds should normalize to 0, got %d", got)
+	}
+}
+
+func TestExecuteSystemCommand_OutputLimitKillsProcess(t *testing.T) {
+	executor, _ := setupTestExecutor(t)
+	SetToolResourceLimits(4096, 0)
+	t.Cleanup(func() { SetToolResourceLimits(0, 0) })
+
+	// 流式路径：持续输出远超 4KB 的数据，应触发进程树终止而不是等命令跑完
+	ctx := context.WithValue(context.Background(), ToolOutputCallbackCtxKey, ToolOutputCallback(func(string) {}))
+	ctx = mcp.WithMCPConversationID(ctx, "exec-output-kill")
+	res, err := executor.executeSystemCommand(ctx, map[string]interface{}{
+		"command": "i=0; while [ $i -lt 100000 ]; do printf 0123456789; i=$((i+1)); done",
+		"shell":   "sh",
+	})
+	if err != nil {
+		t.Fatalf("executeSystemCommand: %v", err)
+	}
+	if res == nil || !res.IsError {
+		t.Fatalf("expected IsError result, got %+v", res)
+	}
+	text := res.Content[0].Text
+	if !strings.Contains(text, "工具输出超限已终止") {
+		t.Fatalf("expected output-limit notice, got: %q", text)
+	}
+}
@@ -0,0 +1,80 @@
+package security
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"cyberstrike-ai/internal/mcp"
+)
+
+// toolResultCache 按 (toolName + 规范化参数) 做带 TTL 的执行结果缓存，
+// 避免同一会话里 LLM 对同一目标重复跑耗时扫描。只有在工具配置里显式
+// 设置 cache_ttl_seconds 的工具才会进缓存。
+type toolResultCache struct {
+	mu      sync.Mutex
+	entries map[string]toolResultCacheEntry
+}
+
+type toolResultCacheEntry struct {
+	result    *mcp.ToolResult
+	expiresAt time.Time
+}
+
+func newToolResultCache() *toolResultCache {
+	return &toolResultCache{entries: make(map[string]toolResultCacheEntry)}
+}
+
+// toolResultCacheKey 生成缓存键；map 经 json.Marshal 后键名有序，
+// 天然对参数顺序不敏感。序列化失败时返回空串表示不可缓存。
+func toolResultCacheKey(toolName string, args map[string]interface{}) string {
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+	return toolName + "\x00" + string(argsJSON)
+}
+
+func (c *toolResultCache) get(key string) (*mcp.ToolResult, bool) {
+	if c == nil || key == "" {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.result, true
+}
+
+func (c *toolResultCache) put(key string, result *mcp.ToolResult, ttl time.Duration) {
+	if c == nil || key == "" || result == nil || ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 顺带清理已过期条目，避免长期运行下无界增长
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = toolResultCacheEntry{result: result, expiresAt: now.Add(ttl)}
+}
+
+// cachedToolResult 复制缓存结果并附加“来自缓存”标注，避免调用方修改缓存内容。
+func cachedToolResult(cached *mcp.ToolResult, ttl time.Duration) *mcp.ToolResult {
+	content := make([]mcp.Content, 0, len(cached.Content)+1)
+	content = append(content, cached.Content...)
+	content = append(content, mcp.Content{
+		Type: "text",
+		Text: "[缓存] 本结果来自 " + ttl.String() + " 内相同参数调用的缓存，如需强制重跑请调整参数或等待缓存过期。",
+	})
+	return &mcp.ToolResult{Content: content, IsError: cached.IsError}
+}
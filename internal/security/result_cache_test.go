@@ -0,0 +1,98 @@
+package security
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"cyberstrike-ai/internal/config"
+	"cyberstrike-ai/internal/mcp"
+
+	"go.uber.org/zap"
+)
+
+func TestExecuteToolReusesCachedResultWithinTTL(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("依赖 date 命令")
+	}
+	logger := zap.NewNop()
+	mcpServer := mcp.NewServer(logger)
+	pos := 0
+	cfg := &config.SecurityConfig{
+		Tools: []config.ToolConfig{
+			{
+				Name:            "nano-clock",
+				Command:         "date",
+				Enabled:         true,
+				CacheTTLSeconds: 60,
+				Parameters: []config.ParameterConfig{
+					{Name: "format", Type: "string", Required: true, Position: &pos, Format: "positional"},
+				},
+			},
+		},
+	}
+	executor := NewExecutor(cfg, mcpServer, logger)
+
+	args := map[string]interface{}{"format": "+%s%N"}
+	first, err := executor.ExecuteTool(context.Background(), "nano-clock", args)
+	if err != nil {
+		t.Fatalf("first ExecuteTool: %v", err)
+	}
+	second, err := executor.ExecuteTool(context.Background(), "nano-clock", args)
+	if err != nil {
+		t.Fatalf("second ExecuteTool: %v", err)
+	}
+
+	if first.Content[0].Text != second.Content[0].Text {
+		t.Fatalf("cached result should replay first output: %q vs %q", first.Content[0].Text, second.Content[0].Text)
+	}
+	marker := second.Content[len(second.Content)-1].Text
+	if !strings.Contains(marker, "缓存") {
+		t.Fatalf("cache hit should be annotated, got %q", marker)
+	}
+
+	// 参数不同则不会命中缓存
+	third, err := executor.ExecuteTool(context.Background(), "nano-clock", map[string]interface{}{"format": "+other-%s%N"})
+	if err != nil {
+		t.Fatalf("third ExecuteTool: %v", err)
+	}
+	if strings.Contains(third.Content[len(third.Content)-1].Text, "缓存") {
+		t.Fatal("different args must not hit the cache")
+	}
+}
+
+func TestExecuteToolWithoutTTLNeverCaches(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("依赖 date 命令")
+	}
+	logger := zap.NewNop()
+	mcpServer := mcp.NewServer(logger)
+	pos := 0
+	cfg := &config.SecurityConfig{
+		Tools: []config.ToolConfig{
+			{
+				Name:    "nano-clock",
+				Command: "date",
+				Enabled: true,
+				Parameters: []config.ParameterConfig{
+					{Name: "format", Type: "string", Required: true, Position: &pos, Format: "positional"},
+				},
+			},
+		},
+	}
+	executor := NewExecutor(cfg, mcpServer, logger)
+
+	args := map[string]interface{}{"format": "+%s%N"}
+	first, err := executor.ExecuteTool(context.Background(), "nano-clock", args)
+	if err != nil {
+		t.Fatalf("first ExecuteTool: %v", err)
+	}
+	second, err := executor.ExecuteTool(context.Background(), "nano-clock", args)
+	if err != nil {
+		t.Fatalf("second ExecuteTool: %v", err)
+	}
+	if first.Content[0].Text == second.Content[0].Text {
+		t.Fatal("without cache_ttl_seconds the tool must run every time")
+	}
+}
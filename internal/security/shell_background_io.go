@@ -109,3 +109,9 @@ func RedirectBackgroundJobStdio(command string) string {
 func PrepareShellCommandForExecute(shellCommand string) string {
 	return RedirectBackgroundJobStdio(PrepareNonInteractiveShellCommand(shellCommand))
 }
+
+// PrepareShellCommandForExecuteKeepStdin 与 PrepareShellCommandForExecute 相同，但保留
+// 标准输入通道（不注入 exec </dev/null），供 exec 工具的 stdin/auto_answers 参数喂数据。
+func PrepareShellCommandForExecuteKeepStdin(shellCommand string) string {
+	return RedirectBackgroundJobStdio(PrependNonInteractiveShellExports(shellCommand))
+}
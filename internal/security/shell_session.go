@@ -20,6 +20,8 @@ func StartShellSession(cmd *exec.Cmd) (*ShellSession, error) {
 	if cmd.Process != nil {
 		pid = cmd.Process.Pid
 	}
+	// 可选 CPU 时间上限（agent.tool_max_cpu_seconds，Linux RLIMIT_CPU）
+	applyToolCPULimit(pid, resolveToolMaxCPUSeconds())
 	return &ShellSession{Cmd: cmd, rootPID: pid}, nil
 }
 
@@ -0,0 +1,146 @@
+package security
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// exec 工具的标准输入支持：stdin 参数一次性写入子进程标准输入后关闭；
+// auto_answers 参数为交互式命令提供自动应答（输出中出现提示词时写入预设答案），
+// 避免进程挂在等待输入上。两者任一存在时不再注入 exec </dev/null（见
+// PrepareShellCommandForExecuteKeepStdin）。
+
+// promptAutoResponderWindowBytes 输出尾部滑动窗口大小，用于匹配跨 chunk 到达的提示词。
+const promptAutoResponderWindowBytes = 2048
+
+// execAutoAnswer 单条自动应答规则：输出中出现 prompt 子串时向 stdin 写入 answer。
+type execAutoAnswer struct {
+	prompt string
+	answer string
+}
+
+// parseExecAutoAnswers 解析 exec 工具的 auto_answers 参数。
+// 支持对象形式 {"提示词": "应答"}，以及数组形式 [{"prompt": "...", "answer": "..."}]（保序）。
+func parseExecAutoAnswers(raw interface{}) []execAutoAnswer {
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		prompts := make([]string, 0, len(v))
+		for p := range v {
+			if strings.TrimSpace(p) != "" {
+				prompts = append(prompts, p)
+			}
+		}
+		// map 遍历无序，按提示词排序保证行为可复现
+		sort.Strings(prompts)
+		answers := make([]execAutoAnswer, 0, len(prompts))
+		for _, p := range prompts {
+			answer, _ := v[p].(string)
+			answers = append(answers, execAutoAnswer{prompt: p, answer: answer})
+		}
+		return answers
+	case []interface{}:
+		var answers []execAutoAnswer
+		for _, item := range v {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			p, _ := m["prompt"].(string)
+			if strings.TrimSpace(p) == "" {
+				continue
+			}
+			answer, _ := m["answer"].(string)
+			answers = append(answers, execAutoAnswer{prompt: p, answer: answer})
+		}
+		return answers
+	}
+	return nil
+}
+
+// promptAutoResponder 为 exec 工具保活 stdin：先写入预置内容，再根据输出中的提示词自动应答。
+// stdin 使用 os.Pipe 的读端直接交给子进程（*os.File 不经过 exec 的拷贝 goroutine，
+// 管道保持打开也不会阻塞 cmd.Wait）。
+type promptAutoResponder struct {
+	pr     *os.File
+	pw     *os.File
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	window  string
+	answers []execAutoAnswer
+	closed  bool
+}
+
+// newPromptAutoResponder 创建自动应答器；initial 非空时异步预写入（可能超过管道缓冲区，
+// 同步写会阻塞，Close 会中断未完成的写入）。
+func newPromptAutoResponder(initial string, answers []execAutoAnswer, logger *zap.Logger) (*promptAutoResponder, error) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	r := &promptAutoResponder{pr: pr, pw: pw, answers: answers, logger: logger}
+	if initial != "" {
+		go func() { _, _ = pw.WriteString(initial) }()
+	}
+	return r, nil
+}
+
+// stdin 返回交给 exec.Cmd.Stdin 的管道读端。
+func (r *promptAutoResponder) stdin() *os.File { return r.pr }
+
+// Observe 接收命令输出增量（未截断的原始 chunk），提示词命中时写入对应应答并自动补换行。
+func (r *promptAutoResponder) Observe(chunk string) {
+	if r == nil || chunk == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	window := r.window + chunk
+	matched := false
+	for _, rule := range r.answers {
+		if !strings.Contains(window, rule.prompt) {
+			continue
+		}
+		answer := rule.answer
+		if !strings.HasSuffix(answer, "\n") {
+			answer += "\n"
+		}
+		if _, err := r.pw.WriteString(answer); err == nil {
+			matched = true
+			if r.logger != nil {
+				r.logger.Info("exec 自动应答命中", zap.String("prompt", rule.prompt))
+			}
+		}
+	}
+	if matched {
+		// 已应答则清空窗口，避免同一提示在后续 chunk 中重复触发
+		r.window = ""
+		return
+	}
+	if len(window) > promptAutoResponderWindowBytes {
+		window = window[len(window)-promptAutoResponderWindowBytes:]
+	}
+	r.window = window
+}
+
+// Close 关闭管道两端；子进程此后读 stdin 得到 EOF。幂等。
+func (r *promptAutoResponder) Close() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	_ = r.pw.Close()
+	_ = r.pr.Close()
+}
@@ -0,0 +1,99 @@
+package security
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExecAutoAnswers(t *testing.T) {
+	// 对象形式：按提示词排序
+	answers := parseExecAutoAnswers(map[string]interface{}{
+		"b-prompt": "no",
+		"a-prompt": "yes",
+	})
+	if len(answers) != 2 || answers[0].prompt != "a-prompt" || answers[0].answer != "yes" {
+		t.Fatalf("unexpected map-form answers: %+v", answers)
+	}
+
+	// 数组形式：保序，忽略缺少 prompt 的条目
+	answers = parseExecAutoAnswers([]interface{}{
+		map[string]interface{}{"prompt": "Continue?", "answer": "y"},
+		map[string]interface{}{"answer": "orphan"},
+		map[string]interface{}{"prompt": "password:", "answer": "secret"},
+	})
+	if len(answers) != 2 || answers[0].prompt != "Continue?" || answers[1].answer != "secret" {
+		t.Fatalf("unexpected array-form answers: %+v", answers)
+	}
+
+	if got := parseExecAutoAnswers("not-a-map"); got != nil {
+		t.Fatalf("invalid input should yield nil, got %+v", got)
+	}
+}
+
+func TestPromptAutoResponder_AnswersPromptAcrossChunks(t *testing.T) {
+	r, err := newPromptAutoResponder("", []execAutoAnswer{{prompt: "Continue? [y/N]", answer: "y"}}, nil)
+	if err != nil {
+		t.Fatalf("newPromptAutoResponder: %v", err)
+	}
+	defer r.Close()
+
+	// 提示词跨两个 chunk 到达，靠滑动窗口拼接命中
+	r.Observe("Continue? [y")
+	r.Observe("/N] ")
+
+	buf := make([]byte, 16)
+	n, err := r.stdin().Read(buf)
+	if err != nil {
+		t.Fatalf("read answer: %v", err)
+	}
+	if got := string(buf[:n]); got != "y\n" {
+		t.Fatalf("answer = %q, want %q", got, "y\n")
+	}
+	if r.window != "" {
+		t.Fatalf("window should reset after answering, got %q", r.window)
+	}
+}
+
+func TestExecuteSystemCommand_StdinFedToCommand(t *testing.T) {
+	executor, _ := setupTestExecutor(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := executor.executeSystemCommand(ctx, map[string]interface{}{
+		"command": "sort -n",
+		"shell":   "sh",
+		"stdin":   "3\n1\n2\n",
+	})
+	if err != nil {
+		t.Fatalf("executeSystemCommand: %v", err)
+	}
+	if res == nil || res.IsError {
+		t.Fatalf("expected success, got %+v", res)
+	}
+	if txt := res.Content[0].Text; !strings.Contains(txt, "1\n2\n3") {
+		t.Fatalf("stdin should reach the command, got %q", txt)
+	}
+}
+
+func TestExecuteSystemCommand_AutoAnswerUnblocksPrompt(t *testing.T) {
+	executor, _ := setupTestExecutor(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := executor.executeSystemCommand(ctx, map[string]interface{}{
+		"command": `printf 'Continue? [y/N] '; read ans; echo "got=$ans"`,
+		"shell":   "sh",
+		"auto_answers": map[string]interface{}{
+			"Continue? [y/N]": "y",
+		},
+	})
+	if err != nil {
+		t.Fatalf("executeSystemCommand: %v", err)
+	}
+	if res == nil || res.IsError {
+		t.Fatalf("expected success, got %+v", res)
+	}
+	if txt := res.Content[0].Text; !strings.Contains(txt, "got=y") {
+		t.Fatalf("auto answer should unblock the prompt, got %q", txt)
+	}
+}
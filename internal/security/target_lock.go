@@ -0,0 +1,76 @@
+package security
+
+import (
+	"context"
+	"sync"
+
+	"cyberstrike-ai/internal/database"
+)
+
+// 同目标并发串行化：同一轮里 LLM 可能对同一目标并发发起多个工具，互相干扰
+// 或触发目标防护（WAF 封禁、连接数限制）。执行前按参数中归一化出的目标主机
+// 加锁，同一主机的调用排队执行，不同主机才真正并行。
+
+// targetLockRegistry 按归一化目标主机管理执行锁；空闲锁随引用计数归零回收。
+type targetLockRegistry struct {
+	mu    sync.Mutex
+	locks map[string]*targetLock
+}
+
+// targetLock 单个目标主机的执行锁；ch 容量 1，持锁即占位，便于与 ctx 取消配合。
+type targetLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+func newTargetLockRegistry() *targetLockRegistry {
+	return &targetLockRegistry{locks: make(map[string]*targetLock)}
+}
+
+// lockTargets 依次锁住 args 中提取出的全部目标主机，返回锁住的主机与释放函数。
+// 提取结果已按字典序排序，多目标调用的加锁顺序全局一致，不会互相死锁；
+// 没有可识别目标时返回 no-op 释放函数。等待期间 ctx 取消则回滚已获取的锁并返回错误。
+func (r *targetLockRegistry) lockTargets(ctx context.Context, args map[string]interface{}) ([]string, func(), error) {
+	hosts := database.ExtractExecutionTargetHosts(args)
+	if r == nil || len(hosts) == 0 {
+		return nil, func() {}, nil
+	}
+	acquired := 0
+	release := func() {
+		for i := acquired - 1; i >= 0; i-- {
+			r.mu.Lock()
+			l := r.locks[hosts[i]]
+			<-l.ch
+			l.refs--
+			if l.refs == 0 {
+				delete(r.locks, hosts[i])
+			}
+			r.mu.Unlock()
+		}
+	}
+	for _, host := range hosts {
+		r.mu.Lock()
+		l := r.locks[host]
+		if l == nil {
+			l = &targetLock{ch: make(chan struct{}, 1)}
+			r.locks[host] = l
+		}
+		l.refs++
+		r.mu.Unlock()
+
+		select {
+		case l.ch <- struct{}{}:
+			acquired++
+		case <-ctx.Done():
+			r.mu.Lock()
+			l.refs--
+			if l.refs == 0 {
+				delete(r.locks, host)
+			}
+			r.mu.Unlock()
+			release()
+			return nil, nil, ctx.Err()
+		}
+	}
+	return hosts, release, nil
+}
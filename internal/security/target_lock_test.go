@@ -0,0 +1,81 @@
+package security
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLockTargetsNoRecognizableTarget(t *testing.T) {
+	r := newTargetLockRegistry()
+	hosts, release, err := r.lockTargets(context.Background(), map[string]interface{}{"wordlist": "common.txt"})
+	if err != nil || len(hosts) != 0 {
+		t.Fatalf("无目标参数应为 no-op: %v, %v", hosts, err)
+	}
+	release()
+	if len(r.locks) != 0 {
+		t.Fatalf("registry 不应残留锁: %d", len(r.locks))
+	}
+}
+
+func TestLockTargetsSerializesSameHost(t *testing.T) {
+	r := newTargetLockRegistry()
+	args := map[string]interface{}{"target": "https://10.0.0.5:8080/admin"}
+
+	hosts, release, err := r.lockTargets(context.Background(), args)
+	if err != nil || len(hosts) != 1 || hosts[0] != "10.0.0.5" {
+		t.Fatalf("lockTargets = %v, %v", hosts, err)
+	}
+
+	second := make(chan struct{})
+	go func() {
+		_, rel, err := r.lockTargets(context.Background(), map[string]interface{}{"host": "10.0.0.5"})
+		if err != nil {
+			t.Errorf("第二次加锁失败: %v", err)
+		}
+		rel()
+		close(second)
+	}()
+
+	select {
+	case <-second:
+		t.Fatal("同一主机的第二次加锁不应在释放前完成")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// 不同主机不受影响，立即可得
+	otherHosts, otherRelease, err := r.lockTargets(context.Background(), map[string]interface{}{"target": "10.0.0.6"})
+	if err != nil || len(otherHosts) != 1 {
+		t.Fatalf("不同主机应并行: %v, %v", otherHosts, err)
+	}
+	otherRelease()
+
+	release()
+	select {
+	case <-second:
+	case <-time.After(time.Second):
+		t.Fatal("释放后第二次加锁应完成")
+	}
+	if len(r.locks) != 0 {
+		t.Fatalf("全部释放后 registry 应为空: %d", len(r.locks))
+	}
+}
+
+func TestLockTargetsRespectsContextCancel(t *testing.T) {
+	r := newTargetLockRegistry()
+	_, release, err := r.lockTargets(context.Background(), map[string]interface{}{"target": "10.0.0.5"})
+	if err != nil {
+		t.Fatalf("首次加锁失败: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, _, err := r.lockTargets(ctx, map[string]interface{}{"target": "10.0.0.5"}); err == nil {
+		t.Fatal("ctx 取消时等待加锁应返回错误")
+	}
+
+	release()
+	if len(r.locks) != 0 {
+		t.Fatalf("取消与释放后 registry 应为空: %d", len(r.locks))
+	}
+}
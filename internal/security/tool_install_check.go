@@ -0,0 +1,44 @@
+package security
+
+import (
+	"os/exec"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"cyberstrike-ai/internal/config"
+)
+
+// 工具安装检查：dalfox 等外部工具未安装时，执行只会抛出难懂的 exit status。
+// 启动时对启用工具的 Command 做 exec.LookPath 检查并告警，调用时直接返回
+// "未安装" 的明确提示，/api/tools 里也一并标注。
+
+// ToolCommandInstalled 判断工具命令是否可执行：
+// internal: 前缀的内部工具、空命令视为已安装，其余按 PATH 查找。
+func ToolCommandInstalled(command string) bool {
+	command = strings.TrimSpace(command)
+	if command == "" || strings.HasPrefix(command, "internal:") {
+		return true
+	}
+	_, err := exec.LookPath(command)
+	return err == nil
+}
+
+// LogMissingTools 检查启用工具的命令是否都在 PATH 中，缺失的逐个告警并返回工具名列表。
+// 供服务启动时调用，便于在部署阶段就发现环境缺工具。
+func LogMissingTools(tools []config.ToolConfig, logger *zap.Logger) []string {
+	var missing []string
+	for _, tool := range tools {
+		if !tool.Enabled || ToolCommandInstalled(tool.Command) {
+			continue
+		}
+		missing = append(missing, tool.Name)
+		if logger != nil {
+			logger.Warn("已启用的工具未安装（命令不在 PATH 中）",
+				zap.String("tool", tool.Name),
+				zap.String("command", tool.Command),
+			)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,36 @@
+package security
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+
+	"cyberstrike-ai/internal/config"
+)
+
+func TestToolCommandInstalled(t *testing.T) {
+	if !ToolCommandInstalled("internal:http_request") {
+		t.Error("internal: 工具应视为已安装")
+	}
+	if !ToolCommandInstalled("") {
+		t.Error("空命令应视为已安装")
+	}
+	if !ToolCommandInstalled("sh") {
+		t.Error("sh 应在 PATH 中")
+	}
+	if ToolCommandInstalled("definitely-not-installed-tool-xyz") {
+		t.Error("不存在的命令不应视为已安装")
+	}
+}
+
+func TestLogMissingTools(t *testing.T) {
+	tools := []config.ToolConfig{
+		{Name: "sh_tool", Command: "sh", Enabled: true},
+		{Name: "missing_enabled", Command: "definitely-not-installed-tool-xyz", Enabled: true},
+		{Name: "missing_disabled", Command: "definitely-not-installed-tool-xyz", Enabled: false},
+	}
+	missing := LogMissingTools(tools, zap.NewNop())
+	if len(missing) != 1 || missing[0] != "missing_enabled" {
+		t.Errorf("missing = %v, want [missing_enabled]", missing)
+	}
+}
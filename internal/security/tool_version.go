@@ -0,0 +1,78 @@
+package security
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"cyberstrike-ai/internal/config"
+)
+
+// 工具版本适配：dalfox 等工具新旧版本 flag 不同（-u 在某些子命令下不被识别）。
+// ToolConfig 可声明 version_profiles，executor 首次执行前探测版本
+// （`command <version_args>`，默认 --version），按版本输出子串匹配 profile
+// 覆盖默认 args/parameters。
+
+// toolVersionProbeTimeout 版本探测命令的执行上限，防止异常工具挂住整个调用。
+const toolVersionProbeTimeout = 5 * time.Second
+
+// resolveToolConfigForVersion 按探测到的版本选择参数 profile；
+// 未配置 version_profiles 或版本未命中任何 profile 时原样返回。
+func (e *Executor) resolveToolConfigForVersion(ctx context.Context, toolConfig *config.ToolConfig) *config.ToolConfig {
+	if toolConfig == nil || len(toolConfig.VersionProfiles) == 0 {
+		return toolConfig
+	}
+	version := e.probeToolVersion(ctx, toolConfig)
+	if version == "" {
+		return toolConfig
+	}
+	for _, profile := range toolConfig.VersionProfiles {
+		match := strings.TrimSpace(profile.Match)
+		if match == "" || !strings.Contains(version, match) {
+			continue
+		}
+		resolved := *toolConfig
+		if len(profile.Args) > 0 {
+			resolved.Args = profile.Args
+		}
+		if len(profile.Parameters) > 0 {
+			resolved.Parameters = profile.Parameters
+		}
+		e.logger.Debug("按工具版本选择参数 profile",
+			zap.String("tool", toolConfig.Name),
+			zap.String("match", match),
+		)
+		return &resolved
+	}
+	return toolConfig
+}
+
+// probeToolVersion 运行版本探测命令并缓存输出（按 command+参数缓存，探测失败缓存空串避免反复重试）。
+func (e *Executor) probeToolVersion(ctx context.Context, toolConfig *config.ToolConfig) string {
+	versionArgs := toolConfig.VersionArgs
+	if len(versionArgs) == 0 {
+		versionArgs = []string{"--version"}
+	}
+	cacheKey := toolConfig.Command + " " + strings.Join(versionArgs, " ")
+	if cached, ok := e.versionCache.Load(cacheKey); ok {
+		return cached.(string)
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, toolVersionProbeTimeout)
+	defer cancel()
+	// 部分工具把版本信息打到 stderr，统一取合并输出
+	out, err := exec.CommandContext(probeCtx, toolConfig.Command, versionArgs...).CombinedOutput()
+	version := strings.TrimSpace(string(out))
+	if err != nil && version == "" {
+		e.logger.Warn("工具版本探测失败",
+			zap.String("tool", toolConfig.Name),
+			zap.String("command", toolConfig.Command),
+			zap.Error(err),
+		)
+	}
+	e.versionCache.Store(cacheKey, version)
+	return version
+}
@@ -0,0 +1,66 @@
+package security
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"cyberstrike-ai/internal/config"
+)
+
+func TestResolveToolConfigForVersion(t *testing.T) {
+	e := NewExecutor(&config.SecurityConfig{}, nil, zap.NewNop())
+	// 用 echo 模拟版本探测输出
+	tool := &config.ToolConfig{
+		Name:        "fake_dalfox",
+		Command:     "echo",
+		Args:        []string{"url"},
+		VersionArgs: []string{"fake_dalfox v2.9.0"},
+		VersionProfiles: []config.ToolVersionProfile{
+			{Match: "v1.", Args: []string{"scan", "-u"}},
+			{Match: "v2.", Args: []string{"url"}, Parameters: []config.ParameterConfig{{Name: "target", Type: "string", Format: "positional"}}},
+		},
+	}
+
+	resolved := e.resolveToolConfigForVersion(context.Background(), tool)
+	if resolved == tool {
+		t.Fatal("命中 profile 时应返回覆盖后的副本")
+	}
+	if len(resolved.Parameters) != 1 || resolved.Parameters[0].Name != "target" {
+		t.Errorf("parameters 未按 v2 profile 覆盖: %+v", resolved.Parameters)
+	}
+	// 原配置不受影响
+	if len(tool.Parameters) != 0 {
+		t.Error("不应修改原始 ToolConfig")
+	}
+
+	// 版本输出未命中任何 profile 时原样返回
+	tool2 := &config.ToolConfig{
+		Name:            "fake_old",
+		Command:         "echo",
+		VersionArgs:     []string{"v0.5"},
+		VersionProfiles: []config.ToolVersionProfile{{Match: "v9.", Args: []string{"new-flag"}}},
+	}
+	if got := e.resolveToolConfigForVersion(context.Background(), tool2); got != tool2 {
+		t.Error("未命中 profile 时应返回原配置")
+	}
+
+	// 未配置 profiles 时不做探测
+	tool3 := &config.ToolConfig{Name: "plain", Command: "definitely-not-installed-tool-xyz"}
+	if got := e.resolveToolConfigForVersion(context.Background(), tool3); got != tool3 {
+		t.Error("无 profiles 时应原样返回")
+	}
+}
+
+func TestProbeToolVersionCaches(t *testing.T) {
+	e := NewExecutor(&config.SecurityConfig{}, nil, zap.NewNop())
+	tool := &config.ToolConfig{Name: "fake", Command: "echo", VersionArgs: []string{"v1.2.3"}}
+	if got := e.probeToolVersion(context.Background(), tool); got != "v1.2.3" {
+		t.Fatalf("version = %q, want v1.2.3", got)
+	}
+	// 第二次走缓存（即使命令换成不存在的也应返回缓存值）
+	if _, ok := e.versionCache.Load("echo v1.2.3"); !ok {
+		t.Error("探测结果未缓存")
+	}
+}